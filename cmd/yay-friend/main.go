@@ -15,11 +15,24 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	// Cancellation kills in-flight commands, and deferred cleanup (temp dirs,
+	// the progress line) runs as the call stack unwinds. A prompt waiting on
+	// stdin never notices, so a second signal exits at once. The first one
+	// never does: yay may be in the middle of a pacman transaction.
+	go func() {
+		<-ctx.Done()
+		again := make(chan os.Signal, 1)
+		signal.Notify(again, syscall.SIGINT, syscall.SIGTERM)
+		<-again
+		fmt.Fprintln(os.Stderr)
+		os.Exit(cmd.ReportError(os.Stderr, cmd.ErrInterrupted))
+	}()
+
 	// Handle the yay-style interface directly
 	if len(os.Args) > 1 {
 		firstArg := os.Args[1]
 		// Known subcommands that should use cobra
-		knownCommands := []string{"analyze", "config", "provider", "cache", "version", "help", "completion", "--help", "-h", "--version"}
+		knownCommands := []string{"analyze", "explain", "note", "config", "provider", "cache", "version", "help", "completion", "--help", "-h", "--version"}
 		
 		isKnownCommand := false
 		for _, cmdName := range knownCommands {
@@ -33,8 +46,7 @@ func main() {
 		if !isKnownCommand {
 			// This is a yay-style command (packages, -S packages, etc.)
 			if err := handleYayStyleCommand(ctx, os.Args[1:]); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				exitWithError(ctx, err)
 			}
 			return
 		}
@@ -42,9 +54,18 @@ func main() {
 
 	// Execute the cobra command for subcommands
 	if err := cmd.Execute(ctx); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		exitWithError(ctx, err)
+	}
+}
+
+// exitWithError reports err and exits with its exit code. After an interrupt
+// the error is whatever the cancellation broke, so a plain "Interrupted." is
+// reported instead.
+func exitWithError(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		err = cmd.ErrInterrupted
 	}
+	os.Exit(cmd.ReportError(os.Stderr, err))
 }
 
 // handleYayStyleCommand handles yay-style commands directly
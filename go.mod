@@ -5,6 +5,7 @@ go 1.23
 require (
 	github.com/gookit/color v1.5.4
 	github.com/spf13/cobra v1.8.0
+	golang.org/x/term v0.15.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -0,0 +1,82 @@
+package aur
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// IsPackageArchive reports whether path names an archive ExtractArchive can
+// unpack, judged by its extension.
+func IsPackageArchive(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") || strings.HasSuffix(lower, ".zip")
+}
+
+// ExtractArchive unpacks a local package archive (.tar.gz, .tgz or .zip) under
+// destDir and returns the directory holding its PKGBUILD. The same limits as
+// snapshots apply: only regular files and directories are written, entries may
+// not escape destDir, and sizes are bounded.
+func ExtractArchive(path, destDir string) (string, error) {
+	if strings.HasSuffix(strings.ToLower(path), ".zip") {
+		if err := extractZip(path, destDir); err != nil {
+			return "", err
+		}
+		return findPKGBUILDDir(destDir)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+	if err := extractTarGz(io.LimitReader(f, maxSnapshotSize), destDir); err != nil {
+		return "", err
+	}
+	return findPKGBUILDDir(destDir)
+}
+
+// extractZip extracts regular files and directories from a zip archive into
+// destDir, rejecting entries that would land outside it.
+func extractZip(path, destDir string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("archive is not a zip file: %w", err)
+	}
+	defer zr.Close()
+
+	var total uint64
+	for _, entry := range zr.File {
+		target, err := entryTarget(destDir, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := entry.FileInfo().Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", target, err)
+			}
+		case mode.IsRegular():
+			if entry.UncompressedSize64 > maxSnapshotFileSize {
+				return fmt.Errorf("archive entry %q is too large (%d bytes)", entry.Name, entry.UncompressedSize64)
+			}
+			if total += entry.UncompressedSize64; total > maxSnapshotSize {
+				return fmt.Errorf("archive expands to more than %d bytes", maxSnapshotSize)
+			}
+			rc, err := entry.Open()
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", entry.Name, err)
+			}
+			err = writeEntry(target, entry.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package aur
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// DependencyName strips the version constraint from a depends=() entry:
+// "foo>=1.2" becomes "foo".
+func DependencyName(dep string) string {
+	if i := strings.IndexAny(dep, "<>="); i >= 0 {
+		dep = dep[:i]
+	}
+	return strings.TrimSpace(dep)
+}
+
+// AURDependencies returns the entries of depends that are AUR packages, by
+// name without version constraints, deduplicated and in their original order.
+// It asks the RPC API about all of them in batched requests; names it doesn't
+// know are official packages or virtual provides, and are left out.
+func (f *AURFetcher) AURDependencies(ctx context.Context, depends []string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, dep := range depends {
+		name := DependencyName(dep)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if err := f.PrefetchMetadata(ctx, names); err != nil {
+		return nil, err
+	}
+
+	var found []string
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range names {
+		if f.metadata[name] != nil {
+			found = append(found, name)
+		}
+	}
+	return found, nil
+}
+
+// PrefetchMetadata fetches the AUR metadata of names with multi-info requests
+// of up to rpcBatchSize names each, so that enriching each package afterwards
+// costs no further RPC call. A multi-package run makes one request instead of
+// one per package, which keeps it under the AUR's rate limit. Names already
+// fetched are skipped.
+func (f *AURFetcher) PrefetchMetadata(ctx context.Context, names []string) error {
+	var missing []string
+	f.mu.Lock()
+	for _, name := range names {
+		if _, fetched := f.metadata[name]; !fetched {
+			missing = append(missing, name)
+		}
+	}
+	f.mu.Unlock()
+
+	for start := 0; start < len(missing); start += rpcBatchSize {
+		batch := missing[start:min(start+rpcBatchSize, len(missing))]
+		query := url.Values{}
+		for _, name := range batch {
+			query.Add("arg[]", name)
+		}
+		aurResp, err := f.rpcGet(ctx, f.rpcBase+"/info?"+query.Encode(), "fetch AUR metadata")
+		if err != nil {
+			return err
+		}
+
+		f.mu.Lock()
+		for _, name := range batch {
+			f.metadata[name] = nil
+		}
+		for i := range aurResp.Results {
+			f.metadata[aurResp.Results[i].Name] = &aurResp.Results[i]
+		}
+		f.mu.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package aur
+
+import "testing"
+
+func TestDependencyName(t *testing.T) {
+	tests := map[string]string{
+		"foo":           "foo",
+		"foo>=1.2":      "foo",
+		"foo<2":         "foo",
+		"foo=1.0-1":     "foo",
+		"lib32-bar>1":   "lib32-bar",
+		" spaced ":      "spaced",
+		"python-x<=3.1": "python-x",
+	}
+	for dep, expected := range tests {
+		if result := DependencyName(dep); result != expected {
+			t.Errorf("DependencyName(%q) = %q, expected %q", dep, result, expected)
+		}
+	}
+}
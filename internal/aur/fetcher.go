@@ -2,26 +2,40 @@ package aur
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
+	"github.com/aaronsb/yay-friend/internal/netclient"
 	"github.com/aaronsb/yay-friend/internal/types"
 )
 
-// AURFetcher handles fetching additional AUR context
+// ErrNetwork marks a failure to reach the AUR (RPC, git, or snapshot
+// download), as opposed to a bad answer from it, for errors.Is.
+var ErrNetwork = errors.New("network error")
+
+// AURFetcher handles fetching additional AUR context. One fetcher serves a
+// whole run, including concurrent calls: it remembers the metadata fetched in
+// batches (PrefetchMetadata, AURDependencies) so per-package lookups don't
+// repeat it, and it backs off as a whole when the AUR rate-limits it.
 type AURFetcher struct {
-	client *http.Client
+	client  *http.Client
+	rpcBase string
+
+	mu        sync.Mutex
+	heldUntil time.Time                  // no requests before this (rate-limit back-off)
+	metadata  map[string]*AURPackageInfo // batch-fetched metadata; nil for names not in the AUR
 }
 
 // NewAURFetcher creates a new AUR context fetcher
 func NewAURFetcher() *AURFetcher {
 	return &AURFetcher{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client:   netclient.New(10 * time.Second),
+		rpcBase:  "https://aur.archlinux.org/rpc/v5",
+		metadata: make(map[string]*AURPackageInfo),
 	}
 }
 
@@ -56,60 +70,114 @@ type AURResponse struct {
 	Results     []AURPackageInfo  `json:"results"`
 }
 
+// EnrichmentStatus records which parts of the AUR context EnrichPackageInfo
+// obtained, so callers can show what an analysis is missing. (AUR comments
+// aren't part of the RPC API and are never fetched, so they have no entry.)
+type EnrichmentStatus struct {
+	MetadataErr      error // RPC lookup failed: no votes, popularity, dates, or dependencies
+	CommitErr        error // git lookup failed: CommitHash is empty and nothing is cached
+	InstallScriptErr error // the declared install script couldn't be fetched
+}
+
+// Complete reports whether the metadata, the commit hash, and any declared
+// install script were obtained.
+func (s EnrichmentStatus) Complete() bool {
+	return s.MetadataErr == nil && s.CommitErr == nil && s.InstallScriptErr == nil
+}
+
+// Completeness records which inputs an analysis of pkgInfo had, given this
+// status and whether the provider saw only part of the PKGBUILD. An install
+// script counts as available when none is declared; it is only fetched after
+// a successful metadata lookup, so it counts as missing without one.
+func (s EnrichmentStatus) Completeness(pkgInfo types.PackageInfo, truncated bool) *types.ContextCompleteness {
+	return &types.ContextCompleteness{
+		FullPKGBUILD:  !truncated,
+		AURMetadata:   s.MetadataErr == nil,
+		InstallScript: pkgInfo.InstallScript != "" || InstallScriptName(pkgInfo.PKGBUILD, pkgInfo.Name, pkgInfo.Base()) == "",
+		CommitHash:    s.CommitErr == nil && pkgInfo.CommitHash != "",
+	}
+}
+
 // EnrichPackageInfo fetches additional AUR context using the official RPC API
-func (f *AURFetcher) EnrichPackageInfo(ctx context.Context, pkgInfo *types.PackageInfo) error {
+// and the package's latest AUR commit. Either lookup may fail on its own (a
+// package from the official repos has neither); the returned status says which
+// did. CommitHash is only set to a real commit, never a placeholder, so callers
+// can key the cache on it. A PackageBase the caller already set (an explicit
+// --package-base) is kept rather than replaced by the AUR's answer.
+func (f *AURFetcher) EnrichPackageInfo(ctx context.Context, pkgInfo *types.PackageInfo) EnrichmentStatus {
+	var status EnrichmentStatus
+
 	// Build AUR package page URL for reference
 	pkgInfo.AURPageURL = fmt.Sprintf("https://aur.archlinux.org/packages/%s", pkgInfo.Name)
 	
+	// Fetch AUR metadata using RPC API first: split packages live in a git
+	// repository named after their package base, which the commit lookup needs.
+	aurData, metaErr := f.fetchAURMetadata(ctx, pkgInfo.Name)
+	if metaErr == nil && aurData.PackageBase != "" && pkgInfo.PackageBase == "" {
+		pkgInfo.PackageBase = aurData.PackageBase
+	}
+	status.MetadataErr = metaErr
+	
 	// Try to fetch git commit hash for AUR packages
-	commitHash, err := GetLatestCommitHash(ctx, pkgInfo.Name)
+	commitHash, err := GetLatestCommitHash(ctx, pkgInfo.Base())
 	if err != nil {
-		// This is likely not an AUR package (could be from official repos)
-		// Set a fallback hash based on package name and version for basic caching
-		pkgInfo.CommitHash = fmt.Sprintf("fallback-%s-%s", pkgInfo.Name, pkgInfo.Version)
+		pkgInfo.CommitHash = ""
+		status.CommitErr = err
 	} else {
 		pkgInfo.CommitHash = commitHash
 	}
 	
-	// Fetch AUR metadata using RPC API (only for AUR packages)
-	aurData, err := f.fetchAURMetadata(ctx, pkgInfo.Name)
-	if err != nil {
-		// This is likely not an AUR package (could be from official repos)
-		// Don't show warning for official packages, just skip AUR enrichment
-		return nil
+	// Only AUR packages have metadata to enrich with
+	if metaErr == nil {
+		f.enrichFromAURData(aurData, pkgInfo)
+	}
+
+	// `yay -G --print` only returns the PKGBUILD; the install script it
+	// declares runs as root, so fetch it from the same commit.
+	if pkgInfo.InstallScript == "" && metaErr == nil {
+		if name := InstallScriptName(pkgInfo.PKGBUILD, pkgInfo.Name, pkgInfo.Base()); name != "" {
+			script, err := f.FetchInstallScript(ctx, pkgInfo.Base(), pkgInfo.CommitHash, name)
+			if err != nil {
+				status.InstallScriptErr = err
+			} else {
+				pkgInfo.InstallScript = script
+			}
+		}
 	}
 	
-	// Enrich package info with AUR data
-	f.enrichFromAURData(aurData, pkgInfo)
-	
-	return nil
+	return status
 }
 
-// fetchAURMetadata fetches package metadata from AUR RPC API
-func (f *AURFetcher) fetchAURMetadata(ctx context.Context, packageName string) (*AURPackageInfo, error) {
-	// Build RPC API URL (v5 format)
-	rpcURL := fmt.Sprintf("https://aur.archlinux.org/rpc/v5/info/%s", url.QueryEscape(packageName))
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", rpcURL, nil)
+// ResolvePackageBase returns the AUR package base of packageName.
+func (f *AURFetcher) ResolvePackageBase(ctx context.Context, packageName string) (string, error) {
+	aurData, err := f.fetchAURMetadata(ctx, packageName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", err
 	}
-	
-	req.Header.Set("User-Agent", "yay-friend/1.0 (security analysis tool)")
-	
-	resp, err := f.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch AUR metadata: %w", err)
+	if aurData.PackageBase == "" {
+		return packageName, nil
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("AUR API returned status %d", resp.StatusCode)
+	return aurData.PackageBase, nil
+}
+
+// fetchAURMetadata fetches package metadata from AUR RPC API, unless a batch
+// request already did.
+func (f *AURFetcher) fetchAURMetadata(ctx context.Context, packageName string) (*AURPackageInfo, error) {
+	f.mu.Lock()
+	info, fetched := f.metadata[packageName]
+	f.mu.Unlock()
+	if fetched {
+		if info == nil {
+			return nil, fmt.Errorf("package not found in AUR")
+		}
+		return info, nil
 	}
-	
-	var aurResp AURResponse
-	if err := json.NewDecoder(resp.Body).Decode(&aurResp); err != nil {
-		return nil, fmt.Errorf("failed to decode AUR response: %w", err)
+
+	// Build RPC API URL (v5 format)
+	rpcURL := fmt.Sprintf("%s/info/%s", f.rpcBase, url.QueryEscape(packageName))
+	aurResp, err := f.rpcGet(ctx, rpcURL, "fetch AUR metadata")
+	if err != nil {
+		return nil, err
 	}
 	
 	if aurResp.ResultCount == 0 {
@@ -133,6 +201,14 @@ func (f *AURFetcher) enrichFromAURData(aurData *AURPackageInfo, pkgInfo *types.P
 	pkgInfo.Dependencies = aurData.Depends
 	pkgInfo.MakeDepends = aurData.MakeDepends
 	pkgInfo.OptDepends = aurData.OptDepends
+
+	// License and keywords for context; an empty License still marks the
+	// metadata as known to the license check
+	pkgInfo.License = aurData.License
+	if pkgInfo.License == nil {
+		pkgInfo.License = []string{}
+	}
+	pkgInfo.Keywords = aurData.Keywords
 	
 	// Note: Comments are not available via RPC API
 	// Instead, we'll use the structured data for trust analysis
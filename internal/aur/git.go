@@ -3,9 +3,14 @@ package aur
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/aaronsb/yay-friend/internal/types"
 )
 
 // GetLatestCommitHash fetches the latest commit hash from AUR git repository
@@ -22,7 +27,7 @@ func GetLatestCommitHash(ctx context.Context, packageName string) (string, error
 	
 	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch git commit hash for %s: %w", packageName, err)
+		return "", fmt.Errorf("%w: failed to fetch git commit hash for %s: %w", ErrNetwork, packageName, err)
 	}
 	
 	// Parse output: "commit_hash\tHEAD"
@@ -66,4 +71,94 @@ func ValidateCommitHash(hash string) bool {
 	}
 	
 	return true
-}
\ No newline at end of file
+}
+
+// ValidatePackageName checks that name is a plausible AUR package name:
+// lowercase letters, digits, and @._+-, not starting with a hyphen or dot.
+// Names are interpolated into git URLs, so anything else is refused.
+func ValidatePackageName(name string) bool {
+	if name == "" || len(name) > 255 || name[0] == '-' || name[0] == '.' {
+		return false
+	}
+
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') || strings.ContainsRune("@._+-", char)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CloneRepository clones the AUR git repository for packageName into dest,
+// replacing any clone previously kept there so the result always reflects the
+// current AUR state.
+func CloneRepository(ctx context.Context, packageName, dest string) error {
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("failed to remove previous clone at %s: %w", dest, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create clone directory: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "clone", "--quiet", GetAURGitURL(packageName), dest)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		// Don't leave a half-written clone behind for the user to trip over.
+		os.RemoveAll(dest)
+		return fmt.Errorf("%w: failed to clone %s: %w: %s", ErrNetwork, packageName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// gitLogFormat separates fields with the ASCII unit separator, which doesn't
+// occur in real author names; the subject comes last so it may contain anything.
+const gitLogFormat = "--format=%H%x1f%ct%x1f%an%x1f%s"
+
+// RecentCommits returns the last n commits of a package's AUR git repository,
+// newest first. It makes a shallow, blob-less clone into a temporary
+// directory, so only commit metadata is transferred, and removes it after.
+func RecentCommits(ctx context.Context, packageBase string, n int) ([]types.GitCommit, error) {
+	tempDir, err := os.MkdirTemp("", "yay-friend-gitlog-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cmdCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	clone := exec.CommandContext(cmdCtx, "git", "clone", "--quiet", "--bare", "--filter=blob:none",
+		"--depth", strconv.Itoa(n), GetAURGitURL(packageBase), tempDir)
+	if output, err := clone.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%w: failed to clone %s: %w: %s", ErrNetwork, packageBase, err, strings.TrimSpace(string(output)))
+	}
+
+	output, err := exec.CommandContext(cmdCtx, "git", "-C", tempDir, "log", gitLogFormat, "-n", strconv.Itoa(n)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git log for %s: %w", packageBase, err)
+	}
+	return parseGitLog(string(output)), nil
+}
+
+// parseGitLog parses `git log` output in gitLogFormat. Malformed lines are
+// skipped.
+func parseGitLog(output string) []types.GitCommit {
+	var commits []types.GitCommit
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		timestamp, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		commits = append(commits, types.GitCommit{
+			Hash:    fields[0],
+			Date:    time.Unix(timestamp, 0).UTC(),
+			Author:  fields[2],
+			Subject: strings.TrimSpace(fields[3]),
+		})
+	}
+	return commits
+}
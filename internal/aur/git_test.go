@@ -57,6 +57,33 @@ func TestValidateCommitHash(t *testing.T) {
 	}
 }
 
+func TestValidatePackageName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"yay", true},
+		{"python-foo", true},
+		{"lib32-gcc-libs", true},
+		{"gtk+3", true},
+		{"foo@bar_baz.1", true},
+
+		{"", false},
+		{"Yay", false},
+		{"-foo", false},
+		{".foo", false},
+		{"foo/bar", false},
+		{"../foo", false},
+		{"foo bar", false},
+	}
+
+	for _, test := range tests {
+		if result := ValidatePackageName(test.name); result != test.expected {
+			t.Errorf("ValidatePackageName(%q) = %v, expected %v", test.name, result, test.expected)
+		}
+	}
+}
+
 // Note: We skip testing GetLatestCommitHash because it requires network access
 // and external dependencies. In a full test suite, this would be tested with mocks
 // or in integration tests.
@@ -76,4 +103,25 @@ func TestGetLatestCommitHash_NotImplemented(t *testing.T) {
 	// if !ValidateCommitHash(commitHash) {
 	//     t.Errorf("GetLatestCommitHash returned invalid commit hash: %s", commitHash)
 	// }
-}
\ No newline at end of file
+}
+
+func TestParseGitLog(t *testing.T) {
+	output := "aaa111\x1f1700000000\x1fAlice\x1fUpdate to 1.2\n" +
+		"bbb222\x1f1600000000\x1fBob Smith\x1fInitial import\x1fwith a separator\n" +
+		"garbage line\n" +
+		"ccc333\x1fnot-a-time\x1fEve\x1fBroken\n"
+
+	commits := parseGitLog(output)
+	if len(commits) != 2 {
+		t.Fatalf("parseGitLog returned %d commits, want 2: %+v", len(commits), commits)
+	}
+	if commits[0].Hash != "aaa111" || commits[0].Author != "Alice" || commits[0].Subject != "Update to 1.2" {
+		t.Errorf("first commit = %+v", commits[0])
+	}
+	if got := commits[0].Date.Format("2006-01-02"); got != "2023-11-14" {
+		t.Errorf("first commit date = %s, want 2023-11-14", got)
+	}
+	if commits[1].Subject != "Initial import\x1fwith a separator" {
+		t.Errorf("subject containing the separator was split: %q", commits[1].Subject)
+	}
+}
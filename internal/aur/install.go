@@ -0,0 +1,72 @@
+package aur
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxInstallScriptSize bounds a fetched install script; real ones are a few
+// kilobytes.
+const maxInstallScriptSize = 1 << 20
+
+// installDeclRe matches a PKGBUILD's install= assignment.
+var installDeclRe = regexp.MustCompile(`(?m)^\s*install=["']?([^"'\s]+)["']?`)
+
+// InstallScriptName returns the install script a PKGBUILD declares, with
+// $pkgname and $pkgbase expanded, or "" when it declares none or the name
+// can't be resolved to a plain file name.
+func InstallScriptName(pkgbuild, pkgname, pkgbase string) string {
+	m := installDeclRe.FindStringSubmatch(pkgbuild)
+	if m == nil {
+		return ""
+	}
+	name := strings.NewReplacer(
+		"${pkgname}", pkgname, "$pkgname", pkgname,
+		"${pkgbase}", pkgbase, "$pkgbase", pkgbase,
+	).Replace(m[1])
+	if strings.ContainsAny(name, "$/") || name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
+
+// FetchInstallScript downloads a file from the package base's AUR git
+// repository at commit (or its HEAD when commit is empty) through the cgit
+// plain-file endpoint.
+func (f *AURFetcher) FetchInstallScript(ctx context.Context, packageBase, commit, name string) (string, error) {
+	query := url.Values{"h": {packageBase}}
+	if commit != "" {
+		query.Set("id", commit)
+	}
+	fileURL := fmt.Sprintf("https://aur.archlinux.org/cgit/aur.git/plain/%s?%s", url.PathEscape(name), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fileURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "yay-friend/1.0 (security analysis tool)")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to fetch %s: %w", ErrNetwork, name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: fetching %s returned status %d", ErrNetwork, name, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxInstallScriptSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(data) > maxInstallScriptSize {
+		return "", fmt.Errorf("%s is larger than %d bytes", name, maxInstallScriptSize)
+	}
+	return string(data), nil
+}
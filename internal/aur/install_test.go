@@ -0,0 +1,43 @@
+package aur
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestInstallScriptName(t *testing.T) {
+	tests := []struct {
+		pkgbuild string
+		expected string
+	}{
+		{"pkgname=foo\ninstall=foo.install\n", "foo.install"},
+		{"pkgname=foo\ninstall=\"$pkgname.install\"\n", "foo.install"},
+		{"pkgbase=foo-base\ninstall='${pkgbase}.install'\n", "foo-base.install"},
+		{"pkgname=foo\n", ""},
+		{"install=../../etc/passwd\n", ""},
+		{"install=$_unknown.install\n", ""},
+	}
+
+	for _, test := range tests {
+		if result := InstallScriptName(test.pkgbuild, "foo", "foo-base"); result != test.expected {
+			t.Errorf("InstallScriptName(%q) = %q, expected %q", test.pkgbuild, result, test.expected)
+		}
+	}
+}
+
+func TestEnrichmentCompletenessInstallScript(t *testing.T) {
+	pkg := types.PackageInfo{Name: "foo", CommitHash: "abc", PKGBUILD: "pkgname=foo\ninstall=foo.install\n"}
+	offline := EnrichmentStatus{MetadataErr: errors.New("offline")}
+
+	c := offline.Completeness(pkg, false)
+	if c.AURMetadata || c.InstallScript || !c.CommitHash || !c.FullPKGBUILD {
+		t.Errorf("offline with an unfetched install script = %+v", c)
+	}
+
+	pkg.PKGBUILD = "pkgname=foo\n"
+	if c := offline.Completeness(pkg, true); !c.InstallScript || c.FullPKGBUILD {
+		t.Errorf("no install script declared, truncated = %+v", c)
+	}
+}
@@ -0,0 +1,128 @@
+package aur
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrRateLimited marks an AUR RPC request refused with 429 Too Many Requests
+// after backing off, for errors.Is. It is always wrapped together with
+// ErrNetwork.
+var ErrRateLimited = errors.New("AUR rate limit exceeded")
+
+const (
+	// maxRateLimitRetries is how often a rate-limited request is retried.
+	maxRateLimitRetries = 3
+	// maxRateLimitWait caps a single back-off; a longer Retry-After gives up
+	// rather than stall the run.
+	maxRateLimitWait = time.Minute
+	// rpcBatchSize bounds the names in one multi-info request, keeping its
+	// URL well under the AUR's limit.
+	rpcBatchSize = 100
+)
+
+// rateLimitBackoff is the first back-off when a 429 carries no Retry-After;
+// it doubles on each retry. A variable so tests needn't wait.
+var rateLimitBackoff = time.Second
+
+// rpcGet requests an AUR RPC URL and decodes the response. A 429 is retried
+// after the Retry-After delay, or an exponential back-off without one, and
+// the delay also holds back every other request of this fetcher, so the run
+// as a whole slows down instead of hammering the AUR. what names the request
+// in errors.
+func (f *AURFetcher) rpcGet(ctx context.Context, rpcURL, what string) (*AURResponse, error) {
+	backoff := rateLimitBackoff
+	for attempt := 0; ; attempt++ {
+		if err := f.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", rpcURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("User-Agent", "yay-friend/1.0 (security analysis tool)")
+
+		resp, err := f.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to %s: %w", ErrNetwork, what, err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+			if !ok {
+				wait = backoff
+				backoff *= 2
+			}
+			if attempt == maxRateLimitRetries || wait > maxRateLimitWait {
+				return nil, fmt.Errorf("%w: %w (retry after %s)", ErrNetwork, ErrRateLimited, wait.Round(time.Second))
+			}
+			fmt.Printf("Warning: AUR rate-limited, backing off %s\n", wait.Round(100*time.Millisecond))
+			f.holdUntil(time.Now().Add(wait))
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%w: AUR API returned status %d", ErrNetwork, resp.StatusCode)
+		}
+		var aurResp AURResponse
+		if err := json.NewDecoder(resp.Body).Decode(&aurResp); err != nil {
+			return nil, fmt.Errorf("failed to decode AUR response: %w", err)
+		}
+		return &aurResp, nil
+	}
+}
+
+// holdUntil delays this fetcher's requests until t.
+func (f *AURFetcher) holdUntil(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t.After(f.heldUntil) {
+		f.heldUntil = t
+	}
+}
+
+// waitForRateLimit sleeps until a back-off set by holdUntil has passed.
+func (f *AURFetcher) waitForRateLimit(ctx context.Context) error {
+	f.mu.Lock()
+	wait := time.Until(f.heldUntil)
+	f.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// retryAfter parses a Retry-After header, either delay seconds or an HTTP
+// date, into the time to wait from now. ok is false when the header is absent
+// or malformed.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,141 @@
+package aur
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// rpcServer serves AUR RPC responses: the first limited requests get a 429
+// with retryAfter, the rest a result for every arg[] (and for the name in an
+// /info/<name> path) except "missing".
+func rpcServer(t *testing.T, limited int32, retryAfter string) (*AURFetcher, *int32) {
+	t.Helper()
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= limited {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		names := r.URL.Query()["arg[]"]
+		if len(names) == 0 {
+			names = []string{r.URL.Path[len("/info/"):]}
+		}
+		var results []string
+		for _, name := range names {
+			if name != "missing" {
+				results = append(results, fmt.Sprintf(`{"Name":%q,"PackageBase":%q}`, name, name))
+			}
+		}
+		fmt.Fprintf(w, `{"resultcount":%d,"results":[`, len(results))
+		for i, result := range results {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprint(w, result)
+		}
+		fmt.Fprint(w, "]}")
+	}))
+	t.Cleanup(srv.Close)
+
+	f := NewAURFetcher()
+	f.client, f.rpcBase = srv.Client(), srv.URL
+	return f, &requests
+}
+
+func TestRateLimitRetriesAfterDelay(t *testing.T) {
+	f, requests := rpcServer(t, 2, "0")
+
+	info, err := f.fetchAURMetadata(context.Background(), "foo")
+	if err != nil {
+		t.Fatalf("fetchAURMetadata: %v", err)
+	}
+	if info.Name != "foo" {
+		t.Errorf("Name = %q, want foo", info.Name)
+	}
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("made %d requests, want 3", got)
+	}
+}
+
+func TestRateLimitGivesUp(t *testing.T) {
+	defer func(backoff time.Duration) { rateLimitBackoff = backoff }(rateLimitBackoff)
+	rateLimitBackoff = time.Millisecond
+	f, requests := rpcServer(t, 100, "")
+
+	_, err := f.fetchAURMetadata(context.Background(), "foo")
+	if !errors.Is(err, ErrRateLimited) || !errors.Is(err, ErrNetwork) {
+		t.Fatalf("err = %v, want ErrRateLimited and ErrNetwork", err)
+	}
+	if got := atomic.LoadInt32(requests); got != maxRateLimitRetries+1 {
+		t.Errorf("made %d requests, want %d", got, maxRateLimitRetries+1)
+	}
+}
+
+func TestRateLimitLongRetryAfterGivesUp(t *testing.T) {
+	f, requests := rpcServer(t, 100, "3600")
+
+	if _, err := f.fetchAURMetadata(context.Background(), "foo"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("made %d requests, want 1", got)
+	}
+}
+
+func TestPrefetchMetadataCoalesces(t *testing.T) {
+	f, requests := rpcServer(t, 0, "")
+	ctx := context.Background()
+
+	if err := f.PrefetchMetadata(ctx, []string{"foo", "bar", "missing"}); err != nil {
+		t.Fatalf("PrefetchMetadata: %v", err)
+	}
+	for _, name := range []string{"foo", "bar"} {
+		if _, err := f.fetchAURMetadata(ctx, name); err != nil {
+			t.Errorf("fetchAURMetadata(%s): %v", name, err)
+		}
+	}
+	if _, err := f.fetchAURMetadata(ctx, "missing"); err == nil {
+		t.Errorf("fetchAURMetadata(missing) succeeded, want not found")
+	}
+	deps, err := f.AURDependencies(ctx, []string{"foo>=1", "missing"})
+	if err != nil {
+		t.Fatalf("AURDependencies: %v", err)
+	}
+	if len(deps) != 1 || deps[0] != "foo" {
+		t.Errorf("AURDependencies = %v, want [foo]", deps)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("made %d requests, want 1", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Wed, 01 May 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 May 2024 11:00:00 GMT", 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
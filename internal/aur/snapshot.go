@@ -0,0 +1,180 @@
+package aur
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/netclient"
+)
+
+const (
+	// snapshotPathPrefix is where the AUR cgit serves package snapshots.
+	snapshotPathPrefix = "/cgit/aur.git/snapshot/"
+	// maxSnapshotSize bounds the download; AUR snapshots are a few KB of
+	// PKGBUILD and helper files, never release tarballs.
+	maxSnapshotSize = 10 << 20
+	// maxSnapshotFileSize bounds any single extracted file.
+	maxSnapshotFileSize = 5 << 20
+)
+
+// ValidateSnapshotURL checks that rawURL is an AUR snapshot tarball served by
+// the configured AUR (same scheme and host as baseURL), so --url can't be
+// pointed at arbitrary hosts or internal services.
+func ValidateSnapshotURL(rawURL, baseURL string) (*url.URL, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid AUR base URL %q", baseURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot URL: %w", err)
+	}
+	if u.Scheme != base.Scheme || !strings.EqualFold(u.Host, base.Host) || u.User != nil {
+		return nil, fmt.Errorf("snapshot URL must be on %s://%s", base.Scheme, base.Host)
+	}
+	name := strings.TrimPrefix(u.Path, snapshotPathPrefix)
+	if name == u.Path || !strings.HasSuffix(name, ".tar.gz") || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("snapshot URL must look like %s%s<package>.tar.gz", base.String(), snapshotPathPrefix)
+	}
+	return u, nil
+}
+
+// DownloadSnapshot fetches an AUR snapshot tarball and extracts it under
+// destDir, returning the directory holding its PKGBUILD. The URL is validated
+// against baseURL first; redirects must stay on the same host.
+func DownloadSnapshot(ctx context.Context, snapshotURL, baseURL, destDir string) (string, error) {
+	u, err := ValidateSnapshotURL(snapshotURL, baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	client := netclient.New(30 * time.Second)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !strings.EqualFold(req.URL.Host, u.Host) {
+			return fmt.Errorf("refusing redirect to %s", req.URL.Host)
+		}
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "yay-friend/1.0 (security analysis tool)")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to download snapshot: %w", ErrNetwork, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: snapshot download returned status %d", ErrNetwork, resp.StatusCode)
+	}
+
+	if err := extractTarGz(io.LimitReader(resp.Body, maxSnapshotSize), destDir); err != nil {
+		return "", err
+	}
+	return findPKGBUILDDir(destDir)
+}
+
+// extractTarGz extracts regular files and directories from a gzipped tarball
+// into destDir. Anything else (symlinks, devices) is skipped, and entries that
+// would land outside destDir are rejected.
+func extractTarGz(r io.Reader, destDir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("snapshot is not a gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot archive: %w", err)
+		}
+
+		target, err := entryTarget(destDir, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", target, err)
+			}
+		case tar.TypeReg:
+			if hdr.Size > maxSnapshotFileSize {
+				return fmt.Errorf("snapshot entry %q is too large (%d bytes)", hdr.Name, hdr.Size)
+			}
+			if err := writeEntry(target, hdr.Name, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// entryTarget returns where an archive entry named name is extracted under
+// destDir. Absolute names and names with .. components are rejected outright
+// rather than clamped, so a crafted archive fails loudly.
+func entryTarget(destDir, name string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %q escapes the extraction directory", name)
+	}
+	target := filepath.Join(destDir, cleaned)
+	if target != filepath.Clean(destDir) && !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %q escapes the extraction directory", name)
+	}
+	return target, nil
+}
+
+// writeEntry writes one regular file from an archive to target, creating its
+// parent directories and copying at most maxSnapshotFileSize bytes.
+func writeEntry(target, name string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	_, err = io.Copy(f, io.LimitReader(r, maxSnapshotFileSize))
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+	return nil
+}
+
+// findPKGBUILDDir returns the directory under root that contains a PKGBUILD;
+// snapshots and shared package archives normally hold a single <package>/
+// directory.
+func findPKGBUILDDir(root string) (string, error) {
+	if _, err := os.Stat(filepath.Join(root, "PKGBUILD")); err == nil {
+		return root, nil
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to read extracted archive: %w", err)
+	}
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, "PKGBUILD")); entry.IsDir() && err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("archive contains no PKGBUILD")
+}
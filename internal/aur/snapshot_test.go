@@ -0,0 +1,157 @@
+package aur
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateSnapshotURL(t *testing.T) {
+	base := "https://aur.archlinux.org"
+	valid := []string{
+		"https://aur.archlinux.org/cgit/aur.git/snapshot/yay.tar.gz",
+		"https://AUR.archlinux.org/cgit/aur.git/snapshot/python-foo.tar.gz",
+	}
+	for _, u := range valid {
+		if _, err := ValidateSnapshotURL(u, base); err != nil {
+			t.Errorf("ValidateSnapshotURL(%q) = %v, want ok", u, err)
+		}
+	}
+
+	invalid := []string{
+		"http://aur.archlinux.org/cgit/aur.git/snapshot/yay.tar.gz", // scheme downgrade
+		"https://evil.example/cgit/aur.git/snapshot/yay.tar.gz",     // other host
+		"https://aur.archlinux.org.evil.example/cgit/aur.git/snapshot/x.tar.gz",
+		"https://user@aur.archlinux.org/cgit/aur.git/snapshot/yay.tar.gz", // userinfo
+		"https://127.0.0.1/cgit/aur.git/snapshot/yay.tar.gz",              // internal address
+		"https://aur.archlinux.org/packages/yay",                          // not a snapshot
+		"https://aur.archlinux.org/cgit/aur.git/snapshot/../../x/yay.tar.gz",
+	}
+	for _, u := range invalid {
+		if _, err := ValidateSnapshotURL(u, base); err == nil {
+			t.Errorf("ValidateSnapshotURL(%q) accepted, want rejection", u)
+		}
+	}
+}
+
+// buildTarGz returns a gzipped tarball with the given entries (name -> body);
+// a name ending in "/" becomes a directory.
+func buildTarGz(t *testing.T, entries [][2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e[0], Mode: 0644, Size: int64(len(e[1])), Typeflag: tar.TypeReg}
+		if e[0][len(e[0])-1] == '/' {
+			hdr = &tar.Header{Name: e[0], Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e[1])); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestExtractSnapshot(t *testing.T) {
+	dest := t.TempDir()
+	archive := buildTarGz(t, [][2]string{
+		{"demo/", ""},
+		{"demo/PKGBUILD", "pkgname=demo\n"},
+		{"demo/demo.install", "post_install() { :; }\n"},
+	})
+	if err := extractTarGz(bytes.NewReader(archive), dest); err != nil {
+		t.Fatalf("extractTarGz: %v", err)
+	}
+	dir, err := findPKGBUILDDir(dest)
+	if err != nil {
+		t.Fatalf("findPKGBUILDDir: %v", err)
+	}
+	if dir != filepath.Join(dest, "demo") {
+		t.Errorf("PKGBUILD dir = %s, want %s", dir, filepath.Join(dest, "demo"))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "demo.install")); err != nil {
+		t.Errorf("install script not extracted: %v", err)
+	}
+}
+
+func TestExtractSnapshotRejectsTraversal(t *testing.T) {
+	parent := t.TempDir()
+	dest := filepath.Join(parent, "x")
+	archive := buildTarGz(t, [][2]string{{"../../outside", "boom"}})
+	if err := extractTarGz(bytes.NewReader(archive), dest); err != nil {
+		return // rejected outright
+	}
+	if _, err := os.Stat(filepath.Join(parent, "outside")); err == nil {
+		t.Error("traversal entry was written outside the extraction directory")
+	}
+}
+
+// buildZip writes a zip of (name, content) entries to a temp file; names
+// ending in "/" are directories.
+func buildZip(t *testing.T, entries [][2]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pkg.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		w, err := zw.Create(e[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(e[0], "/") {
+			w.Write([]byte(e[1]))
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return path
+}
+
+func TestExtractArchiveZip(t *testing.T) {
+	archive := buildZip(t, [][2]string{
+		{"demo/", ""},
+		{"demo/PKGBUILD", "pkgname=demo\n"},
+		{"demo/helper.sh", "echo hi\n"},
+	})
+	dest := t.TempDir()
+	dir, err := ExtractArchive(archive, dest)
+	if err != nil {
+		t.Fatalf("ExtractArchive: %v", err)
+	}
+	if dir != filepath.Join(dest, "demo") {
+		t.Errorf("PKGBUILD dir = %s, want %s", dir, filepath.Join(dest, "demo"))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "helper.sh")); err != nil {
+		t.Errorf("helper not extracted: %v", err)
+	}
+}
+
+func TestExtractArchiveZipRejectsTraversal(t *testing.T) {
+	parent := t.TempDir()
+	dest := filepath.Join(parent, "x")
+	archive := buildZip(t, [][2]string{{"PKGBUILD", "pkgname=x\n"}, {"../outside", "boom"}})
+	if _, err := ExtractArchive(archive, dest); err == nil {
+		t.Error("expected zip-slip entry to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(parent, "outside")); err == nil {
+		t.Error("traversal entry was written outside the extraction directory")
+	}
+}
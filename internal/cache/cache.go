@@ -11,11 +11,13 @@ import (
 	"time"
 
 	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
 )
 
 // CacheManager handles analysis result caching
 type CacheManager struct {
 	cacheDir string
+	readOnly bool
 }
 
 // CacheMetadata represents metadata for cached analysis
@@ -70,6 +72,25 @@ func NewCacheManager() (*CacheManager, error) {
 	return &CacheManager{cacheDir: cacheDir}, nil
 }
 
+// CheckWritable verifies that analyses can be written to the cache directory,
+// so callers can disable caching up front instead of failing on every save.
+func (c *CacheManager) CheckWritable() error {
+	probe, err := os.CreateTemp(c.cacheDir, ".write-probe-*")
+	if err != nil {
+		return fmt.Errorf("cache directory %s is not writable: %w", c.cacheDir, err)
+	}
+	probeName := probe.Name()
+	probe.Close()
+	os.Remove(probeName)
+	return nil
+}
+
+// SetReadOnly makes SaveAnalysis a no-op, for runs that may read a shared or
+// persistent cache but must not add to it (--no-cache-write).
+func (c *CacheManager) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
 // GetCachedAnalysis retrieves a cached analysis if it exists
 func (c *CacheManager) GetCachedAnalysis(packageName, commitHash string) (*types.SecurityAnalysis, error) {
 	cacheFile := c.getCacheFilePath(packageName, commitHash)
@@ -94,12 +115,20 @@ func (c *CacheManager) GetCachedAnalysis(packageName, commitHash string) (*types
 	if cached.CacheMetadata.CommitHash != commitHash {
 		return nil, fmt.Errorf("cache corruption: commit hash mismatch")
 	}
+	if cached.Analysis != nil {
+		cached.Analysis.FillFingerprints() // entries cached before fingerprints existed
+		cached.Analysis.NormalizeRecommendation() // and before recommendations were normalized
+	}
 	
 	return cached.Analysis, nil
 }
 
 // SaveAnalysis saves an analysis result to cache
 func (c *CacheManager) SaveAnalysis(packageName, commitHash string, analysis *types.SecurityAnalysis) error {
+	if c.readOnly {
+		return nil
+	}
+
 	// Create package-specific cache directory
 	packageDir := filepath.Join(c.cacheDir, sanitizePackageName(packageName))
 	if err := os.MkdirAll(packageDir, 0755); err != nil {
@@ -172,7 +201,7 @@ func (c *CacheManager) CleanExpiredCache(maxAge time.Duration) error {
 	}
 	
 	if removedCount > 0 {
-		fmt.Printf("🧹 Cleaned %d expired cache entries\n", removedCount)
+		fmt.Printf("%s Cleaned %d expired cache entries\n", ui.Clean, removedCount)
 	}
 	
 	return nil
@@ -234,7 +263,8 @@ func (c *CacheManager) GetCacheStats() (CacheStats, error) {
 	return stats, nil
 }
 
-// GetPackageVersions returns all cached versions (commit hashes) for a package
+// GetPackageVersions returns all cached versions (commit hashes) for a package,
+// most recently cached first.
 func (c *CacheManager) GetPackageVersions(packageName string) ([]string, error) {
 	packageDir := filepath.Join(c.cacheDir, sanitizePackageName(packageName))
 	
@@ -247,6 +277,7 @@ func (c *CacheManager) GetPackageVersions(packageName string) ([]string, error)
 	}
 	
 	var versions []string
+	modTimes := make(map[string]time.Time)
 	for _, entry := range entries {
 		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
 			continue
@@ -255,14 +286,67 @@ func (c *CacheManager) GetPackageVersions(packageName string) ([]string, error)
 		// Extract commit hash from filename (remove .json extension)
 		commitHash := strings.TrimSuffix(entry.Name(), ".json")
 		versions = append(versions, commitHash)
+		if info, err := entry.Info(); err == nil {
+			modTimes[commitHash] = info.ModTime()
+		}
 	}
 	
-	// Sort versions (most recent first if we can determine)
-	sort.Strings(versions)
+	// Newest first; entries are written once when cached, so the file's
+	// modification time is when it was cached. Ties fall back to hash order.
+	sort.Slice(versions, func(i, j int) bool {
+		ti, tj := modTimes[versions[i]], modTimes[versions[j]]
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return versions[i] < versions[j]
+	})
 	
 	return versions, nil
 }
 
+// GetPreviousAnalysis returns the most recently cached analysis of packageName
+// at a commit other than currentCommit, i.e. what the package looked like the
+// last time it was analyzed before its current state.
+func (c *CacheManager) GetPreviousAnalysis(packageName, currentCommit string) (*CachedAnalysis, error) {
+	versions, err := c.GetPackageVersions(packageName)
+	if err != nil {
+		return nil, err
+	}
+	
+	for _, commitHash := range versions {
+		if commitHash == currentCommit {
+			continue
+		}
+		cached, err := c.readCachedEntry(packageName, commitHash)
+		if err != nil {
+			continue // Skip unreadable entries; an older one may still be usable
+		}
+		return cached, nil
+	}
+	
+	return nil, fmt.Errorf("no previous analysis cached for %s", packageName)
+}
+
+// readCachedEntry reads and parses a cache entry including its metadata.
+func (c *CacheManager) readCachedEntry(packageName, commitHash string) (*CachedAnalysis, error) {
+	data, err := os.ReadFile(c.getCacheFilePath(packageName, commitHash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+	
+	var cached CachedAnalysis
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("failed to parse cached analysis: %w", err)
+	}
+	if cached.Analysis == nil {
+		return nil, fmt.Errorf("cached entry for %s has no analysis", packageName)
+	}
+	cached.Analysis.FillFingerprints()
+	cached.Analysis.NormalizeRecommendation()
+	
+	return &cached, nil
+}
+
 // getCacheFilePath returns the full path for a cache file
 func (c *CacheManager) getCacheFilePath(packageName, commitHash string) string {
 	packageDir := filepath.Join(c.cacheDir, sanitizePackageName(packageName))
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -140,6 +141,59 @@ func TestCacheManager_PackageVersions(t *testing.T) {
 	}
 }
 
+func TestCacheManager_GetPreviousAnalysis(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "yay-friend-cache-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cacheManager := &CacheManager{cacheDir: tmpDir}
+	packageName := "test-package"
+	older := "1111111111111111111111111111111111111111"
+	newer := "2222222222222222222222222222222222222222"
+	current := "3333333333333333333333333333333333333333"
+
+	if _, err := cacheManager.GetPreviousAnalysis(packageName, current); err == nil {
+		t.Error("Expected error with no cached analyses")
+	}
+
+	for i, commitHash := range []string{older, newer, current} {
+		analysis := &types.SecurityAnalysis{
+			PackageName:    packageName,
+			PackageVersion: commitHash[:1],
+			AnalyzedAt:     time.Now(),
+		}
+		if err := cacheManager.SaveAnalysis(packageName, commitHash, analysis); err != nil {
+			t.Fatalf("Failed to save analysis for commit %s: %v", commitHash, err)
+		}
+		// Spread modification times so the cache order is unambiguous
+		modTime := time.Now().Add(time.Duration(i-3) * time.Hour)
+		if err := os.Chtimes(cacheManager.getCacheFilePath(packageName, commitHash), modTime, modTime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+
+	previous, err := cacheManager.GetPreviousAnalysis(packageName, current)
+	if err != nil {
+		t.Fatalf("Failed to get previous analysis: %v", err)
+	}
+	if previous.CacheMetadata.CommitHash != newer {
+		t.Errorf("Expected most recent other commit %s, got %s", newer, previous.CacheMetadata.CommitHash)
+	}
+	if previous.Analysis.PackageVersion != "2" {
+		t.Errorf("Expected version 2, got %q", previous.Analysis.PackageVersion)
+	}
+
+	versions, err := cacheManager.GetPackageVersions(packageName)
+	if err != nil {
+		t.Fatalf("Failed to get package versions: %v", err)
+	}
+	if len(versions) != 3 || versions[0] != current || versions[2] != older {
+		t.Errorf("Expected versions newest first, got %v", versions)
+	}
+}
+
 func TestCacheManager_CleanExpiredCache(t *testing.T) {
 	// Create temporary directory for testing
 	tmpDir, err := os.MkdirTemp("", "yay-friend-cache-test")
@@ -296,4 +350,73 @@ func TestValidateCommitHash(t *testing.T) {
 			t.Errorf("ValidateCommitHash(%q) = %v, expected %v", test.hash, result, test.valid)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestCacheManager_UnwritableCacheDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root bypasses directory permissions")
+	}
+
+	tmpDir := t.TempDir()
+	if err := os.Chmod(tmpDir, 0555); err != nil {
+		t.Fatalf("Failed to make cache dir read-only: %v", err)
+	}
+	defer os.Chmod(tmpDir, 0755)
+
+	cacheManager := &CacheManager{cacheDir: tmpDir}
+	if err := cacheManager.CheckWritable(); err == nil {
+		t.Error("Expected CheckWritable to fail for a read-only cache dir")
+	}
+}
+
+func TestNewCacheManager_DataDirBlocked(t *testing.T) {
+	// A file where the data directory should be makes it impossible to create,
+	// which is what a read-only mount looks like regardless of privileges.
+	dataHome := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataHome, "yay-friend"), []byte("not a dir"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	if _, err := NewCacheManager(); err == nil {
+		t.Error("Expected NewCacheManager to fail when the data dir can't be created")
+	}
+}
+
+func TestCacheManager_CheckWritableLeavesNoProbe(t *testing.T) {
+	tmpDir := t.TempDir()
+	cacheManager := &CacheManager{cacheDir: tmpDir}
+
+	if err := cacheManager.CheckWritable(); err != nil {
+		t.Fatalf("CheckWritable failed on a writable dir: %v", err)
+	}
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected probe file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestCacheManager_ReadOnlySkipsSave(t *testing.T) {
+	tmpDir := t.TempDir()
+	cacheManager := &CacheManager{cacheDir: tmpDir}
+	commitHash := "1234567890abcdef1234567890abcdef12345678"
+	analysis := &types.SecurityAnalysis{PackageName: "pkg", OverallLevel: types.SecurityLow}
+
+	if err := cacheManager.SaveAnalysis("pkg", commitHash, analysis); err != nil {
+		t.Fatalf("SaveAnalysis failed: %v", err)
+	}
+
+	cacheManager.SetReadOnly(true)
+	otherHash := "abcdef1234567890abcdef1234567890abcdef12"
+	if err := cacheManager.SaveAnalysis("pkg", otherHash, analysis); err != nil {
+		t.Fatalf("read-only SaveAnalysis should succeed silently: %v", err)
+	}
+	if cacheManager.IsCached("pkg", otherHash) {
+		t.Error("read-only cache manager wrote an analysis")
+	}
+	if _, err := cacheManager.GetCachedAnalysis("pkg", commitHash); err != nil {
+		t.Errorf("read-only cache manager can't read existing entries: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// getClonesDir returns the directory retained AUR clones are kept under. It is
+// a sibling of the cache directory, not inside it, so a clone's files are never
+// mistaken for cached analyses.
+func getClonesDir() string {
+	return filepath.Join(getDataDir(), "clones")
+}
+
+// ClonePath returns the stable location a retained AUR clone of packageName is
+// kept at for manual inspection.
+func ClonePath(packageName string) string {
+	return filepath.Join(getClonesDir(), sanitizePackageName(packageName))
+}
+
+// ListClones returns the package names that currently have a retained clone.
+func ListClones() ([]string, error) {
+	entries, err := os.ReadDir(getClonesDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read clones directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// CleanClones removes retained clones last modified more than maxAge ago and
+// returns how many were removed. A maxAge of 0 removes every clone.
+func CleanClones(maxAge time.Duration) (int, error) {
+	clonesDir := getClonesDir()
+	entries, err := os.ReadDir(clonesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read clones directory: %w", err)
+	}
+
+	cutoffTime := time.Now().Add(-maxAge)
+	removedCount := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if maxAge > 0 && !info.ModTime().Before(cutoffTime) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(clonesDir, entry.Name())); err != nil {
+			fmt.Printf("Warning: Failed to remove retained clone %s: %v\n", entry.Name(), err)
+			continue
+		}
+		removedCount++
+	}
+
+	return removedCount, nil
+}
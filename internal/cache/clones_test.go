@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCleanClones(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	fresh := ClonePath("fresh-package")
+	stale := ClonePath("stale-package")
+	for _, dir := range []string{fresh, stale} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create clone dir: %v", err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatalf("Failed to age clone dir: %v", err)
+	}
+
+	removed, err := CleanClones(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("CleanClones: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 clone removed, got %d", removed)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("Expected stale clone to be removed")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Error("Expected fresh clone to be kept")
+	}
+
+	// A zero max age clears everything.
+	if _, err := CleanClones(0); err != nil {
+		t.Fatalf("CleanClones(0): %v", err)
+	}
+	clones, err := ListClones()
+	if err != nil {
+		t.Fatalf("ListClones: %v", err)
+	}
+	if len(clones) != 0 {
+		t.Errorf("Expected no clones left, got %v", clones)
+	}
+}
+
+func TestClonePathOutsideCacheDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	cacheDir := filepath.Join(getDataDir(), "cache")
+	if strings.HasPrefix(ClonePath("pkg"), cacheDir+string(filepath.Separator)) {
+		t.Errorf("clone path %s must not live inside the cache dir %s", ClonePath("pkg"), cacheDir)
+	}
+}
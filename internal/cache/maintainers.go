@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AcknowledgedMaintainer is the maintainer the user last accepted for a
+// package base, which security_thresholds.block_on_maintainer_change
+// compares against.
+type AcknowledgedMaintainer struct {
+	Maintainer     string    `json:"maintainer"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+}
+
+// getMaintainersDir returns the directory acknowledged maintainers are kept
+// under. Like the notes it sits beside the cache, so clearing or pruning the
+// cache never moves the baseline.
+func getMaintainersDir() string {
+	return filepath.Join(getDataDir(), "maintainers")
+}
+
+// maintainerPath returns the file holding packageBase's acknowledged maintainer.
+func maintainerPath(packageBase string) string {
+	return filepath.Join(getMaintainersDir(), sanitizePackageName(packageBase)+".json")
+}
+
+// LoadAcknowledgedMaintainer returns the maintainer recorded for packageBase,
+// or nil when none has been.
+func LoadAcknowledgedMaintainer(packageBase string) (*AcknowledgedMaintainer, error) {
+	data, err := os.ReadFile(maintainerPath(packageBase))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read acknowledged maintainer: %w", err)
+	}
+
+	var acknowledged AcknowledgedMaintainer
+	if err := json.Unmarshal(data, &acknowledged); err != nil {
+		return nil, fmt.Errorf("failed to parse acknowledged maintainer: %w", err)
+	}
+	return &acknowledged, nil
+}
+
+// AcknowledgeMaintainer records maintainer as the one the user accepted for
+// packageBase, replacing any earlier one.
+func AcknowledgeMaintainer(packageBase, maintainer string) error {
+	if err := os.MkdirAll(getMaintainersDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create maintainers directory: %w", err)
+	}
+	data, err := json.MarshalIndent(AcknowledgedMaintainer{Maintainer: maintainer, AcknowledgedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal acknowledged maintainer: %w", err)
+	}
+	if err := os.WriteFile(maintainerPath(packageBase), data, 0644); err != nil {
+		return fmt.Errorf("failed to write acknowledged maintainer: %w", err)
+	}
+	return nil
+}
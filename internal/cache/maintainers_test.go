@@ -0,0 +1,27 @@
+package cache
+
+import "testing"
+
+func TestAcknowledgedMaintainerRoundTrip(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if acknowledged, err := LoadAcknowledgedMaintainer("some-package"); err != nil || acknowledged != nil {
+		t.Fatalf("LoadAcknowledgedMaintainer before any = %+v, %v; want none", acknowledged, err)
+	}
+
+	for _, maintainer := range []string{"alice <alice@example.org>", "bob <bob@example.org>"} {
+		if err := AcknowledgeMaintainer("some-package", maintainer); err != nil {
+			t.Fatalf("AcknowledgeMaintainer: %v", err)
+		}
+	}
+	acknowledged, err := LoadAcknowledgedMaintainer("some-package")
+	if err != nil {
+		t.Fatalf("LoadAcknowledgedMaintainer: %v", err)
+	}
+	if acknowledged == nil || acknowledged.Maintainer != "bob <bob@example.org>" || acknowledged.AcknowledgedAt.IsZero() {
+		t.Errorf("acknowledged = %+v, want the latest maintainer with a timestamp", acknowledged)
+	}
+	if other, _ := LoadAcknowledgedMaintainer("other-package"); other != nil {
+		t.Errorf("acknowledgement leaked to another package: %+v", other)
+	}
+}
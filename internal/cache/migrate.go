@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MigrationResult summarizes a MigrateToPackageBase run.
+type MigrationResult struct {
+	Moved      int      // entries moved under their package base
+	Duplicates int      // entries dropped because the base already had that commit
+	Unresolved []string // package directories whose base couldn't be determined
+}
+
+// MigrateToPackageBase reorganizes entries cached under a split package's own
+// name into the directory of its package base, so siblings built from the same
+// PKGBUILD share one analysis per commit. resolve maps a cached package name to
+// its base; names it can't resolve (e.g. no longer in the AUR) are left alone
+// and reported. Running it again is harmless.
+func (c *CacheManager) MigrateToPackageBase(resolve func(packageName string) (string, error)) (MigrationResult, error) {
+	var result MigrationResult
+
+	entries, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		base, err := resolve(name)
+		if err != nil || base == "" {
+			result.Unresolved = append(result.Unresolved, name)
+			continue
+		}
+		if sanitizePackageName(base) == name {
+			continue // already keyed on its base
+		}
+
+		if err := c.moveEntries(name, base, &result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// moveEntries moves every cached commit of packageName under base. A commit the
+// base already has is the same PKGBUILD, so the duplicate is dropped.
+func (c *CacheManager) moveEntries(packageName, base string, result *MigrationResult) error {
+	sourceDir := filepath.Join(c.cacheDir, packageName)
+	targetDir := filepath.Join(c.cacheDir, sanitizePackageName(base))
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return fmt.Errorf("failed to create package cache directory: %w", err)
+	}
+
+	files, err := os.ReadDir(sourceDir)
+	if err != nil {
+		return fmt.Errorf("failed to read package cache directory: %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		source := filepath.Join(sourceDir, file.Name())
+		target := filepath.Join(targetDir, file.Name())
+		if _, err := os.Stat(target); err == nil {
+			if err := os.Remove(source); err != nil {
+				return fmt.Errorf("failed to remove duplicate cache entry: %w", err)
+			}
+			result.Duplicates++
+			continue
+		}
+		if err := os.Rename(source, target); err != nil {
+			return fmt.Errorf("failed to move cache entry %s: %w", source, err)
+		}
+		result.Moved++
+	}
+
+	// Only succeeds once the directory is empty; leftovers are kept.
+	os.Remove(sourceDir)
+	return nil
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestMigrateToPackageBase(t *testing.T) {
+	cacheManager := &CacheManager{cacheDir: t.TempDir()}
+	shared := "1111111111111111111111111111111111111111"
+	only := "2222222222222222222222222222222222222222"
+
+	save := func(name, commitHash string) {
+		analysis := &types.SecurityAnalysis{PackageName: name, AnalyzedAt: time.Now()}
+		if err := cacheManager.SaveAnalysis(name, commitHash, analysis); err != nil {
+			t.Fatalf("Failed to save analysis: %v", err)
+		}
+	}
+	save("foo-cli", shared)
+	save("foo-gui", shared)
+	save("foo-gui", only)
+	save("gone", shared)
+	save("bar", shared)
+
+	bases := map[string]string{"foo-cli": "foo", "foo-gui": "foo", "bar": "bar"}
+	resolve := func(name string) (string, error) {
+		if base, ok := bases[name]; ok {
+			return base, nil
+		}
+		return "", fmt.Errorf("package not found in AUR")
+	}
+
+	result, err := cacheManager.MigrateToPackageBase(resolve)
+	if err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	if result.Moved != 2 || result.Duplicates != 1 {
+		t.Errorf("Moved=%d Duplicates=%d, want 2 and 1", result.Moved, result.Duplicates)
+	}
+	if len(result.Unresolved) != 1 || result.Unresolved[0] != "gone" {
+		t.Errorf("Unresolved = %v, want [gone]", result.Unresolved)
+	}
+
+	for _, commitHash := range []string{shared, only} {
+		if !cacheManager.IsCached("foo", commitHash) {
+			t.Errorf("Expected foo@%s after migration", commitHash[:8])
+		}
+	}
+	for _, name := range []string{"foo-cli", "foo-gui"} {
+		if _, err := os.Stat(filepath.Join(cacheManager.cacheDir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s directory to be removed", name)
+		}
+	}
+	if !cacheManager.IsCached("bar", shared) || !cacheManager.IsCached("gone", shared) {
+		t.Error("Expected unaffected entries to stay in place")
+	}
+
+	// A second run has nothing left to move.
+	again, err := cacheManager.MigrateToPackageBase(resolve)
+	if err != nil || again.Moved != 0 || again.Duplicates != 0 {
+		t.Errorf("Second migration = %+v, %v; want no changes", again, err)
+	}
+}
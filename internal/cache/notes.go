@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Note is a remark the user left about a package, shown again whenever the
+// package is analyzed or installed.
+type Note struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// getNotesDir returns the directory package notes are kept under. Like the
+// clones, it sits beside the cache rather than in it, so clearing the cache
+// never loses them.
+func getNotesDir() string {
+	return filepath.Join(getDataDir(), "notes")
+}
+
+// notesPath returns the file holding packageName's notes.
+func notesPath(packageName string) string {
+	return filepath.Join(getNotesDir(), sanitizePackageName(packageName)+".json")
+}
+
+// LoadNotes returns packageName's notes, oldest first. A package without
+// notes has none, not an error.
+func LoadNotes(packageName string) ([]Note, error) {
+	data, err := os.ReadFile(notesPath(packageName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Note{}, nil
+		}
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+
+	var notes []Note
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return nil, fmt.Errorf("failed to parse notes: %w", err)
+	}
+	return notes, nil
+}
+
+// AddNote appends a note to packageName's notes.
+func AddNote(packageName, text string) error {
+	notes, err := LoadNotes(packageName)
+	if err != nil {
+		return err
+	}
+	notes = append(notes, Note{Text: text, CreatedAt: time.Now()})
+
+	if err := os.MkdirAll(getNotesDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create notes directory: %w", err)
+	}
+	data, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal notes: %w", err)
+	}
+	if err := os.WriteFile(notesPath(packageName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	return nil
+}
+
+// ClearNotes removes all of packageName's notes and reports how many there
+// were.
+func ClearNotes(packageName string) (int, error) {
+	notes, err := LoadNotes(packageName)
+	if err != nil {
+		return 0, err
+	}
+	if len(notes) == 0 {
+		return 0, nil
+	}
+	if err := os.Remove(notesPath(packageName)); err != nil {
+		return 0, fmt.Errorf("failed to remove notes: %w", err)
+	}
+	return len(notes), nil
+}
@@ -0,0 +1,36 @@
+package cache
+
+import "testing"
+
+func TestNotesRoundTrip(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	notes, err := LoadNotes("some-package")
+	if err != nil || len(notes) != 0 {
+		t.Fatalf("LoadNotes before any note = %v, %v; want none", notes, err)
+	}
+
+	for _, text := range []string{"reviewed 1.0, install hook is fine", "upstream moved to codeberg"} {
+		if err := AddNote("some-package", text); err != nil {
+			t.Fatalf("AddNote: %v", err)
+		}
+	}
+	notes, err = LoadNotes("some-package")
+	if err != nil {
+		t.Fatalf("LoadNotes: %v", err)
+	}
+	if len(notes) != 2 || notes[0].Text != "reviewed 1.0, install hook is fine" || notes[1].CreatedAt.IsZero() {
+		t.Errorf("notes = %+v, want both notes in order with timestamps", notes)
+	}
+	if other, _ := LoadNotes("other-package"); len(other) != 0 {
+		t.Errorf("notes leaked to another package: %+v", other)
+	}
+
+	removed, err := ClearNotes("some-package")
+	if err != nil || removed != 2 {
+		t.Fatalf("ClearNotes = %d, %v; want 2", removed, err)
+	}
+	if notes, _ := LoadNotes("some-package"); len(notes) != 0 {
+		t.Errorf("notes after clear = %+v, want none", notes)
+	}
+}
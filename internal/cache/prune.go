@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+)
+
+// PrunedPackage records the cache entries PruneKeepLatest removed for one
+// package.
+type PrunedPackage struct {
+	Package string
+	Removed int
+	Kept    int
+}
+
+// PruneKeepLatest keeps only the keep most recently cached analyses of each
+// package, ordered as GetPackageVersions orders them, and removes the rest.
+// Packages with no more than keep entries are left alone and not reported.
+func (c *CacheManager) PruneKeepLatest(keep int) ([]PrunedPackage, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("must keep at least 1 analysis per package, got %d", keep)
+	}
+
+	entries, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var pruned []PrunedPackage
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		versions, err := c.GetPackageVersions(entry.Name())
+		if err != nil {
+			return pruned, err
+		}
+		if len(versions) <= keep {
+			continue
+		}
+
+		result := PrunedPackage{Package: entry.Name(), Kept: keep}
+		for _, commitHash := range versions[keep:] {
+			if err := os.Remove(c.getCacheFilePath(entry.Name(), commitHash)); err != nil {
+				return append(pruned, result), fmt.Errorf("failed to remove cached analysis %s of %s: %w", commitHash, entry.Name(), err)
+			}
+			result.Removed++
+		}
+		pruned = append(pruned, result)
+	}
+
+	return pruned, nil
+}
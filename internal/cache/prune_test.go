@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestPruneKeepLatest(t *testing.T) {
+	cacheManager := &CacheManager{cacheDir: t.TempDir()}
+	commits := []string{
+		"1111111111111111111111111111111111111111",
+		"2222222222222222222222222222222222222222",
+		"3333333333333333333333333333333333333333",
+	}
+	for i, commitHash := range commits {
+		for _, packageName := range []string{"busy-package", "quiet-package"} {
+			if packageName == "quiet-package" && i > 0 {
+				continue
+			}
+			analysis := &types.SecurityAnalysis{PackageName: packageName, AnalyzedAt: time.Now()}
+			if err := cacheManager.SaveAnalysis(packageName, commitHash, analysis); err != nil {
+				t.Fatalf("SaveAnalysis: %v", err)
+			}
+			modTime := time.Now().Add(time.Duration(i-3) * time.Hour)
+			if err := os.Chtimes(cacheManager.getCacheFilePath(packageName, commitHash), modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if _, err := cacheManager.PruneKeepLatest(0); err == nil {
+		t.Error("expected keeping 0 analyses to be rejected")
+	}
+
+	pruned, err := cacheManager.PruneKeepLatest(2)
+	if err != nil {
+		t.Fatalf("PruneKeepLatest: %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].Package != "busy-package" || pruned[0].Removed != 1 {
+		t.Errorf("pruned = %+v, want one entry removed from busy-package only", pruned)
+	}
+
+	versions, err := cacheManager.GetPackageVersions("busy-package")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0] != commits[2] || versions[1] != commits[1] {
+		t.Errorf("busy-package versions = %v, want the two newest", versions)
+	}
+	if !cacheManager.IsCached("quiet-package", commits[0]) {
+		t.Error("quiet-package's only analysis was pruned")
+	}
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InvalidEntry is a cache file that can't be used as a cached analysis.
+type InvalidEntry struct {
+	Path   string
+	Reason string
+}
+
+// VerifyResult summarizes a VerifyEntries run.
+type VerifyResult struct {
+	Checked int            // cache entries examined
+	Invalid []InvalidEntry // entries that failed a check
+	Removed int            // invalid entries deleted (prune only)
+}
+
+// VerifyEntries checks every cache entry: it must parse, hold an analysis, and
+// record the commit its file is named after. The package name is not checked,
+// since entries moved under their package base by MigrateToPackageBase keep the
+// split package's name. With prune, invalid entries are removed.
+func (c *CacheManager) VerifyEntries(prune bool) (VerifyResult, error) {
+	var result VerifyResult
+
+	err := filepath.Walk(c.cacheDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".json") {
+			return nil
+		}
+
+		result.Checked++
+		reason := verifyEntry(path)
+		if reason == "" {
+			return nil
+		}
+		result.Invalid = append(result.Invalid, InvalidEntry{Path: path, Reason: reason})
+		if prune {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			result.Removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to verify cache: %w", err)
+	}
+
+	return result, nil
+}
+
+// verifyEntry returns why the cache file at path is unusable, or "" if it is
+// fine.
+func verifyEntry(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("unreadable: %v", err)
+	}
+
+	var cached CachedAnalysis
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return fmt.Sprintf("not valid JSON: %v", err)
+	}
+	if cached.Analysis == nil {
+		return "no analysis"
+	}
+	commitHash := strings.TrimSuffix(filepath.Base(path), ".json")
+	if cached.CacheMetadata.CommitHash != commitHash {
+		return fmt.Sprintf("metadata commit %q does not match the file name", cached.CacheMetadata.CommitHash)
+	}
+	return ""
+}
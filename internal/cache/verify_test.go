@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestVerifyEntries(t *testing.T) {
+	cacheManager := &CacheManager{cacheDir: t.TempDir()}
+	good := "1111111111111111111111111111111111111111"
+	analysis := &types.SecurityAnalysis{PackageName: "foo", AnalyzedAt: time.Now()}
+	if err := cacheManager.SaveAnalysis("foo", good, analysis); err != nil {
+		t.Fatalf("Failed to save analysis: %v", err)
+	}
+
+	// A truncated write, an entry without an analysis, and one copied to the
+	// wrong commit.
+	corrupt := map[string]string{
+		"2222222222222222222222222222222222222222": `{"cache_metadata": {"commit_hash": "2222`,
+		"3333333333333333333333333333333333333333": `{"cache_metadata": {"commit_hash": "3333333333333333333333333333333333333333"}}`,
+	}
+	for commitHash, content := range corrupt {
+		if err := os.WriteFile(cacheManager.getCacheFilePath("foo", commitHash), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write corrupt entry: %v", err)
+		}
+	}
+	data, _ := os.ReadFile(cacheManager.getCacheFilePath("foo", good))
+	moved := cacheManager.getCacheFilePath("foo", "4444444444444444444444444444444444444444")
+	if err := os.WriteFile(moved, data, 0644); err != nil {
+		t.Fatalf("Failed to write mismatched entry: %v", err)
+	}
+
+	result, err := cacheManager.VerifyEntries(false)
+	if err != nil {
+		t.Fatalf("VerifyEntries: %v", err)
+	}
+	if result.Checked != 4 || len(result.Invalid) != 3 || result.Removed != 0 {
+		t.Fatalf("result = %+v, want 4 checked, 3 invalid, none removed", result)
+	}
+	for _, entry := range result.Invalid {
+		if filepath.Base(entry.Path) == good+".json" {
+			t.Errorf("valid entry reported: %+v", entry)
+		}
+	}
+
+	result, err = cacheManager.VerifyEntries(true)
+	if err != nil {
+		t.Fatalf("VerifyEntries with prune: %v", err)
+	}
+	if result.Removed != 3 {
+		t.Errorf("Removed = %d, want 3", result.Removed)
+	}
+	if !cacheManager.IsCached("foo", good) || cacheManager.IsCached("foo", "4444444444444444444444444444444444444444") {
+		t.Error("prune should keep the valid entry and remove the invalid ones")
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/aaronsb/yay-friend/internal/aur"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+	"github.com/aaronsb/yay-friend/internal/yay"
+)
+
+// allInstalledFlag analyzes every installed foreign package instead of one.
+var allInstalledFlag bool
+
+// installedAudit is the result of analyze --all-installed, most risky first.
+type installedAudit struct {
+	Packages []auditedPackage `json:"packages" yaml:"packages"`
+	Critical int              `json:"critical" yaml:"critical"`
+	High     int              `json:"high" yaml:"high"`
+	Failed   int              `json:"failed" yaml:"failed"`
+}
+
+// auditedPackage is one installed package of the audit, with its analysis or
+// why there is none.
+type auditedPackage struct {
+	yay.InstalledPackage `yaml:",inline"`
+	Analysis             *types.SecurityAnalysis `json:"analysis,omitempty" yaml:"analysis,omitempty"`
+	Error                string                  `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// runAnalyzeAllInstalled analyzes each installed foreign package (-Qm), one
+// at a time and from the cache wherever the current AUR commit was analyzed
+// before, then prints them sorted by risk with a count of the HIGH and
+// CRITICAL ones. The AUR's current PKGBUILD is analyzed, which may be newer
+// than the installed version. Packages that aren't in the AUR (built by hand,
+// or since deleted) are listed as failed and don't stop the audit. Any
+// package at or above block_level fails the run with ErrBlockedByPolicy.
+func runAnalyzeAllInstalled(ctx context.Context) error {
+	cfg, rules, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	yayClient := yay.NewYayClient(cfg.Yay.Path)
+	if err := yayClient.IsAvailable(); err != nil {
+		return fmt.Errorf("yay not available: %w", err)
+	}
+	installed, err := yayClient.ForeignPackages(ctx)
+	if err != nil {
+		return err
+	}
+	if len(installed) == 0 {
+		fmt.Printf("%s No foreign (AUR) packages are installed\n", ui.OK)
+		return emitAudit(&installedAudit{}, cfg)
+	}
+
+	// Initialize the provider
+	aiProvider, err := selectProvider(ctx, cfg, rules, true)
+	if err != nil {
+		return err
+	}
+
+	walker := &dependencyWalker{
+		cfg:          cfg,
+		yayClient:    yayClient,
+		provider:     aiProvider,
+		cacheManager: openAnalysisCache(cfg),
+		aurFetcher:   aur.NewAURFetcher(),
+	}
+	if limit := aiProvider.GetCapabilities().RateLimitPerMinute; limit > 0 {
+		walker.interval = time.Minute / time.Duration(limit)
+	}
+
+	names := make([]string, len(installed))
+	for i, pkg := range installed {
+		names[i] = pkg.Name
+	}
+	if err := walker.aurFetcher.PrefetchMetadata(ctx, names); err != nil {
+		fmt.Printf("Warning: Could not prefetch AUR metadata: %v\n", err)
+	}
+
+	fmt.Printf("%s Auditing %d installed foreign package(s) with %s...\n", ui.Search, len(installed), aiProvider.Name())
+	audit := &installedAudit{}
+	for _, pkg := range installed {
+		entry := auditedPackage{InstalledPackage: pkg}
+		if _, analysis, err := walker.analyze(ctx, pkg.Name, 0); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			entry.Error = err.Error()
+			audit.Failed++
+		} else {
+			entry.Analysis = filterFindings(analysis, findingTypeFilter)
+			switch analysis.DecisionLevel() {
+			case types.EntropyCritical:
+				audit.Critical++
+			case types.EntropyHigh:
+				audit.High++
+			}
+		}
+		audit.Packages = append(audit.Packages, entry)
+	}
+	printTotalAnalysisTime()
+
+	sortAudit(audit.Packages)
+	if err := emitAudit(audit, cfg); err != nil {
+		return err
+	}
+
+	var blocked []string
+	for _, entry := range audit.Packages {
+		if entry.Analysis != nil && entry.Analysis.DecisionLevel() >= cfg.SecurityThresholds.BlockLevel {
+			blocked = append(blocked, entry.Name)
+		}
+	}
+	if len(blocked) > 0 {
+		return fmt.Errorf("installed package(s) %s %w", strings.Join(blocked, ", "), ErrBlockedByPolicy)
+	}
+	return nil
+}
+
+// sortAudit orders packages by decision level, then risk score, highest
+// first; packages that couldn't be analyzed go last. Ties keep name order.
+func sortAudit(packages []auditedPackage) {
+	sort.SliceStable(packages, func(i, j int) bool {
+		a, b := packages[i].Analysis, packages[j].Analysis
+		switch {
+		case a == nil || b == nil:
+			return a != nil && b == nil
+		case a.DecisionLevel() != b.DecisionLevel():
+			return a.DecisionLevel() > b.DecisionLevel()
+		case a.RiskScore != b.RiskScore:
+			return a.RiskScore > b.RiskScore
+		}
+		return packages[i].Name < packages[j].Name
+	})
+}
+
+// emitAudit writes the audit in the format chosen with --format: a table
+// and totals, a JSON or YAML document, or one line per package.
+func emitAudit(audit *installedAudit, cfg *types.Config) error {
+	switch formatFlag {
+	case "json":
+		encoder := json.NewEncoder(resultOut)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(audit); err != nil {
+			return fmt.Errorf("failed to encode audit as JSON: %w", err)
+		}
+		return nil
+	case "yaml":
+		encoder := yaml.NewEncoder(resultOut)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(audit); err != nil {
+			return fmt.Errorf("failed to encode audit as YAML: %w", err)
+		}
+		return encoder.Close()
+	case "oneline":
+		for _, entry := range audit.Packages {
+			if entry.Analysis != nil {
+				fmt.Fprintln(resultOut, onelineResult(entry.Analysis, cfg))
+			} else {
+				fmt.Fprintf(resultOut, "package=%s level=UNKNOWN rec=FAILED\n", entry.Name)
+			}
+		}
+		return nil
+	}
+
+	if len(audit.Packages) == 0 {
+		return nil
+	}
+	fmt.Printf("\nInstalled AUR Packages by Risk:\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("%-30s %-16s %-10s %s\n", "Package", "Installed", "Level", "Score")
+	for _, entry := range audit.Packages {
+		if entry.Analysis == nil {
+			fmt.Printf("%-30s %-16s %s analysis failed: %s\n", entry.Name, entry.Version, ui.Fail, entry.Error)
+			continue
+		}
+		level := entry.Analysis.DecisionLevel()
+		fmt.Printf("%-30s %-16s ", entry.Name, entry.Version)
+		getEntropyColor(level).Printf("%-10s", level.String())
+		fmt.Printf(" %.0f\n", entry.Analysis.RiskScore)
+	}
+
+	fmt.Printf("\n%d package(s) audited: %s %d CRITICAL, %s %d HIGH",
+		len(audit.Packages), getEntropyIcon(types.EntropyCritical), audit.Critical, getEntropyIcon(types.EntropyHigh), audit.High)
+	if audit.Failed > 0 {
+		fmt.Printf(", %d could not be analyzed", audit.Failed)
+	}
+	fmt.Printf("\n")
+	return nil
+}
@@ -2,24 +2,53 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
 	"github.com/aaronsb/yay-friend/internal/aur"
-	"github.com/aaronsb/yay-friend/internal/cache"
-	"github.com/aaronsb/yay-friend/internal/config"
 	"github.com/aaronsb/yay-friend/internal/providers"
+	"github.com/aaronsb/yay-friend/internal/scanner"
+	"github.com/aaronsb/yay-friend/internal/trust"
 	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
 	"github.com/aaronsb/yay-friend/internal/yay"
 )
 
-var fileFlag string
+var (
+	fileFlag   string
+	urlFlag    string
+	lintFlag   bool
+	formatFlag string
+	// packageBaseFlag overrides pkgbase detection for the git URL, commit
+	// lookup, and cache key.
+	packageBaseFlag string
+	// findingTypeFlag limits the displayed findings to these types.
+	findingTypeFlag      string
+	findingTypeFilter    []string
+	listFindingTypesFlag bool
+	// compareUpstreamFlag checks pkgver against the GitHub upstream's releases.
+	compareUpstreamFlag bool
+	// onlyNewFindingsFlag hides findings already present in the previous
+	// cached analysis.
+	onlyNewFindingsFlag bool
+	// promptOnlyFlag prints the prompt that would be sent and stops before
+	// the provider is called.
+	promptOnlyFlag bool
+	// recursiveFlag also analyzes the package's AUR dependencies, down to
+	// dependencyDepthFlag levels.
+	recursiveFlag       bool
+	dependencyDepthFlag int
+)
 
 // newAnalyzeCmd creates the analyze command
 func newAnalyzeCmd() *cobra.Command {
@@ -32,12 +61,79 @@ This is useful for checking packages before deciding whether to install them.
 You can analyze:
   - AUR packages by name: yay-friend analyze package-name
   - Local PKGBUILD files: yay-friend analyze --file /path/to/PKGBUILD
-  - Local directories: yay-friend analyze --file /path/to/package-dir/`,
+  - Local directories: yay-friend analyze --file /path/to/package-dir/
+  - Package archives: yay-friend analyze --file /path/to/package.tar.gz (or .tgz, .zip)
+  - AUR snapshots: yay-friend analyze --url https://aur.archlinux.org/cgit/aur.git/snapshot/<pkg>.tar.gz
+  - Every installed AUR package: yay-friend analyze --all-installed`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if listFindingTypesFlag {
+				listFindingTypes(os.Stdout)
+				return nil
+			}
+			filter, err := parseFindingTypes(findingTypeFlag)
+			if err != nil {
+				return fmt.Errorf("invalid --type: %w", err)
+			}
+			findingTypeFilter = filter
+
+			switch formatFlag {
+			case "text":
+			case "json", "yaml", "oneline":
+				// Keep stdout clean for the document; progress still goes to stderr
+				restore := redirectDecorativeOutput()
+				defer restore()
+			default:
+				return fmt.Errorf("unknown --format %q (expected text, json, yaml or oneline)", formatFlag)
+			}
+			if promptOnlyFlag {
+				if formatFlag != "text" || recursiveFlag || onlyNewFindingsFlag || reportToFlag != "" {
+					return fmt.Errorf("--prompt-only runs no analysis; it can't be combined with --format, --recursive, --only-new-findings or --report-to")
+				}
+				// Only the prompt goes to stdout, so it can be redirected to a file
+				restore := redirectDecorativeOutput()
+				defer restore()
+			}
+			if onlyNewFindingsFlag && (fileFlag != "" || urlFlag != "") {
+				return fmt.Errorf("--only-new-findings compares cached analyses of AUR commits; pass a package name instead of --file or --url")
+			}
+			if recursiveFlag && (fileFlag != "" || urlFlag != "") {
+				return fmt.Errorf("--recursive resolves dependencies through the AUR; pass a package name instead of --file or --url")
+			}
+			if cmd.Flags().Changed("depth") && !recursiveFlag {
+				return fmt.Errorf("--depth only applies with --recursive")
+			}
+			if dependencyDepthFlag < 1 {
+				return fmt.Errorf("invalid --depth %d: must be at least 1", dependencyDepthFlag)
+			}
+			if allInstalledFlag {
+				if len(args) > 0 || fileFlag != "" || urlFlag != "" {
+					return fmt.Errorf("--all-installed analyzes every installed foreign package; don't name packages or pass --file or --url")
+				}
+				if recursiveFlag || onlyNewFindingsFlag || promptOnlyFlag || reportToFlag != "" {
+					return fmt.Errorf("--all-installed can't be combined with --recursive, --only-new-findings, --prompt-only or --report-to")
+				}
+				return runAnalyzeAllInstalled(cmd.Context())
+			}
+			if reportToFlag != "" && (urlFlag != "" || aur.IsPackageArchive(fileFlag)) {
+				return fmt.Errorf("--report-to files a report on a package name or a local PKGBUILD; it does not apply to --url or archives")
+			}
+			if err := resolveReportTo(); err != nil {
+				return err
+			}
+			if packageBaseFlag != "" && !aur.ValidatePackageName(packageBaseFlag) {
+				return fmt.Errorf("invalid --package-base %q: not a valid AUR package name", packageBaseFlag)
+			}
+
 			if fileFlag != "" {
+				if aur.IsPackageArchive(fileFlag) {
+					return runAnalyzeArchive(cmd.Context(), fileFlag)
+				}
 				return runAnalyzeLocal(cmd.Context(), fileFlag)
 			}
+			if urlFlag != "" {
+				return runAnalyzeURL(cmd.Context(), urlFlag)
+			}
 			if len(args) == 0 {
 				return fmt.Errorf("please specify a package name or use --file flag")
 			}
@@ -45,153 +141,344 @@ You can analyze:
 		},
 	}
 
-	cmd.Flags().StringVar(&fileFlag, "file", "", "Analyze a local PKGBUILD file or directory")
+	cmd.Flags().StringVar(&fileFlag, "file", "", "Analyze a local PKGBUILD file, directory, or .tar.gz/.tgz/.zip archive")
+	cmd.Flags().StringVar(&urlFlag, "url", "", "Analyze an AUR snapshot tarball URL (must be on aur.base_url)")
+	cmd.Flags().BoolVar(&lintFlag, "lint", false, "Also check the PKGBUILD for common packaging mistakes (informational)")
+	cmd.Flags().StringVar(&formatFlag, "format", "text", "Output format: text, json, yaml or oneline")
+	cmd.Flags().StringVar(&packageBaseFlag, "package-base", "", "Use this AUR package base for the git URL, commit lookup, and cache key")
+	cmd.Flags().StringVar(&findingTypeFlag, "type", "", "Show only findings of these types, comma-separated (the verdict is unchanged)")
+	cmd.Flags().BoolVar(&listFindingTypesFlag, "list-findings-types", false, "List the known finding types and exit")
+	cmd.Flags().BoolVar(&compareUpstreamFlag, "compare-upstream", false, "Check pkgver against the GitHub upstream's releases (uses GITHUB_TOKEN if set)")
+	cmd.Flags().BoolVar(&recursiveFlag, "recursive", false, "Also analyze the package's AUR dependencies and apply the thresholds to the worst level in the tree")
+	cmd.Flags().IntVar(&dependencyDepthFlag, "depth", 3, "With --recursive, how many levels of dependencies to follow")
+	cmd.Flags().StringVar(&reportToFlag, "report-to", "", "After the analysis, offer to report the package to this reporter target name, \"local\", or https URL when it reaches warn_level")
+	cmd.Flags().BoolVar(&allInstalledFlag, "all-installed", false, "Audit every installed foreign (AUR) package and list them by risk")
+	cmd.Flags().BoolVar(&promptOnlyFlag, "prompt-only", false, "Collect the package context, print the prompt that would be sent to the provider, and exit without calling it")
+	cmd.Flags().BoolVar(&onlyNewFindingsFlag, "only-new-findings", false, "Show only findings not in the previous cached analysis of an older commit (the verdict is unchanged)")
 
 	return cmd
 }
 
-func runAnalyze(ctx context.Context, packageName string) error {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
-	// Initialize yay client
-	yayClient := yay.NewYayClient(cfg.Yay.Path)
-	if err := yayClient.IsAvailable(); err != nil {
-		return fmt.Errorf("yay not available: %w", err)
-	}
-
-	// Initialize providers
+// newProviderRegistry registers every provider, with claude configured from
+// cfg, the user scanner rules, and the --verbose and --debug flags.
+func newProviderRegistry(cfg *types.Config, rules []scanner.ScannerRule) *providers.ProviderRegistry {
 	registry := providers.NewProviderRegistry()
 	claudeProvider := providers.NewClaudeProvider()
 	claudeProvider.SetConfig(cfg)
+	claudeProvider.SetUserRules(rules)
+	claudeProvider.SetVerbose(verbose)
+	claudeProvider.SetDebug(debug)
 	registry.Register("claude", claudeProvider)
 	registry.Register("qwen", providers.NewQwenProvider())
 	registry.Register("copilot", providers.NewCopilotProvider())
 	registry.Register("goose", providers.NewGooseProvider())
+	return registry
+}
 
-	// Determine which provider to use
+// selectProvider returns the provider named by --provider, else by
+// default_provider, else claude, authenticated unless authenticate is false.
+// rules are the user scanner rules from loadConfig.
+func selectProvider(ctx context.Context, cfg *types.Config, rules []scanner.ScannerRule, authenticate bool) (types.AIProvider, error) {
 	providerName := provider
 	if providerName == "" {
 		providerName = cfg.DefaultProvider
 	}
 	if providerName == "" {
-		providerName = "claude"
+		providerName = "claude" // Default fallback
+	}
+
+	aiProvider, err := newProviderRegistry(cfg, rules).Get(providerName)
+	if err != nil {
+		return nil, fmt.Errorf("provider error: %w", err)
 	}
+	if authenticate {
+		if err := aiProvider.Authenticate(ctx); err != nil {
+			return nil, fmt.Errorf("authentication failed for %s: %w", providerName, err)
+		}
+	}
+	return aiProvider, nil
+}
 
-	aiProvider, err := registry.Get(providerName)
+func runAnalyze(ctx context.Context, packageName string) error {
+	// Load configuration
+	cfg, rules, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("provider error: %w", err)
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Initialize yay client
+	yayClient := yay.NewYayClient(cfg.Yay.Path)
+	if err := yayClient.IsAvailable(); err != nil {
+		return fmt.Errorf("yay not available: %w", err)
 	}
 
-	// Authenticate provider
-	if err := aiProvider.Authenticate(ctx); err != nil {
-		return fmt.Errorf("authentication failed for %s: %w", providerName, err)
+	// Trusted repository packages have no AUR PKGBUILD to analyze
+	if repo, _ := partitionRepoPackages(ctx, cfg, []string{packageName}); len(repo) > 0 {
+		return nil
+	}
+
+	// Initialize the provider; showing the prompt doesn't need authentication
+	aiProvider, err := selectProvider(ctx, cfg, rules, !promptOnlyFlag)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("🔍 Analyzing %s with %s...\n", packageName, aiProvider.Name())
+	fmt.Printf("%s Analyzing %s with %s...\n", ui.Search, packageName, aiProvider.Name())
 
 	// Get package info
 	pkgInfo, err := yayClient.GetPackageInfo(ctx, packageName)
 	if err != nil {
 		return fmt.Errorf("failed to get package info: %w", err)
 	}
+	if packageBaseFlag != "" {
+		// Kept by EnrichPackageInfo, so the commit lookup uses it too
+		pkgInfo.PackageBase = packageBaseFlag
+	}
 
 	// Fetch additional AUR context (including commit hash)
 	fmt.Printf("Fetching AUR context...\n")
 	aurFetcher := aur.NewAURFetcher()
-	if err := aurFetcher.EnrichPackageInfo(ctx, pkgInfo); err != nil {
-		fmt.Printf("Warning: Could not enrich with AUR context: %v\n", err)
-	}
+	enrichment := aurFetcher.EnrichPackageInfo(ctx, pkgInfo)
 
-	// Initialize cache manager
-	cacheManager, err := cache.NewCacheManager()
-	if err != nil {
-		fmt.Printf("Warning: Could not initialize cache: %v\n", err)
-		// Continue without caching
+	if promptOnlyFlag {
+		addRecentCommits(ctx, cfg, pkgInfo)
+		displayCollectedDataAnalyze(pkgInfo, &enrichment)
+		return printPrompt(aiProvider, *pkgInfo)
 	}
 
+	// Initialize cache manager (nil when caching is disabled or unavailable)
+	cacheManager := openAnalysisCache(cfg)
+
 	// Check cache first if enabled and we have commit hash and cache manager
 	var analysis *types.SecurityAnalysis
 	if cfg.Cache.Enabled && cacheManager != nil && pkgInfo.CommitHash != "" {
-		cachedAnalysis, cacheErr := cacheManager.GetCachedAnalysis(pkgInfo.Name, pkgInfo.CommitHash)
+		// Keyed on the package base so split-package siblings share one analysis
+		cachedAnalysis, cacheErr := cacheManager.GetCachedAnalysis(pkgInfo.Base(), pkgInfo.CommitHash)
 		if cacheErr == nil {
-			fmt.Printf("📋 Using cached analysis (commit: %s)\n", pkgInfo.CommitHash[:8])
+			fmt.Printf("%s Using cached analysis (commit: %s)\n", ui.Cached, pkgInfo.CommitHash[:8])
 			analysis = cachedAnalysis
+			analysis.PackageName = pkgInfo.Name // may have been cached for a sibling
 		} else {
-			fmt.Printf("🤖 Running fresh analysis (commit: %s)\n", pkgInfo.CommitHash[:8])
+			fmt.Printf("%s Running fresh analysis (commit: %s)\n", ui.Fresh, pkgInfo.CommitHash[:8])
 			// Cache miss - continue to run AI analysis
 		}
 	}
 
 	// If no cached analysis found, run AI analysis
 	if analysis == nil {
+		// Git history is only prompt context, so skip fetching it on a cache hit
+		addRecentCommits(ctx, cfg, pkgInfo)
+
 		// Display what we collected for analysis
-		displayCollectedDataAnalyze(pkgInfo)
+		displayCollectedDataAnalyze(pkgInfo, &enrichment)
 
 		// Analyze security with options (support --no-spinner)
-		// Check if provider supports options (for Claude)
-		if claudeProvider, ok := aiProvider.(*providers.ClaudeProvider); ok {
-			analysis, err = claudeProvider.AnalyzePKGBUILDWithOptions(ctx, *pkgInfo, noSpinner)
-		} else {
-			analysis, err = aiProvider.AnalyzePKGBUILD(ctx, *pkgInfo)
-		}
+		analysis, err = aiProvider.AnalyzePKGBUILDWithOptions(ctx, *pkgInfo, types.AnalysisOptions{NoSpinner: noSpinner})
 		
 		if err != nil {
 			return fmt.Errorf("analysis failed: %w", err)
 		}
+		printAnalysisTime(analysis)
+		analysis.Context = enrichment.Completeness(*pkgInfo, analysis.Truncated())
 
 		// Save to cache if enabled and available
 		if cfg.Cache.Enabled && cacheManager != nil && pkgInfo.CommitHash != "" {
-			if cacheErr := cacheManager.SaveAnalysis(pkgInfo.Name, pkgInfo.CommitHash, analysis); cacheErr != nil {
+			if cacheErr := cacheManager.SaveAnalysis(pkgInfo.Base(), pkgInfo.CommitHash, analysis); cacheErr != nil {
 				fmt.Printf("Warning: Could not save analysis to cache: %v\n", cacheErr)
 			}
 		}
 	}
 
+	// Applied after caching: the floors are local policy, and votes change.
+	// Rescoring also covers entries cached before the risk score existed.
+	analysis.RiskScore = trust.RiskScore(analysis, *pkgInfo)
+	trust.ApplyTypeFloors(analysis, *pkgInfo, cfg.Security.TypeFloors)
+	trust.ApplyCommunityFloors(analysis, *pkgInfo, cfg.SecurityThresholds.MinVotes, cfg.SecurityThresholds.MinPopularity)
+	compareUpstream(ctx, analysis, *pkgInfo)
+
 	// Display detailed results
-	displayDetailedAnalysis(analysis)
+	shown := analysis
+	if onlyNewFindingsFlag {
+		shown = onlyNewFindings(cacheManager, pkgInfo, analysis)
+	}
+	if err := emitAnalysis(shown, cfg); err != nil {
+		return err
+	}
+	printNotes(pkgInfo.Name)
+	runPostAnalysisHook(ctx, cfg, analysis)
+	if lintFlag {
+		displayLintIssues(pkgInfo.PKGBUILD)
+	}
+
+	retainCloneIfFlagged(ctx, cfg, pkgInfo.Base(), analysis)
+	fileReportTo(analysis, *pkgInfo, cfg)
+
+	if recursiveFlag {
+		walker := &dependencyWalker{
+			cfg:          cfg,
+			yayClient:    yayClient,
+			provider:     aiProvider,
+			cacheManager: cacheManager,
+			aurFetcher:   aurFetcher,
+			maxDepth:     dependencyDepthFlag,
+		}
+		err := analyzeDependencyTree(ctx, walker, pkgInfo, analysis)
+		printTotalAnalysisTime()
+		if err != nil {
+			return err
+		}
+	}
+	return onelineExit(analysis, cfg)
+}
+
+// resultOut receives the analysis document; redirectDecorativeOutput points
+// everything else at stderr when a machine-readable format is requested.
+var resultOut io.Writer = os.Stdout
+
+// redirectDecorativeOutput sends progress messages, colors, and the spinner
+// to stderr so stdout carries only the formatted analysis. It returns a
+// function that undoes the redirection.
+func redirectDecorativeOutput() func() {
+	stdout := os.Stdout
+	resultOut = stdout
+	os.Stdout = os.Stderr
+	color.SetOutput(os.Stderr)
+	savedNoSpinner := noSpinner
+	noSpinner = true
+	return func() {
+		os.Stdout = stdout
+		color.SetOutput(stdout)
+		noSpinner = savedNoSpinner
+	}
+}
+
+// emitAnalysis writes the analysis in the format chosen with --format, with
+// the findings narrowed by --type.
+func emitAnalysis(analysis *types.SecurityAnalysis, cfg *types.Config) error {
+	analysis = filterFindings(analysis, findingTypeFilter)
+	switch formatFlag {
+	case "json":
+		encoder := json.NewEncoder(resultOut)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(analysis); err != nil {
+			return fmt.Errorf("failed to encode analysis as JSON: %w", err)
+		}
+	case "yaml":
+		encoder := yaml.NewEncoder(resultOut)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(analysis); err != nil {
+			return fmt.Errorf("failed to encode analysis as YAML: %w", err)
+		}
+		return encoder.Close()
+	case "oneline":
+		fmt.Fprintln(resultOut, onelineResult(analysis, cfg))
+	default:
+		displayDetailedAnalysis(analysis, cfg.UI.ShowEducation)
+	}
+	return nil
+}
+
+// onelineResult renders the --format oneline summary: space-separated
+// key=value pairs for grep and awk, with rec the verdict of the block and
+// warn thresholds (BLOCK, WARN or OK) and score the risk score.
+func onelineResult(analysis *types.SecurityAnalysis, cfg *types.Config) string {
+	return fmt.Sprintf("package=%s level=%s rec=%s findings=%d score=%.0f",
+		analysis.PackageName, analysis.DecisionLevel().String(),
+		thresholdVerdict(analysis.DecisionLevel(), cfg), len(analysis.Findings), analysis.RiskScore)
+}
+
+// thresholdVerdict names what the thresholds make of level.
+func thresholdVerdict(level types.SecurityEntropy, cfg *types.Config) string {
+	switch {
+	case level >= cfg.SecurityThresholds.BlockLevel:
+		return "BLOCK"
+	case level >= cfg.SecurityThresholds.WarnLevel:
+		return "WARN"
+	}
+	return "OK"
+}
 
+// onelineExit fails a --format oneline analysis whose level reaches the
+// block threshold with ErrBlockedByPolicy, so a script gets exit code 2
+// along with the line. Other formats only report.
+func onelineExit(analysis *types.SecurityAnalysis, cfg *types.Config) error {
+	if formatFlag == "oneline" && thresholdVerdict(analysis.DecisionLevel(), cfg) == "BLOCK" {
+		return fmt.Errorf("package %s %w", analysis.PackageName, ErrBlockedByPolicy)
+	}
 	return nil
 }
 
-func displayDetailedAnalysis(analysis *types.SecurityAnalysis) {
+func displayDetailedAnalysis(analysis *types.SecurityAnalysis, showEducation bool) {
 	fmt.Printf("\n%s\n", strings.Repeat("=", 60))
-	fmt.Printf("Security Analysis for %s\n", analysis.PackageName)
+	fmt.Println(ui.T(ui.MsgResultsFor, analysis.PackageName))
 	fmt.Printf("%s\n", strings.Repeat("=", 60))
-	fmt.Printf("Provider: %s\n", analysis.Provider)
-	fmt.Printf("Analyzed: %s\n", analysis.AnalyzedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("Overall Level: %s\n", getColoredLevel(analysis.OverallLevel))
-	fmt.Printf("\nSummary:\n%s\n", analysis.Summary)
+	fmt.Println(ui.T(ui.MsgProvider, analysis.Provider))
+	fmt.Println(ui.T(ui.MsgAnalyzedAt, analysis.AnalyzedAt.Format("2006-01-02 15:04:05")))
+	fmt.Println(ui.T(ui.MsgOverallLevel, getColoredLevel(analysis.OverallLevel), analysis.RiskScore))
+	displayContext(analysis)
+	displayInstallScriptRisk(analysis)
+	displayPersistence(analysis)
+	displayTruncation(analysis)
+	fmt.Printf("\n%s\n%s\n", ui.T(ui.MsgSummaryHeading), ui.Wrap("", "", analysis.Summary))
 	
-	if analysis.Recommendation != "" {
-		fmt.Printf("\nRecommendation: %s\n", analysis.Recommendation)
+	if recommendation := recommendationText(analysis); recommendation != "" {
+		fmt.Printf("\n%s\n", ui.Wrap(ui.T(ui.MsgRecommendationLabel), "   ", recommendation))
 	}
 
 	if len(analysis.Findings) > 0 {
-		fmt.Printf("\nDetailed Findings:\n")
+		fmt.Printf("\n%s\n", ui.T(ui.MsgDetailedFindings))
 		fmt.Printf("%s\n", strings.Repeat("-", 40))
 		for i, finding := range analysis.Findings {
-			fmt.Printf("%d. [%s] %s\n", i+1, getColoredLevel(finding.Severity), finding.Type)
-			fmt.Printf("   %s\n", finding.Description)
+			fmt.Printf("%d. [%s] %s%s%s\n", i+1, getColoredLevel(finding.Severity), finding.Type, rootMarker(finding), policyMarker(finding))
+			fmt.Printf("%s\n", ui.Wrap("   ", "   ", finding.Description))
+			if verboseFindings {
+				displayEntropyNotes(finding)
+			}
 			
 			if finding.LineNumber > 0 {
-				fmt.Printf("   Line: %d\n", finding.LineNumber)
+				fmt.Println(ui.T(ui.MsgFindingLine, finding.LineNumber))
 			}
 			
 			if finding.Context != "" {
-				fmt.Printf("   Context: %s\n", finding.Context)
+				fmt.Println(ui.T(ui.MsgFindingContext, finding.Context))
 			}
 			
 			if finding.Suggestion != "" {
-				fmt.Printf("   💡 %s\n", finding.Suggestion)
+				fmt.Printf("%s\n", ui.Wrap(fmt.Sprintf("   %s ", ui.Tip), "      ", finding.Suggestion))
 			}
 			fmt.Println()
 		}
+	} else if len(findingTypeFilter) > 0 {
+		fmt.Printf("\n%s %s\n", ui.Info, ui.T(ui.MsgNoFindingsOfType, strings.Join(findingTypeFilter, ", ")))
+	} else if analysis.NewSinceCommit != "" {
+		fmt.Printf("\n%s %s\n", ui.OK, ui.T(ui.MsgNoNewFindings, shortCommit(analysis.NewSinceCommit)))
 	} else {
-		fmt.Println("\n✅ No security issues found!")
+		fmt.Printf("\n%s %s\n", ui.OK, ui.T(ui.MsgNoFindings))
+	}
+
+	if showEducation {
+		displayEducation(analysis)
+	}
+}
+
+// displayLintIssues shows packaging mistakes found in the PKGBUILD. They are
+// a quality signal only and never affect the security verdict.
+func displayLintIssues(pkgbuild string) {
+	issues := scanner.Lint(pkgbuild)
+
+	fmt.Printf("\n")
+	color.Bold.Printf("Packaging Lint (informational, does not affect the verdict):\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 40))
+	if len(issues) == 0 {
+		fmt.Printf("%s No common packaging mistakes found\n", ui.OK)
+		return
+	}
+	for _, issue := range issues {
+		if issue.Line > 0 {
+			fmt.Printf("%s [%s] line %d: %s\n", ui.Info, issue.Check, issue.Line, issue.Message)
+		} else {
+			fmt.Printf("%s [%s] %s\n", ui.Info, issue.Check, issue.Message)
+		}
 	}
 }
 
@@ -200,8 +487,9 @@ func getColoredLevel(level types.SecurityLevel) string {
 	return level.String()
 }
 
-// displayCollectedDataAnalyze shows what information we gathered for analysis (analyze command version)
-func displayCollectedDataAnalyze(pkgInfo *types.PackageInfo) {
+// displayCollectedDataAnalyze shows what information we gathered for analysis (analyze command version).
+// enrichment is nil when no AUR lookup was attempted (local files).
+func displayCollectedDataAnalyze(pkgInfo *types.PackageInfo, enrichment *aur.EnrichmentStatus) {
 	fmt.Printf("\n")
 	color.Bold.Printf("Collected for Analysis:\n")
 	fmt.Printf("─────────────────────────\n")
@@ -211,7 +499,15 @@ func displayCollectedDataAnalyze(pkgInfo *types.PackageInfo) {
 	fmt.Printf("• PKGBUILD: %d lines of shell script\n", pkgbuildLines)
 	
 	// Package metadata
-	fmt.Printf("• Package metadata: %s v%s by %s\n", pkgInfo.Name, pkgInfo.Version, pkgInfo.Maintainer)
+	if pkgInfo.DynamicVersion {
+		fmt.Printf("• Package metadata: %s by %s\n", pkgInfo.Name, pkgInfo.Maintainer)
+		fmt.Printf("• Version: %s\n", pkgInfo.VersionLabel())
+	} else {
+		fmt.Printf("• Package metadata: %s v%s by %s\n", pkgInfo.Name, pkgInfo.Version, pkgInfo.Maintainer)
+	}
+	if pkgInfo.PackageBase != "" && pkgInfo.PackageBase != pkgInfo.Name {
+		fmt.Printf("• Package base: %s\n", pkgInfo.PackageBase)
+	}
 	
 	// Dependencies
 	if len(pkgInfo.Dependencies) > 0 {
@@ -222,6 +518,17 @@ func displayCollectedDataAnalyze(pkgInfo *types.PackageInfo) {
 		fmt.Printf("• Build dependencies: %d packages (%s)\n", 
 			len(pkgInfo.MakeDepends), truncateListAnalyze(pkgInfo.MakeDepends, 3))
 	}
+
+	// Sources
+	if len(pkgInfo.Sources) > 0 {
+		fmt.Printf("• Sources: %d (%s)\n",
+			len(pkgInfo.Sources), truncateListAnalyze(sourceDomains(pkgInfo.Sources), 3))
+	}
+
+	// AUR git history
+	if len(pkgInfo.RecentCommits) > 0 {
+		fmt.Printf("• Git history: %s\n", describeRecentCommits(pkgInfo.RecentCommits))
+	}
 	
 	// AUR history
 	if pkgInfo.FirstSubmitted != "" && pkgInfo.LastUpdated != "" {
@@ -237,7 +544,28 @@ func displayCollectedDataAnalyze(pkgInfo *types.PackageInfo) {
 	
 	// Optional dependencies
 	if len(pkgInfo.OptDepends) > 0 {
-		fmt.Printf("• Optional dependencies: %d packages\n", len(pkgInfo.OptDepends))
+		fmt.Printf("• Optional dependencies: %d packages (%s)\n",
+			len(pkgInfo.OptDepends), truncateListAnalyze(optDependNames(pkgInfo.OptDepends), 5))
+	}
+
+	// License and keywords from the AUR metadata
+	if len(pkgInfo.License) > 0 {
+		fmt.Printf("• License: %s\n", strings.Join(pkgInfo.License, ", "))
+	}
+	if len(pkgInfo.Keywords) > 0 {
+		fmt.Printf("• Keywords: %s\n", truncateListAnalyze(pkgInfo.Keywords, 5))
+	}
+
+	// Config files the package manages
+	if backup := scanner.ParseBackup(pkgInfo.PKGBUILD); len(backup) > 0 {
+		fmt.Printf("• Backup files: %d (%s)\n", len(backup), truncateListAnalyze(backup, 3))
+	}
+
+	// What the AUR lookups couldn't provide
+	if enrichment != nil {
+		for _, gap := range enrichmentGaps(*enrichment) {
+			fmt.Printf("%s Missing: %s\n", ui.Warn, gap)
+		}
 	}
 	
 	fmt.Printf("\n")
@@ -251,40 +579,59 @@ func truncateListAnalyze(items []string, maxItems int) string {
 	return strings.Join(items[:maxItems], ", ") + fmt.Sprintf(" (+%d more)", len(items)-maxItems)
 }
 
-// runAnalyzeLocal analyzes a local PKGBUILD file or directory
-func runAnalyzeLocal(ctx context.Context, path string) error {
-	// Load configuration
-	cfg, err := config.Load()
+// runAnalyzeURL downloads an AUR snapshot tarball into a temporary directory
+// and analyzes it like a local package directory.
+func runAnalyzeURL(ctx context.Context, snapshotURL string) error {
+	cfg, _, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Initialize providers
-	registry := providers.NewProviderRegistry()
-	claudeProvider := providers.NewClaudeProvider()
-	claudeProvider.SetConfig(cfg)
-	registry.Register("claude", claudeProvider)
-	registry.Register("qwen", providers.NewQwenProvider())
-	registry.Register("copilot", providers.NewCopilotProvider())
-	registry.Register("goose", providers.NewGooseProvider())
+	tmpDir, err := os.MkdirTemp("", "yay-friend-snapshot-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
 
-	// Determine which provider to use
-	providerName := provider
-	if providerName == "" {
-		providerName = cfg.DefaultProvider
+	fmt.Printf("Downloading snapshot %s...\n", snapshotURL)
+	pkgDir, err := aur.DownloadSnapshot(ctx, snapshotURL, cfg.AUR.BaseURL, tmpDir)
+	if err != nil {
+		return fmt.Errorf("failed to fetch snapshot: %w", err)
 	}
-	if providerName == "" {
-		providerName = "claude"
+
+	return runAnalyzeLocal(ctx, pkgDir)
+}
+
+// runAnalyzeArchive extracts a local package archive into a temporary
+// directory and analyzes it like a local package directory.
+func runAnalyzeArchive(ctx context.Context, archivePath string) error {
+	tmpDir, err := os.MkdirTemp("", "yay-friend-archive-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	fmt.Printf("Extracting %s...\n", archivePath)
+	pkgDir, err := aur.ExtractArchive(archivePath, tmpDir)
+	if err != nil {
+		return fmt.Errorf("failed to extract archive: %w", err)
 	}
 
-	aiProvider, err := registry.Get(providerName)
+	return runAnalyzeLocal(ctx, pkgDir)
+}
+
+// runAnalyzeLocal analyzes a local PKGBUILD file or directory
+func runAnalyzeLocal(ctx context.Context, path string) error {
+	// Load configuration
+	cfg, rules, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("provider error: %w", err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Authenticate provider
-	if err := aiProvider.Authenticate(ctx); err != nil {
-		return fmt.Errorf("authentication failed for %s: %w", providerName, err)
+	// Initialize the provider; showing the prompt doesn't need authentication
+	aiProvider, err := selectProvider(ctx, cfg, rules, !promptOnlyFlag)
+	if err != nil {
+		return err
 	}
 
 	// Determine if path is a file or directory
@@ -305,11 +652,14 @@ func runAnalyzeLocal(ctx context.Context, path string) error {
 		}
 	}
 
-	fmt.Printf("🔍 Analyzing local PKGBUILD: %s with %s...\n", pkgbuildPath, aiProvider.Name())
+	fmt.Printf("%s Analyzing local PKGBUILD: %s with %s...\n", ui.Search, pkgbuildPath, aiProvider.Name())
 	fmt.Printf("Note: Local PKGBUILD analysis is not cached\n")
 
 	// Parse basic package info from PKGBUILD
 	pkgInfo := parseLocalPKGBUILD(string(pkgbuildContent), pkgbuildPath)
+	if packageBaseFlag != "" {
+		pkgInfo.PackageBase = packageBaseFlag
+	}
 	
 	// Try to read additional files from the same directory
 	dir := filepath.Dir(pkgbuildPath)
@@ -334,7 +684,7 @@ func runAnalyzeLocal(ctx context.Context, path string) error {
 	}
 
 	// Display what we collected for analysis
-	displayCollectedDataAnalyze(&pkgInfo)
+	displayCollectedDataAnalyze(&pkgInfo, nil)
 	
 	// Show if we found additional files
 	if len(pkgInfo.AdditionalFiles) > 0 {
@@ -349,23 +699,66 @@ func runAnalyzeLocal(ctx context.Context, path string) error {
 		fmt.Printf("• Install script: %s\n", filepath.Base(installScriptPath))
 	}
 
+	if promptOnlyFlag {
+		return printPrompt(aiProvider, pkgInfo)
+	}
+
 	// Analyze security
 	var analysis *types.SecurityAnalysis
-	if claudeProvider, ok := aiProvider.(*providers.ClaudeProvider); ok {
-		analysis, err = claudeProvider.AnalyzePKGBUILDWithOptions(ctx, pkgInfo, noSpinner)
-	} else {
-		analysis, err = aiProvider.AnalyzePKGBUILD(ctx, pkgInfo)
-	}
+	analysis, err = aiProvider.AnalyzePKGBUILDWithOptions(ctx, pkgInfo, types.AnalysisOptions{NoSpinner: noSpinner})
 	
 	if err != nil {
 		return fmt.Errorf("analysis failed: %w", err)
 	}
+	printAnalysisTime(analysis)
+
+	// The same local policy as for AUR packages; the community floors only
+	// apply where AUR metadata exists, which a local PKGBUILD lacks.
+	analysis.RiskScore = trust.RiskScore(analysis, pkgInfo)
+	trust.ApplyTypeFloors(analysis, pkgInfo, cfg.Security.TypeFloors)
+	trust.ApplyCommunityFloors(analysis, pkgInfo, cfg.SecurityThresholds.MinVotes, cfg.SecurityThresholds.MinPopularity)
+	compareUpstream(ctx, analysis, pkgInfo)
 
 	// Display detailed results
-	displayDetailedAnalysis(analysis)
+	if err := emitAnalysis(analysis, cfg); err != nil {
+		return err
+	}
+	runPostAnalysisHook(ctx, cfg, analysis)
+	if lintFlag {
+		displayLintIssues(pkgInfo.PKGBUILD)
+	}
+	fileReportTo(analysis, pkgInfo, cfg)
 
+	return onelineExit(analysis, cfg)
+}
+
+// printPrompt writes the prompt aiProvider would send for pkgInfo to
+// resultOut, for --prompt-only.
+func printPrompt(aiProvider types.AIProvider, pkgInfo types.PackageInfo) error {
+	builder, ok := aiProvider.(providers.PromptBuilder)
+	if !ok {
+		return fmt.Errorf("--prompt-only: provider %s builds no prompt to show", aiProvider.Name())
+	}
+	fmt.Fprintln(resultOut, builder.BuildPrompt(pkgInfo))
 	return nil
 }
+
+// compareUpstream adds the --compare-upstream finding to analysis. Like the
+// community floors it is applied after caching, since upstream releases move
+// on; a failed lookup only warns.
+func compareUpstream(ctx context.Context, analysis *types.SecurityAnalysis, pkgInfo types.PackageInfo) {
+	if !compareUpstreamFlag {
+		return
+	}
+	if _, _, ok := trust.GitHubRepo(pkgInfo); !ok {
+		fmt.Printf("%s No github.com url or source; skipping --compare-upstream\n", ui.Info)
+		return
+	}
+	if _, err := trust.ApplyUpstreamRelease(ctx, trust.NewReleaseChecker(), analysis, pkgInfo); err != nil {
+		fmt.Printf("Warning: Could not compare against upstream releases: %v\n", err)
+	}
+}
+
 // parseLocalPKGBUILD extracts basic package information from a PKGBUILD
 func parseLocalPKGBUILD(content string, path string) types.PackageInfo {
 	info := types.PackageInfo{
@@ -377,15 +770,22 @@ func parseLocalPKGBUILD(content string, path string) types.PackageInfo {
 		info.Name = match
 	}
 	
+	// Split packages name their base explicitly
+	if match := extractBashVar(content, "pkgbase"); match != "" {
+		info.PackageBase = match
+	}
+
 	// Extract version
 	if match := extractBashVar(content, "pkgver"); match != "" {
 		info.Version = match
 	}
+	info.DynamicVersion = scanner.HasPkgverFunction(content)
 	
 	// Extract description
 	if match := extractBashVar(content, "pkgdesc"); match != "" {
 		info.Description = match
 	}
+	info.URL = extractBashVar(content, "url")
 	
 	// Extract maintainer from comments
 	lines := strings.Split(content, "\n")
@@ -405,6 +805,9 @@ func parseLocalPKGBUILD(content string, path string) types.PackageInfo {
 	if deps := extractBashArray(content, "makedepends"); deps != nil {
 		info.MakeDepends = deps
 	}
+
+	info.Sources = scanner.ParseSources(content)
+	info.OptDepends = scanner.ParseOptDepends(content)
 	
 	// Set defaults for local analysis
 	info.AURPageURL = "Local PKGBUILD"
@@ -472,43 +875,29 @@ func findInstallScript(pkgbuild, dir string) string {
 func findAdditionalFiles(pkgbuild, dir string) []string {
 	var files []string
 	
-	// Look in source array (can be multi-line)
-	re := regexp.MustCompile(`(?s)source=\(([^)]+)\)`)
-	if matches := re.FindStringSubmatch(pkgbuild); len(matches) > 1 {
-		// Split by newlines and whitespace, clean up quotes
-		sourceContent := matches[1]
-		// Replace newlines with spaces for easier parsing
-		sourceContent = strings.ReplaceAll(sourceContent, "\n", " ")
-		sourceContent = strings.ReplaceAll(sourceContent, "\t", " ")
-		
-		// Find quoted strings and unquoted tokens
-		tokenRe := regexp.MustCompile(`(['"]?)([^'"\s]+)(['"]?)`)
-		tokens := tokenRe.FindAllStringSubmatch(sourceContent, -1)
-		
-		for _, token := range tokens {
-			item := token[2] // The actual value without quotes
-			// Skip URLs
-			if !strings.HasPrefix(item, "http://") && 
-			   !strings.HasPrefix(item, "https://") && 
-			   !strings.HasPrefix(item, "ftp://") {
-				// If contains variable, try to expand it
-				if strings.Contains(item, "$") {
-					// Common variable: $_channel = stable
-					expanded := strings.ReplaceAll(item, "$_channel", "stable")
-					expanded = strings.ReplaceAll(expanded, "${_channel}", "stable")
-					filePath := filepath.Join(dir, expanded)
-					if _, err := ioutil.ReadFile(filePath); err == nil {
-						files = append(files, expanded)
-						continue
-					}
-				}
-				
-				// Check if file exists as-is
-				filePath := filepath.Join(dir, item)
+	// Look in the source arrays (all architectures)
+	for _, item := range scanner.ParseSources(pkgbuild) {
+		// Skip URLs
+		if !strings.HasPrefix(item, "http://") && 
+		   !strings.HasPrefix(item, "https://") && 
+		   !strings.HasPrefix(item, "ftp://") {
+			// If contains variable, try to expand it
+			if strings.Contains(item, "$") {
+				// Common variable: $_channel = stable
+				expanded := strings.ReplaceAll(item, "$_channel", "stable")
+				expanded = strings.ReplaceAll(expanded, "${_channel}", "stable")
+				filePath := filepath.Join(dir, expanded)
 				if _, err := ioutil.ReadFile(filePath); err == nil {
-					files = append(files, item)
+					files = append(files, expanded)
+					continue
 				}
 			}
+			
+			// Check if file exists as-is
+			filePath := filepath.Join(dir, item)
+			if _, err := ioutil.ReadFile(filePath); err == nil {
+				files = append(files, item)
+			}
 		}
 	}
 	
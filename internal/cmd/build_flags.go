@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+	"github.com/aaronsb/yay-friend/internal/yay"
+)
+
+// warnWeakenedChecks announces and returns the options of operation that
+// skip checksum, PGP or dependency checks, and the MAKEFLAGS options that
+// change what make builds. The analysis reads the PKGBUILD as written; with
+// these options the build doesn't verify what it downloads, or doesn't do
+// what the PKGBUILD shows, so a source swapped after the analysis goes
+// unnoticed. The list is recorded on each analysis of the install.
+func warnWeakenedChecks(operation *types.YayOperation) []string {
+	weakened := append(yay.WeakenedChecks(operation), yay.WeakenedMakeflags(os.Getenv("MAKEFLAGS"))...)
+	if len(weakened) == 0 {
+		return nil
+	}
+	fmt.Printf("%s Warning: you are opting out of integrity checks for this install:\n", ui.Warn)
+	for _, check := range weakened {
+		fmt.Printf("   • %s\n", check)
+	}
+	fmt.Printf("   The analysis assumes these checks run; without them, sources are not verified against the PKGBUILD.\n\n")
+	return weakened
+}
+
+// displayWeakenedChecks repeats the analysis's weakened checks next to its
+// overall level.
+func displayWeakenedChecks(analysis *types.SecurityAnalysis) {
+	if len(analysis.WeakenedChecks) == 0 {
+		return
+	}
+	fmt.Printf("Build Flags: %s integrity checks disabled by your options\n", ui.Warn)
+	for _, check := range analysis.WeakenedChecks {
+		fmt.Printf("   • %s\n", check)
+	}
+}
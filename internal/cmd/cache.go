@@ -9,7 +9,10 @@ import (
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 
+	"github.com/aaronsb/yay-friend/internal/aur"
 	"github.com/aaronsb/yay-friend/internal/cache"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
 )
 
 // newCacheCmd creates the cache command
@@ -25,6 +28,12 @@ keyed by AUR git commit hashes to avoid redundant AI calls.`,
 	cmd.AddCommand(newCacheCleanCmd())
 	cmd.AddCommand(newCacheClearCmd())
 	cmd.AddCommand(newCacheShowCmd())
+	cmd.AddCommand(newCacheMigrateCmd())
+	cmd.AddCommand(newCacheReplayCmd())
+	cmd.AddCommand(newCacheDiffCmd())
+	cmd.AddCommand(newCacheWarmCmd())
+	cmd.AddCommand(newCacheVerifyCmd())
+	cmd.AddCommand(newCachePruneCmd())
 
 	return cmd
 }
@@ -50,7 +59,8 @@ func newCacheCleanCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "clean",
 		Short: "Clean expired cache entries",
-		Long:  `Remove cache entries older than the specified number of days (default: 90).`,
+		Long: `Remove cache entries older than the specified number of days (default: 90).
+Retained AUR clones (see --keep-clone) older than the same age are removed too.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runCacheClean(cmd.Context(), days)
 		},
@@ -68,7 +78,7 @@ func newCacheClearCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "clear",
 		Short: "Clear all cache entries",
-		Long:  `Remove all cached analysis results. This cannot be undone.`,
+		Long:  `Remove all cached analysis results and retained AUR clones. This cannot be undone.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runCacheClear(cmd.Context(), confirm)
 		},
@@ -81,16 +91,129 @@ func newCacheClearCmd() *cobra.Command {
 
 // newCacheShowCmd creates the cache show command
 func newCacheShowCmd() *cobra.Command {
+	var since, until string
+
 	cmd := &cobra.Command{
 		Use:   "show <package>",
 		Short: "Show cached analyses for a package",
-		Long:  `Display all cached security analyses for the specified package.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Display all cached security analyses for the specified package.
+--since and --until (YYYY-MM-DD, both inclusive) limit the list to analyses
+run within that date range.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, to, err := parseDateRange(since, until)
+			if err != nil {
+				return err
+			}
+			return runCacheShow(cmd.Context(), args[0], from, to)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Only show analyses run on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&until, "until", "", "Only show analyses run on or before this date (YYYY-MM-DD)")
+
+	return cmd
+}
+
+// parseDateRange parses --since/--until dates in local time. The returned
+// bounds are [from, to): to is the start of the day after until, so the until
+// date itself is included. A zero bound means unbounded.
+func parseDateRange(since, until string) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	if since != "" {
+		if from, err = time.ParseInLocation("2006-01-02", since, time.Local); err != nil {
+			return from, to, fmt.Errorf("invalid --since date %q (expected YYYY-MM-DD): %w", since, err)
+		}
+	}
+	if until != "" {
+		if to, err = time.ParseInLocation("2006-01-02", until, time.Local); err != nil {
+			return from, to, fmt.Errorf("invalid --until date %q (expected YYYY-MM-DD): %w", until, err)
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return from, to, fmt.Errorf("--since %s is after --until %s", since, until)
+	}
+	return from, to, nil
+}
+
+// newCacheMigrateCmd creates the cache migrate command
+func newCacheMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Regroup cached analyses by AUR package base",
+		Long: `Move analyses cached under a split package's own name into the directory of
+its AUR package base, so sibling packages built from the same PKGBUILD share
+one analysis per commit. Package bases are looked up in the AUR; entries whose
+base can't be determined are left in place. Safe to run more than once.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheMigrate(cmd.Context())
+		},
+	}
+
+	return cmd
+}
+
+// newCacheVerifyCmd creates the cache verify command
+func newCacheVerifyCmd() *cobra.Command {
+	var prune bool
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Find corrupt cache entries",
+		Long: `Check every cached analysis: it must parse, contain an analysis, and record
+the commit its file is named after. Invalid entries, e.g. from an interrupted
+write or a manual edit, are listed; --prune removes them.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheVerify(cmd.Context(), prune)
+		},
+	}
+
+	cmd.Flags().BoolVar(&prune, "prune", false, "Remove the invalid entries")
+
+	return cmd
+}
+
+// newCachePruneCmd creates the cache prune command
+func newCachePruneCmd() *cobra.Command {
+	var keepLatest int
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Keep only the newest cached analyses of each package",
+		Long: `Keep only the --keep-latest most recently cached analyses of each package
+and remove the older ones. Unlike clean, which removes entries by age, this
+trims packages that update often without touching rarely updated ones.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCachePrune(cmd.Context(), keepLatest)
+		},
+	}
+
+	cmd.Flags().IntVar(&keepLatest, "keep-latest", 0, "Number of cached analyses to keep per package")
+
+	return cmd
+}
+
+// newCacheReplayCmd creates the cache replay command
+func newCacheReplayCmd() *cobra.Command {
+	var commit string
+
+	cmd := &cobra.Command{
+		Use:   "replay <package>",
+		Short: "Re-display a cached analysis",
+		Long: `Show a cached security analysis in full, exactly as analyze displays it,
+without contacting the AUR or any AI provider. Uses the most recently cached
+analysis unless --commit selects another (a prefix, as shown by cache show,
+is enough). Split packages are cached under their package base; pass the base.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCacheShow(cmd.Context(), args[0])
+			return runCacheReplay(cmd.Context(), args[0], commit)
 		},
 	}
 
+	cmd.Flags().StringVar(&commit, "commit", "", "Replay the analysis cached for this AUR commit")
+
 	return cmd
 }
 
@@ -119,6 +242,10 @@ func runCacheStatus(ctx context.Context) error {
 	if !stats.NewestEntry.IsZero() {
 		fmt.Printf("Newest Entry: %s\n", stats.NewestEntry.Format("2006-01-02 15:04:05"))
 	}
+
+	if clones, err := cache.ListClones(); err == nil && len(clones) > 0 {
+		fmt.Printf("Retained Clones: %d (%s)\n", len(clones), strings.Join(clones, ", "))
+	}
 	
 	if stats.RecentHits > 0 || stats.RecentMisses > 0 {
 		total := stats.RecentHits + stats.RecentMisses
@@ -138,14 +265,22 @@ func runCacheClean(ctx context.Context, days int) error {
 		return fmt.Errorf("failed to initialize cache manager: %w", err)
 	}
 
-	fmt.Printf("🧹 Cleaning cache entries older than %d days...\n", days)
+	fmt.Printf("%s Cleaning cache entries older than %d days...\n", ui.Clean, days)
 	
 	maxAge := time.Duration(days) * 24 * time.Hour
 	if err := cacheManager.CleanExpiredCache(maxAge); err != nil {
 		return fmt.Errorf("failed to clean cache: %w", err)
 	}
 
-	fmt.Printf("✅ Cache cleaning completed\n")
+	removedClones, err := cache.CleanClones(maxAge)
+	if err != nil {
+		return fmt.Errorf("failed to clean retained clones: %w", err)
+	}
+	if removedClones > 0 {
+		fmt.Printf("%s Removed %d retained AUR clone(s)\n", ui.Clean, removedClones)
+	}
+
+	fmt.Printf("%s Cache cleaning completed\n", ui.OK)
 	return nil
 }
 
@@ -165,18 +300,27 @@ func runCacheClear(ctx context.Context, confirm bool) error {
 		return fmt.Errorf("failed to initialize cache manager: %w", err)
 	}
 
-	fmt.Printf("🗑️  Clearing all cache entries...\n")
+	fmt.Printf("%s Clearing all cache entries...\n", ui.Delete)
 	
 	// Clean all entries (0 days = everything)
 	if err := cacheManager.CleanExpiredCache(0); err != nil {
 		return fmt.Errorf("failed to clear cache: %w", err)
 	}
+	if _, err := cache.CleanClones(0); err != nil {
+		return fmt.Errorf("failed to clear retained clones: %w", err)
+	}
 
-	fmt.Printf("✅ All cache entries cleared\n")
+	fmt.Printf("%s All cache entries cleared\n", ui.OK)
 	return nil
 }
 
-func runCacheShow(ctx context.Context, packageName string) error {
+func runCacheShow(ctx context.Context, packageName string, from, to time.Time) error {
+	// The package base lookup goes to the AUR, with network.ca_cert and
+	// --insecure applied
+	if _, _, err := loadConfig(); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
 	cacheManager, err := cache.NewCacheManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize cache manager: %w", err)
@@ -187,6 +331,18 @@ func runCacheShow(ctx context.Context, packageName string) error {
 		return fmt.Errorf("failed to get package versions: %w", err)
 	}
 
+	// Analyses are cached under the package base; a split package's own name
+	// has no entries, so look its base up before giving up.
+	if len(versions) == 0 {
+		if base, err := aur.NewAURFetcher().ResolvePackageBase(ctx, packageName); err == nil && base != packageName {
+			packageName = base
+			versions, err = cacheManager.GetPackageVersions(packageName)
+			if err != nil {
+				return fmt.Errorf("failed to get package versions: %w", err)
+			}
+		}
+	}
+
 	if len(versions) == 0 {
 		fmt.Printf("No cached analyses found for package '%s'\n", packageName)
 		return nil
@@ -196,15 +352,24 @@ func runCacheShow(ctx context.Context, packageName string) error {
 	color.Bold.Printf("Cached Analyses for %s\n", packageName)
 	fmt.Printf(strings.Repeat("=", 40) + "\n")
 
-	for i, commitHash := range versions {
+	shown := 0
+	for _, commitHash := range versions {
 		analysis, err := cacheManager.GetCachedAnalysis(packageName, commitHash)
 		if err != nil {
-			fmt.Printf("%d. %s (error reading cache)\n", i+1, commitHash[:8])
+			shown++
+			fmt.Printf("%d. %s (error reading cache)\n", shown, commitHash[:8])
+			continue
+		}
+		if (!from.IsZero() && analysis.AnalyzedAt.Before(from)) || (!to.IsZero() && !analysis.AnalyzedAt.Before(to)) {
 			continue
 		}
+		shown++
 
-		fmt.Printf("%d. Commit: %s\n", i+1, commitHash[:8])
+		fmt.Printf("%d. Commit: %s\n", shown, commitHash[:8])
 		fmt.Printf("   Level: %s\n", analysis.OverallLevel.String())
+		if analysis.RiskScore > 0 {
+			fmt.Printf("   Risk score: %.0f/100\n", analysis.RiskScore)
+		}
 		fmt.Printf("   Provider: %s\n", analysis.Provider)
 		fmt.Printf("   Analyzed: %s\n", analysis.AnalyzedAt.Format("2006-01-02 15:04:05"))
 		if analysis.Summary != "" {
@@ -216,12 +381,191 @@ func runCacheShow(ctx context.Context, packageName string) error {
 			fmt.Printf("   Summary: %s\n", summary)
 		}
 		fmt.Printf("   Findings: %d\n", len(analysis.Findings))
+		if verboseFindings {
+			for _, finding := range analysis.Findings {
+				fmt.Printf("   - [%s] %s\n", finding.Severity.String(), finding.Type)
+				displayEntropyNotes(finding)
+			}
+		}
 		fmt.Println()
 	}
+	if shown == 0 {
+		fmt.Printf("No cached analyses of %s in the requested date range\n", packageName)
+	}
 
 	return nil
 }
 
+func runCacheReplay(ctx context.Context, packageName, commit string) error {
+	cacheManager, err := cache.NewCacheManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize cache manager: %w", err)
+	}
+
+	analysis, commitHash, err := findCachedAnalysis(cacheManager, packageName, commit)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Replaying cached analysis of %s at commit %s\n", packageName, shortCommit(commitHash))
+	// A replay is for demos and audits, so it always shows everything
+	displayDetailedAnalysis(analysis, true)
+
+	return nil
+}
+
+// findCachedAnalysis returns the analysis cached for packageName at the commit
+// matching the given prefix, or the newest one when commit is empty, along
+// with its full commit hash.
+func findCachedAnalysis(cacheManager *cache.CacheManager, packageName, commit string) (*types.SecurityAnalysis, string, error) {
+	versions, err := cacheManager.GetPackageVersions(packageName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get package versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, "", fmt.Errorf("no cached analyses found for package '%s' (split packages are cached under their package base)", packageName)
+	}
+
+	// Versions are newest first, so with no --commit the first one wins
+	commitHash := versions[0]
+	if commit != "" {
+		var matches []string
+		for _, v := range versions {
+			if strings.HasPrefix(v, commit) {
+				matches = append(matches, v)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, "", fmt.Errorf("no analysis of %s cached for commit %s", packageName, commit)
+		case 1:
+			commitHash = matches[0]
+		default:
+			return nil, "", fmt.Errorf("commit prefix %s is ambiguous for %s: matches %d cached analyses", commit, packageName, len(matches))
+		}
+	}
+
+	analysis, err := cacheManager.GetCachedAnalysis(packageName, commitHash)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read cached analysis: %w", err)
+	}
+	return analysis, commitHash, nil
+}
+
+func runCacheMigrate(ctx context.Context) error {
+	// Package bases are resolved from the AUR, with network.ca_cert and
+	// --insecure applied
+	if _, _, err := loadConfig(); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cacheManager, err := cache.NewCacheManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize cache manager: %w", err)
+	}
+
+	fmt.Printf("Resolving package bases from the AUR...\n")
+	fetcher := aur.NewAURFetcher()
+	result, err := cacheManager.MigrateToPackageBase(func(packageName string) (string, error) {
+		return fetcher.ResolvePackageBase(ctx, packageName)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate cache: %w", err)
+	}
+
+	fmt.Printf("%s Moved %d cached analyses under their package base", ui.OK, result.Moved)
+	if result.Duplicates > 0 {
+		fmt.Printf(" (%d duplicates of a sibling's analysis removed)", result.Duplicates)
+	}
+	fmt.Printf("\n")
+	if len(result.Unresolved) > 0 {
+		fmt.Printf("Left in place (package base unknown): %s\n", strings.Join(result.Unresolved, ", "))
+	}
+
+	return nil
+}
+
+func runCacheVerify(ctx context.Context, prune bool) error {
+	cacheManager, err := cache.NewCacheManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize cache manager: %w", err)
+	}
+
+	result, err := cacheManager.VerifyEntries(prune)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range result.Invalid {
+		fmt.Printf("%s %s: %s\n", ui.Fail, entry.Path, entry.Reason)
+	}
+	if len(result.Invalid) == 0 {
+		fmt.Printf("%s All %d cache entries are valid\n", ui.OK, result.Checked)
+		return nil
+	}
+
+	fmt.Printf("\nChecked %d cache entries: %d invalid\n", result.Checked, len(result.Invalid))
+	if prune {
+		fmt.Printf("%s Removed %d invalid entries\n", ui.Clean, result.Removed)
+	} else {
+		fmt.Printf("Run 'yay-friend cache verify --prune' to remove them\n")
+	}
+	return nil
+}
+
+func runCachePrune(ctx context.Context, keepLatest int) error {
+	if keepLatest < 1 {
+		return fmt.Errorf("--keep-latest must be at least 1, got %d", keepLatest)
+	}
+
+	cacheManager, err := cache.NewCacheManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize cache manager: %w", err)
+	}
+
+	pruned, err := cacheManager.PruneKeepLatest(keepLatest)
+	total := 0
+	for _, pkg := range pruned {
+		fmt.Printf("%s %s: removed %d, kept %d\n", ui.Clean, pkg.Package, pkg.Removed, pkg.Kept)
+		total += pkg.Removed
+	}
+	if err != nil {
+		return err
+	}
+
+	if total == 0 {
+		fmt.Printf("%s No package has more than %d cached analyses\n", ui.OK, keepLatest)
+		return nil
+	}
+	fmt.Printf("\nPruned %d cache entries from %d package(s)\n", total, len(pruned))
+	return nil
+}
+
+// openAnalysisCache returns the cache manager for an analysis run, or nil when
+// caching is disabled or the cache directory can't be created or written (e.g.
+// a read-only data dir). The warning is printed once here and the run then
+// skips every cache read and write rather than failing per package. With
+// --no-cache-write the cache is only read, so it need not be writable.
+func openAnalysisCache(cfg *types.Config) *cache.CacheManager {
+	if !cfg.Cache.Enabled {
+		return nil
+	}
+
+	cacheManager, err := cache.NewCacheManager()
+	if err == nil && noCacheWrite {
+		cacheManager.SetReadOnly(true)
+		return cacheManager
+	}
+	if err == nil {
+		err = cacheManager.CheckWritable()
+	}
+	if err != nil {
+		fmt.Printf("Warning: Caching disabled for this run: %v\n", err)
+		return nil
+	}
+	return cacheManager
+}
+
 // formatBytes formats a byte count into a human-readable string
 func formatBytes(bytes int64) string {
 	const unit = 1024
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aaronsb/yay-friend/internal/cache"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+)
+
+// newCacheDiffCmd creates the cache diff command
+func newCacheDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <package> <commitA> <commitB>",
+		Short: "Compare the cached analyses of two commits",
+		Long: `Compare the analyses cached for two AUR commits of a package: the change in
+overall level and predictability score, and the findings added and removed
+going from commitA to commitB. Findings are matched on type and context, as
+for the "since you last analyzed this" banner. Commit prefixes, as shown by
+cache show, are enough. Split packages are cached under their package base;
+pass the base.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheDiff(cmd.Context(), args[0], args[1], args[2])
+		},
+	}
+}
+
+func runCacheDiff(ctx context.Context, packageName, commitA, commitB string) error {
+	cacheManager, err := cache.NewCacheManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize cache manager: %w", err)
+	}
+
+	before, hashA, err := findCachedAnalysis(cacheManager, packageName, commitA)
+	if err != nil {
+		return err
+	}
+	after, hashB, err := findCachedAnalysis(cacheManager, packageName, commitB)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Comparing cached analyses of %s: %s (%s) → %s (%s)\n", packageName,
+		shortCommit(hashA), before.AnalyzedAt.Format("2006-01-02"),
+		shortCommit(hashB), after.AnalyzedAt.Format("2006-01-02"))
+	fmt.Printf("─────────────────────────\n")
+	printAnalysisDiff(before, after)
+
+	return nil
+}
+
+// printAnalysisDiff prints how after differs from before: overall level,
+// predictability score, and the findings added and removed.
+func printAnalysisDiff(before, after *types.SecurityAnalysis) {
+	switch {
+	case after.OverallLevel > before.OverallLevel:
+		fmt.Printf("%s Level: %s %s → %s %s (raised)\n", ui.Warn,
+			getEntropyIcon(before.OverallLevel), before.OverallLevel,
+			getEntropyIcon(after.OverallLevel), after.OverallLevel)
+	case after.OverallLevel < before.OverallLevel:
+		fmt.Printf("%s Level: %s %s → %s %s (lowered)\n", ui.Info,
+			getEntropyIcon(before.OverallLevel), before.OverallLevel,
+			getEntropyIcon(after.OverallLevel), after.OverallLevel)
+	default:
+		fmt.Printf("Level: %s %s (unchanged)\n", getEntropyIcon(after.OverallLevel), after.OverallLevel)
+	}
+
+	// Entries from before a score was recorded leave it at zero
+	if before.RiskScore != 0 || after.RiskScore != 0 {
+		fmt.Printf("Risk score: %.0f → %.0f (%+.0f)\n", before.RiskScore, after.RiskScore, after.RiskScore-before.RiskScore)
+	}
+	if before.PredictabilityScore != 0 || after.PredictabilityScore != 0 {
+		delta := after.PredictabilityScore - before.PredictabilityScore
+		fmt.Printf("Predictability: %.2f → %.2f (%+.2f)\n", before.PredictabilityScore, after.PredictabilityScore, delta)
+	}
+
+	added := newFindings(before.Findings, after.Findings)
+	removed := newFindings(after.Findings, before.Findings)
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Printf("\nFindings: no changes (%d in both)\n", len(after.Findings))
+		return
+	}
+	printFindingChanges("Added", "+", added)
+	printFindingChanges("Removed", "-", removed)
+}
+
+// printFindingChanges lists one side of a finding diff under a heading.
+func printFindingChanges(heading, marker string, findings []types.SecurityFinding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Printf("\n%s findings (%d):\n", heading, len(findings))
+	for _, finding := range findings {
+		fmt.Printf("  %s %s %s: %s\n", marker, getEntropyIcon(finding.Severity), finding.Type, finding.Description)
+		if finding.Context != "" {
+			fmt.Printf("      Context: %s\n", finding.Context)
+		}
+	}
+}
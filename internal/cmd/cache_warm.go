@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aaronsb/yay-friend/internal/aur"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+	"github.com/aaronsb/yay-friend/internal/yay"
+)
+
+// newCacheWarmCmd creates the cache warm command
+func newCacheWarmCmd() *cobra.Command {
+	var packages []string
+	var listFile string
+
+	cmd := &cobra.Command{
+		Use:   "warm --packages a,b,c | --file list.txt",
+		Short: "Pre-analyze packages into the cache",
+		Long: `Analyze a list of packages and save the results to the cache, e.g. before
+going offline or when setting up a new machine. Packages whose current AUR
+commit is already cached are skipped. Provider calls are spaced out to stay
+within the provider's rate limit. The list file has one package per line;
+blank lines and lines starting with # are ignored.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := packages
+			if listFile != "" {
+				fromFile, err := readPackageList(listFile)
+				if err != nil {
+					return err
+				}
+				names = append(names, fromFile...)
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("please specify packages with --packages or --file")
+			}
+			return runCacheWarm(cmd.Context(), names)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&packages, "packages", nil, "Comma-separated packages to analyze")
+	cmd.Flags().StringVar(&listFile, "file", "", "Read packages to analyze from a file, one per line")
+
+	return cmd
+}
+
+// readPackageList reads package names from path, one per line, ignoring
+// blank lines and # comments.
+func readPackageList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package list: %w", err)
+	}
+	defer f.Close()
+
+	var names []string
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read package list: %w", err)
+	}
+	return names, nil
+}
+
+func runCacheWarm(ctx context.Context, packages []string) error {
+	if noCacheWrite {
+		return fmt.Errorf("cache warm only writes the cache; it can't run with --no-cache-write")
+	}
+
+	cfg, rules, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cacheManager := openAnalysisCache(cfg)
+	if cacheManager == nil {
+		return fmt.Errorf("cache is disabled or unavailable; nothing to warm")
+	}
+
+	yayClient := yay.NewYayClient(cfg.Yay.Path)
+	if err := yayClient.IsAvailable(); err != nil {
+		return fmt.Errorf("yay not available: %w", err)
+	}
+
+	// Initialize the provider
+	aiProvider, err := selectProvider(ctx, cfg, rules, true)
+	if err != nil {
+		return err
+	}
+
+	// Space provider calls evenly so a long list stays under the rate limit
+	var interval time.Duration
+	if limit := aiProvider.GetCapabilities().RateLimitPerMinute; limit > 0 {
+		interval = time.Minute / time.Duration(limit)
+	}
+
+	aurFetcher := aur.NewAURFetcher()
+	if err := aurFetcher.PrefetchMetadata(ctx, packages); err != nil {
+		fmt.Printf("Warning: Could not prefetch AUR metadata: %v\n", err)
+	}
+	var analyzed, cached int
+	var failed []string
+	var lastCall time.Time
+
+	fmt.Printf("Warming cache for %d package(s) with %s...\n", len(packages), aiProvider.Name())
+	for i, name := range packages {
+		label := fmt.Sprintf("[%d/%d] %s", i+1, len(packages), name)
+
+		pkgInfo, err := yayClient.GetPackageInfo(ctx, name)
+		if err != nil {
+			fmt.Printf("%s: failed: %v\n", label, err)
+			failed = append(failed, name)
+			continue
+		}
+		enrichment := aurFetcher.EnrichPackageInfo(ctx, pkgInfo)
+		if enrichment.CommitErr != nil {
+			// Cache entries are keyed on the commit, so there's nothing to save
+			fmt.Printf("%s: failed: AUR commit hash unavailable: %v\n", label, enrichment.CommitErr)
+			failed = append(failed, name)
+			continue
+		}
+		if enrichment.MetadataErr != nil {
+			fmt.Printf("Warning: %s: AUR metadata unavailable: %v\n", name, enrichment.MetadataErr)
+		}
+		if cacheManager.IsCached(pkgInfo.Base(), pkgInfo.CommitHash) {
+			fmt.Printf("%s: already cached (commit: %s)\n", label, shortCommit(pkgInfo.CommitHash))
+			cached++
+			continue
+		}
+
+		if wait := interval - time.Since(lastCall); !lastCall.IsZero() && wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		lastCall = time.Now()
+
+		addRecentCommits(ctx, cfg, pkgInfo)
+		analysis, err := aiProvider.AnalyzePKGBUILDWithOptions(ctx, *pkgInfo, types.AnalysisOptions{NoSpinner: true})
+		if err != nil {
+			fmt.Printf("%s: failed: %v\n", label, err)
+			failed = append(failed, name)
+			continue
+		}
+		analysis.Context = enrichment.Completeness(*pkgInfo, analysis.Truncated())
+		if err := cacheManager.SaveAnalysis(pkgInfo.Base(), pkgInfo.CommitHash, analysis); err != nil {
+			fmt.Printf("%s: failed: could not save analysis: %v\n", label, err)
+			failed = append(failed, name)
+			continue
+		}
+		recordAnalysisTime(analysis)
+		fmt.Printf("%s: %s (commit: %s, %s)\n", label, analysis.OverallLevel, shortCommit(pkgInfo.CommitHash), formatSeconds(analysis.DurationSeconds))
+		analyzed++
+	}
+	printTotalAnalysisTime()
+
+	fmt.Printf("\n%s Analyzed %d, already cached %d, failed %d\n", ui.OK, analyzed, cached, len(failed))
+	if len(failed) > 0 {
+		return fmt.Errorf("could not warm the cache for: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -2,10 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/aaronsb/yay-friend/internal/config"
+	"github.com/aaronsb/yay-friend/internal/ui"
 )
 
 // newConfigCmd creates the config command
@@ -19,6 +26,8 @@ func newConfigCmd() *cobra.Command {
 	cmd.AddCommand(newConfigInitCmd())
 	cmd.AddCommand(newConfigShowCmd())
 	cmd.AddCommand(newConfigSetCmd())
+	cmd.AddCommand(newConfigExplainCmd())
+	cmd.AddCommand(newConfigEditCmd())
 
 	return cmd
 }
@@ -40,7 +49,7 @@ func newConfigShowCmd() *cobra.Command {
 		Short: "Show current configuration",
 		Long:  "Display the current configuration settings",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.Load()
+			cfg, _, err := loadConfig()
 			if err != nil {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
@@ -48,14 +57,31 @@ func newConfigShowCmd() *cobra.Command {
 			fmt.Println("Current Configuration:")
 			fmt.Printf("Default Provider: %s\n", cfg.DefaultProvider)
 			fmt.Printf("Claude Model: %s\n", cfg.Claude.Model)
+			fmt.Printf("Analysis Profile: %s\n", cfg.Analysis.Profile)
+			fmt.Printf("Dependency Depth on Install: %d\n", cfg.Analysis.DependencyDepth)
+			fmt.Printf("Allow --skip-analysis: %v\n", cfg.Security.AllowSkip)
+			fmt.Printf("Trusted Repositories: %s\n", strings.Join(cfg.Security.TrustedRepos, ", "))
+			if len(cfg.Security.TypeFloors) > 0 {
+				floorTypes := make([]string, 0, len(cfg.Security.TypeFloors))
+				for findingType := range cfg.Security.TypeFloors {
+					floorTypes = append(floorTypes, findingType)
+				}
+				sort.Strings(floorTypes)
+				fmt.Printf("Finding Type Floors:\n")
+				for _, findingType := range floorTypes {
+					fmt.Printf("  %s: %s\n", findingType, cfg.Security.TypeFloors[findingType].String())
+				}
+			}
 			fmt.Printf("Security Thresholds:\n")
 			fmt.Printf("  Block Level: %s\n", cfg.SecurityThresholds.BlockLevel.String())
 			fmt.Printf("  Warn Level: %s\n", cfg.SecurityThresholds.WarnLevel.String())
 			fmt.Printf("  Auto Proceed: %v\n", cfg.SecurityThresholds.AutoProceed)
+			fmt.Printf("  Min Votes: %d, Min Popularity: %g\n", cfg.SecurityThresholds.MinVotes, cfg.SecurityThresholds.MinPopularity)
 			fmt.Printf("UI Settings:\n")
 			fmt.Printf("  Show Details: %v\n", cfg.UI.ShowDetails)
 			fmt.Printf("  Use Colors: %v\n", cfg.UI.UseColors)
 			fmt.Printf("  Verbose Output: %v\n", cfg.UI.VerboseOutput)
+			fmt.Printf("  Locale: %s\n", ui.ResolveLocale(cfg.UI.Locale))
 			fmt.Printf("Yay Settings:\n")
 			fmt.Printf("  Path: %s\n", cfg.Yay.Path)
 			fmt.Printf("  Default Flags: %v\n", cfg.Yay.Flags)
@@ -86,3 +112,112 @@ func newConfigSetCmd() *cobra.Command {
 
 	return cmd
 }
+
+func newConfigExplainCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain",
+		Short: "Show each effective setting and where it comes from",
+		Long: `Show every configuration key with its effective value and its source:
+the built-in default, the analysis profile, the config file, a YAY_FRIEND_*
+environment variable, or a command-line flag. Later sources override
+earlier ones in that order.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigExplain()
+		},
+	}
+}
+
+func runConfigExplain() error {
+	config.SetProfile(profile)
+	config.SetProviderArgsFromEnv(providerArgsFromEnv)
+	cfg, sources, err := config.Explain()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	for key, flag := range applyFlagOverrides(cfg) {
+		sources[key] = "flag " + flag
+	}
+
+	path := config.Path()
+	if _, err := os.Stat(path); err != nil {
+		fmt.Printf("Config file: %s (not found; defaults apply)\n\n", path)
+	} else {
+		fmt.Printf("Config file: %s\n\n", path)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "KEY\tVALUE\tSOURCE\n")
+	for _, setting := range config.Settings(cfg, sources) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+	return w.Flush()
+}
+
+func newConfigEditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in your editor",
+		Long: `Open the config file in $VISUAL or $EDITOR (falling back to nano or vi),
+creating it with the defaults first if it doesn't exist. When the editor
+exits the file is validated; errors are reported and, on a terminal, the
+file can be reopened to fix them. Edits are never discarded.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigEdit()
+		},
+	}
+}
+
+func runConfigEdit() error {
+	path := config.Path()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := config.InitializeConfig(); err != nil {
+			return err
+		}
+	}
+
+	editor, err := editorCommand()
+	if err != nil {
+		return err
+	}
+	for {
+		cmd := exec.Command(editor[0], append(editor[1:], path)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("editor %s failed: %w", editor[0], err)
+		}
+
+		err := config.ValidateFile(path)
+		if err == nil {
+			fmt.Printf("%s Configuration is valid: %s\n", ui.OK, path)
+			return nil
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("%w (edits kept)", err)
+		}
+		fmt.Printf("%s %v\n", ui.Fail, err)
+		fmt.Print("Edit again? [Y/n]: ")
+		var response string
+		fmt.Scanln(&response)
+		if response == "n" || response == "N" {
+			return fmt.Errorf("config left invalid; edits kept in %s", path)
+		}
+	}
+}
+
+// editorCommand returns the editor to run, split into words: $VISUAL, then
+// $EDITOR, then the first of nano and vi on PATH.
+func editorCommand() ([]string, error) {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if words := strings.Fields(os.Getenv(name)); len(words) > 0 {
+			return words, nil
+		}
+	}
+	for _, editor := range []string{"nano", "vi"} {
+		if _, err := exec.LookPath(editor); err == nil {
+			return []string{editor}, nil
+		}
+	}
+	return nil, fmt.Errorf("no editor found: set $EDITOR")
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/aur"
+	"github.com/aaronsb/yay-friend/internal/cache"
+	"github.com/aaronsb/yay-friend/internal/trust"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+	"github.com/aaronsb/yay-friend/internal/yay"
+)
+
+// dependencyNode is one AUR package in the dependency tree of an analysis.
+type dependencyNode struct {
+	name     string
+	analysis *types.SecurityAnalysis
+	err      error
+	// repeat says why the node isn't expanded again: "cycle" when it is its
+	// own ancestor, "see above" when it was analyzed elsewhere in the tree.
+	repeat   string
+	children []*dependencyNode
+}
+
+// dependencyWalker analyzes the AUR dependencies of a package, depth first,
+// down to maxDepth levels. Each package is analyzed once per run, from the
+// cache whenever its current commit was analyzed before.
+type dependencyWalker struct {
+	cfg          *types.Config
+	yayClient    *yay.YayClient
+	provider     types.AIProvider
+	cacheManager *cache.CacheManager
+	aurFetcher   *aur.AURFetcher
+	maxDepth     int
+	seen         map[string]*dependencyNode
+	// confirm asks before going on when a dependency crosses the warn
+	// threshold, as the install path does for the package itself.
+	confirm bool
+	// weakened are the install's weakened checks (warnWeakenedChecks),
+	// recorded on every dependency's analysis.
+	weakened []string
+	// interval spaces fresh provider calls to stay within its rate limit;
+	// zero doesn't wait.
+	interval time.Duration
+	lastCall time.Time
+}
+
+// analyzeDependencyTree analyzes the AUR dependencies (depends and
+// makedepends) of an analyzed package down to maxDepth levels, prints the
+// tree of verdicts, and applies the block and warn thresholds to the worst
+// level anywhere in it, so a clean PKGBUILD can't vouch for a malicious
+// dependency. Official packages are skipped. A dependency that can't be
+// analyzed fails the tree, as a package that can't be analyzed fails on its
+// own: its risk is unknown.
+func analyzeDependencyTree(ctx context.Context, w *dependencyWalker, pkgInfo *types.PackageInfo, analysis *types.SecurityAnalysis) error {
+	w.seen = make(map[string]*dependencyNode)
+	root := &dependencyNode{name: pkgInfo.Name, analysis: analysis}
+	w.seen[root.name] = root
+
+	fmt.Printf("\n%s Resolving AUR dependencies of %s (depth %d)...\n", ui.Search, pkgInfo.Name, w.maxDepth)
+	root.children = w.expand(ctx, pkgInfo, 1, map[string]bool{root.name: true})
+
+	fmt.Printf("\n")
+	fmt.Printf("Dependency Tree (AUR packages only):\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 40))
+	printDependencyNode(root, "", "")
+
+	worst, worstName := analysis.DecisionLevel(), root.name
+	var failed []string
+	for name, node := range w.seen {
+		switch {
+		case node.err != nil:
+			failed = append(failed, name)
+		case node.analysis != nil && node.analysis.DecisionLevel() > worst:
+			worst, worstName = node.analysis.DecisionLevel(), name
+		}
+	}
+
+	fmt.Printf("\nWorst level in the dependency tree: %s %s (%s)\n", getEntropyIcon(worst), worst.String(), worstName)
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		fmt.Printf("%s Could not analyze %d dependencies (%s); their risk is unknown\n", ui.Fail, len(failed), strings.Join(failed, ", "))
+		return fmt.Errorf("could not analyze dependencies of %s: %s", root.name, strings.Join(failed, ", "))
+	}
+
+	if worst >= w.cfg.SecurityThresholds.BlockLevel {
+		fmt.Printf("\nBLOCKED: %s (%s) in the dependency tree exceeds block threshold (%s)\n",
+			worstName, worst.String(), w.cfg.SecurityThresholds.BlockLevel.String())
+		if worstName == root.name {
+			return fmt.Errorf("package %s %w", root.name, ErrBlockedByPolicy)
+		}
+		return fmt.Errorf("dependency %s of %s %w", worstName, root.name, ErrBlockedByPolicy)
+	}
+	if worst >= w.cfg.SecurityThresholds.WarnLevel {
+		fmt.Printf("\nWARNING: Security concerns in the dependency tree (%s entropy level)\n", worst.String())
+		// The package itself was confirmed already; ask again only for a
+		// dependency
+		if w.confirm && worstName != root.name && !w.cfg.SecurityThresholds.AutoProceed {
+			fmt.Print("\n" + ui.T(ui.MsgContinuePrompt))
+			var response string
+			fmt.Scanln(&response)
+			if response != "y" && response != "Y" {
+				return fmt.Errorf("installation of %s %w", root.name, ErrUserCancelled)
+			}
+		}
+	}
+	return nil
+}
+
+// expand analyzes the AUR dependencies of pkgInfo, which sits at depth-1,
+// and recurses into them. path holds the packages from the root down, to
+// catch cycles.
+func (w *dependencyWalker) expand(ctx context.Context, pkgInfo *types.PackageInfo, depth int, path map[string]bool) []*dependencyNode {
+	if depth > w.maxDepth {
+		return nil
+	}
+
+	depends := append(append([]string(nil), pkgInfo.Dependencies...), pkgInfo.MakeDepends...)
+	names, err := w.aurFetcher.AURDependencies(ctx, depends)
+	if err != nil {
+		fmt.Printf("Warning: Could not resolve the dependencies of %s: %v\n", pkgInfo.Name, err)
+		return nil
+	}
+
+	var nodes []*dependencyNode
+	for _, name := range names {
+		node := &dependencyNode{name: name}
+		switch previous := w.seen[name]; {
+		case path[name]:
+			node.repeat = "cycle"
+		case previous != nil:
+			node.analysis, node.err, node.repeat = previous.analysis, previous.err, "see above"
+		default:
+			w.seen[name] = node
+			var info *types.PackageInfo
+			info, node.analysis, node.err = w.analyze(ctx, name, depth)
+			if node.err == nil {
+				path[name] = true
+				node.children = w.expand(ctx, info, depth+1, path)
+				delete(path, name)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// analyze analyzes one dependency, printing a progress line instead of the
+// full report. Fresh analyses are cached like any other.
+func (w *dependencyWalker) analyze(ctx context.Context, name string, depth int) (*types.PackageInfo, *types.SecurityAnalysis, error) {
+	label := fmt.Sprintf("%s%s", strings.Repeat("  ", depth), name)
+
+	pkgInfo, err := w.yayClient.GetPackageInfo(ctx, name)
+	if err != nil {
+		fmt.Printf("%s: failed: %v\n", label, err)
+		return nil, nil, err
+	}
+	enrichment := w.aurFetcher.EnrichPackageInfo(ctx, pkgInfo)
+	if enrichment.MetadataErr != nil {
+		fmt.Printf("Warning: %s: AUR metadata unavailable: %v\n", name, enrichment.MetadataErr)
+	}
+
+	cacheable := w.cfg.Cache.Enabled && w.cacheManager != nil && pkgInfo.CommitHash != ""
+	var analysis *types.SecurityAnalysis
+	if cacheable {
+		if cached, err := w.cacheManager.GetCachedAnalysis(pkgInfo.Base(), pkgInfo.CommitHash); err == nil {
+			analysis = cached
+			analysis.PackageName = pkgInfo.Name // may have been cached for a sibling
+			analysis.WeakenedChecks = w.weakened
+			fmt.Printf("%s: %s cached (commit: %s)\n", label, ui.Cached, shortCommit(pkgInfo.CommitHash))
+		}
+	}
+	if analysis == nil {
+		if wait := w.interval - time.Since(w.lastCall); !w.lastCall.IsZero() && wait > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		w.lastCall = time.Now()
+
+		fmt.Printf("%s: %s analyzing...\n", label, ui.Fresh)
+		addRecentCommits(ctx, w.cfg, pkgInfo)
+		analysis, err = w.provider.AnalyzePKGBUILDWithOptions(ctx, *pkgInfo, types.AnalysisOptions{NoSpinner: noSpinner})
+		if err != nil {
+			fmt.Printf("%s: failed: %v\n", label, err)
+			return nil, nil, err
+		}
+		recordAnalysisTime(analysis)
+		analysis.Context = enrichment.Completeness(*pkgInfo, analysis.Truncated())
+		analysis.WeakenedChecks = w.weakened
+		if analysis.DurationSeconds > 0 {
+			fmt.Printf("%s: %s analyzed in %s\n", label, ui.Timer, formatSeconds(analysis.DurationSeconds))
+		}
+		if cacheable {
+			if err := w.cacheManager.SaveAnalysis(pkgInfo.Base(), pkgInfo.CommitHash, analysis); err != nil {
+				fmt.Printf("Warning: Could not save analysis to cache: %v\n", err)
+			}
+		}
+	}
+
+	analysis.RiskScore = trust.RiskScore(analysis, *pkgInfo)
+	trust.ApplyTypeFloors(analysis, *pkgInfo, w.cfg.Security.TypeFloors)
+	trust.ApplyCommunityFloors(analysis, *pkgInfo, w.cfg.SecurityThresholds.MinVotes, w.cfg.SecurityThresholds.MinPopularity)
+	return pkgInfo, analysis, nil
+}
+
+// printDependencyNode prints node and its subtree. prefix starts node's own
+// line and childPrefix the lines below it.
+func printDependencyNode(node *dependencyNode, prefix, childPrefix string) {
+	fmt.Printf("%s%s  ", prefix, node.name)
+	switch {
+	case node.repeat == "cycle":
+		fmt.Printf("(cycle)\n")
+	case node.err != nil:
+		fmt.Printf("%s analysis failed: %v\n", ui.Fail, node.err)
+	default:
+		level := node.analysis.DecisionLevel()
+		fmt.Printf("%s ", getEntropyIcon(level))
+		getEntropyColor(level).Printf("%s", level.String())
+		if node.repeat != "" {
+			fmt.Printf(" (%s)", node.repeat)
+		}
+		fmt.Printf("\n")
+	}
+
+	for i, child := range node.children {
+		if i == len(node.children)-1 {
+			printDependencyNode(child, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			printDependencyNode(child, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aaronsb/yay-friend/internal/aur"
+	"github.com/aaronsb/yay-friend/internal/providers"
+)
+
+var (
+	// ErrBlockedByPolicy marks a package refused by the block threshold, as
+	// opposed to an analysis that failed to run.
+	ErrBlockedByPolicy = errors.New("blocked by security policy")
+	// ErrMaintainerChanged marks a package refused by
+	// security_thresholds.block_on_maintainer_change. It wraps
+	// ErrBlockedByPolicy but has its own exit code.
+	ErrMaintainerChanged = fmt.Errorf("%w: maintainer changed", ErrBlockedByPolicy)
+	// ErrUserCancelled marks an installation the user declined at a prompt.
+	ErrUserCancelled = errors.New("cancelled by user")
+	// ErrInterrupted stands in for the error of a run stopped by SIGINT or
+	// SIGTERM, whose own error is only a side effect of the cancellation.
+	ErrInterrupted = errors.New("interrupted")
+)
+
+// Exit codes, so scripts can tell why yay-friend stopped. Anything not listed
+// exits with 1.
+const (
+	ExitError           = 1
+	ExitBlocked         = 2
+	ExitProviderAuth    = 3
+	ExitProviderFailure = 4
+	ExitNetwork         = 5
+	ExitMaintainer      = 6
+	ExitCancelled       = 130 // as for an interrupt
+)
+
+// packageFailures is the error of a --keep-going run in which some packages
+// did not pass. It wraps every package's error, so errors.Is sees a block or
+// provider failure in any of them.
+type packageFailures []error
+
+func (f packageFailures) Error() string {
+	return fmt.Sprintf("%d package(s) failed analysis", len(f))
+}
+
+func (f packageFailures) Unwrap() []error {
+	return f
+}
+
+// ExitCode maps an error to the process exit code. When a --keep-going run
+// failed for several reasons, the first match in this order wins:
+// cancellation, maintainer change, policy block, provider auth, provider
+// failure, network.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrUserCancelled), errors.Is(err, ErrInterrupted), errors.Is(err, context.Canceled):
+		return ExitCancelled
+	case errors.Is(err, ErrMaintainerChanged):
+		return ExitMaintainer
+	case errors.Is(err, ErrBlockedByPolicy):
+		return ExitBlocked
+	case errors.Is(err, providers.ErrProviderAuth):
+		return ExitProviderAuth
+	case errors.Is(err, providers.ErrProviderResponse):
+		return ExitProviderFailure
+	case errors.Is(err, aur.ErrNetwork):
+		return ExitNetwork
+	default:
+		return ExitError
+	}
+}
+
+// ReportError prints err and, for the failures that have an obvious next
+// step, a hint. It returns the exit code for err.
+func ReportError(w io.Writer, err error) int {
+	if errors.Is(err, ErrInterrupted) {
+		fmt.Fprintf(w, "Interrupted.\n")
+		return ExitCancelled
+	}
+	fmt.Fprintf(w, "Error: %v\n", err)
+
+	code := ExitCode(err)
+	switch code {
+	case ExitProviderAuth:
+		fmt.Fprintf(w, "Hint: check the provider with `yay-friend provider test`.\n")
+	case ExitProviderFailure:
+		fmt.Fprintf(w, "Hint: rerun with --debug to see the provider's raw output.\n")
+	case ExitNetwork:
+		fmt.Fprintf(w, "Hint: the AUR could not be reached; check your connection and retry.\n")
+	}
+	return code
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/spf13/cobra"
+
+	"github.com/aaronsb/yay-friend/internal/aur"
+	"github.com/aaronsb/yay-friend/internal/cache"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+	"github.com/aaronsb/yay-friend/internal/yay"
+)
+
+// newExplainCmd creates the explain command
+func newExplainCmd() *cobra.Command {
+	var cached bool
+
+	cmd := &cobra.Command{
+		Use:   "explain <package>",
+		Short: "Explain a package's security for learning",
+		Long: `Show why a package is considered risky or safe, for teaching rather than
+installing: the overall level, the educational summary, and the key security
+lessons. Findings and install prompts are left out; use analyze for those.
+
+The analysis for the package's current AUR commit is reused from the cache when
+present, otherwise it is run and cached. With --cached the newest cached
+analysis is shown without contacting the AUR or any AI provider.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExplain(cmd.Context(), args[0], cached)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cached, "cached", false, "Explain the newest cached analysis, offline")
+
+	return cmd
+}
+
+func runExplain(ctx context.Context, packageName string, cached bool) error {
+	if cached {
+		cacheManager, err := cache.NewCacheManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize cache manager: %w", err)
+		}
+		analysis, _, err := findCachedAnalysis(cacheManager, packageName, "")
+		if err != nil {
+			return err
+		}
+		displayExplanation(analysis)
+		return nil
+	}
+
+	cfg, rules, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	yayClient := yay.NewYayClient(cfg.Yay.Path)
+	if err := yayClient.IsAvailable(); err != nil {
+		return fmt.Errorf("yay not available: %w", err)
+	}
+
+	pkgInfo, err := yayClient.GetPackageInfo(ctx, packageName)
+	if err != nil {
+		return fmt.Errorf("failed to get package info: %w", err)
+	}
+	aurFetcher := aur.NewAURFetcher()
+	enrichment := aurFetcher.EnrichPackageInfo(ctx, pkgInfo)
+	for _, gap := range enrichmentGaps(enrichment) {
+		fmt.Printf("Warning: %s\n", gap)
+	}
+
+	cacheManager := openAnalysisCache(cfg)
+	if cacheManager != nil && pkgInfo.CommitHash != "" {
+		if analysis, err := cacheManager.GetCachedAnalysis(pkgInfo.Base(), pkgInfo.CommitHash); err == nil {
+			analysis.PackageName = pkgInfo.Name // may have been cached for a sibling
+			displayExplanation(analysis)
+			return nil
+		}
+	}
+
+	// Initialize the provider
+	aiProvider, err := selectProvider(ctx, cfg, rules, true)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Analyzing %s with %s...\n", ui.Search, packageName, aiProvider.Name())
+	addRecentCommits(ctx, cfg, pkgInfo)
+	analysis, err := aiProvider.AnalyzePKGBUILDWithOptions(ctx, *pkgInfo, types.AnalysisOptions{NoSpinner: noSpinner})
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+	printAnalysisTime(analysis)
+	analysis.Context = enrichment.Completeness(*pkgInfo, analysis.Truncated())
+	if cacheManager != nil && pkgInfo.CommitHash != "" {
+		if err := cacheManager.SaveAnalysis(pkgInfo.Base(), pkgInfo.CommitHash, analysis); err != nil {
+			fmt.Printf("Warning: Could not save analysis to cache: %v\n", err)
+		}
+	}
+
+	displayExplanation(analysis)
+	return nil
+}
+
+// displayExplanation shows the learning-focused view of an analysis: the
+// overall level and the educational fields, without findings or prompts.
+func displayExplanation(analysis *types.SecurityAnalysis) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 60))
+	color.Bold.Printf("Understanding %s\n", analysis.PackageName)
+	fmt.Printf("%s\n", strings.Repeat("=", 60))
+	fmt.Printf("Security Entropy: %s %s\n", getEntropyIcon(analysis.OverallLevel), analysis.OverallLevel.String())
+
+	if analysis.EducationalSummary == "" && len(analysis.SecurityLessons) == 0 {
+		fmt.Printf("\nThis analysis has no educational notes. Summary:\n%s\n", ui.Wrap("", "", analysis.Summary))
+		fmt.Printf("\nRun `yay-friend analyze %s` for the detailed findings.\n", analysis.PackageName)
+		return
+	}
+	displayEducation(analysis)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/namcap"
+	"github.com/aaronsb/yay-friend/internal/scanner"
+	"github.com/aaronsb/yay-friend/internal/trust"
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// findingTypes lists the finding types an analysis can contain: the
+// categories the AI provider is asked to use, the static pre-scan rules, and
+// the community-vetting floor.
+var findingTypes = []struct {
+	name        string
+	description string
+}{
+	{"malicious_code", "Code that appears intended to harm the system or user"},
+	{"suspicious_behavior", "Unusual behavior that is not clearly malicious"},
+	{"source_analysis", "Where sources come from and how they are verified"},
+	{"build_process", "What the build and package steps do"},
+	{"file_operations", "Files created, changed, or removed outside the package"},
+	{"maintainer_trust", "Maintainer and repository history"},
+	{"dependency_analysis", "Dependencies and what they pull in"},
+
+	{string(scanner.KindSuspiciousCommand), "Pre-scan: a command commonly used by malware"},
+	{string(scanner.KindBuildTimeDownload), "Pre-scan: a network fetch in pkgver(), prepare(), build() or check()"},
+	{string(scanner.KindShellIndirection), "Pre-scan: commands assembled from variables or escapes"},
+	{string(scanner.KindObfuscatedLiteral), "Pre-scan: an encoded or obfuscated literal"},
+	{string(scanner.KindSetuidMode), "Pre-scan: setuid or setgid permissions"},
+	{string(scanner.KindWorldWritable), "Pre-scan: a file or directory made writable by every user"},
+	{string(scanner.KindSystemWrite), "Pre-scan: a write to a system path outside $pkgdir"},
+	{string(scanner.KindSourceHostMismatch), "Pre-scan: a source hosted away from the upstream URL"},
+	{string(scanner.KindSourceVersionMismatch), "Pre-scan: a source URL or tag naming a different version than pkgver"},
+	{string(scanner.KindNameMismatch), "Pre-scan: a well-known or generic package name at odds with where its sources come from"},
+	{string(scanner.KindInsecureSource), "Pre-scan: a source over plain http/ftp or on a raw IP address"},
+	{string(scanner.KindUnpinnedVCSSource), "Pre-scan: a VCS source on a moving branch, or git submodules fetched at build time"},
+	{string(scanner.KindUserDataAccess), "Pre-scan: access to user data such as keys or browser profiles"},
+	{string(scanner.KindHiddenSystemFile), "Pre-scan: a hidden file installed into a system directory or shipped as a source"},
+	{string(scanner.KindPathTraversal), "Pre-scan: a ../ path escaping $pkgdir, $srcdir or a system path"},
+	{string(scanner.KindPrivilegeEscalation), "Pre-scan: sudo, su, pkexec, doas or run0 run from a build function or install hook"},
+	{string(scanner.KindSensitiveBackup), "Pre-scan: backup=() claims sudoers, PAM, account or similar system files"},
+	{string(scanner.KindPersistence), "Pre-scan: a cron job, systemd timer or service, or autostart entry installed or enabled"},
+	{string(scanner.KindShellProfileWrite), "Pre-scan: a write to ~/.bashrc, /etc/profile.d or another shell startup file"},
+	{string(scanner.KindLicenseInfo), "Pre-scan: no license anywhere, or an AUR-listed license the PKGBUILD doesn't declare"},
+	{string(scanner.KindRiskyOptDepend), "Pre-scan: an optional dependency on keylogging, mining, tunnelling or credential tools"},
+	{scanner.UserKindPrefix + "<name>", "Pre-scan: a match of the user rule <name> from scanner.rules_dir"},
+
+	{"limited_community_vetting", "Votes or popularity below the configured community floor"},
+	{"upstream_release_mismatch", "pkgver matches no GitHub upstream release (--compare-upstream)"},
+	{trust.PromptTruncatedType, "Part of the PKGBUILD, install script or helper files too large to send for analysis"},
+	{namcap.FindingType, "A namcap packaging error or warning, informational only (namcap.enabled, installs only)"},
+}
+
+// listFindingTypes prints every known finding type with its description.
+func listFindingTypes(w io.Writer) {
+	for _, t := range findingTypes {
+		fmt.Fprintf(w, "  %-26s %s\n", t.name, t.description)
+	}
+}
+
+// parseFindingTypes splits a comma-separated --type value and rejects names
+// that are not known finding types.
+func parseFindingTypes(value string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		known := strings.HasPrefix(name, scanner.UserKindPrefix) // user rules aren't known in advance
+		for _, t := range findingTypes {
+			if t.name == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown finding type %q (see --list-findings-types)", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// filterFindings returns a copy of analysis showing only the findings of the
+// given types, or analysis itself when no types are given. The levels and
+// recommendation are left alone: the filter narrows what is displayed, not
+// the verdict.
+func filterFindings(analysis *types.SecurityAnalysis, names []string) *types.SecurityAnalysis {
+	if len(names) == 0 {
+		return analysis
+	}
+	filtered := *analysis
+	filtered.Findings = []types.SecurityFinding{}
+	for _, finding := range analysis.Findings {
+		for _, name := range names {
+			if finding.Type == name {
+				filtered.Findings = append(filtered.Findings, finding)
+				break
+			}
+		}
+	}
+	return &filtered
+}
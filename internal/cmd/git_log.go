@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/aur"
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// addRecentCommits attaches the package's recent AUR commits to pkgInfo when
+// trust.include_git_log is set. Failure only costs the extra context.
+func addRecentCommits(ctx context.Context, cfg *types.Config, pkgInfo *types.PackageInfo) {
+	if !cfg.Trust.IncludeGitLog {
+		return
+	}
+	commits, err := aur.RecentCommits(ctx, pkgInfo.Base(), cfg.Trust.GitLogCommits)
+	if err != nil {
+		fmt.Printf("Warning: Could not read AUR git history: %v\n", err)
+		return
+	}
+	pkgInfo.RecentCommits = commits
+}
+
+// describeRecentCommits summarizes the commits for the collected-data view:
+// how many, the span they cover, and who wrote them.
+func describeRecentCommits(commits []types.GitCommit) string {
+	newest, oldest := commits[0], commits[len(commits)-1]
+	var authors []string
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		if !seen[commit.Author] {
+			seen[commit.Author] = true
+			authors = append(authors, commit.Author)
+		}
+	}
+	return fmt.Sprintf("%d recent commits, %s to %s, by %s", len(commits),
+		oldest.Date.Format("2006-01-02"), newest.Date.Format("2006-01-02"), strings.Join(authors, ", "))
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// postAnalysisHookTimeout bounds a hooks.post_analysis command, so a hung
+// webhook can't stall an install.
+const postAnalysisHookTimeout = time.Minute
+
+// runPostAnalysisHook runs the hooks.post_analysis command, if one is
+// configured, after a package's analysis completes. The command runs under
+// sh -c with the analysis as JSON on stdin. In the template, {FILE} becomes
+// the path of a temporary file holding the same JSON, {PACKAGE} the package
+// name and {LEVEL} its overall level, each shell-quoted. The hook's output
+// goes to stderr so it can't mix with --format json. A failing hook only
+// warns; it never blocks the package.
+func runPostAnalysisHook(ctx context.Context, cfg *types.Config, analysis *types.SecurityAnalysis) {
+	template := strings.TrimSpace(cfg.Hooks.PostAnalysis)
+	if template == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(analysis, "", "  ")
+	if err != nil {
+		fmt.Printf("Warning: Post-analysis hook skipped: failed to encode analysis: %v\n", err)
+		return
+	}
+
+	file := ""
+	if strings.Contains(template, "{FILE}") {
+		f, err := os.CreateTemp("", "yay-friend-analysis-*.json")
+		if err != nil {
+			fmt.Printf("Warning: Post-analysis hook skipped: %v\n", err)
+			return
+		}
+		defer os.Remove(f.Name())
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Printf("Warning: Post-analysis hook skipped: failed to write analysis file: %v\n", err)
+			return
+		}
+		file = f.Name()
+	}
+
+	command := strings.NewReplacer(
+		"{FILE}", shellQuote(file),
+		"{PACKAGE}", shellQuote(analysis.PackageName),
+		"{LEVEL}", shellQuote(analysis.OverallLevel.String()),
+	).Replace(template)
+
+	hookCtx, cancel := context.WithTimeout(ctx, postAnalysisHookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(hookCtx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if verbose {
+		fmt.Printf("Running post-analysis hook: %s\n", command)
+	}
+	if err := cmd.Run(); err != nil {
+		if hookCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", postAnalysisHookTimeout)
+		}
+		fmt.Printf("Warning: Post-analysis hook failed for %s: %v\n", analysis.PackageName, err)
+	}
+}
+
+// shellQuote quotes s as a single sh word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
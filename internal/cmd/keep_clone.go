@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aaronsb/yay-friend/internal/aur"
+	"github.com/aaronsb/yay-friend/internal/cache"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+)
+
+// retainCloneIfFlagged keeps a clone of the package's AUR repository under the
+// data dir when the analysis came back HIGH or CRITICAL and --keep-clone (or
+// trust.keep_clone) is set, so the user can inspect commit history and diffs
+// themselves. The repository is named after the package base, which split
+// packages share. Failure is only a warning; it never changes the verdict.
+func retainCloneIfFlagged(ctx context.Context, cfg *types.Config, packageBase string, analysis *types.SecurityAnalysis) {
+	if !keepClone && !cfg.Trust.KeepClone {
+		return
+	}
+	if !isFlaggedForInspection(analysis) {
+		return
+	}
+
+	clonePath := cache.ClonePath(packageBase)
+	fmt.Printf("Keeping a clone of the AUR repository for inspection...\n")
+	if err := aur.CloneRepository(ctx, packageBase, clonePath); err != nil {
+		fmt.Printf("Warning: Could not keep AUR clone: %v\n", err)
+		return
+	}
+	fmt.Printf("%s AUR repository kept at %s\n", ui.Folder, clonePath)
+	fmt.Printf("   Inspect with: git -C %s log -p\n", clonePath)
+	fmt.Printf("   Remove with: yay-friend cache clean (or cache clear)\n")
+}
+
+// isFlaggedForInspection reports whether the analysis, or any single finding,
+// reached HIGH entropy or above.
+func isFlaggedForInspection(analysis *types.SecurityAnalysis) bool {
+	if analysis.OverallLevel >= types.EntropyHigh {
+		return true
+	}
+	for _, finding := range analysis.Findings {
+		if finding.Entropy >= types.EntropyHigh {
+			return true
+		}
+	}
+	return false
+}
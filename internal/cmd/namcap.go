@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aaronsb/yay-friend/internal/namcap"
+	"github.com/aaronsb/yay-friend/internal/trust"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+)
+
+// runNamcapChecks runs namcap on each approved package when namcap.enabled
+// is set, adds its errors and warnings to the package's analysis as MINIMAL
+// findings, and lists them. namcap sources the PKGBUILD, running its
+// top-level code, so it only runs once the install is approved, just before
+// makepkg would source the PKGBUILD anyway; analyze alone never runs it. Its
+// findings are packaging checks and don't change the decision, and like the
+// community floors they aren't cached. A missing namcap is noted once; a
+// failed run only warns.
+func runNamcapChecks(ctx context.Context, cfg *types.Config, approved []*types.SecurityAnalysis, packages []*types.PackageInfo) {
+	if !cfg.Namcap.Enabled {
+		return
+	}
+	for i, pkgInfo := range packages {
+		issues, err := namcap.Run(ctx, cfg.Namcap.Path, pkgInfo.PKGBUILD)
+		if errors.Is(err, namcap.ErrNotInstalled) {
+			fmt.Printf("%s namcap.enabled is set but namcap isn't installed; skipping packaging checks\n", ui.Info)
+			return
+		}
+		if err != nil {
+			fmt.Printf("Warning: Could not run namcap on %s: %v\n", pkgInfo.Name, err)
+			continue
+		}
+		findings := namcap.Findings(issues)
+		if len(findings) == 0 {
+			continue
+		}
+		approved[i].Findings = append(approved[i].Findings, findings...)
+		approved[i].RiskScore = trust.RiskScore(approved[i], *pkgInfo)
+		fmt.Printf("%s namcap packaging checks for %s:\n", ui.Info, pkgInfo.Name)
+		for _, finding := range findings {
+			fmt.Printf("   • %s\n", finding.Description)
+		}
+	}
+}
+
+// runApprovedHooks runs the post-analysis hook on each approved analysis.
+// The install path holds the hook back until namcap has run, or until the
+// install is called off, so the hook sees namcap's findings; a blocked or
+// declined package gets its hook at once.
+func runApprovedHooks(ctx context.Context, cfg *types.Config, approved []*types.SecurityAnalysis) {
+	for _, analysis := range approved {
+		runPostAnalysisHook(ctx, cfg, analysis)
+	}
+}
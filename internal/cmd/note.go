@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aaronsb/yay-friend/internal/aur"
+	"github.com/aaronsb/yay-friend/internal/cache"
+	"github.com/aaronsb/yay-friend/internal/ui"
+)
+
+// newNoteCmd creates the note command
+func newNoteCmd() *cobra.Command {
+	var clear bool
+
+	cmd := &cobra.Command{
+		Use:   "note <package> [text]",
+		Short: "Keep personal notes about a package",
+		Long: `Leave yourself a note about a package, such as what you decided when you
+last reviewed it. Notes are kept under the data directory and shown again
+whenever the package is analyzed or installed.
+
+With only a package name, the package's notes are listed. With --clear they
+are removed.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			packageName := args[0]
+			if !aur.ValidatePackageName(packageName) {
+				return fmt.Errorf("invalid package name %q", packageName)
+			}
+			text := strings.TrimSpace(strings.Join(args[1:], " "))
+
+			switch {
+			case clear:
+				if text != "" {
+					return fmt.Errorf("--clear takes only a package name")
+				}
+				removed, err := cache.ClearNotes(packageName)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s Removed %d note(s) for %s\n", ui.Delete, removed, packageName)
+			case text != "":
+				if err := cache.AddNote(packageName, text); err != nil {
+					return err
+				}
+				fmt.Printf("%s Note saved for %s\n", ui.OK, packageName)
+			default:
+				if !printNotes(packageName) {
+					fmt.Printf("No notes for %s\n", packageName)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, "Remove all notes for the package")
+
+	return cmd
+}
+
+// printNotes shows the user's notes for packageName, if any, and reports
+// whether there were some. A note that can't be read is only a warning.
+func printNotes(packageName string) bool {
+	notes, err := cache.LoadNotes(packageName)
+	if err != nil {
+		fmt.Printf("Warning: Could not read notes for %s: %v\n", packageName, err)
+		return false
+	}
+	if len(notes) == 0 {
+		return false
+	}
+
+	fmt.Printf("\n%s Your notes on %s:\n", ui.Note, packageName)
+	for _, note := range notes {
+		fmt.Printf("   %s  %s\n", note.CreatedAt.Format("2006-01-02"), note.Text)
+	}
+	return true
+}
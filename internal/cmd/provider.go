@@ -1,23 +1,34 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/gookit/color"
 	"github.com/spf13/cobra"
 
+	"github.com/aaronsb/yay-friend/internal/aur"
 	"github.com/aaronsb/yay-friend/internal/providers"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+	"github.com/aaronsb/yay-friend/internal/yay"
 )
 
 // newProviderCmd creates the provider command
 func newProviderCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "provider",
-		Short: "Manage AI providers",
-		Long:  "Manage and test AI provider connections",
+		Use:     "provider",
+		Aliases: []string{"providers"},
+		Short:   "Manage AI providers",
+		Long:    "Manage and test AI provider connections",
 	}
 
 	cmd.AddCommand(newProviderListCmd())
 	cmd.AddCommand(newProviderTestCmd())
+	cmd.AddCommand(newProviderBenchmarkCmd())
 
 	return cmd
 }
@@ -41,9 +52,9 @@ func newProviderListCmd() *cobra.Command {
 				provider, _ := registry.Get(name)
 				capabilities := provider.GetCapabilities()
 				
-				status := "❌ Not authenticated"
+				status := fmt.Sprintf("%s Not authenticated", ui.Fail)
 				if provider.IsAuthenticated() {
-					status = "✅ Authenticated"
+					status = fmt.Sprintf("%s Authenticated", ui.OK)
 				}
 
 				fmt.Printf("Name: %s\n", name)
@@ -83,10 +94,10 @@ func newProviderTestCmd() *cobra.Command {
 
 				fmt.Printf("Testing %s...\n", providerName)
 				if err := provider.Authenticate(cmd.Context()); err != nil {
-					fmt.Printf("❌ Authentication failed: %v\n", err)
+					fmt.Printf("%s Authentication failed: %v\n", ui.Fail, err)
 					return err
 				}
-				fmt.Printf("✅ %s authentication successful\n", providerName)
+				fmt.Printf("%s %s authentication successful\n", ui.OK, providerName)
 			} else {
 				// Test all providers
 				fmt.Println("Testing all providers...")
@@ -94,9 +105,9 @@ func newProviderTestCmd() *cobra.Command {
 				
 				for name, err := range results {
 					if err != nil {
-						fmt.Printf("❌ %s: %v\n", name, err)
+						fmt.Printf("%s %s: %v\n", ui.Fail, name, err)
 					} else {
-						fmt.Printf("✅ %s: Authentication successful\n", name)
+						fmt.Printf("%s %s: Authentication successful\n", ui.OK, name)
 					}
 				}
 			}
@@ -104,4 +115,113 @@ func newProviderTestCmd() *cobra.Command {
 			return nil
 		},
 	}
-}
\ No newline at end of file
+}
+
+func newProviderBenchmarkCmd() *cobra.Command {
+	var packages, only []string
+
+	cmd := &cobra.Command{
+		Use:   "benchmark --packages a,b,c",
+		Short: "Compare providers on a fixed set of packages",
+		Long: `Run every authenticated provider over the same packages and compare them:
+latency, success rate, and how often each pair agrees on the overall level and
+recommendation. Every analysis is a real provider call; the analysis cache is
+neither read nor written, since cached results aren't tied to a provider.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(packages) == 0 {
+				return fmt.Errorf("please specify packages with --packages")
+			}
+			return runProviderBenchmark(cmd.Context(), packages, only)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&packages, "packages", nil, "Comma-separated packages to analyze")
+	cmd.Flags().StringSliceVar(&only, "providers", nil, "Comma-separated providers to benchmark (default: all authenticated)")
+
+	return cmd
+}
+
+func runProviderBenchmark(ctx context.Context, packages, only []string) error {
+	cfg, rules, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	yayClient := yay.NewYayClient(cfg.Yay.Path)
+	if err := yayClient.IsAvailable(); err != nil {
+		return fmt.Errorf("yay not available: %w", err)
+	}
+
+	registry := newProviderRegistry(cfg, rules)
+
+	names := only
+	if len(names) == 0 {
+		names = registry.List()
+		sort.Strings(names)
+	}
+
+	var candidates []types.AIProvider
+	for _, name := range names {
+		p, err := registry.Get(name)
+		if err != nil {
+			return err
+		}
+		if err := p.Authenticate(ctx); err != nil {
+			fmt.Printf("Skipping %s: %v\n", name, err)
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no authenticated providers to benchmark")
+	}
+
+	// Fetch each package once so every provider sees identical input
+	aurFetcher := aur.NewAURFetcher()
+	var pkgInfos []types.PackageInfo
+	for _, name := range packages {
+		pkgInfo, err := yayClient.GetPackageInfo(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to get package info for %s: %w", name, err)
+		}
+		for _, gap := range enrichmentGaps(aurFetcher.EnrichPackageInfo(ctx, pkgInfo)) {
+			fmt.Printf("Warning: %s: %s\n", name, gap)
+		}
+		pkgInfos = append(pkgInfos, *pkgInfo)
+	}
+
+	fmt.Printf("Benchmarking %d provider(s) on %d package(s)...\n", len(candidates), len(pkgInfos))
+	report := providers.RunBenchmark(ctx, candidates, pkgInfos)
+
+	fmt.Printf("\n")
+	color.Bold.Printf("Provider Benchmark\n")
+	fmt.Printf(strings.Repeat("=", 60) + "\n")
+	fmt.Printf("%-10s %9s %12s %12s\n", "Provider", "Success", "Avg", "Max")
+	for _, s := range report.Stats() {
+		fmt.Printf("%-10s %4d/%-4d %12s %12s\n", s.Provider, s.Successes, s.Runs,
+			s.AvgLatency.Round(time.Millisecond), s.MaxLatency.Round(time.Millisecond))
+	}
+	for _, name := range report.Providers {
+		for _, run := range report.Runs[name] {
+			if run.Err != nil {
+				fmt.Printf("  %s failed on %s: %v\n", name, run.Package, run.Err)
+			}
+		}
+	}
+
+	if pairs := report.Agreement(); len(pairs) > 0 {
+		fmt.Printf("\n")
+		color.Bold.Printf("Agreement\n")
+		fmt.Printf(strings.Repeat("-", 60) + "\n")
+		for _, p := range pairs {
+			if p.Compared == 0 {
+				fmt.Printf("%s vs %s: no package analyzed by both\n", p.A, p.B)
+				continue
+			}
+			fmt.Printf("%s vs %s: level %d/%d, recommendation %d/%d\n",
+				p.A, p.B, p.SameLevel, p.Compared, p.SameRecommendation, p.Compared)
+		}
+	}
+
+	return nil
+}
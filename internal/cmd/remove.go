@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/scanner"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+	"github.com/aaronsb/yay-friend/internal/yay"
+)
+
+// reviewRemovalHooks shows the pre_remove and post_remove hooks of every
+// package a -R operation would remove, since pacman runs them as root, and
+// asks before going on when there are any (unless --noconfirm). Packages the
+// operation pulls in, such as -Rs dependencies, are included. It returns
+// ErrUserCancelled when the user declines; a lookup failure only warns, and
+// yay then reports the problem itself. rules are the user scanner rules from
+// loadConfig.
+func reviewRemovalHooks(ctx context.Context, operation *types.YayOperation, cfg *types.Config, rules []scanner.ScannerRule) error {
+	targets, err := yay.RemovalTargets(ctx, operation.Command, operation.Packages)
+	if err != nil {
+		fmt.Printf("Warning: Could not check removal hooks: %v\n", err)
+		return nil
+	}
+
+	opts := scanner.DefaultOptions()
+	if cfg.Scanner.SuspiciousCommands != nil {
+		opts.SuspiciousCommands = cfg.Scanner.SuspiciousCommands
+	}
+	opts.UserRules = rules
+
+	found := false
+	for _, pkg := range targets {
+		script, err := yay.InstalledInstallScript(yay.PacmanLocalDB, pkg)
+		if err != nil {
+			fmt.Printf("Warning: Could not check removal hooks of %s: %v\n", pkg, err)
+			continue
+		}
+		for _, hook := range scanner.RemovalHooks(script, opts) {
+			found = true
+			fmt.Printf("\n%s %s runs %s() as root when removed: %s ", ui.Warn, pkg, hook.Name, getEntropyIcon(hook.Level))
+			getEntropyColor(hook.Level).Printf("%s\n", hook.Level.String())
+			for _, line := range hook.Body {
+				fmt.Printf("   │ %s\n", strings.TrimRight(line, " \t"))
+			}
+			for _, reason := range hook.Reasons {
+				fmt.Printf("   • %s\n", reason)
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	if hasFlag(operation.Flags, "--noconfirm") {
+		return nil
+	}
+	fmt.Print("\nContinue with removal? [y/N]: ")
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(strings.TrimSpace(response))
+	if response != "y" && response != "yes" {
+		return fmt.Errorf("removal %w", ErrUserCancelled)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/aaronsb/yay-friend/internal/reporter"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+)
+
+// reportToFlag names where analyze files a report: a configured reporter
+// target, "local", or an https endpoint URL.
+var reportToFlag string
+
+// reportToReporter and reportToTarget are resolved from --report-to;
+// reportToReporter is nil without the flag.
+var (
+	reportToReporter *reporter.Reporter
+	reportToTarget   reporter.ReportTarget
+)
+
+// resolveReportTo checks --report-to before any analysis runs, so a
+// mistyped name or a URL outside allowed_hosts fails fast instead of after
+// a long analysis. The reporter config is only read, never changed.
+func resolveReportTo() error {
+	if reportToFlag == "" {
+		return nil
+	}
+	r, err := reporter.NewReporter()
+	if err != nil {
+		return err
+	}
+	target, err := r.ResolveTarget(reportToFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --report-to: %w", err)
+	}
+	reportToReporter, reportToTarget = r, target
+	return nil
+}
+
+// fileReportTo offers to report the analyzed package to the
+// --report-to target when its level reaches warn_level. Nothing is sent
+// without an explicit yes, a reason, and a separate yes for the PKGBUILD;
+// without a terminal to ask on, no report is filed. A failed submission
+// only warns: the report is still kept in the local archive.
+func fileReportTo(analysis *types.SecurityAnalysis, pkgInfo types.PackageInfo, cfg *types.Config) {
+	if reportToReporter == nil {
+		return
+	}
+	target := reportToTarget
+	level := analysis.DecisionLevel()
+	if level < cfg.SecurityThresholds.WarnLevel {
+		fmt.Printf("\n%s %s is %s, below warn_level %s: no report filed to %s\n",
+			ui.Info, pkgInfo.Name, level.String(), cfg.SecurityThresholds.WarnLevel.String(), target.Name)
+		return
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Printf("Warning: Not reporting %s to %s: --report-to asks for confirmation on a terminal\n", pkgInfo.Name, target.Name)
+		return
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	ask := func(prompt string) string {
+		fmt.Print(prompt)
+		input, _ := reader.ReadString('\n')
+		return strings.TrimSpace(input)
+	}
+	yes := func(answer string) bool {
+		answer = strings.ToLower(answer)
+		return answer == "y" || answer == "yes"
+	}
+
+	fmt.Printf("\n%s Report %s (%s) to %s", ui.Send, pkgInfo.Name, level.String(), target.Name)
+	if target.Endpoint != "local" {
+		fmt.Printf(" at %s", target.Endpoint)
+	}
+	if !yes(ask("? [y/N]: ")) {
+		fmt.Println("No report filed.")
+		return
+	}
+	reason := ask("Reason for the report: ")
+	if reason == "" {
+		fmt.Println("No reason given; no report filed.")
+		return
+	}
+	includePKGBUILD := yes(ask("Include the PKGBUILD itself in the report? [y/N]: "))
+
+	if err := reportToReporter.ReportTo(target, pkgInfo.Name, pkgInfo.Version, pkgInfo.Maintainer,
+		analysis, pkgInfo.PKGBUILD, reason, includePKGBUILD); err != nil {
+		fmt.Printf("Warning: %v (the report is saved locally)\n", err)
+		return
+	}
+	fmt.Printf("%s Report for %s filed to %s\n", ui.OK, pkgInfo.Name, target.Name)
+}
@@ -2,8 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/gookit/color"
 	"github.com/spf13/cobra"
@@ -11,8 +17,11 @@ import (
 	"github.com/aaronsb/yay-friend/internal/aur"
 	"github.com/aaronsb/yay-friend/internal/cache"
 	"github.com/aaronsb/yay-friend/internal/config"
-	"github.com/aaronsb/yay-friend/internal/providers"
+	"github.com/aaronsb/yay-friend/internal/netclient"
+	"github.com/aaronsb/yay-friend/internal/scanner"
+	"github.com/aaronsb/yay-friend/internal/trust"
 	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
 	"github.com/aaronsb/yay-friend/internal/yay"
 )
 
@@ -22,6 +31,28 @@ var (
 	skipAnalysis bool
 	provider     string
 	noSpinner    bool
+	keepClone    bool
+	contextLines int
+	debug        bool
+	keepGoing    bool
+	noEducation  bool
+	noIcons      bool
+	colorMode    string
+	outputWidth  int
+	noCacheWrite bool
+	insecure     bool
+	profile      string
+	// providerArgsFromEnv lets YAY_FRIEND_CLAUDE_ARGS set claude.args.
+	providerArgsFromEnv bool
+	// verboseFindings adds each finding's entropy notes to the analyze and
+	// cache show reports; the install report always has them.
+	verboseFindings bool
+	// acceptMaintainer names the packages whose changed maintainer is
+	// recorded as acknowledged.
+	acceptMaintainer []string
+	// insecureWarned keeps the --insecure warning to once per run, though
+	// analyze --url loads the config again for the downloaded snapshot.
+	insecureWarned bool
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -33,6 +64,9 @@ PKGBUILD files for potential security issues before installation.
 
 It acts as a security layer between you and the Arch User Repository (AUR),
 analyzing packages for suspicious patterns, malicious code, and security risks.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return initConfig()
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runInstall(cmd.Context(), args)
 	},
@@ -52,14 +86,26 @@ func Execute(ctx context.Context) error {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ${XDG_CONFIG_HOME:-$HOME/.config}/yay-friend/config.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolVar(&skipAnalysis, "skip-analysis", false, "skip security analysis and proceed directly to yay")
 	rootCmd.PersistentFlags().StringVar(&provider, "provider", "", "AI provider to use (claude, qwen, copilot, goose)")
 	rootCmd.PersistentFlags().BoolVar(&noSpinner, "no-spinner", false, "disable spinner animations (useful for scripts/automation)")
+	rootCmd.PersistentFlags().BoolVar(&keepClone, "keep-clone", false, "keep a clone of the AUR repo for HIGH/CRITICAL packages for manual inspection")
+	rootCmd.PersistentFlags().BoolVar(&keepGoing, "keep-going", false, "continue analyzing the remaining packages when one fails or is blocked, then report all failures")
+	rootCmd.PersistentFlags().BoolVar(&noEducation, "no-education", false, "hide the Security Education and Key Security Lessons sections (overrides ui.show_education)")
+	rootCmd.PersistentFlags().BoolVar(&noCacheWrite, "no-cache-write", false, "read cached analyses but don't save new ones (for CI or a shared cache)")
+	rootCmd.PersistentFlags().StringSliceVar(&acceptMaintainer, "accept-maintainer", nil, "accept the new maintainer of these packages (--accept-maintainer=foo,bar), recording it for security_thresholds.block_on_maintainer_change")
+	rootCmd.PersistentFlags().BoolVar(&noIcons, "no-icons", false, "print plain ASCII labels ([OK], [CRIT], ...) instead of emoji (overrides ui.use_icons)")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", ui.ColorAuto, "color output: auto (when stdout is a terminal and NO_COLOR is unset), always or never (always/never override NO_COLOR and ui.use_colors)")
+	rootCmd.PersistentFlags().BoolVar(&insecure, "insecure", false, "skip TLS certificate verification for AUR and provider requests (testing only; prefer network.ca_cert)")
+	rootCmd.PersistentFlags().IntVar(&outputWidth, "width", 0, "wrap long text at this many columns (default: the terminal width, or 80 when output isn't a terminal)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "analysis profile: strict, balanced or lenient (overrides analysis.profile; explicit config keys still win)")
+	rootCmd.PersistentFlags().BoolVar(&providerArgsFromEnv, "provider-args-from-env", false, "read extra claude arguments from YAY_FRIEND_CLAUDE_ARGS (other YAY_FRIEND_* variables always apply)")
+	rootCmd.PersistentFlags().BoolVar(&verboseFindings, "verbose-findings", false, "show each finding's entropy notes (why it affects predictability) in analyze and cache show")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "print the raw provider response and extracted JSON to stderr")
+	rootCmd.PersistentFlags().IntVar(&contextLines, "context-lines", -1, "max PKGBUILD lines sent for analysis, 0 = unlimited (default from prompts.max_pkgbuild_lines)")
 
 	// Add yay-compatible flags
 	rootCmd.Flags().BoolP("sync", "S", false, "install packages")
@@ -73,21 +119,90 @@ func init() {
 	// Add subcommands
 	rootCmd.AddCommand(newAnalyzeCmd())
 	rootCmd.AddCommand(newCacheCmd())
+	rootCmd.AddCommand(newExplainCmd())
+	rootCmd.AddCommand(newNoteCmd())
 	rootCmd.AddCommand(newConfigCmd())
 	rootCmd.AddCommand(newProviderCmd())
 	rootCmd.AddCommand(newVersionCmd())
 }
 
 // initConfig wires the --config flag into the config package so that
-// config.Load reads from the requested file (or the default path when empty).
-func initConfig() {
+// config.Load reads from the requested file (or the default path when empty),
+// and applies the output flags. It runs before every command, so an invalid
+// --color is reported even by commands that don't load the config.
+func initConfig() error {
 	config.SetConfigPath(cfgFile)
+
+	// Settle --no-icons, --color and --width now, since several commands
+	// (cache clean, provider list, …) print without loading the config.
+	// Commands that load it apply the ui.* settings in loadConfig.
+	ui.SetIcons(!noIcons)
+	if _, err := ui.SetColor(colorMode, true); err != nil {
+		return err
+	}
+	ui.SetWidth(outputWidth)
+	return nil
+}
+
+// loadConfig loads the configuration, with the user scanner rules compiled
+// from scanner.rules_dir, and applies the global flags that override config
+// values for this run.
+func loadConfig() (*types.Config, []scanner.ScannerRule, error) {
+	config.SetProfile(profile)
+	config.SetProviderArgsFromEnv(providerArgsFromEnv)
+	cfg, rules, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	applyFlagOverrides(cfg)
+	ui.SetIcons(cfg.UI.UseIcons)
+	if _, err := ui.SetColor(colorMode, cfg.UI.UseColors); err != nil {
+		return nil, nil, err
+	}
+	ui.SetLocale(cfg.UI.Locale)
+	ui.SetWidth(outputWidth)
+	if err := netclient.Configure(cfg.Network.CACert, insecure); err != nil {
+		return nil, nil, err
+	}
+	if insecure && !insecureWarned {
+		insecureWarned = true
+		fmt.Printf("%s WARNING: --insecure turns off TLS certificate verification. Anyone on the network path\n", ui.Warn)
+		fmt.Printf("   can tamper with AUR metadata and downloads. Use it for testing only.\n")
+	}
+	return cfg, rules, nil
+}
+
+// applyFlagOverrides applies the global flags that override config values
+// for this run. It returns the keys they set, each mapped to its flag.
+func applyFlagOverrides(cfg *types.Config) map[string]string {
+	flags := make(map[string]string)
+	if provider != "" {
+		cfg.DefaultProvider = provider
+		flags["default_provider"] = "--provider"
+	}
+	if contextLines >= 0 {
+		cfg.Prompts.MaxPKGBUILDLines = contextLines
+		flags["prompts.max_pkgbuild_lines"] = "--context-lines"
+	}
+	if noEducation {
+		cfg.UI.ShowEducation = false
+		flags["ui.show_education"] = "--no-education"
+	}
+	if noIcons {
+		cfg.UI.UseIcons = false
+		flags["ui.use_icons"] = "--no-icons"
+	}
+	if colorMode == ui.ColorAlways || colorMode == ui.ColorNever {
+		cfg.UI.UseColors = colorMode == ui.ColorAlways
+		flags["ui.use_colors"] = "--color"
+	}
+	return flags
 }
 
 // runInstall handles the main package installation workflow
 func runInstall(ctx context.Context, args []string) error {
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, rules, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -98,11 +213,21 @@ func runInstall(ctx context.Context, args []string) error {
 			cfg.DefaultProvider, int(cfg.SecurityThresholds.BlockLevel), int(cfg.SecurityThresholds.WarnLevel))
 	}
 
+	// A managed install can make analysis mandatory
+	if skipAnalysis && !cfg.Security.AllowSkip {
+		return fmt.Errorf("--skip-analysis is not allowed: security.allow_skip is false in this configuration")
+	}
+
 	// Parse yay command
 	operation, err := yay.ParseYayCommand(args)
 	if err != nil {
 		return fmt.Errorf("failed to parse command: %w", err)
 	}
+	if !cfg.Security.AllowSkip {
+		if err := enforceMandatoryAnalysis(operation); err != nil {
+			return err
+		}
+	}
 
 	// Initialize yay client
 	yayClient := yay.NewYayClient(cfg.Yay.Path)
@@ -119,19 +244,43 @@ func runInstall(ctx context.Context, args []string) error {
 		return yayClient.InstallPackages(ctx, operation)
 	}
 
-	// For non-install operations (like -Q, -R, etc.), pass through to yay
-	if operation.Operation != "install" && operation.Operation != "analyze" {
+	// Removal runs the packages' pre_remove/post_remove hooks, so show them first
+	if operation.Operation == "remove" {
+		if err := reviewRemovalHooks(ctx, operation, cfg, rules); err != nil {
+			return err
+		}
+	}
+
+	// Queries (-Q, -Ss, -Si, ...), -D/-F, removals and -U pass through to
+	// yay without fetching a PKGBUILD
+	if !yay.NeedsAnalysis(operation) {
+		return yayClient.InstallPackages(ctx, operation)
+	}
+
+	// Packages from the trusted sync repositories are signed and have no AUR
+	// PKGBUILD to analyze; they skip straight to yay along with the approved
+	// packages. --aur and --mode=aur make yay build the AUR package of the
+	// same name, so then every package is analyzed.
+	aurPackages := operation.Packages
+	if !yay.ForcesAUR(operation) {
+		_, aurPackages = partitionRepoPackages(ctx, cfg, operation.Packages)
+	}
+	if len(aurPackages) == 0 {
+		if operation.Operation == "analyze" {
+			return nil
+		}
 		return yayClient.InstallPackages(ctx, operation)
 	}
 
 	// Handle potential search queries by checking if packages exist
 	var finalPackages []string
-	for _, pkg := range operation.Packages {
+	selected := make(map[string][]string) // argument -> the packages it resolved to
+	for _, pkg := range aurPackages {
 		// Try to get package info directly first
 		_, err := yayClient.GetPackageInfo(ctx, pkg)
 		if err != nil {
 			// Package not found directly, might be a search query
-			fmt.Printf("🔍 Package '%s' not found exactly, searching...\n", pkg)
+			fmt.Printf("%s Package '%s' not found exactly, searching...\n", ui.Search, pkg)
 
 			// Search for packages
 			searchResults, searchErr := yayClient.SearchPackages(ctx, pkg)
@@ -155,56 +304,99 @@ func runInstall(ctx context.Context, args []string) error {
 			}
 
 			finalPackages = append(finalPackages, selectedPkgs...)
+			selected[pkg] = selectedPkgs
 		} else {
 			// Package found directly
 			finalPackages = append(finalPackages, pkg)
+			selected[pkg] = []string{pkg}
 		}
 	}
 
-	// Update operation with final package list
-	operation.Packages = finalPackages
-
-	// Initialize providers
-	registry := providers.NewProviderRegistry()
-	claudeProvider := providers.NewClaudeProvider()
-	claudeProvider.SetConfig(cfg)
-	registry.Register("claude", claudeProvider)
-	registry.Register("qwen", providers.NewQwenProvider())
-	registry.Register("copilot", providers.NewCopilotProvider())
-	registry.Register("goose", providers.NewGooseProvider())
-
-	// Determine which provider to use
-	providerName := provider
-	if providerName == "" {
-		providerName = cfg.DefaultProvider
-	}
-	if providerName == "" {
-		providerName = "claude" // Default fallback
+	// Update operation with final package list, keeping the user's order
+	var ordered []string
+	for _, pkg := range operation.Packages {
+		if packages, ok := selected[pkg]; ok {
+			ordered = append(ordered, packages...)
+			delete(selected, pkg) // a repeated argument is installed once
+		} else if !slices.Contains(aurPackages, pkg) {
+			ordered = append(ordered, pkg) // a trusted repository package
+		}
 	}
+	operation.Packages = ordered
 
-	aiProvider, err := registry.Get(providerName)
+	// Flags such as --mflags --skipinteg change what the build verifies
+	weakened := warnWeakenedChecks(operation)
+
+	// Initialize the provider
+	aiProvider, err := selectProvider(ctx, cfg, rules, true)
 	if err != nil {
-		return fmt.Errorf("provider error: %w", err)
+		return err
 	}
 
-	// Authenticate provider
-	if err := aiProvider.Authenticate(ctx); err != nil {
-		return fmt.Errorf("authentication failed for %s: %w", providerName, err)
+	// Initialize cache manager once for the run (nil when caching is disabled or unavailable)
+	cacheManager := openAnalysisCache(cfg)
+
+	// One AUR fetcher for the run, so its HTTP client reuses connections and
+	// the metadata of every package comes from one batched request
+	aurFetcher := aur.NewAURFetcher()
+	if len(finalPackages) > 1 {
+		if err := aurFetcher.PrefetchMetadata(ctx, finalPackages); err != nil {
+			fmt.Printf("Warning: Could not prefetch AUR metadata: %v\n", err)
+		}
 	}
 
-	// Analyze packages
+	// Analyze packages. With --keep-going a failure is recorded and the loop
+	// moves on; successful analyses are cached, so a re-run only redoes the
+	// failed ones. Nothing is installed unless every package passed.
 	allSafe := true
-	for _, packageName := range operation.Packages {
-		if err := analyzeAndDecide(ctx, yayClient, aiProvider, packageName, cfg); err != nil {
-			return fmt.Errorf("analysis failed for %s: %w", packageName, err)
+	var failures []string
+	var failureErrs []error
+	var approved []*types.SecurityAnalysis
+	var approvedInfo []*types.PackageInfo
+	for _, packageName := range finalPackages {
+		pkgInfo, analysis, err := analyzeAndDecide(ctx, yayClient, aiProvider, cacheManager, aurFetcher, packageName, cfg, weakened)
+		if err != nil {
+			if !keepGoing {
+				runApprovedHooks(ctx, cfg, approved)
+				return fmt.Errorf("analysis failed for %s: %w", packageName, err)
+			}
+			outcome := "analysis failed"
+			switch {
+			case errors.Is(err, ErrBlockedByPolicy):
+				outcome = "blocked"
+			case errors.Is(err, ErrUserCancelled):
+				outcome = "declined"
+			}
+			fmt.Printf("\n%s %s %s (continuing with --keep-going)\n", ui.Fail, packageName, outcome)
+			failures = append(failures, fmt.Sprintf("%s (%s): %v", packageName, outcome, err))
+			failureErrs = append(failureErrs, err)
+			continue
+		}
+		approved = append(approved, analysis)
+		approvedInfo = append(approvedInfo, pkgInfo)
+	}
+	printTotalAnalysisTime()
+
+	if len(failures) > 0 {
+		fmt.Printf("\n%d of %d packages did not pass:\n", len(failures), len(finalPackages))
+		for _, failure := range failures {
+			fmt.Printf("  • %s\n", failure)
 		}
+		fmt.Printf("Nothing was installed. Re-run to retry; passing packages are served from the cache.\n")
+		runApprovedHooks(ctx, cfg, approved)
+		return packageFailures(failureErrs)
+	}
+
+	// If we get here, all packages passed analysis. Recap them so a warning
+	// that scrolled off screen in a long run isn't missed.
+	if len(approved) > 1 {
+		printInstallRecap(approved, cfg)
 	}
 
-	// If we get here, all packages passed analysis
 	if operation.Operation == "analyze" {
 		// In analyze-only mode, ask user if they want to proceed with installation
 		if allSafe {
-			fmt.Printf("\n✅ All packages passed security analysis.\n")
+			fmt.Printf("\n%s All packages passed security analysis.\n", ui.OK)
 			fmt.Printf("Would you like to proceed with installation? [y/N]: ")
 
 			var response string
@@ -216,209 +408,508 @@ func runInstall(ctx context.Context, args []string) error {
 				operation.Command = "-S"
 				operation.Operation = "install"
 				fmt.Printf("Proceeding with installation...\n")
+				runNamcapChecks(ctx, cfg, approved, approvedInfo)
+				runApprovedHooks(ctx, cfg, approved)
 				return yayClient.InstallPackages(ctx, operation)
 			} else {
 				fmt.Printf("Installation cancelled.\n")
+				runApprovedHooks(ctx, cfg, approved)
 				return nil
 			}
 		} else {
-			fmt.Printf("\n⚠️  Security concerns found. Installation not recommended.\n")
+			fmt.Printf("\n%s Security concerns found. Installation not recommended.\n", ui.Warn)
+			runApprovedHooks(ctx, cfg, approved)
 			return nil
 		}
 	} else {
-		// Regular install mode, proceed automatically if safe
-		fmt.Printf("✅ All packages passed security analysis, proceeding with installation...\n")
+		// Regular install mode, proceed automatically if safe. A multi-package
+		// install gets one final confirmation after the recap.
+		if len(approved) > 1 && !cfg.SecurityThresholds.AutoProceed && !hasFlag(operation.Flags, "--noconfirm") {
+			fmt.Printf("Proceed with installing these %d packages? [y/N]: ", len(approved))
+			var response string
+			fmt.Scanln(&response)
+			response = strings.ToLower(strings.TrimSpace(response))
+			if response != "y" && response != "yes" {
+				runApprovedHooks(ctx, cfg, approved)
+				return fmt.Errorf("installation %w", ErrUserCancelled)
+			}
+		}
+		fmt.Printf("%s All packages passed security analysis, proceeding with installation...\n", ui.OK)
+		runNamcapChecks(ctx, cfg, approved, approvedInfo)
+		runApprovedHooks(ctx, cfg, approved)
 		return yayClient.InstallPackages(ctx, operation)
 	}
 }
 
-// analyzeAndDecide analyzes a package and decides whether to proceed
-func analyzeAndDecide(ctx context.Context, yayClient *yay.YayClient, provider types.AIProvider, packageName string, cfg *types.Config) error {
+// enforceMandatoryAnalysis closes the ways around analysis other than
+// --skip-analysis when security.allow_skip is false. A system upgrade would
+// build AUR updates unanalyzed, so a package-less one is limited to the
+// repositories with --repo and one that also names packages is refused; -U
+// installs package files that have no PKGBUILD to analyze, so it is refused.
+func enforceMandatoryAnalysis(operation *types.YayOperation) error {
+	if operation.Operation == "upgrade" && operation.Command != "-Syu" {
+		return fmt.Errorf("%s is not allowed: security.allow_skip is false and package files can't be analyzed", operation.Command)
+	}
+	if !yay.Sysupgrade(operation) {
+		return nil
+	}
+	if yay.ForcesAUR(operation) || len(operation.Packages) > 0 {
+		return fmt.Errorf("%s would build AUR updates unanalyzed, which security.allow_skip: false doesn't allow; upgrade with yay-friend -Syu, then install the packages", operation.Command)
+	}
+	fmt.Printf("%s Upgrading repository packages only: AUR updates are analyzed when you install them by name\n", ui.Info)
+	operation.Flags = append(operation.Flags, "--repo")
+	return nil
+}
+
+// partitionRepoPackages splits packages into those from the trusted sync
+// repositories (security.trusted_repos), which need no analysis, and the
+// rest, noting each one skipped on stderr so that analyze's JSON, YAML and
+// oneline output stays machine-readable.
+func partitionRepoPackages(ctx context.Context, cfg *types.Config, packages []string) (repo, rest []string) {
+	for _, pkg := range packages {
+		if name, ok := yay.RepoPackage(ctx, pkg, cfg.Security.TrustedRepos); ok {
+			fmt.Fprintf(os.Stderr, "%s %s: signed package from the %s repository — analysis not applicable\n", ui.OK, pkg, name)
+			repo = append(repo, pkg)
+			continue
+		}
+		rest = append(rest, pkg)
+	}
+	return repo, rest
+}
+
+// analyzeAndDecide analyzes a package and decides whether to proceed. It
+// returns the package and analysis of an approved package. weakened, from
+// warnWeakenedChecks, is recorded on the analysis and its dependencies'.
+func analyzeAndDecide(ctx context.Context, yayClient *yay.YayClient, provider types.AIProvider, cacheManager *cache.CacheManager, aurFetcher *aur.AURFetcher, packageName string, cfg *types.Config, weakened []string) (*types.PackageInfo, *types.SecurityAnalysis, error) {
 	fmt.Printf("Analyzing %s...\n", packageName)
 
 	// Get package info
 	pkgInfo, err := yayClient.GetPackageInfo(ctx, packageName)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Fetch additional AUR context (including commit hash)
 	fmt.Printf("Fetching AUR context...\n")
-	aurFetcher := aur.NewAURFetcher()
-	if err := aurFetcher.EnrichPackageInfo(ctx, pkgInfo); err != nil {
-		fmt.Printf("Warning: Could not enrich with AUR context: %v\n", err)
-	} else {
+	enrichment := aurFetcher.EnrichPackageInfo(ctx, pkgInfo)
+	if enrichment.MetadataErr == nil {
 		fmt.Printf("AUR context: %d votes, %.3f popularity, %d comments\n",
 			pkgInfo.Votes, pkgInfo.Popularity, len(pkgInfo.Comments))
 	}
 
-	// Initialize cache manager
-	cacheManager, err := cache.NewCacheManager()
-	if err != nil {
-		fmt.Printf("Warning: Could not initialize cache: %v\n", err)
-		// Continue without caching
-	}
-
 	// Check cache first if enabled and we have commit hash and cache manager
 	var analysis *types.SecurityAnalysis
 	if cfg.Cache.Enabled && cacheManager != nil && pkgInfo.CommitHash != "" {
-		cachedAnalysis, cacheErr := cacheManager.GetCachedAnalysis(pkgInfo.Name, pkgInfo.CommitHash)
+		// Keyed on the package base so split-package siblings share one analysis
+		cachedAnalysis, cacheErr := cacheManager.GetCachedAnalysis(pkgInfo.Base(), pkgInfo.CommitHash)
 		if cacheErr == nil {
-			fmt.Printf("📋 Using cached analysis (commit: %s)\n", pkgInfo.CommitHash[:8])
+			fmt.Printf("%s Using cached analysis (commit: %s)\n", ui.Cached, pkgInfo.CommitHash[:8])
 			analysis = cachedAnalysis
+			analysis.PackageName = pkgInfo.Name // may have been cached for a sibling
+			analysis.WeakenedChecks = weakened  // this install's options, not the cached run's
 		} else {
-			fmt.Printf("🤖 Running fresh analysis (commit: %s)\n", pkgInfo.CommitHash[:8])
+			fmt.Printf("%s Running fresh analysis (commit: %s)\n", ui.Fresh, pkgInfo.CommitHash[:8])
 			// Cache miss - continue to run AI analysis
 		}
 	}
 
 	// If no cached analysis found, run AI analysis
 	if analysis == nil {
+		// Git history is only prompt context, so skip fetching it on a cache hit
+		addRecentCommits(ctx, cfg, pkgInfo)
+
 		// Display what we collected for analysis
-		displayCollectedData(pkgInfo)
+		displayCollectedData(pkgInfo, &enrichment)
 
 		// Analyze security with enriched context
-		// Check if provider supports options (for Claude)
-		if claudeProvider, ok := provider.(*providers.ClaudeProvider); ok {
-			analysis, err = claudeProvider.AnalyzePKGBUILDWithOptions(ctx, *pkgInfo, noSpinner)
-		} else {
-			analysis, err = provider.AnalyzePKGBUILD(ctx, *pkgInfo)
-		}
+		analysis, err = provider.AnalyzePKGBUILDWithOptions(ctx, *pkgInfo, types.AnalysisOptions{NoSpinner: noSpinner})
 
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
+		printAnalysisTime(analysis)
+		analysis.Context = enrichment.Completeness(*pkgInfo, analysis.Truncated())
+		analysis.WeakenedChecks = weakened
 
 		// Save to cache if enabled and available
 		if cfg.Cache.Enabled && cacheManager != nil && pkgInfo.CommitHash != "" {
-			if cacheErr := cacheManager.SaveAnalysis(pkgInfo.Name, pkgInfo.CommitHash, analysis); cacheErr != nil {
+			if cacheErr := cacheManager.SaveAnalysis(pkgInfo.Base(), pkgInfo.CommitHash, analysis); cacheErr != nil {
 				fmt.Printf("Warning: Could not save analysis to cache: %v\n", cacheErr)
 			}
 		}
 	}
 
-	// Display results and make decision
-	return handleAnalysisResult(analysis, cfg)
+	retainCloneIfFlagged(ctx, cfg, pkgInfo.Base(), analysis)
+	printChangesSinceLast(cacheManager, pkgInfo, analysis)
+	if cfg.SecurityThresholds.BlockOnMaintainerChange {
+		if err := checkMaintainerChange(cacheManager, pkgInfo); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Applied after caching: the floors are local policy, and votes change.
+	// Rescoring also covers entries cached before the risk score existed.
+	analysis.RiskScore = trust.RiskScore(analysis, *pkgInfo)
+	trust.ApplyTypeFloors(analysis, *pkgInfo, cfg.Security.TypeFloors)
+	trust.ApplyCommunityFloors(analysis, *pkgInfo, cfg.SecurityThresholds.MinVotes, cfg.SecurityThresholds.MinPopularity)
+
+	printNotes(pkgInfo.Name)
+
+	// Display results and make decision. An approved package's post-analysis
+	// hook waits for namcap (see runApprovedHooks).
+	if err := handleAnalysisResult(analysis, cfg); err != nil {
+		runPostAnalysisHook(ctx, cfg, analysis)
+		return nil, nil, err
+	}
+
+	// yay builds the package's AUR dependencies too, so they pass the same
+	// thresholds before anything is installed
+	if cfg.Analysis.DependencyDepth > 0 {
+		walker := &dependencyWalker{
+			cfg:          cfg,
+			yayClient:    yayClient,
+			provider:     provider,
+			cacheManager: cacheManager,
+			aurFetcher:   aurFetcher,
+			maxDepth:     cfg.Analysis.DependencyDepth,
+			confirm:      true,
+			weakened:     weakened,
+		}
+		if limit := provider.GetCapabilities().RateLimitPerMinute; limit > 0 {
+			walker.interval = time.Minute / time.Duration(limit)
+		}
+		if err := analyzeDependencyTree(ctx, walker, pkgInfo, analysis); err != nil {
+			runPostAnalysisHook(ctx, cfg, analysis)
+			return nil, nil, err
+		}
+	}
+	return pkgInfo, analysis, nil
+}
+
+// displayEducation shows the analysis's educational summary and lessons, if any
+func displayEducation(analysis *types.SecurityAnalysis) {
+	if analysis.EducationalSummary != "" {
+		fmt.Printf("\n")
+		color.Bold.Printf("Security Education:\n")
+		fmt.Printf(strings.Repeat("-", 60) + "\n")
+		fmt.Printf("%s\n", ui.Wrap("", "", analysis.EducationalSummary))
+	}
+
+	if len(analysis.SecurityLessons) > 0 {
+		fmt.Printf("\n")
+		color.Bold.Printf("Key Security Lessons:\n")
+		for i, lesson := range analysis.SecurityLessons {
+			fmt.Printf("%s\n", ui.Wrap(fmt.Sprintf("   %d. ", i+1), "      ", lesson))
+		}
+	}
+}
+
+// displayInstallScriptRisk shows the install script's own verdict, when the
+// package has one. It is set apart because the script runs as root.
+func displayInstallScriptRisk(analysis *types.SecurityAnalysis) {
+	risk := analysis.InstallScript
+	if risk == nil {
+		return
+	}
+	fmt.Printf("Install Script Risk: %s ", getEntropyIcon(risk.Level))
+	getEntropyColor(risk.Level).Printf("%s", risk.Level.String())
+	fmt.Printf(" (runs as root on your system)\n")
+	for _, reason := range risk.Reasons {
+		fmt.Printf("   • %s\n", reason)
+	}
+}
+
+// displayPersistence lists the pre-scan's persistence findings (cron jobs,
+// systemd timers and services, autostart entries) next to the overall level,
+// since a package that arranges to run again later deserves a look even when
+// the rest of the report is quiet.
+func displayPersistence(analysis *types.SecurityAnalysis) {
+	var found []types.SecurityFinding
+	level := types.EntropyMinimal
+	for _, finding := range analysis.Findings {
+		if finding.Type != string(scanner.KindPersistence) {
+			continue
+		}
+		found = append(found, finding)
+		if finding.Entropy > level {
+			level = finding.Entropy
+		}
+	}
+	if len(found) == 0 {
+		return
+	}
+	fmt.Printf("Persistence: %s ", getEntropyIcon(level))
+	getEntropyColor(level).Printf("%s", level.String())
+	fmt.Printf(" (sets itself up to run again later)\n")
+	for _, finding := range found {
+		fmt.Printf("   • line %d: %s\n", finding.LineNumber, strings.TrimPrefix(finding.Description, "persistence: "))
+	}
+}
+
+// displayTruncation warns that the provider saw only part of the PKGBUILD
+// because of the context-lines limit or the provider's maximum analysis
+// size, so its verdict may miss what was left out. The pre-scan always reads
+// the whole file.
+func displayTruncation(analysis *types.SecurityAnalysis) {
+	if !analysis.Truncated() {
+		return
+	}
+	fmt.Printf("%s PKGBUILD truncated to %d of %d lines for analysis; the provider's verdict may miss what was left out.\n",
+		ui.Warn, analysis.PKGBUILDLines-analysis.OmittedLines, analysis.PKGBUILDLines)
+	fmt.Printf("   Raise --context-lines or prompts.max_pkgbuild_lines (0 = unlimited) to send more; the provider's\n")
+	fmt.Printf("   maximum analysis size still caps the prompt.\n")
+}
+
+// displayContext shows how complete the analysis's inputs were, next to the
+// verdict, so an analysis made offline or at an unknown commit isn't read as
+// if it had everything. Local files and entries cached before the record
+// existed show nothing.
+func displayContext(analysis *types.SecurityAnalysis) {
+	if analysis.Context == nil {
+		return
+	}
+	line := ui.T(ui.MsgContext, analysis.Context.String())
+	if missing := analysis.Context.Missing(); len(missing) > 0 {
+		line += ui.T(ui.MsgContextMissing, strings.Join(missing, ", "))
+	}
+	fmt.Println(line)
+}
+
+// rootMarker labels a finding inside an install hook, which pacman runs as
+// root; the pre-scan has already raised its level one step for it.
+func rootMarker(finding types.SecurityFinding) string {
+	if !finding.AsRoot {
+		return ""
+	}
+	return fmt.Sprintf(" %s %s", ui.Warn, ui.T(ui.MsgFindingAsRoot))
+}
+
+// policyMarker labels a finding that security.type_floors raised above the
+// level the analysis gave it.
+func policyMarker(finding types.SecurityFinding) string {
+	if !finding.RaisedByPolicy {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", ui.T(ui.MsgFindingRaisedByPolicy, finding.Entropy.String()))
+}
+
+// recommendationText formats the recommendation for display: the verdict,
+// followed by the model's explanation when it gave one, or the model's
+// wording alone, marked as such, when it names no verdict.
+func recommendationText(analysis *types.SecurityAnalysis) string {
+	text := analysis.RecommendationText
+	if analysis.Recommendation == "" {
+		if text == "" {
+			return ""
+		}
+		return ui.T(ui.MsgUnknownRecommendation, text)
+	}
+	verdict := string(analysis.Recommendation)
+	if strings.HasPrefix(strings.ToUpper(text), verdict) {
+		text = strings.TrimLeft(text[len(verdict):], " .,:;-—")
+	}
+	if text == "" {
+		return verdict
+	}
+	return verdict + " — " + text
+}
+
+// displayEntropyNotes prints why a finding affects predictability. The
+// install report always shows it; analyze and cache show only under
+// --verbose-findings. The notes are always in --format json/yaml output.
+func displayEntropyNotes(finding types.SecurityFinding) {
+	if finding.EntropyNotes != "" {
+		fmt.Printf("%s\n", ui.Wrap(ui.T(ui.MsgFindingAnalysis), "      ", finding.EntropyNotes))
+	}
 }
 
 // handleAnalysisResult processes the analysis result and makes a decision
 func handleAnalysisResult(analysis *types.SecurityAnalysis, cfg *types.Config) error {
 	// Display analysis summary with better formatting
 	fmt.Printf("\n" + strings.Repeat("=", 60) + "\n")
-	color.Bold.Print("Security Analysis Results: ")
+	color.Bold.Print(ui.T(ui.MsgResultsTitle))
 	color.Magenta.Printf("%s\n", analysis.PackageName)
 	fmt.Printf(strings.Repeat("=", 60) + "\n")
 
 	// Display entropy level with color coding
 	entropyIcon := getEntropyIcon(analysis.OverallLevel)
-	fmt.Printf("Security Entropy: %s %s\n", entropyIcon, analysis.OverallLevel.String())
+	fmt.Println(ui.T(ui.MsgSecurityEntropy, entropyIcon, analysis.OverallLevel.String(), analysis.RiskScore))
+	displayContext(analysis)
+	displayInstallScriptRisk(analysis)
+	displayPersistence(analysis)
+	displayWeakenedChecks(analysis)
+	displayTruncation(analysis)
 
 	if analysis.PredictabilityScore > 0 {
-		fmt.Printf("Predictability Score: %.2f/1.0\n", analysis.PredictabilityScore)
+		fmt.Println(ui.T(ui.MsgPredictability, analysis.PredictabilityScore))
 	}
 
 	if len(analysis.EntropyFactors) > 0 {
-		fmt.Printf("Risk Factors: %s\n", strings.Join(analysis.EntropyFactors, ", "))
+		fmt.Println(ui.T(ui.MsgRiskFactors, strings.Join(analysis.EntropyFactors, ", ")))
 	}
 
-	fmt.Printf("Summary: %s\n", analysis.Summary)
-
-	// Show educational content
-	if analysis.EducationalSummary != "" {
-		fmt.Printf("\n")
-		color.Bold.Printf("Security Education:\n")
-		fmt.Printf(strings.Repeat("-", 60) + "\n")
-		fmt.Printf("%s\n", analysis.EducationalSummary)
+	fmt.Printf("%s\n", ui.Wrap(ui.T(ui.MsgSummaryLabel), "   ", analysis.Summary))
+	if recommendation := recommendationText(analysis); recommendation != "" {
+		fmt.Printf("%s\n", ui.Wrap(ui.T(ui.MsgRecommendationLabel), "   ", recommendation))
 	}
 
-	if len(analysis.SecurityLessons) > 0 {
-		fmt.Printf("\n")
-		color.Bold.Printf("Key Security Lessons:\n")
-		for i, lesson := range analysis.SecurityLessons {
-			fmt.Printf("   %d. %s\n", i+1, lesson)
-		}
+	if cfg.UI.ShowEducation {
+		displayEducation(analysis)
 	}
 
+	// The thresholds apply to the install script's verdict too
+	level := analysis.DecisionLevel()
+
 	// Debug threshold comparison (only show if verbose mode)
 	if verbose {
 		fmt.Printf("\nDebug - Analysis Level: %d (%s), Block Threshold: %d (%s), Warn Threshold: %d (%s)\n",
-			int(analysis.OverallLevel), analysis.OverallLevel.String(),
+			int(level), level.String(),
 			int(cfg.SecurityThresholds.BlockLevel), cfg.SecurityThresholds.BlockLevel.String(),
 			int(cfg.SecurityThresholds.WarnLevel), cfg.SecurityThresholds.WarnLevel.String())
 	}
 
 	// Check against thresholds
-	if analysis.OverallLevel >= cfg.SecurityThresholds.BlockLevel {
-		fmt.Printf("\nBLOCKED: Package security level (%s) exceeds block threshold (%s)\n",
-			analysis.OverallLevel.String(), cfg.SecurityThresholds.BlockLevel.String())
-		return fmt.Errorf("package %s blocked by security policy", analysis.PackageName)
+	if level >= cfg.SecurityThresholds.BlockLevel {
+		fmt.Printf("\n%s\n", ui.T(ui.MsgBlocked, level.String(), cfg.SecurityThresholds.BlockLevel.String()))
+		printBlockReasons(analysis)
+		return fmt.Errorf("package %s %w", analysis.PackageName, ErrBlockedByPolicy)
 	}
 
 	// Show detailed findings
 	if len(analysis.Findings) > 0 {
 		fmt.Printf("\n")
-		color.Bold.Printf("Detailed Security Analysis:\n")
+		color.Bold.Println(ui.T(ui.MsgDetailedAnalysis))
 		fmt.Printf(strings.Repeat("-", 60) + "\n")
 		for i, finding := range analysis.Findings {
 			icon := getEntropyIcon(finding.Entropy)
 			entropyColor := getEntropyColor(finding.Entropy)
 			fmt.Printf("%d. %s ", i+1, icon)
 			entropyColor.Printf("[%s] ", finding.Entropy.String())
-			fmt.Printf("%s\n", finding.Type)
-			fmt.Printf("   Description: %s\n", finding.Description)
+			fmt.Printf("%s%s%s\n", finding.Type, rootMarker(finding), policyMarker(finding))
+			fmt.Printf("%s\n", ui.Wrap(ui.T(ui.MsgFindingDescription), "      ", finding.Description))
 
 			if finding.Context != "" {
-				fmt.Printf("   Code: %s\n", finding.Context)
+				fmt.Println(ui.T(ui.MsgFindingCode, finding.Context))
 			}
 
-			if finding.EntropyNotes != "" {
-				fmt.Printf("   Analysis: %s\n", finding.EntropyNotes)
-			}
+			displayEntropyNotes(finding)
 
 			if finding.Suggestion != "" {
-				fmt.Printf("   Action: %s\n", finding.Suggestion)
+				fmt.Printf("%s\n", ui.Wrap(ui.T(ui.MsgFindingAction), "      ", finding.Suggestion))
 			}
 
 			if finding.LineNumber > 0 {
-				fmt.Printf("   Line: %d\n", finding.LineNumber)
+				fmt.Println(ui.T(ui.MsgFindingLine, finding.LineNumber))
 			}
 			fmt.Println()
 		}
 	}
 
-	if analysis.OverallLevel >= cfg.SecurityThresholds.WarnLevel {
-		fmt.Printf("\nWARNING: Security concerns detected (%s entropy level)\n", analysis.OverallLevel.String())
+	// A BLOCK recommendation below the block level still needs the user's
+	// say-so, whatever the warn level and auto_proceed_safe
+	blockRecommended := analysis.Recommendation == types.RecommendBlock
+	if level >= cfg.SecurityThresholds.WarnLevel || blockRecommended {
+		if level >= cfg.SecurityThresholds.WarnLevel {
+			fmt.Printf("\n%s\n", ui.T(ui.MsgSecurityWarning, level.String()))
+		}
+		if blockRecommended {
+			fmt.Printf("\n%s\n", ui.T(ui.MsgBlockRecommended, level.String()))
+		}
 
 		// Ask user for confirmation unless auto-proceed is enabled
-		if !cfg.SecurityThresholds.AutoProceed {
-			fmt.Print("\nContinue with installation? [y/N]: ")
+		if !cfg.SecurityThresholds.AutoProceed || blockRecommended {
+			fmt.Print("\n" + ui.T(ui.MsgContinuePrompt))
 			var response string
 			fmt.Scanln(&response)
 			if response != "y" && response != "Y" {
-				return fmt.Errorf("installation cancelled by user")
+				return fmt.Errorf("installation of %s %w", analysis.PackageName, ErrUserCancelled)
 			}
 		}
 	}
 
-	fmt.Printf("\n%s approved for installation\n", analysis.PackageName)
+	fmt.Printf("\n%s\n", ui.T(ui.MsgApproved, analysis.PackageName))
 	return nil
 }
 
+// printInstallRecap lists every approved package with its entropy level and
+// risk score, riskiest first, marking those that crossed the warn threshold.
+func printInstallRecap(approved []*types.SecurityAnalysis, cfg *types.Config) {
+	fmt.Printf("\n")
+	color.Bold.Printf("Analysis Recap (riskiest first):\n")
+	fmt.Printf(strings.Repeat("-", 60) + "\n")
+	sorted := append([]*types.SecurityAnalysis(nil), approved...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RiskScore > sorted[j].RiskScore
+	})
+	for _, analysis := range sorted {
+		note := ""
+		if analysis.DecisionLevel() >= cfg.SecurityThresholds.WarnLevel {
+			note = fmt.Sprintf("  %s warned", ui.Warn)
+		}
+		fmt.Printf("%s %-30s %-8s %3.0f/100%s\n", getEntropyIcon(analysis.OverallLevel), analysis.PackageName,
+			analysis.OverallLevel.String(), analysis.RiskScore, note)
+	}
+	fmt.Printf("\n")
+}
+
+// hasFlag reports whether flag appears among the flags passed through to yay.
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// maxBlockReasons caps how many findings are repeated in the block message.
+const maxBlockReasons = 3
+
+// printBlockReasons lists the highest-entropy findings behind a block, so the
+// user can see why without scrolling back, and points at the full analysis.
+func printBlockReasons(analysis *types.SecurityAnalysis) {
+	findings := append([]types.SecurityFinding(nil), analysis.Findings...)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Entropy > findings[j].Entropy
+	})
+	if len(findings) > maxBlockReasons {
+		findings = findings[:maxBlockReasons]
+	}
+
+	if len(findings) > 0 {
+		fmt.Printf("\nWhy it was blocked:\n")
+		for i, finding := range findings {
+			icon := getEntropyIcon(finding.Entropy)
+			fmt.Printf("%d. %s ", i+1, icon)
+			getEntropyColor(finding.Entropy).Printf("[%s] ", finding.Entropy.String())
+			fmt.Printf("%s\n", ui.Wrap(finding.Type+": ", "   ", finding.Description))
+			if finding.Suggestion != "" {
+				fmt.Printf("%s\n", ui.Wrap("   Action: ", "      ", finding.Suggestion))
+			}
+		}
+		if remaining := len(analysis.Findings) - len(findings); remaining > 0 {
+			fmt.Printf("   (+%d more)\n", remaining)
+		}
+	}
+
+	fmt.Printf("\nFor full detail run: yay-friend analyze %s\n", analysis.PackageName)
+}
+
 // getEntropyIcon returns an icon based on entropy level
 func getEntropyIcon(level types.SecurityEntropy) string {
 	switch level {
 	case types.EntropyMinimal:
-		return "🟢"
+		return ui.LevelSafe.String()
 	case types.EntropyLow:
-		return "🟢"
+		return ui.LevelSafe.String()
 	case types.EntropyModerate:
-		return "🟡"
+		return ui.LevelModerate.String()
 	case types.EntropyHigh:
-		return "🔴"
+		return ui.LevelHigh.String()
 	case types.EntropyCritical:
-		return "🔴"
+		return ui.LevelCritical.String()
 	default:
-		return "❓"
+		return ui.LevelUnknown.String()
 	}
 }
 
@@ -440,8 +931,25 @@ func getEntropyColor(level types.SecurityEntropy) color.Style {
 	}
 }
 
-// displayCollectedData shows what information we gathered for analysis
-func displayCollectedData(pkgInfo *types.PackageInfo) {
+// enrichmentGaps describes the AUR context an analysis is missing, one entry
+// per failed lookup; empty when the enrichment was complete.
+func enrichmentGaps(status aur.EnrichmentStatus) []string {
+	var gaps []string
+	if status.MetadataErr != nil {
+		gaps = append(gaps, fmt.Sprintf("AUR metadata unavailable, no votes, dates or dependencies (%v)", status.MetadataErr))
+	}
+	if status.CommitErr != nil {
+		gaps = append(gaps, fmt.Sprintf("AUR commit unknown, this analysis won't be cached (%v)", status.CommitErr))
+	}
+	if status.InstallScriptErr != nil {
+		gaps = append(gaps, fmt.Sprintf("install script not fetched, it gets no verdict of its own (%v)", status.InstallScriptErr))
+	}
+	return gaps
+}
+
+// displayCollectedData shows what information we gathered for analysis.
+// enrichment is nil when no AUR lookup was attempted.
+func displayCollectedData(pkgInfo *types.PackageInfo, enrichment *aur.EnrichmentStatus) {
 	fmt.Printf("\n")
 	color.Bold.Printf("Collected for Analysis:\n")
 	fmt.Printf("─────────────────────────\n")
@@ -451,7 +959,12 @@ func displayCollectedData(pkgInfo *types.PackageInfo) {
 	fmt.Printf("• PKGBUILD: %d lines of shell script\n", pkgbuildLines)
 
 	// Package metadata
-	fmt.Printf("• Package metadata: %s v%s by %s\n", pkgInfo.Name, pkgInfo.Version, pkgInfo.Maintainer)
+	if pkgInfo.DynamicVersion {
+		fmt.Printf("• Package metadata: %s by %s\n", pkgInfo.Name, pkgInfo.Maintainer)
+		fmt.Printf("• Version: %s\n", pkgInfo.VersionLabel())
+	} else {
+		fmt.Printf("• Package metadata: %s v%s by %s\n", pkgInfo.Name, pkgInfo.Version, pkgInfo.Maintainer)
+	}
 
 	// Dependencies
 	if len(pkgInfo.Dependencies) > 0 {
@@ -463,6 +976,17 @@ func displayCollectedData(pkgInfo *types.PackageInfo) {
 			len(pkgInfo.MakeDepends), truncateList(pkgInfo.MakeDepends, 3))
 	}
 
+	// Sources
+	if len(pkgInfo.Sources) > 0 {
+		fmt.Printf("• Sources: %d (%s)\n",
+			len(pkgInfo.Sources), truncateList(sourceDomains(pkgInfo.Sources), 3))
+	}
+
+	// AUR git history
+	if len(pkgInfo.RecentCommits) > 0 {
+		fmt.Printf("• Git history: %s\n", describeRecentCommits(pkgInfo.RecentCommits))
+	}
+
 	// AUR history
 	if pkgInfo.FirstSubmitted != "" && pkgInfo.LastUpdated != "" {
 		fmt.Printf("• AUR history: submitted %s, last updated %s\n",
@@ -477,12 +1001,68 @@ func displayCollectedData(pkgInfo *types.PackageInfo) {
 
 	// Optional dependencies
 	if len(pkgInfo.OptDepends) > 0 {
-		fmt.Printf("• Optional dependencies: %d packages\n", len(pkgInfo.OptDepends))
+		fmt.Printf("• Optional dependencies: %d packages (%s)\n",
+			len(pkgInfo.OptDepends), truncateList(optDependNames(pkgInfo.OptDepends), 5))
+	}
+
+	// License and keywords from the AUR metadata
+	if len(pkgInfo.License) > 0 {
+		fmt.Printf("• License: %s\n", strings.Join(pkgInfo.License, ", "))
+	}
+	if len(pkgInfo.Keywords) > 0 {
+		fmt.Printf("• Keywords: %s\n", truncateList(pkgInfo.Keywords, 5))
+	}
+
+	// Config files the package manages
+	if backup := scanner.ParseBackup(pkgInfo.PKGBUILD); len(backup) > 0 {
+		fmt.Printf("• Backup files: %d (%s)\n", len(backup), truncateList(backup, 3))
+	}
+
+	// What the AUR lookups couldn't provide
+	if enrichment != nil {
+		for _, gap := range enrichmentGaps(*enrichment) {
+			fmt.Printf("%s Missing: %s\n", ui.Warn, gap)
+		}
 	}
 
 	fmt.Printf("\n")
 }
 
+// sourceDomains returns the distinct hosts the sources are fetched from, in
+// order, with "local" standing for files shipped alongside the PKGBUILD.
+func sourceDomains(sources []string) []string {
+	var domains []string
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		// Drop a "name::" rename prefix and a VCS prefix such as "git+"
+		if i := strings.LastIndex(source, "::"); i >= 0 {
+			source = source[i+2:]
+		}
+		if scheme, rest, ok := strings.Cut(source, "+"); ok && !strings.ContainsAny(scheme, "/:") {
+			source = rest
+		}
+		domain := "local"
+		if u, err := url.Parse(source); err == nil && u.Host != "" {
+			domain = u.Hostname()
+		}
+		if !seen[domain] {
+			seen[domain] = true
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// optDependNames returns the package names of optdepends entries, without
+// their descriptions.
+func optDependNames(entries []string) []string {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, scanner.OptDependName(entry))
+	}
+	return names
+}
+
 // truncateList truncates a string slice for display
 func truncateList(items []string, maxItems int) string {
 	if len(items) <= maxItems {
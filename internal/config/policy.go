@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// SourcePolicy is the source of a value set by the system policy file.
+const SourcePolicy = "system policy"
+
+// SystemPolicyPath is the machine-wide policy file an administrator deploys.
+// It is read after the user's config and the environment and can only
+// tighten them; neither --config nor YAY_FRIEND_* can override it.
+var SystemPolicyPath = "/etc/yay-friend/policy.yaml"
+
+// requireRootOwned refuses a policy file that isn't owned by root or that
+// others can write. Tests turn it off to use a temporary file.
+var requireRootOwned = true
+
+// systemPolicy is the part of the configuration the policy file may set.
+type systemPolicy struct {
+	Security struct {
+		AllowSkip    *bool    `yaml:"allow_skip"`
+		TrustedRepos []string `yaml:"trusted_repos"`
+	} `yaml:"security"`
+}
+
+// applySystemPolicy enforces the policy file at path, if there is one, on
+// cfg, returning the keys it set. security.allow_skip: false makes analysis
+// mandatory whatever the user's config says. Adding a repository to
+// security.trusted_repos is another way to skip analysis, so under a
+// mandatory policy the trusted repositories are the policy's, or the
+// defaults when it names none. A policy that anyone but root could have
+// written is an error rather than silently ignored.
+func applySystemPolicy(cfg *types.Config, path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read system policy %s: %w", path, err)
+	}
+	if requireRootOwned {
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok || stat.Uid != 0 || info.Mode().Perm()&0o022 != 0 {
+			return nil, fmt.Errorf("system policy %s must be owned by root and writable only by root", path)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read system policy %s: %w", path, err)
+	}
+	var policy systemPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse system policy %s: %w", path, err)
+	}
+
+	var keys []string
+	mandatory := policy.Security.AllowSkip != nil && !*policy.Security.AllowSkip
+	if mandatory {
+		cfg.Security.AllowSkip = false
+		keys = append(keys, "security.allow_skip")
+	}
+	if policy.Security.TrustedRepos != nil {
+		cfg.Security.TrustedRepos = policy.Security.TrustedRepos
+		keys = append(keys, "security.trusted_repos")
+	} else if mandatory {
+		cfg.Security.TrustedRepos = defaultConfig().Security.TrustedRepos
+		keys = append(keys, "security.trusted_repos")
+	}
+	return keys, nil
+}
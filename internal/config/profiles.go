@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aaronsb/yay-friend/internal/scanner"
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// DefaultProfile is the analysis profile used when none is configured. It
+// matches the built-in defaults, so selecting it changes nothing.
+const DefaultProfile = "balanced"
+
+// profiles bundle coherent thresholds, scanner sensitivity, and community
+// floors under one name. A profile is applied to the defaults before the
+// config file is overlaid, so any key set explicitly in the file still wins.
+var profiles = map[string]func(cfg *types.Config){
+	// strict blocks at HIGH, warns from LOW, measures shorter and less dense
+	// literals, flags a few more commands, and requires some community uptake.
+	"strict": func(cfg *types.Config) {
+		cfg.SecurityThresholds.BlockLevel = types.SecurityHigh
+		cfg.SecurityThresholds.WarnLevel = types.SecurityLow
+		cfg.SecurityThresholds.MinVotes = 10
+		cfg.SecurityThresholds.MinPopularity = 0.1
+		cfg.Scanner.ObfuscationEntropy = 5.0
+		cfg.Scanner.ObfuscationMinLength = 32
+		cfg.Scanner.SuspiciousCommands = append(scanner.DefaultSuspiciousCommands(),
+			types.SuspiciousCommand{Command: "sh -c", Level: types.EntropyLow},
+			types.SuspiciousCommand{Command: "bash -c", Level: types.EntropyLow},
+			types.SuspiciousCommand{Command: "xxd", Level: types.EntropyLow},
+			types.SuspiciousCommand{Command: "openssl", Level: types.EntropyLow},
+		)
+	},
+	"balanced": func(cfg *types.Config) {},
+	// lenient blocks only CRITICAL, warns from HIGH, tolerates denser literals,
+	// and keeps only the HIGH-level (raw network tool) suspicious commands.
+	"lenient": func(cfg *types.Config) {
+		cfg.SecurityThresholds.BlockLevel = types.SecurityCritical
+		cfg.SecurityThresholds.WarnLevel = types.SecurityHigh
+		cfg.Scanner.ObfuscationEntropy = 5.6
+		var core []types.SuspiciousCommand
+		for _, sc := range scanner.DefaultSuspiciousCommands() {
+			if sc.Level >= types.EntropyHigh {
+				core = append(core, sc)
+			}
+		}
+		cfg.Scanner.SuspiciousCommands = core
+	},
+}
+
+// ProfileNames returns the known profile names, sorted.
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile applies the named profile to cfg and records it there.
+func applyProfile(cfg *types.Config, name string) error {
+	apply, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown analysis profile %q (expected one of %v)", name, ProfileNames())
+	}
+	apply(cfg)
+	cfg.Analysis.Profile = name
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package namcap runs namcap, Arch's packaging linter, on a PKGBUILD and
+// turns its errors and warnings into informational findings.
+package namcap
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// FindingType is the type of every finding namcap contributes.
+const FindingType = "namcap"
+
+// Timeout bounds one namcap run.
+const Timeout = 30 * time.Second
+
+// ErrNotInstalled is returned by Run when the namcap executable isn't found.
+var ErrNotInstalled = errors.New("namcap is not installed")
+
+// Issue is one error or warning namcap reported.
+type Issue struct {
+	Severity string // "E" (error) or "W" (warning)
+	Message  string
+}
+
+// issueRe matches a namcap report line: "PKGBUILD (foo) W: message".
+var issueRe = regexp.MustCompile(`^\S+ \([^)]*\) ([EW]): (.+)$`)
+
+// Run writes pkgbuild to a temporary directory and runs namcap (path, or
+// "namcap" on PATH when empty) on it, returning the issues it reported.
+// namcap sources the PKGBUILD with bash, so its top-level code runs: callers
+// should only pass PKGBUILDs that have already been analyzed.
+func Run(ctx context.Context, path, pkgbuild string) ([]Issue, error) {
+	if path == "" {
+		path = "namcap"
+	}
+	executable, err := exec.LookPath(path)
+	if err != nil {
+		return nil, ErrNotInstalled
+	}
+
+	dir, err := os.MkdirTemp("", "yay-friend-namcap-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create namcap directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "PKGBUILD"), []byte(pkgbuild), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write PKGBUILD for namcap: %w", err)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, executable, "PKGBUILD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("namcap failed: %w", err)
+	}
+	return Parse(string(output)), nil
+}
+
+// Parse extracts the errors and warnings from namcap's output, in order.
+// Other lines, such as informational ones, are ignored.
+func Parse(output string) []Issue {
+	var issues []Issue
+	lines := bufio.NewScanner(strings.NewReader(output))
+	for lines.Scan() {
+		if m := issueRe.FindStringSubmatch(strings.TrimSpace(lines.Text())); m != nil {
+			issues = append(issues, Issue{Severity: m[1], Message: m[2]})
+		}
+	}
+	return issues
+}
+
+// Findings converts issues into MINIMAL findings of type FindingType: namcap
+// checks packaging correctness, not security, so its issues are reported but
+// never raise the verdict.
+func Findings(issues []Issue) []types.SecurityFinding {
+	var findings []types.SecurityFinding
+	for _, issue := range issues {
+		kind := "warning"
+		if issue.Severity == "E" {
+			kind = "error"
+		}
+		finding := types.SecurityFinding{
+			Type:         FindingType,
+			Entropy:      types.EntropyMinimal,
+			Severity:     types.EntropyMinimal, // For compatibility
+			Description:  fmt.Sprintf("namcap %s: %s", kind, issue.Message),
+			Context:      issue.Message,
+			Suggestion:   "A packaging check, not a security verdict; see namcap(1)",
+			EntropyNotes: "Reported by namcap",
+		}
+		finding.Fingerprint = finding.ComputeFingerprint()
+		findings = append(findings, finding)
+	}
+	return findings
+}
@@ -0,0 +1,71 @@
+package namcap
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+const sampleOutput = `PKGBUILD (hello) E: Missing url
+PKGBUILD (hello) W: Non-unique source name (v1.0.tar.gz). Use a unique filename.
+PKGBUILD (hello) I: Some informational note
+not a namcap line
+`
+
+func TestParse(t *testing.T) {
+	issues := Parse(sampleOutput)
+	want := []Issue{
+		{"E", "Missing url"},
+		{"W", "Non-unique source name (v1.0.tar.gz). Use a unique filename."},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Parse = %+v, want %+v", issues, want)
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Errorf("issue %d = %+v, want %+v", i, issues[i], want[i])
+		}
+	}
+}
+
+func TestFindingsAreInformational(t *testing.T) {
+	findings := Findings(Parse(sampleOutput))
+	if len(findings) != 2 {
+		t.Fatalf("Findings = %+v, want 2", findings)
+	}
+	for _, f := range findings {
+		if f.Type != FindingType || f.Entropy != types.EntropyMinimal || f.Fingerprint == "" {
+			t.Errorf("finding = %+v, want a fingerprinted MINIMAL namcap finding", f)
+		}
+	}
+	if findings[0].Description != "namcap error: Missing url" {
+		t.Errorf("description = %q", findings[0].Description)
+	}
+	if findings[0].Fingerprint == findings[1].Fingerprint {
+		t.Error("different issues share a fingerprint")
+	}
+}
+
+func TestRun(t *testing.T) {
+	if _, err := Run(context.Background(), filepath.Join(t.TempDir(), "missing"), "pkgname=x"); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("missing namcap: err = %v, want ErrNotInstalled", err)
+	}
+
+	// A stand-in namcap that checks it was given the PKGBUILD
+	fake := filepath.Join(t.TempDir(), "namcap")
+	script := "#!/bin/sh\ngrep -q '^pkgname=hello$' \"$1\" && echo 'PKGBUILD (hello) W: Missing license'\n"
+	if err := os.WriteFile(fake, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := Run(context.Background(), fake, "pkgname=hello\n")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(issues) != 1 || issues[0] != (Issue{"W", "Missing license"}) {
+		t.Errorf("Run = %+v", issues)
+	}
+}
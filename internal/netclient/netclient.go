@@ -0,0 +1,67 @@
+// Package netclient builds the HTTP clients yay-friend talks to the network
+// with: the AUR fetcher, snapshot downloads, the GitHub release check, and
+// any provider that calls an HTTP API. All of them share one transport, so a
+// proxy or custom CA configured once applies everywhere.
+package netclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	mu        sync.Mutex
+	transport http.RoundTripper = newTransport(nil, false)
+)
+
+// Configure sets up the shared transport. caCert, when set, is a PEM file of
+// root certificates trusted in addition to the system's, for a corporate
+// TLS-intercepting proxy. insecure turns off certificate verification
+// entirely; it is meant for testing only. Proxies always come from the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func Configure(caCert string, insecure bool) error {
+	var roots *x509.CertPool
+	if caCert != "" {
+		pem, err := os.ReadFile(caCert)
+		if err != nil {
+			return fmt.Errorf("failed to read network.ca_cert: %w", err)
+		}
+		roots, err = x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("network.ca_cert %s holds no PEM certificates", caCert)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	transport = newTransport(roots, insecure)
+	return nil
+}
+
+// New returns a client with the given timeout over the shared transport.
+func New(timeout time.Duration) *http.Client {
+	mu.Lock()
+	defer mu.Unlock()
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// newTransport clones the default transport, keeping its connection pooling
+// and timeouts, and sets the proxy and TLS settings. nil roots means the
+// system pool.
+func newTransport(roots *x509.CertPool, insecure bool) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	t.TLSClientConfig = &tls.Config{
+		RootCAs:            roots,
+		InsecureSkipVerify: insecure,
+	}
+	return t
+}
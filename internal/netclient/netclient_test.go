@@ -0,0 +1,75 @@
+package netclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// tlsServer starts a TLS test server with a self-signed certificate and
+// writes that certificate to a PEM file, returning both.
+func tlsServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Configure("", false) })
+	return server, path
+}
+
+func get(url string) error {
+	resp, err := New(5 * time.Second).Get(url)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return err
+}
+
+func TestCustomCA(t *testing.T) {
+	server, caPath := tlsServer(t)
+
+	if err := get(server.URL); err == nil {
+		t.Fatalf("request to a self-signed server succeeded without its CA")
+	}
+	if err := Configure(caPath, false); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if err := get(server.URL); err != nil {
+		t.Errorf("request with the custom CA failed: %v", err)
+	}
+}
+
+func TestInsecure(t *testing.T) {
+	server, _ := tlsServer(t)
+
+	if err := Configure("", true); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if err := get(server.URL); err != nil {
+		t.Errorf("request with verification off failed: %v", err)
+	}
+}
+
+func TestConfigureRejectsBadCA(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Configure("", false) })
+
+	if err := Configure(path, false); err == nil {
+		t.Errorf("Configure accepted a file without certificates")
+	}
+	if err := Configure(filepath.Join(t.TempDir(), "missing.pem"), false); err == nil {
+		t.Errorf("Configure accepted a missing file")
+	}
+}
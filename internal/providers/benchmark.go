@@ -0,0 +1,123 @@
+package providers
+
+import (
+	"context"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// BenchmarkRun is one provider's analysis of one package.
+type BenchmarkRun struct {
+	Package  string
+	Latency  time.Duration
+	Analysis *types.SecurityAnalysis // nil when Err is set
+	Err      error
+}
+
+// BenchmarkStats summarizes one provider's runs.
+type BenchmarkStats struct {
+	Provider   string
+	Runs       int
+	Successes  int
+	AvgLatency time.Duration // over successful runs
+	MaxLatency time.Duration // over successful runs
+}
+
+// BenchmarkAgreement compares two providers over the packages both analyzed.
+type BenchmarkAgreement struct {
+	A, B               string
+	Compared           int // packages both providers analyzed successfully
+	SameLevel          int
+	SameRecommendation int
+}
+
+// BenchmarkReport holds every run of a benchmark, keyed by provider name.
+type BenchmarkReport struct {
+	Providers []string // in the order they were run
+	Runs      map[string][]BenchmarkRun
+}
+
+// RunBenchmark analyzes every package with every provider, one call at a
+// time so latencies aren't skewed by contention. Providers are called
+// directly; nothing is read from or written to the analysis cache.
+func RunBenchmark(ctx context.Context, providers []types.AIProvider, packages []types.PackageInfo) *BenchmarkReport {
+	report := &BenchmarkReport{Runs: make(map[string][]BenchmarkRun)}
+	for _, p := range providers {
+		name := p.Name()
+		report.Providers = append(report.Providers, name)
+		for _, pkg := range packages {
+			start := time.Now()
+			analysis, err := p.AnalyzePKGBUILDWithOptions(ctx, pkg, types.AnalysisOptions{NoSpinner: true})
+			report.Runs[name] = append(report.Runs[name], BenchmarkRun{
+				Package: pkg.Name, Latency: time.Since(start), Analysis: analysis, Err: err,
+			})
+		}
+	}
+	return report
+}
+
+// Stats returns per-provider latency and success figures, in run order.
+func (r *BenchmarkReport) Stats() []BenchmarkStats {
+	var stats []BenchmarkStats
+	for _, name := range r.Providers {
+		s := BenchmarkStats{Provider: name}
+		var total time.Duration
+		for _, run := range r.Runs[name] {
+			s.Runs++
+			if run.Err != nil {
+				continue
+			}
+			s.Successes++
+			total += run.Latency
+			if run.Latency > s.MaxLatency {
+				s.MaxLatency = run.Latency
+			}
+		}
+		if s.Successes > 0 {
+			s.AvgLatency = total / time.Duration(s.Successes)
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// Agreement returns, for each pair of providers, how often they reached the
+// same overall level and the same recommendation.
+func (r *BenchmarkReport) Agreement() []BenchmarkAgreement {
+	var pairs []BenchmarkAgreement
+	for i, a := range r.Providers {
+		for _, b := range r.Providers[i+1:] {
+			pair := BenchmarkAgreement{A: a, B: b}
+			byPackage := make(map[string]*types.SecurityAnalysis)
+			for _, run := range r.Runs[b] {
+				if run.Err == nil {
+					byPackage[run.Package] = run.Analysis
+				}
+			}
+			for _, run := range r.Runs[a] {
+				other, ok := byPackage[run.Package]
+				if run.Err != nil || !ok {
+					continue
+				}
+				pair.Compared++
+				if run.Analysis.OverallLevel == other.OverallLevel {
+					pair.SameLevel++
+				}
+				if sameRecommendation(run.Analysis.Recommendation, other.Recommendation) {
+					pair.SameRecommendation++
+				}
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// sameRecommendation compares two recommendations by their verdict
+// (PROCEED, REVIEW, BLOCK), so differing explanations still compare equal.
+func sameRecommendation(a, b types.Recommendation) bool {
+	verdictA, _ := types.ParseRecommendation(string(a))
+	verdictB, _ := types.ParseRecommendation(string(b))
+	return verdictA == verdictB
+}
@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// fakeProvider returns a canned level/recommendation per package, or an error
+// for packages it has no answer for.
+type fakeProvider struct {
+	name    string
+	answers map[string]types.SecurityAnalysis
+}
+
+func (f *fakeProvider) Name() string                           { return f.name }
+func (f *fakeProvider) Authenticate(ctx context.Context) error { return nil }
+func (f *fakeProvider) IsAuthenticated() bool                  { return true }
+func (f *fakeProvider) GetCapabilities() types.ProviderCapabilities {
+	return types.ProviderCapabilities{}
+}
+func (f *fakeProvider) AnalyzePKGBUILD(ctx context.Context, pkgInfo types.PackageInfo) (*types.SecurityAnalysis, error) {
+	return f.AnalyzePKGBUILDWithOptions(ctx, pkgInfo, types.AnalysisOptions{})
+}
+func (f *fakeProvider) AnalyzePKGBUILDWithOptions(ctx context.Context, pkgInfo types.PackageInfo, opts types.AnalysisOptions) (*types.SecurityAnalysis, error) {
+	answer, ok := f.answers[pkgInfo.Name]
+	if !ok {
+		return nil, fmt.Errorf("%s failed on %s", f.name, pkgInfo.Name)
+	}
+	return &answer, nil
+}
+
+func TestBenchmarkStatsAndAgreement(t *testing.T) {
+	a := &fakeProvider{name: "a", answers: map[string]types.SecurityAnalysis{
+		"x": {OverallLevel: types.EntropyLow, Recommendation: "PROCEED"},
+		"y": {OverallLevel: types.EntropyHigh, Recommendation: "REVIEW - downloads at build time"},
+		"z": {OverallLevel: types.EntropyLow, Recommendation: "PROCEED"},
+	}}
+	b := &fakeProvider{name: "b", answers: map[string]types.SecurityAnalysis{
+		"x": {OverallLevel: types.EntropyLow, Recommendation: "proceed"},
+		"y": {OverallLevel: types.EntropyModerate, Recommendation: "REVIEW"},
+	}}
+	pkgs := []types.PackageInfo{{Name: "x"}, {Name: "y"}, {Name: "z"}}
+
+	report := RunBenchmark(context.Background(), []types.AIProvider{a, b}, pkgs)
+
+	stats := report.Stats()
+	if len(stats) != 2 || stats[0].Provider != "a" || stats[1].Provider != "b" {
+		t.Fatalf("Stats() = %+v, want a then b", stats)
+	}
+	if stats[0].Successes != 3 || stats[1].Successes != 2 || stats[1].Runs != 3 {
+		t.Errorf("success counts = %+v", stats)
+	}
+
+	pairs := report.Agreement()
+	if len(pairs) != 1 {
+		t.Fatalf("Agreement() = %+v, want one pair", pairs)
+	}
+	p := pairs[0]
+	if p.Compared != 2 || p.SameLevel != 1 || p.SameRecommendation != 2 {
+		t.Errorf("agreement = %+v, want compared 2, same level 1, same recommendation 2", p)
+	}
+}
@@ -5,16 +5,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/aaronsb/yay-friend/internal/config"
 	"github.com/aaronsb/yay-friend/internal/scanner"
+	"github.com/aaronsb/yay-friend/internal/trust"
 	"github.com/aaronsb/yay-friend/internal/types"
 )
 
@@ -33,11 +36,20 @@ var deniedTools = []string{
 	"WebFetch", "WebSearch", "Task", "TodoWrite",
 }
 
+// authTTL is how long a successful Authenticate is trusted within one process.
+// Batch and watch runs call Authenticate per command or package; within the
+// TTL they reuse the earlier result instead of re-running the check.
+const authTTL = 5 * time.Minute
+
 // ClaudeProvider implements the AIProvider interface for Claude Code
 type ClaudeProvider struct {
-	authenticated bool
+	authenticated   bool
+	authenticatedAt time.Time // when authenticated was last confirmed
 	config        *types.Config
 	claudePath    string // Store the resolved path to claude command
+	verbose       bool
+	debug         bool
+	userRules     []scanner.ScannerRule // from scanner.rules_dir, set by SetUserRules
 }
 
 // NewClaudeProvider creates a new Claude provider
@@ -46,8 +58,24 @@ func NewClaudeProvider() *ClaudeProvider {
 }
 
 // SetConfig sets the configuration for the provider
-func (c *ClaudeProvider) SetConfig(config *types.Config) {
-	c.config = config
+func (c *ClaudeProvider) SetConfig(cfg *types.Config) {
+	c.config = cfg
+}
+
+// SetUserRules sets the scanner rules the pre-scan runs after the built-in
+// ones: those config.Load compiled from scanner.rules_dir.
+func (c *ClaudeProvider) SetUserRules(rules []scanner.ScannerRule) {
+	c.userRules = rules
+}
+
+// SetVerbose enables logging of the full claude command line
+func (c *ClaudeProvider) SetVerbose(verbose bool) {
+	c.verbose = verbose
+}
+
+// SetDebug enables dumping the raw claude output and extracted JSON to stderr
+func (c *ClaudeProvider) SetDebug(debug bool) {
+	c.debug = debug
 }
 
 // Name returns the provider name
@@ -57,6 +85,15 @@ func (c *ClaudeProvider) Name() string {
 
 // findClaudeCommand searches for the claude command in various locations
 func (c *ClaudeProvider) findClaudeCommand() (string, error) {
+	// A configured path is used as given, never silently replaced by another
+	if c.config != nil && c.config.Claude.Path != "" {
+		path, err := exec.LookPath(c.config.Claude.Path)
+		if err != nil {
+			return "", fmt.Errorf("configured claude.path %s is not executable: %w", c.config.Claude.Path, err)
+		}
+		return path, nil
+	}
+
 	// List of possible locations for the claude command
 	possiblePaths := []string{
 		"claude",                           // In PATH
@@ -99,20 +136,26 @@ func (c *ClaudeProvider) findClaudeCommand() (string, error) {
 
 // Authenticate checks if Claude Code is available and authenticated
 func (c *ClaudeProvider) Authenticate(ctx context.Context) error {
+	if c.authenticated && time.Since(c.authenticatedAt) < authTTL {
+		return nil
+	}
+	c.authenticated = false
+
 	// Find the claude command
 	claudePath, err := c.findClaudeCommand()
 	if err != nil {
-		return fmt.Errorf("claude command not found: %w", err)
+		return fmt.Errorf("%w: claude command not found: %w", ErrProviderAuth, err)
 	}
 	c.claudePath = claudePath
 
 	// Test authentication by running a simple command
 	cmd := exec.CommandContext(ctx, c.claudePath, "--version")
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run claude command at %s: %w", c.claudePath, err)
+		return fmt.Errorf("%w: failed to run claude command at %s: %w", ErrProviderAuth, c.claudePath, err)
 	}
 
 	c.authenticated = true
+	c.authenticatedAt = time.Now()
 	return nil
 }
 
@@ -123,16 +166,16 @@ func (c *ClaudeProvider) IsAuthenticated() bool {
 
 // AnalyzePKGBUILD analyzes a PKGBUILD using Claude Code
 func (c *ClaudeProvider) AnalyzePKGBUILD(ctx context.Context, pkgInfo types.PackageInfo) (*types.SecurityAnalysis, error) {
-	return c.AnalyzePKGBUILDWithOptions(ctx, pkgInfo, false)
+	return c.AnalyzePKGBUILDWithOptions(ctx, pkgInfo, types.AnalysisOptions{})
 }
 
 // AnalyzePKGBUILDWithOptions analyzes a PKGBUILD with additional options
-func (c *ClaudeProvider) AnalyzePKGBUILDWithOptions(ctx context.Context, pkgInfo types.PackageInfo, noSpinner bool) (*types.SecurityAnalysis, error) {
+func (c *ClaudeProvider) AnalyzePKGBUILDWithOptions(ctx context.Context, pkgInfo types.PackageInfo, opts types.AnalysisOptions) (*types.SecurityAnalysis, error) {
 	if !c.authenticated {
-		return nil, fmt.Errorf("claude provider not authenticated")
+		return nil, fmt.Errorf("%w: claude provider not authenticated", ErrProviderAuth)
 	}
 
-	prompt := c.buildSimpleSecurityPrompt(pkgInfo)
+	prompt, omitted, cut := c.buildPrompt(pkgInfo)
 
 	// Get or create a dedicated directory for claude executions. Running from a
 	// neutral directory keeps claude from auto-discovering a project CLAUDE.md or
@@ -152,19 +195,34 @@ func (c *ClaudeProvider) AnalyzePKGBUILDWithOptions(ctx context.Context, pkgInfo
 	// (automation, CI), fall back to a single quiet one-shot call.
 	var resultText string
 	var err error
-	if noSpinner || !isTerminal(os.Stdout) {
+	start := time.Now()
+	if opts.NoSpinner || !isTerminal(os.Stdout) {
 		resultText, err = c.runClaudeOneShot(ctx, prompt, claudeWorkDir)
 	} else {
 		resultText, err = c.runClaudeStreaming(ctx, prompt, claudeWorkDir)
 	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrProviderResponse, err)
 	}
+	duration := time.Since(start)
 
 	// Parse the response
 	analysis, err := c.parseAnalysisResponse(resultText, pkgInfo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse analysis: %w", err)
+		return nil, fmt.Errorf("%w: failed to parse analysis: %w", ErrProviderResponse, err)
+	}
+
+	// Fold the deterministic rule findings into the verdict.
+	scanner.ScanWithOptions(prescanInput(pkgInfo), c.scanOptions(pkgInfo)).MergeInto(analysis)
+	analysis.InstallScript = scanner.InstallScriptRisk(pkgInfo.InstallScript, c.scanOptions(pkgInfo))
+	analysis.RiskScore = trust.RiskScore(analysis, pkgInfo)
+	trust.ApplyPromptTruncation(analysis, pkgInfo, cut)
+	analysis.DurationSeconds = duration.Seconds()
+	analysis.PromptChars = len(prompt)
+	// Cached with the analysis, so a later run still says it was partial
+	if omitted > 0 {
+		analysis.PKGBUILDLines = len(strings.Split(pkgInfo.PKGBUILD, "\n"))
+		analysis.OmittedLines = omitted
 	}
 
 	return analysis, nil
@@ -189,10 +247,24 @@ func (c *ClaudeProvider) baseClaudeArgs() []string {
 	}
 }
 
+// invocationArgs builds the full argument list for one claude run: the
+// output-mode flags, the hardened base flags, then any user-configured
+// claude.args (validated by config to leave the managed flags alone).
+func (c *ClaudeProvider) invocationArgs(modeArgs ...string) []string {
+	args := append(modeArgs, c.baseClaudeArgs()...)
+	if c.config != nil {
+		args = append(args, c.config.Claude.Args...)
+	}
+	if c.verbose {
+		fmt.Fprintf(os.Stderr, "Running: %s %s\n", c.claudePath, strings.Join(args, " "))
+	}
+	return args
+}
+
 // runClaudeOneShot runs a single non-interactive analysis and returns the model's
 // text result, unwrapped from the `--output-format json` envelope.
 func (c *ClaudeProvider) runClaudeOneShot(ctx context.Context, prompt, workDir string) (string, error) {
-	args := append([]string{"--print", "--output-format", "json"}, c.baseClaudeArgs()...)
+	args := c.invocationArgs("--print", "--output-format", "json")
 
 	// Status goes to stderr so stdout stays clean for callers capturing output.
 	fmt.Fprintln(os.Stderr, "Analyzing with Claude...")
@@ -202,7 +274,11 @@ func (c *ClaudeProvider) runClaudeOneShot(ctx context.Context, prompt, workDir s
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("claude analysis interrupted: %w", ctx.Err())
+	}
 	fmt.Fprintln(os.Stderr, "Analysis complete.")
+	c.debugDump("raw claude output", string(output))
 
 	if err != nil {
 		if stderr.Len() > 0 {
@@ -218,7 +294,11 @@ func (c *ClaudeProvider) runClaudeOneShot(ctx context.Context, prompt, workDir s
 // events arrive, while capturing the final result event for parsing. stdout and
 // stderr are handled on separate pipes so a chatty stderr can't deadlock reads.
 func (c *ClaudeProvider) runClaudeStreaming(ctx context.Context, prompt, workDir string) (string, error) {
-	args := append([]string{"--print", "--output-format", "stream-json", "--verbose"}, c.baseClaudeArgs()...)
+	modeArgs := []string{"--print", "--output-format", "stream-json", "--verbose"}
+	if c.config != nil && c.config.Claude.StreamPartial {
+		modeArgs = append(modeArgs, "--include-partial-messages")
+	}
+	args := c.invocationArgs(modeArgs...)
 
 	cmd := exec.CommandContext(ctx, c.claudePath, args...)
 	cmd.Dir = workDir
@@ -236,6 +316,7 @@ func (c *ClaudeProvider) runClaudeStreaming(ctx context.Context, prompt, workDir
 	start := time.Now()
 	var mu sync.Mutex
 	phase := "starting"
+	var progress streamProgress
 
 	// Progress ticker: repaint the elapsed/phase line a few times a second.
 	// wg lets us join the goroutine before printing the final line, so a stale
@@ -253,7 +334,7 @@ func (c *ClaudeProvider) runClaudeStreaming(ctx context.Context, prompt, workDir
 				return
 			case <-ticker.C:
 				mu.Lock()
-				p := phase
+				p := progress.describe(phase)
 				mu.Unlock()
 				fmt.Printf("\r\033[KAnalyzing with Claude (%ds, %s)…", int(time.Since(start).Seconds()), p)
 			}
@@ -266,23 +347,37 @@ func (c *ClaudeProvider) runClaudeStreaming(ctx context.Context, prompt, workDir
 	// fallback if the result event is missing or unparseable (parity with the
 	// one-shot path, which falls back to raw text).
 	var resultEvent *claudeEvent
-	var assistantText strings.Builder
+	var assistantText, rawOutput strings.Builder
 	reader := bufio.NewReader(stdout)
 	for {
 		line, rerr := reader.ReadString('\n')
+		if c.debug {
+			rawOutput.WriteString(line)
+		}
 		if trimmed := strings.TrimSpace(line); trimmed != "" {
 			var ev claudeEvent
 			if json.Unmarshal([]byte(trimmed), &ev) == nil {
 				switch ev.Type {
+				case "stream_event":
+					// Partial chunks, when claude.stream_partial adds
+					// --include-partial-messages; the assistant event that
+					// follows still carries the whole message
+					if ev.Event.Delta.Type == "text_delta" {
+						mu.Lock()
+						phase = "receiving"
+						progress.add(ev.Event.Delta.Text)
+						mu.Unlock()
+					}
 				case "assistant":
-					mu.Lock()
-					phase = "receiving"
-					mu.Unlock()
 					for _, block := range ev.Message.Content {
 						if block.Type == "text" {
 							assistantText.WriteString(block.Text)
 						}
 					}
+					mu.Lock()
+					phase = "receiving"
+					progress.set(assistantText.String())
+					mu.Unlock()
 				case "result":
 					e := ev
 					resultEvent = &e
@@ -297,7 +392,14 @@ func (c *ClaudeProvider) runClaudeStreaming(ctx context.Context, prompt, workDir
 	close(doneTick)
 	wg.Wait()
 	waitErr := cmd.Wait()
+	if ctx.Err() != nil {
+		// Claude was killed by the cancellation. Clear the progress line (and
+		// the ^C echoed onto it) so the shell prompt starts on a clean line.
+		fmt.Printf("\r\033[K")
+		return "", fmt.Errorf("claude analysis interrupted: %w", ctx.Err())
+	}
 	fmt.Printf("\r\033[KAnalyzing with Claude… complete (%ds).\n", int(time.Since(start).Seconds()))
+	c.debugDump("raw claude output", rawOutput.String())
 
 	if resultEvent != nil {
 		if resultEvent.IsError {
@@ -322,6 +424,17 @@ func (c *ClaudeProvider) runClaudeStreaming(ctx context.Context, prompt, workDir
 	return "", fmt.Errorf("claude produced no result event")
 }
 
+// debugDump writes content to stderr between clearly marked delimiters when
+// --debug is set, keeping it apart from the normal stdout report.
+func (c *ClaudeProvider) debugDump(label, content string) {
+	if !c.debug {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\n----- debug: %s (%d bytes) -----\n", label, len(content))
+	fmt.Fprintln(os.Stderr, strings.TrimRight(content, "\n"))
+	fmt.Fprintf(os.Stderr, "----- end %s -----\n", label)
+}
+
 // isTerminal reports whether f is an interactive character device (a TTY),
 // as opposed to a pipe or regular file.
 func isTerminal(f *os.File) bool {
@@ -340,6 +453,37 @@ func (c *ClaudeProvider) getModel() string {
 	return config.DefaultClaudeModel
 }
 
+// maxPKGBUILDLines returns the configured PKGBUILD line limit (0 = unlimited)
+func (c *ClaudeProvider) maxPKGBUILDLines() int {
+	if c.config != nil {
+		return c.config.Prompts.MaxPKGBUILDLines
+	}
+	return 0
+}
+
+// scanOptions returns the pre-scan options for pkgInfo: thresholds from
+// config (or the defaults) and the package's already-parsed sources.
+func (c *ClaudeProvider) scanOptions(pkgInfo types.PackageInfo) scanner.Options {
+	opts := scanner.DefaultOptions()
+	opts.Sources = pkgInfo.Sources
+	opts.OptDepends = pkgInfo.OptDepends
+	opts.AURLicense = pkgInfo.License
+	opts.UserRules = c.userRules
+	if c.config == nil {
+		return opts
+	}
+	if c.config.Scanner.ObfuscationEntropy > 0 {
+		opts.ObfuscationEntropy = c.config.Scanner.ObfuscationEntropy
+	}
+	if c.config.Scanner.ObfuscationMinLength > 0 {
+		opts.ObfuscationMinLength = c.config.Scanner.ObfuscationMinLength
+	}
+	if c.config.Scanner.SuspiciousCommands != nil {
+		opts.SuspiciousCommands = c.config.Scanner.SuspiciousCommands
+	}
+	return opts
+}
+
 // claudeEvent captures the fields we need from `claude --output-format json`.
 // That format emits either a single result object or (in richer environments) a
 // JSON array of events ending in a result event; extractClaudeResult handles both.
@@ -356,6 +500,14 @@ type claudeEvent struct {
 			Text string `json:"text"`
 		} `json:"content"`
 	} `json:"message"`
+	// Event is populated only on "stream_event" events, sent with
+	// --include-partial-messages; a text_delta carries the next chunk.
+	Event struct {
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+	} `json:"event"`
 }
 
 // extractClaudeResult unwraps the JSON envelope from `claude --output-format json`
@@ -417,10 +569,21 @@ func (c *ClaudeProvider) GetCapabilities() types.ProviderCapabilities {
 }
 
 
+// Errors for a response that can't be parsed. They call for different fixes:
+// no output at all points at the CLI or its login, output without JSON at the
+// prompt or model.
+var (
+	ErrEmptyResponse = errors.New("provider returned no output")
+	ErrNoJSON        = errors.New("provider output contained no JSON")
+)
+
 // parseAnalysisResponse parses Claude's JSON response
 func (c *ClaudeProvider) parseAnalysisResponse(response string, pkgInfo types.PackageInfo) (*types.SecurityAnalysis, error) {
 	// Remove markdown code blocks if present
 	response = strings.TrimSpace(response)
+	if response == "" {
+		return nil, fmt.Errorf("%w: the claude CLI may not be installed or logged in; check with `yay-friend provider test claude` or rerun with --debug", ErrEmptyResponse)
+	}
 	if strings.Contains(response, "```json") {
 		// Extract content between ```json and ```
 		start := strings.Index(response, "```json")
@@ -447,16 +610,17 @@ func (c *ClaudeProvider) parseAnalysisResponse(response string, pkgInfo types.Pa
 	jsonStart := strings.Index(response, "{")
 	jsonEnd := strings.LastIndex(response, "}")
 	
-	if jsonStart == -1 || jsonEnd == -1 {
+	if jsonStart == -1 || jsonEnd < jsonStart {
 		// Debug: show part of response to understand the issue
 		responsePreview := response
 		if len(responsePreview) > 500 {
 			responsePreview = responsePreview[:500] + "..."
 		}
-		return nil, fmt.Errorf("no JSON found in response. Preview: %s", responsePreview)
+		return nil, fmt.Errorf("%w: the model answered in prose instead of the requested JSON; a customized prompts.security_analysis may be at fault. Preview: %s", ErrNoJSON, responsePreview)
 	}
 	
 	jsonStr := response[jsonStart : jsonEnd+1]
+	c.debugDump("extracted JSON", jsonStr)
 	
 	var analysisData struct {
 		OverallEntropy      string   `json:"overall_entropy"`
@@ -493,10 +657,12 @@ func (c *ClaudeProvider) parseAnalysisResponse(response string, pkgInfo types.Pa
 
 	analysis := &types.SecurityAnalysis{
 		PackageName:         pkgInfo.Name,
+		PackageVersion:      pkgInfo.Version,
+		Maintainer:          pkgInfo.Maintainer,
 		OverallEntropy:      overallEntropy,
 		OverallLevel:        overallEntropy, // For compatibility
 		Summary:             analysisData.Summary,
-		Recommendation:      analysisData.Recommendation,
+		Recommendation:      types.Recommendation(analysisData.Recommendation),
 		AnalyzedAt:          time.Now(),
 		Provider:            "claude",
 		EntropyFactors:      analysisData.EntropyFactors,
@@ -526,6 +692,10 @@ func (c *ClaudeProvider) parseAnalysisResponse(response string, pkgInfo types.Pa
 			EntropyNotes: finding.EntropyNotes,
 		})
 	}
+	analysis.FillFingerprints()
+	if !analysis.NormalizeRecommendation() {
+		fmt.Fprintf(os.Stderr, "Warning: The model's recommendation %q names no verdict (PROCEED, REVIEW or BLOCK); the entropy level alone decides\n", analysis.RecommendationText)
+	}
 	
 	return analysis, nil
 }
@@ -548,24 +718,48 @@ func parseSecurityEntropy(level string) types.SecurityEntropy {
 	}
 }
 
+// BuildPrompt returns the prompt AnalyzePKGBUILD would send for pkgInfo. It
+// needs no authentication and runs nothing.
+func (c *ClaudeProvider) BuildPrompt(pkgInfo types.PackageInfo) string {
+	return c.buildSimpleSecurityPrompt(pkgInfo)
+}
+
 // buildSimpleSecurityPrompt creates a prompt using the config template
 func (c *ClaudeProvider) buildSimpleSecurityPrompt(pkgInfo types.PackageInfo) string {
+	prompt, _, _ := c.buildPrompt(pkgInfo)
+	return prompt
+}
+
+// buildPrompt fills in the config template and reports how many PKGBUILD
+// lines were left out of it, and what the provider's MaxAnalysisSize forced
+// out (cut), as opposed to the context-lines limit the user chose. The
+// install script, helper files and git log each get a capped share of the
+// size (see installScriptShare); the PKGBUILD goes in last: it gets the
+// context-lines limit, then whatever is left once the rest of the prompt is
+// in, so a PKGBUILD that fits is sent whole.
+func (c *ClaudeProvider) buildPrompt(pkgInfo types.PackageInfo) (prompt string, omitted int, cut []string) {
 	// Build dependency strings
 	depends := strings.Join(pkgInfo.Dependencies, ", ")
 	makeDepends := strings.Join(pkgInfo.MakeDepends, ", ")
+	optDepends := strings.Join(pkgInfo.OptDepends, "; ")
 	if len(depends) > 200 {
 		depends = depends[:197] + "..."
 	}
 	if len(makeDepends) > 200 {
 		makeDepends = makeDepends[:197] + "..."
 	}
+	if len(optDepends) > 400 {
+		optDepends = optDepends[:397] + "..."
+	}
+
+	maxSize := c.GetCapabilities().MaxAnalysisSize
 
 	// Get the prompt template from config, or use default if not available
 	template := c.getPromptTemplate()
 	
 	// Replace template variables
-	prompt := strings.ReplaceAll(template, "{NAME}", pkgInfo.Name)
-	prompt = strings.ReplaceAll(prompt, "{VERSION}", pkgInfo.Version)
+	prompt = strings.ReplaceAll(template, "{NAME}", pkgInfo.Name)
+	prompt = strings.ReplaceAll(prompt, "{VERSION}", pkgInfo.VersionLabel())
 	prompt = strings.ReplaceAll(prompt, "{MAINTAINER}", pkgInfo.Maintainer)
 	prompt = strings.ReplaceAll(prompt, "{VOTES}", fmt.Sprintf("%d", pkgInfo.Votes))
 	prompt = strings.ReplaceAll(prompt, "{POPULARITY}", fmt.Sprintf("%.3f", pkgInfo.Popularity))
@@ -573,42 +767,109 @@ func (c *ClaudeProvider) buildSimpleSecurityPrompt(pkgInfo types.PackageInfo) st
 	prompt = strings.ReplaceAll(prompt, "{LAST_UPDATED}", pkgInfo.LastUpdated)
 	prompt = strings.ReplaceAll(prompt, "{DEPENDENCIES}", depends)
 	prompt = strings.ReplaceAll(prompt, "{MAKE_DEPENDS}", makeDepends)
-	prompt = strings.ReplaceAll(prompt, "{PKGBUILD}", pkgInfo.PKGBUILD)
-	
+	prompt = strings.ReplaceAll(prompt, "{OPT_DEPENDS}", optDepends)
+	prompt = strings.ReplaceAll(prompt, "{LICENSE}", strings.Join(pkgInfo.License, ", "))
+	prompt = strings.ReplaceAll(prompt, "{KEYWORDS}", strings.Join(pkgInfo.Keywords, ", "))
+	prompt = strings.ReplaceAll(prompt, "{SOURCES}", formatSources(pkgInfo.Sources))
+	gitLog, _ := trimSection(formatGitLog(pkgInfo.RecentCommits), maxSize/gitLogShare)
+	prompt = strings.ReplaceAll(prompt, "{GIT_LOG}", gitLog)
 	// Always replace install script placeholder
 	if pkgInfo.InstallScript != "" {
-		prompt = strings.ReplaceAll(prompt, "{INSTALL_SCRIPT}", pkgInfo.InstallScript)
+		installScript, trimmed := trimSection(pkgInfo.InstallScript, maxSize/installScriptShare)
+		if trimmed {
+			cut = append(cut, "the install script")
+		}
+		prompt = strings.ReplaceAll(prompt, "{INSTALL_SCRIPT}", installScript)
 	} else {
 		prompt = strings.ReplaceAll(prompt, "{INSTALL_SCRIPT}", "[No install script present - this may be due to local PKGBUILD analysis limitations]")
 	}
 	
 	// Always replace additional files placeholder
 	if pkgInfo.AdditionalFiles != nil && len(pkgInfo.AdditionalFiles) > 0 {
+		names := make([]string, 0, len(pkgInfo.AdditionalFiles))
+		for name := range pkgInfo.AdditionalFiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
 		var filesContent []string
-		for name, content := range pkgInfo.AdditionalFiles {
-			filesContent = append(filesContent, fmt.Sprintf("=== %s ===\n%s", name, content))
+		for _, name := range names {
+			filesContent = append(filesContent, fmt.Sprintf("=== %s ===\n%s", name, pkgInfo.AdditionalFiles[name]))
+		}
+		files, trimmed := trimSection(strings.Join(filesContent, "\n\n"), maxSize/additionalFilesShare)
+		if trimmed {
+			cut = append(cut, "the helper files")
 		}
-		prompt = strings.ReplaceAll(prompt, "{ADDITIONAL_FILES}", strings.Join(filesContent, "\n\n"))
+		prompt = strings.ReplaceAll(prompt, "{ADDITIONAL_FILES}", files)
 	} else {
 		prompt = strings.ReplaceAll(prompt, "{ADDITIONAL_FILES}", "[No additional files present - this may be due to local PKGBUILD analysis limitations]")
 	}
 
-	// Deterministic entropy pre-scan, injected as trusted ground truth. Scans
-	// the PKGBUILD plus any install script and helper files so a payload hidden
-	// in an .install hook is surfaced too. Injection-proof: computed from bytes.
+	// Deterministic entropy pre-scan, injected as trusted ground truth.
+	// Injection-proof: computed from bytes.
+	prompt = strings.ReplaceAll(prompt, "{STATIC_PRESCAN}", scanner.ScanWithOptions(prescanInput(pkgInfo), c.scanOptions(pkgInfo)).AgentBlock())
+
+	budget := maxSize - (len(prompt) - len("{PKGBUILD}")*strings.Count(prompt, "{PKGBUILD}"))
+	if budget < 1 {
+		budget = 1
+	}
+	_, limited := limitPKGBUILD(pkgInfo.PKGBUILD, c.maxPKGBUILDLines())
+	pkgbuild, omitted := fitPKGBUILD(pkgInfo.PKGBUILD, c.maxPKGBUILDLines(), budget)
+	if omitted > limited {
+		cut = append(cut, fmt.Sprintf("%d PKGBUILD lines", omitted-limited))
+	}
+	if omitted > 0 && c.verbose {
+		fmt.Fprintf(os.Stderr, "Omitting %d PKGBUILD lines from the prompt (context-lines limit or the %d-byte analysis size)\n", omitted, maxSize)
+	}
+	prompt = strings.ReplaceAll(prompt, "{PKGBUILD}", pkgbuild)
+
+	return prompt, omitted, cut
+}
+
+// formatSources lists source entries one per line for the prompt.
+func formatSources(sources []string) string {
+	if len(sources) == 0 {
+		return "[No source entries found]"
+	}
+	return strings.Join(sources, "\n")
+}
+
+// formatGitLog lists recent commits one per line for the prompt.
+func formatGitLog(commits []types.GitCommit) string {
+	if len(commits) == 0 {
+		return "[Git history not collected]"
+	}
+	lines := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		lines = append(lines, fmt.Sprintf("%s  %s  %s", commit.Date.Format("2006-01-02"), commit.Author, commit.Subject))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prescanInput concatenates the PKGBUILD plus any install script and helper
+// files, so a payload hidden in an .install hook is surfaced too. Files are
+// appended in name order so line numbers are stable across runs, and the
+// install script is not scanned twice when it also appears as a helper file.
+func prescanInput(pkgInfo types.PackageInfo) string {
 	var scanInput strings.Builder
 	scanInput.WriteString(pkgInfo.PKGBUILD)
 	if pkgInfo.InstallScript != "" {
 		scanInput.WriteString("\n")
 		scanInput.WriteString(pkgInfo.InstallScript)
 	}
-	for _, content := range pkgInfo.AdditionalFiles {
+	names := make([]string, 0, len(pkgInfo.AdditionalFiles))
+	for name := range pkgInfo.AdditionalFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content := pkgInfo.AdditionalFiles[name]
+		if content == pkgInfo.InstallScript {
+			continue
+		}
 		scanInput.WriteString("\n")
 		scanInput.WriteString(content)
 	}
-	prompt = strings.ReplaceAll(prompt, "{STATIC_PRESCAN}", scanner.Scan(scanInput.String()).AgentBlock())
-
-	return prompt
+	return scanInput.String()
 }
 
 // getPromptTemplate returns the security analysis prompt template from config
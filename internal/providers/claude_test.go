@@ -1,8 +1,13 @@
 package providers
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aaronsb/yay-friend/internal/types"
 )
@@ -22,6 +27,76 @@ func TestBuildPromptInjectsPrescan(t *testing.T) {
 	}
 }
 
+func TestBuildPromptFitsMaxAnalysisSize(t *testing.T) {
+	c := NewClaudeProvider()
+	maxSize := c.GetCapabilities().MaxAnalysisSize
+
+	var body strings.Builder
+	body.WriteString("pkgname=huge\npkgver=1\n")
+	for body.Len() < 2*maxSize {
+		body.WriteString("sha256sums+=('0000000000000000000000000000000000000000000000000000000000000000')\n")
+	}
+	body.WriteString("package() {\n  install -Dm755 huge \"$pkgdir/usr/bin/huge\"\n}\n")
+
+	prompt, omitted, cut := c.buildPrompt(types.PackageInfo{Name: "huge", PKGBUILD: body.String()})
+	if len(prompt) > maxSize || omitted == 0 {
+		t.Errorf("prompt is %d bytes with %d lines omitted, want at most %d", len(prompt), omitted, maxSize)
+	}
+	if len(cut) != 1 || !strings.HasSuffix(cut[0], "PKGBUILD lines") {
+		t.Errorf("cut = %q, want the PKGBUILD lines", cut)
+	}
+	if !strings.Contains(prompt, `install -Dm755 huge`) {
+		t.Error("size trimming dropped package() from the prompt")
+	}
+
+	small := types.PackageInfo{Name: "small", PKGBUILD: "pkgname=small\nbuild() {\n  make\n}\n"}
+	if prompt, omitted, cut := c.buildPrompt(small); omitted != 0 || cut != nil || !strings.Contains(prompt, small.PKGBUILD) {
+		t.Error("a PKGBUILD within the budget was not sent whole")
+	}
+
+	// A padded install script is trimmed to its share instead of squeezing
+	// the PKGBUILD out of the prompt
+	padded := small
+	padded.InstallScript = "post_install() {\n  true\n}\n" + strings.Repeat("# padding\n", maxSize/5)
+	prompt, omitted, cut = c.buildPrompt(padded)
+	if len(prompt) > maxSize {
+		t.Errorf("prompt with a padded install script is %d bytes, want at most %d", len(prompt), maxSize)
+	}
+	if omitted != 0 || !strings.Contains(prompt, small.PKGBUILD) {
+		t.Error("a padded install script pushed the PKGBUILD out of the prompt")
+	}
+	if len(cut) != 1 || cut[0] != "the install script" {
+		t.Errorf("cut = %q, want the install script", cut)
+	}
+}
+
+func TestBuildPromptContextLinesIsNotACut(t *testing.T) {
+	c := NewClaudeProvider()
+	cfg := &types.Config{}
+	cfg.Prompts.MaxPKGBUILDLines = 2
+	c.SetConfig(cfg)
+	_, omitted, cut := c.buildPrompt(types.PackageInfo{Name: "x", PKGBUILD: "pkgname=x\npkgver=1\npkgrel=1\nbuild() {\n  make\n}\n"})
+	if omitted == 0 || cut != nil {
+		t.Errorf("omitted = %d, cut = %q; the context-lines limit should omit lines without a cut", omitted, cut)
+	}
+}
+
+func TestBuildPromptNeedsNoAuthentication(t *testing.T) {
+	var builder PromptBuilder = NewClaudeProvider()
+	pkg := types.PackageInfo{
+		Name:     "hello",
+		License:  []string{"MIT"},
+		Keywords: []string{"greeting"},
+		PKGBUILD: "pkgname=hello\nsource=('x.tar.gz')",
+	}
+	prompt := builder.BuildPrompt(pkg)
+	for _, want := range []string{"pkgname=hello", "License: MIT | Keywords: greeting"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
 func TestBuildPromptCleanPackageNoFlag(t *testing.T) {
 	c := NewClaudeProvider()
 	pkg := types.PackageInfo{
@@ -106,3 +181,122 @@ func TestExtractClaudeResult(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildPromptListsSources(t *testing.T) {
+	c := NewClaudeProvider()
+	pkg := types.PackageInfo{
+		Name:     "x",
+		PKGBUILD: "source=('https://example.com/x.tar.gz')",
+		Sources:  []string{"https://example.com/x.tar.gz", "local.patch"},
+	}
+	prompt := c.buildSimpleSecurityPrompt(pkg)
+	if !strings.Contains(prompt, "<sources>\nhttps://example.com/x.tar.gz\nlocal.patch\n</sources>") {
+		t.Error("prompt does not list the package's sources in the sources block")
+	}
+}
+
+func TestParseAnalysisResponseWithoutJSON(t *testing.T) {
+	c := NewClaudeProvider()
+	pkg := types.PackageInfo{Name: "x"}
+	cases := []struct {
+		name     string
+		response string
+		want     error
+	}{
+		{"empty", "", ErrEmptyResponse},
+		{"whitespace", " \n\t\n ", ErrEmptyResponse},
+		{"prose", "I can't analyze this package right now.", ErrNoJSON},
+		{"misordered braces", "} nothing here {", ErrNoJSON},
+	}
+	for _, tc := range cases {
+		analysis, err := c.parseAnalysisResponse(tc.response, pkg)
+		if analysis != nil || !errors.Is(err, tc.want) {
+			t.Errorf("%s: got (%v, %v), want error %v", tc.name, analysis, err, tc.want)
+		}
+	}
+}
+
+func TestBuildPromptIncludesGitHistory(t *testing.T) {
+	c := NewClaudeProvider()
+	pkg := types.PackageInfo{Name: "x", PKGBUILD: "pkgname=x"}
+	if !strings.Contains(c.buildSimpleSecurityPrompt(pkg), "<git_history>\n[Git history not collected]\n</git_history>") {
+		t.Error("prompt without commits should say the history was not collected")
+	}
+
+	pkg.RecentCommits = []types.GitCommit{{Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Author: "Alice", Subject: "Update to 1.2"}}
+	if !strings.Contains(c.buildSimpleSecurityPrompt(pkg), "2024-03-01  Alice  Update to 1.2") {
+		t.Error("prompt does not list the recent commit")
+	}
+}
+
+func TestAuthenticateReusesRecentResult(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho x >> " + calls + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "claude"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	countCalls := func() int {
+		data, _ := os.ReadFile(calls)
+		return strings.Count(string(data), "x")
+	}
+
+	c := NewClaudeProvider()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := c.Authenticate(ctx); err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+	}
+	if n := countCalls(); n != 1 {
+		t.Errorf("claude --version ran %d times within the TTL, want 1", n)
+	}
+
+	c.authenticatedAt = time.Now().Add(-authTTL)
+	if err := c.Authenticate(ctx); err != nil {
+		t.Fatalf("Authenticate after TTL: %v", err)
+	}
+	if n := countCalls(); n != 2 {
+		t.Errorf("claude --version ran %d times after the TTL expired, want 2", n)
+	}
+}
+
+func TestAnalysisRecordsTruncatedPrompt(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+[ "$1" = "--version" ] && exit 0
+cat >/dev/null
+echo '{"type":"result","subtype":"success","is_error":false,"result":"{\\"overall_entropy\\":\\"LOW\\",\\"summary\\":\\"ok\\",\\"findings\\":[]}"}'
+`
+	if err := os.WriteFile(filepath.Join(dir, "claude"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	pkgbuild := "pkgname=x\npkgver=1\n" + strings.Repeat("# filler\n", 20) + "build() {\n  make\n}"
+	analyze := func(maxLines int) *types.SecurityAnalysis {
+		c := NewClaudeProvider()
+		cfg := &types.Config{}
+		cfg.Prompts.MaxPKGBUILDLines = maxLines
+		c.SetConfig(cfg)
+		if err := c.Authenticate(context.Background()); err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+		analysis, err := c.AnalyzePKGBUILDWithOptions(context.Background(), types.PackageInfo{Name: "x", PKGBUILD: pkgbuild}, types.AnalysisOptions{NoSpinner: true})
+		if err != nil {
+			t.Fatalf("analysis with max %d lines: %v", maxLines, err)
+		}
+		return analysis
+	}
+
+	if full := analyze(0); full.Truncated() || full.PKGBUILDLines != 0 {
+		t.Errorf("unlimited analysis = %d of %d lines omitted, want not truncated", full.OmittedLines, full.PKGBUILDLines)
+	}
+	partial := analyze(10)
+	if !partial.Truncated() || partial.PKGBUILDLines != 25 || partial.OmittedLines != 15 {
+		t.Errorf("limited analysis = %d of %d lines omitted, want 15 of 25", partial.OmittedLines, partial.PKGBUILDLines)
+	}
+}
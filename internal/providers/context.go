@@ -0,0 +1,141 @@
+package providers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// funcHeadRe matches the opening of a shell function definition, e.g.
+// "package() {" or "package_foo()".
+var funcHeadRe = regexp.MustCompile(`^\s*(?:function\s+)?[A-Za-z_][\w-]*\s*\(\s*\)`)
+
+// limitPKGBUILD trims a PKGBUILD to at most maxLines lines for the prompt and
+// reports how many lines were left out. Function bodies (prepare, build,
+// package, ...) are where a PKGBUILD actually does things, so they are kept
+// first; whatever budget remains goes to top-level lines such as metadata and
+// source arrays, in file order. Each omitted run is replaced by a marker line
+// so the model knows content is missing. A maxLines of 0 means unlimited.
+//
+// Only the prompt is trimmed: the deterministic pre-scan always sees the full
+// file.
+func limitPKGBUILD(pkgbuild string, maxLines int) (string, int) {
+	lines := strings.Split(pkgbuild, "\n")
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return pkgbuild, 0
+	}
+
+	inFunction := functionLines(lines)
+	keep := make([]bool, len(lines))
+	budget := maxLines
+	for _, wantFunction := range []bool{true, false} {
+		for i := range lines {
+			if budget == 0 {
+				break
+			}
+			if inFunction[i] == wantFunction && !keep[i] {
+				keep[i] = true
+				budget--
+			}
+		}
+	}
+
+	var out []string
+	omitted, run := 0, 0
+	flush := func() {
+		if run > 0 {
+			out = append(out, fmt.Sprintf("# [yay-friend: %d lines omitted by the context-lines limit]", run))
+			omitted += run
+			run = 0
+		}
+	}
+	for i, line := range lines {
+		if !keep[i] {
+			run++
+			continue
+		}
+		flush()
+		out = append(out, line)
+	}
+	flush()
+
+	return strings.Join(out, "\n"), omitted
+}
+
+// fitPKGBUILD is limitPKGBUILD with a size budget as well: after the line
+// limit, the PKGBUILD is trimmed further, by the same priorities, to the
+// most lines that take at most maxBytes. A maxBytes of 0 or less means no
+// budget. At least one line is always kept, even if it alone is over.
+func fitPKGBUILD(pkgbuild string, maxLines, maxBytes int) (string, int) {
+	limited, omitted := limitPKGBUILD(pkgbuild, maxLines)
+	if maxBytes <= 0 || len(limited) <= maxBytes {
+		return limited, omitted
+	}
+
+	// The trimmed size grows with the line count, so search for the largest
+	// count that fits.
+	kept := len(strings.Split(pkgbuild, "\n")) - omitted
+	best, lo, hi := 1, 1, kept-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		if trimmed, _ := limitPKGBUILD(pkgbuild, mid); len(trimmed) <= maxBytes {
+			best, lo = mid, mid+1
+		} else {
+			hi = mid - 1
+		}
+	}
+	return limitPKGBUILD(pkgbuild, best)
+}
+
+// Each supporting section of the prompt may take at most 1/share of the
+// provider's MaxAnalysisSize, so that padding one can't crowd the PKGBUILD,
+// which goes in last, out of the prompt.
+const (
+	installScriptShare   = 5
+	additionalFilesShare = 5
+	gitLogShare          = 20
+)
+
+// trimSection cuts text to at most maxBytes, at a line boundary where there
+// is one, and ends it with a marker saying how much was left out. It reports
+// whether anything was cut.
+func trimSection(text string, maxBytes int) (string, bool) {
+	if len(text) <= maxBytes {
+		return text, false
+	}
+	marker := fmt.Sprintf("\n# [yay-friend: %d bytes omitted to fit the analysis size]", len(text))
+	keep := maxBytes - len(marker)
+	if keep < 0 {
+		keep = 0
+	}
+	if i := strings.LastIndex(text[:keep], "\n"); i >= 0 {
+		keep = i
+	}
+	marker = fmt.Sprintf("\n# [yay-friend: %d bytes omitted to fit the analysis size]", len(text)-keep)
+	return text[:keep] + marker, true
+}
+
+// functionLines marks the lines that belong to a shell function, from its
+// head through the line that closes its body. Brace counting is approximate
+// (a brace inside a quoted string counts), which is fine for prioritizing.
+func functionLines(lines []string) []bool {
+	marked := make([]bool, len(lines))
+	depth, inside, opened := 0, false, false
+	for i, line := range lines {
+		if !inside {
+			if !funcHeadRe.MatchString(line) {
+				continue
+			}
+			inside, opened, depth = true, false, 0
+		}
+		marked[i] = true
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if strings.Contains(line, "{") {
+			opened = true
+		}
+		if opened && depth <= 0 {
+			inside = false
+		}
+	}
+	return marked
+}
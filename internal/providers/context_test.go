@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+const longPKGBUILD = `pkgname=demo
+pkgver=1.0
+pkgrel=1
+pkgdesc="demo"
+arch=('x86_64')
+license=('MIT')
+url="https://example.com"
+source=("https://example.com/demo.tar.gz")
+sha256sums=('SKIP')
+
+build() {
+	make
+}
+
+package() {
+	install -Dm755 demo "$pkgdir/usr/bin/demo"
+}`
+
+func TestLimitPKGBUILDUnlimited(t *testing.T) {
+	for _, maxLines := range []int{0, 100} {
+		got, omitted := limitPKGBUILD(longPKGBUILD, maxLines)
+		if got != longPKGBUILD || omitted != 0 {
+			t.Errorf("maxLines=%d: expected PKGBUILD unchanged, omitted %d", maxLines, omitted)
+		}
+	}
+}
+
+func TestLimitPKGBUILDKeepsFunctions(t *testing.T) {
+	got, omitted := limitPKGBUILD(longPKGBUILD, 8)
+	if omitted != 9 {
+		t.Errorf("omitted = %d, want 9", omitted)
+	}
+	for _, want := range []string{"build() {", "make", "package() {", `install -Dm755 demo "$pkgdir/usr/bin/demo"`, "pkgname=demo", "pkgver=1.0"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("trimmed PKGBUILD lost %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "sha256sums") {
+		t.Errorf("expected trailing metadata to be omitted:\n%s", got)
+	}
+	if !strings.Contains(got, "lines omitted by the context-lines limit") {
+		t.Errorf("expected an omission marker:\n%s", got)
+	}
+}
+
+func TestLimitPKGBUILDFunctionsExceedBudget(t *testing.T) {
+	got, omitted := limitPKGBUILD(longPKGBUILD, 3)
+	if omitted != 14 {
+		t.Errorf("omitted = %d, want 14", omitted)
+	}
+	if !strings.HasPrefix(got, "# [yay-friend:") || !strings.Contains(got, "build() {") {
+		t.Errorf("expected only the leading function lines to survive:\n%s", got)
+	}
+}
+
+func TestFitPKGBUILDToSize(t *testing.T) {
+	if got, omitted := fitPKGBUILD(longPKGBUILD, 0, len(longPKGBUILD)); got != longPKGBUILD || omitted != 0 {
+		t.Errorf("PKGBUILD within budget was trimmed, omitted %d", omitted)
+	}
+
+	got, omitted := fitPKGBUILD(longPKGBUILD, 0, 200)
+	if len(got) > 200 || omitted == 0 {
+		t.Errorf("fitted PKGBUILD is %d bytes with %d lines omitted, want at most 200 bytes", len(got), omitted)
+	}
+	if !strings.Contains(got, "build() {") {
+		t.Errorf("size trimming dropped function lines before metadata:\n%s", got)
+	}
+
+	byLines, linesOmitted := fitPKGBUILD(longPKGBUILD, 8, 10000)
+	if want, wantOmitted := limitPKGBUILD(longPKGBUILD, 8); byLines != want || linesOmitted != wantOmitted {
+		t.Error("a generous size budget changed the line-limited PKGBUILD")
+	}
+}
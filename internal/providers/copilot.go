@@ -25,7 +25,7 @@ func (c *CopilotProvider) Name() string {
 // Authenticate checks if GitHub Copilot CLI is available and authenticated
 func (c *CopilotProvider) Authenticate(ctx context.Context) error {
 	// TODO: Implement Copilot authentication
-	return fmt.Errorf("copilot provider not implemented yet")
+	return fmt.Errorf("%w: copilot provider not implemented yet", ErrProviderAuth)
 }
 
 // IsAuthenticated returns whether the provider is authenticated
@@ -35,8 +35,13 @@ func (c *CopilotProvider) IsAuthenticated() bool {
 
 // AnalyzePKGBUILD analyzes a PKGBUILD using GitHub Copilot CLI
 func (c *CopilotProvider) AnalyzePKGBUILD(ctx context.Context, pkgInfo types.PackageInfo) (*types.SecurityAnalysis, error) {
+	return c.AnalyzePKGBUILDWithOptions(ctx, pkgInfo, types.AnalysisOptions{})
+}
+
+// AnalyzePKGBUILDWithOptions analyzes a PKGBUILD with additional options
+func (c *CopilotProvider) AnalyzePKGBUILDWithOptions(ctx context.Context, pkgInfo types.PackageInfo, opts types.AnalysisOptions) (*types.SecurityAnalysis, error) {
 	// TODO: Implement Copilot analysis
-	return nil, fmt.Errorf("copilot provider not implemented yet")
+	return nil, fmt.Errorf("%w: copilot provider not implemented yet", ErrProviderAuth)
 }
 
 // GetCapabilities returns the provider capabilities
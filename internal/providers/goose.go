@@ -25,7 +25,7 @@ func (g *GooseProvider) Name() string {
 // Authenticate checks if Goose AI is available and authenticated
 func (g *GooseProvider) Authenticate(ctx context.Context) error {
 	// TODO: Implement Goose authentication
-	return fmt.Errorf("goose provider not implemented yet")
+	return fmt.Errorf("%w: goose provider not implemented yet", ErrProviderAuth)
 }
 
 // IsAuthenticated returns whether the provider is authenticated
@@ -35,8 +35,13 @@ func (g *GooseProvider) IsAuthenticated() bool {
 
 // AnalyzePKGBUILD analyzes a PKGBUILD using Goose AI
 func (g *GooseProvider) AnalyzePKGBUILD(ctx context.Context, pkgInfo types.PackageInfo) (*types.SecurityAnalysis, error) {
+	return g.AnalyzePKGBUILDWithOptions(ctx, pkgInfo, types.AnalysisOptions{})
+}
+
+// AnalyzePKGBUILDWithOptions analyzes a PKGBUILD with additional options
+func (g *GooseProvider) AnalyzePKGBUILDWithOptions(ctx context.Context, pkgInfo types.PackageInfo, opts types.AnalysisOptions) (*types.SecurityAnalysis, error) {
 	// TODO: Implement Goose analysis
-	return nil, fmt.Errorf("goose provider not implemented yet")
+	return nil, fmt.Errorf("%w: goose provider not implemented yet", ErrProviderAuth)
 }
 
 // GetCapabilities returns the provider capabilities
@@ -2,11 +2,27 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aaronsb/yay-friend/internal/types"
 )
 
+// Errors that tell provider failures apart, for errors.Is. ErrProviderAuth
+// means the provider can't be used at all (missing, logged out, or not
+// implemented); ErrProviderResponse means it ran but produced no usable
+// analysis.
+var (
+	ErrProviderAuth     = errors.New("provider unavailable")
+	ErrProviderResponse = errors.New("provider failed")
+)
+
+// PromptBuilder is implemented by providers that send a text prompt, so the
+// prompt can be shown without running the analysis (analyze --prompt-only).
+type PromptBuilder interface {
+	BuildPrompt(pkgInfo types.PackageInfo) string
+}
+
 // ProviderRegistry manages all available AI providers
 type ProviderRegistry struct {
 	providers       map[string]types.AIProvider
@@ -0,0 +1,23 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestStubProvidersNotImplementedWithOptions(t *testing.T) {
+	stubs := []types.AIProvider{NewQwenProvider(), NewCopilotProvider(), NewGooseProvider()}
+	for _, p := range stubs {
+		_, err := p.AnalyzePKGBUILDWithOptions(context.Background(), types.PackageInfo{Name: "x"}, types.AnalysisOptions{NoSpinner: true})
+		if err == nil || !strings.Contains(err.Error(), "not implemented") {
+			t.Errorf("%s: err = %v, want not implemented", p.Name(), err)
+		}
+		if !errors.Is(err, ErrProviderAuth) {
+			t.Errorf("%s: err = %v, want it to wrap ErrProviderAuth", p.Name(), err)
+		}
+	}
+}
@@ -25,7 +25,7 @@ func (q *QwenProvider) Name() string {
 // Authenticate checks if Qwen Code is available and authenticated
 func (q *QwenProvider) Authenticate(ctx context.Context) error {
 	// TODO: Implement Qwen authentication
-	return fmt.Errorf("qwen provider not implemented yet")
+	return fmt.Errorf("%w: qwen provider not implemented yet", ErrProviderAuth)
 }
 
 // IsAuthenticated returns whether the provider is authenticated
@@ -35,8 +35,13 @@ func (q *QwenProvider) IsAuthenticated() bool {
 
 // AnalyzePKGBUILD analyzes a PKGBUILD using Qwen Code
 func (q *QwenProvider) AnalyzePKGBUILD(ctx context.Context, pkgInfo types.PackageInfo) (*types.SecurityAnalysis, error) {
+	return q.AnalyzePKGBUILDWithOptions(ctx, pkgInfo, types.AnalysisOptions{})
+}
+
+// AnalyzePKGBUILDWithOptions analyzes a PKGBUILD with additional options
+func (q *QwenProvider) AnalyzePKGBUILDWithOptions(ctx context.Context, pkgInfo types.PackageInfo, opts types.AnalysisOptions) (*types.SecurityAnalysis, error) {
 	// TODO: Implement Qwen analysis
-	return nil, fmt.Errorf("qwen provider not implemented yet")
+	return nil, fmt.Errorf("%w: qwen provider not implemented yet", ErrProviderAuth)
 }
 
 // GetCapabilities returns the provider capabilities
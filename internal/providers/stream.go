@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// findingEntropyRe matches the per-finding "entropy" key of the response
+// format; the top-level "overall_entropy" doesn't match.
+var findingEntropyRe = regexp.MustCompile(`"entropy"\s*:`)
+
+// streamProgress follows a streamed response for the progress line: how much
+// of the response text has arrived and how many findings it already holds.
+// It only feeds the display; the complete document is still parsed once the
+// stream ends, exactly as for a one-shot call.
+type streamProgress struct {
+	text strings.Builder
+}
+
+// add appends a partial chunk of the response text.
+func (p *streamProgress) add(chunk string) {
+	p.text.WriteString(chunk)
+}
+
+// set replaces the text received so far with a complete message, which
+// supersedes any partial chunks of it.
+func (p *streamProgress) set(text string) {
+	p.text.Reset()
+	p.text.WriteString(text)
+}
+
+// findings counts the findings begun so far: entropy keys after the start of
+// the "findings" array.
+func (p *streamProgress) findings() int {
+	text := p.text.String()
+	start := strings.Index(text, `"findings"`)
+	if start < 0 {
+		return 0
+	}
+	return len(findingEntropyRe.FindAllStringIndex(text[start:], -1))
+}
+
+// describe renders the progress for phase: the phase alone until text
+// arrives, then with the characters and findings received.
+func (p *streamProgress) describe(phase string) string {
+	if p.text.Len() == 0 {
+		return phase
+	}
+	description := fmt.Sprintf("%s, %d chars", phase, p.text.Len())
+	if n := p.findings(); n == 1 {
+		description += ", 1 finding"
+	} else if n > 1 {
+		description += fmt.Sprintf(", %d findings", n)
+	}
+	return description
+}
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestStreamProgress(t *testing.T) {
+	var p streamProgress
+	if got := p.describe("starting"); got != "starting" {
+		t.Errorf("describe before any text = %q, want the phase alone", got)
+	}
+
+	chunks := []string{
+		`{"overall_entropy": "LOW", "summary": "ok", "findings": [`,
+		`{"type": "build_process", "entropy": "LOW"},`,
+		` {"type": "source_analysis", "entropy" : "MINIMAL"`,
+	}
+	want := []int{0, 1, 2}
+	for i, chunk := range chunks {
+		p.add(chunk)
+		if n := p.findings(); n != want[i] {
+			t.Errorf("after chunk %d: %d findings, want %d", i, n, want[i])
+		}
+	}
+	if got := p.describe("receiving"); !strings.HasPrefix(got, "receiving, ") || !strings.HasSuffix(got, ", 2 findings") {
+		t.Errorf("describe = %q, want the phase, size and 2 findings", got)
+	}
+
+	p.set(`{"findings": []}`)
+	if n := p.findings(); n != 0 {
+		t.Errorf("after set: %d findings, want 0", n)
+	}
+}
+
+func TestStreamingAssemblesPartialMessages(t *testing.T) {
+	dir := t.TempDir()
+	args := filepath.Join(dir, "args")
+	script := `#!/bin/sh
+[ "$1" = "--version" ] && exit 0
+echo "$@" > ` + args + `
+cat >/dev/null
+echo '{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"{\"overall_entropy\":"}}}'
+echo '{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"\"LOW\"}"}}}'
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"{\"overall_entropy\":\"LOW\"}"}]}}'
+echo '{"type":"result","subtype":"success","is_error":false,"result":"{\"overall_entropy\":\"LOW\"}"}'
+`
+	if err := os.WriteFile(filepath.Join(dir, "claude"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	c := NewClaudeProvider()
+	cfg := &types.Config{}
+	cfg.Claude.StreamPartial = true
+	c.SetConfig(cfg)
+	if err := c.Authenticate(context.Background()); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	result, err := c.runClaudeStreaming(context.Background(), "prompt", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != `{"overall_entropy":"LOW"}` {
+		t.Errorf("result = %q, want the result event's document", result)
+	}
+	data, _ := os.ReadFile(args)
+	if !strings.Contains(string(data), "--include-partial-messages") {
+		t.Errorf("claude ran with %q, want --include-partial-messages", strings.TrimSpace(string(data)))
+	}
+}
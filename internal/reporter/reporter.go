@@ -5,13 +5,17 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
 )
 
 // getDataDir returns the XDG-compliant data directory for reports
@@ -66,6 +70,54 @@ type ReporterConfig struct {
 	AnonymousID  string         `json:"anonymous_id"`  // Persistent anonymous identifier
 	AutoReport   bool           `json:"auto_report"`   // Auto-report CRITICAL findings
 	SharePKGBUILD bool          `json:"share_pkgbuild"` // Whether to include PKGBUILD content
+	AllowedHosts []string       `json:"allowed_hosts,omitempty"` // If set, remote endpoints must match one ("host" or "host/path-prefix")
+}
+
+// ErrEndpointRejected marks a remote target that failed endpoint validation;
+// nothing is sent to it.
+var ErrEndpointRejected = errors.New("report endpoint rejected")
+
+// ValidateEndpoint checks that a remote report endpoint is an https URL and,
+// when allowedHosts is non-empty, that it matches one of its entries. An entry
+// is a host name, optionally followed by a path prefix that the endpoint's
+// path must start with on a segment boundary.
+func ValidateEndpoint(endpoint string, allowedHosts []string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %q is not a valid URL: %v", ErrEndpointRejected, endpoint, err)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%w: %q must use https", ErrEndpointRejected, endpoint)
+	}
+	if u.Hostname() == "" || u.User != nil {
+		return fmt.Errorf("%w: %q must name a host and carry no credentials", ErrEndpointRejected, endpoint)
+	}
+	if len(allowedHosts) == 0 {
+		return nil
+	}
+
+	// Match on the cleaned path, so /api/../elsewhere can't pass as /api
+	endpointPath := u.Path
+	if endpointPath != "" {
+		endpointPath = path.Clean(endpointPath)
+	}
+	for _, segment := range strings.Split(endpointPath, "/") {
+		if segment == ".." {
+			return fmt.Errorf("%w: %q must not climb out of its path with ..", ErrEndpointRejected, endpoint)
+		}
+	}
+
+	for _, allowed := range allowedHosts {
+		host, prefix, _ := strings.Cut(allowed, "/")
+		if !strings.EqualFold(host, u.Hostname()) {
+			continue
+		}
+		prefix = "/" + strings.Trim(prefix, "/")
+		if prefix == "/" || endpointPath == prefix || strings.HasPrefix(endpointPath, prefix+"/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not in allowed_hosts", ErrEndpointRejected, u.Host+endpointPath)
 }
 
 // NewReporter creates a new reporter instance
@@ -135,20 +187,7 @@ func (r *Reporter) saveConfig() error {
 func (r *Reporter) ReportMaliciousPackage(packageName, packageVersion, maintainer string, 
 	analysis *types.SecurityAnalysis, pkgbuildContent, reason string, userConsent bool) error {
 	
-	report := MaliciousPackageReport{
-		ID:              generateReportID(),
-		Timestamp:       time.Now(),
-		PackageName:     packageName,
-		PackageVersion:  packageVersion,
-		Maintainer:      maintainer,
-		SecurityLevel:   analysis.OverallLevel,
-		Findings:        analysis.Findings,
-		PKGBUILDHash:    fmt.Sprintf("%x", sha256.Sum256([]byte(pkgbuildContent))),
-		Provider:        analysis.Provider,
-		ReporterID:      r.config.AnonymousID,
-		UserConsent:     userConsent,
-		ReportReason:    reason,
-	}
+	report := r.newReport(packageName, packageVersion, maintainer, analysis, pkgbuildContent, reason, userConsent)
 
 	// Include PKGBUILD content if user consented and config allows
 	if userConsent && r.config.SharePKGBUILD {
@@ -163,7 +202,9 @@ func (r *Reporter) ReportMaliciousPackage(packageName, packageVersion, maintaine
 	// Submit to enabled remote targets
 	for _, target := range r.config.Targets {
 		if target.Enabled && target.Endpoint != "local" {
-			if err := r.submitReport(report, target); err != nil {
+			if err := r.submitReport(report, target); errors.Is(err, ErrEndpointRejected) {
+				fmt.Printf("Warning: Not sending report to %s: %v\n", target.Name, err)
+			} else if err != nil {
 				fmt.Printf("Warning: Failed to submit report to %s: %v\n", target.Name, err)
 			}
 		}
@@ -172,6 +213,80 @@ func (r *Reporter) ReportMaliciousPackage(packageName, packageVersion, maintaine
 	return nil
 }
 
+// ResolveTarget finds a report target for a one-off report, without editing
+// the reporter config: a configured target by name (any case, enabled or
+// not), "local" for the local archive only, or an endpoint URL. Remote
+// endpoints must pass ValidateEndpoint against the configured allowed_hosts.
+func (r *Reporter) ResolveTarget(nameOrURL string) (ReportTarget, error) {
+	if nameOrURL == "local" {
+		return ReportTarget{Name: "Local Archive", Endpoint: "local", Enabled: true}, nil
+	}
+	var names []string
+	for _, target := range r.config.Targets {
+		if strings.EqualFold(target.Name, nameOrURL) {
+			if target.Endpoint != "local" {
+				if err := ValidateEndpoint(target.Endpoint, r.config.AllowedHosts); err != nil {
+					return ReportTarget{}, err
+				}
+			}
+			target.Enabled = true
+			return target, nil
+		}
+		names = append(names, target.Name)
+	}
+
+	if !strings.Contains(nameOrURL, "://") {
+		return ReportTarget{}, fmt.Errorf("unknown report target %q: give an https URL or one of: %s, local", nameOrURL, strings.Join(names, ", "))
+	}
+	if err := ValidateEndpoint(nameOrURL, r.config.AllowedHosts); err != nil {
+		return ReportTarget{}, err
+	}
+	u, _ := url.Parse(nameOrURL)
+	return ReportTarget{Name: u.Host, Endpoint: nameOrURL, Enabled: true}, nil
+}
+
+// ReportTo files a report to target alone, whatever targets the config
+// enables. The report is always saved locally first. With includePKGBUILD
+// the PKGBUILD itself is attached: the caller asked for this one report, so
+// share_pkgbuild doesn't apply.
+func (r *Reporter) ReportTo(target ReportTarget, packageName, packageVersion, maintainer string,
+	analysis *types.SecurityAnalysis, pkgbuildContent, reason string, includePKGBUILD bool) error {
+	report := r.newReport(packageName, packageVersion, maintainer, analysis, pkgbuildContent, reason, includePKGBUILD)
+	if includePKGBUILD {
+		report.PKGBUILDContent = pkgbuildContent
+	}
+
+	if err := r.saveLocalReport(report); err != nil {
+		return fmt.Errorf("failed to save local report: %w", err)
+	}
+	if target.Endpoint == "local" {
+		return nil
+	}
+	if err := r.submitReport(report, target); err != nil {
+		return fmt.Errorf("failed to submit report to %s: %w", target.Name, err)
+	}
+	return nil
+}
+
+// newReport builds a report of analysis, without the PKGBUILD content.
+func (r *Reporter) newReport(packageName, packageVersion, maintainer string,
+	analysis *types.SecurityAnalysis, pkgbuildContent, reason string, userConsent bool) MaliciousPackageReport {
+	return MaliciousPackageReport{
+		ID:              generateReportID(),
+		Timestamp:       time.Now(),
+		PackageName:     packageName,
+		PackageVersion:  packageVersion,
+		Maintainer:      maintainer,
+		SecurityLevel:   analysis.OverallLevel,
+		Findings:        analysis.Findings,
+		PKGBUILDHash:    fmt.Sprintf("%x", sha256.Sum256([]byte(pkgbuildContent))),
+		Provider:        analysis.Provider,
+		ReporterID:      r.config.AnonymousID,
+		UserConsent:     userConsent,
+		ReportReason:    reason,
+	}
+}
+
 // saveLocalReport saves a report to the local reports directory
 func (r *Reporter) saveLocalReport(report MaliciousPackageReport) error {
 	filename := fmt.Sprintf("report_%s_%s_%s.json",
@@ -191,8 +306,15 @@ func (r *Reporter) saveLocalReport(report MaliciousPackageReport) error {
 
 // submitReport submits a report to a remote target
 func (r *Reporter) submitReport(report MaliciousPackageReport, target ReportTarget) error {
+	// Checked here, before anything about the report leaves the process
+	if target.Endpoint != "local" {
+		if err := ValidateEndpoint(target.Endpoint, r.config.AllowedHosts); err != nil {
+			return err
+		}
+	}
+
 	// For now, this is a stub implementation
-	fmt.Printf("📡 [STUB] Would submit report for %s to %s\n", report.PackageName, target.Name)
+	fmt.Printf("%s [STUB] Would submit report for %s to %s\n", ui.Send, report.PackageName, target.Name)
 	fmt.Printf("   Endpoint: %s\n", target.Endpoint)
 	fmt.Printf("   Security Level: %s\n", report.SecurityLevel.String())
 	fmt.Printf("   Findings: %d\n", len(report.Findings))
@@ -219,7 +341,7 @@ func (r *Reporter) submitHTTPReport(report MaliciousPackageReport, target Report
 	}
 
 	// Simulate HTTP request (stub)
-	fmt.Printf("🚫 [STUB] HTTP submission not implemented\n")
+	fmt.Printf("%s [STUB] HTTP submission not implemented\n", ui.Blocked)
 	fmt.Printf("   Would POST %d bytes to %s\n", len(jsonData), target.Endpoint)
 	
 	// In real implementation:
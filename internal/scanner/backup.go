@@ -0,0 +1,155 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindSensitiveBackup: backup=() names a security-sensitive system file. The
+// array lists files the package owns and manages, so a claim on sudoers,
+// the account databases, or PAM means the package ships its own version of
+// them.
+const KindSensitiveBackup Kind = "sensitive_backup"
+
+// backupStartRe matches the opening of backup=(); the body is read by
+// quotedArrayValues.
+var backupStartRe = regexp.MustCompile(`(?m)^\s*backup\+?=\(`)
+
+// sensitiveBackupFiles are files no ordinary package should own.
+var sensitiveBackupFiles = map[string]bool{
+	"etc/sudoers": true, "etc/doas.conf": true,
+	"etc/passwd": true, "etc/shadow": true, "etc/group": true, "etc/gshadow": true,
+	"etc/login.defs": true, "etc/nsswitch.conf": true,
+	"etc/ld.so.preload": true, "etc/ld.so.conf": true,
+	"etc/hosts": true, "etc/resolv.conf": true,
+	"etc/crontab": true, "etc/environment": true, "etc/profile": true,
+	"etc/ssh/sshd_config": true, "etc/ssh/ssh_config": true,
+}
+
+// sensitiveBackupDirs hold authentication, privilege, and scheduling
+// configuration; any file under them counts.
+var sensitiveBackupDirs = []string{
+	"etc/sudoers.d/", "etc/pam.d/", "etc/security/", "etc/polkit-1/",
+	"etc/ld.so.conf.d/", "etc/cron.d/", "etc/profile.d/", "etc/ssh/sshd_config.d/",
+}
+
+// packageScopedDirs are sensitive directories where a package commonly
+// ships its own file: a PAM service, a profile snippet, or a limits file
+// named after the package. Such a file is the package's own, not a claim on
+// the system's.
+var packageScopedDirs = []string{"etc/pam.d/", "etc/profile.d/", "etc/security/"}
+
+// systemScopedFiles are the PAM and security files of the base system
+// (pam, shadow, util-linux, sudo, …). A package named su, login or system
+// still doesn't own them.
+var systemScopedFiles = map[string]bool{
+	"system-auth": true, "system-login": true, "system-local-login": true,
+	"system-remote-login": true, "system-services": true, "other": true,
+	"login": true, "su": true, "su-l": true, "sudo": true, "sudo-i": true,
+	"passwd": true, "chpasswd": true, "chfn": true, "chsh": true,
+	"runuser": true, "runuser-l": true, "polkit-1": true, "sshd": true,
+	"access.conf": true, "limits.conf": true, "pam_env.conf": true,
+	"faillock.conf": true, "pwquality.conf": true, "group.conf": true,
+	"namespace.conf": true, "time.conf": true,
+}
+
+// pkgnameVarRe matches a reference to the package's own name in a path.
+var pkgnameVarRe = regexp.MustCompile(`\$\{?(?:pkgname|pkgbase|_pkgname)\}?`)
+
+func init() {
+	registerRule(sensitiveBackupRule, KindSensitiveBackup)
+}
+
+// sensitiveBackupRule flags backup=() entries that are security-sensitive
+// system files. Each is HIGH: the package takes over a file that decides who
+// can log in or run what as root. A file under packageScopedDirs named after
+// the package (pkgname or pkgbase) is exempt.
+func sensitiveBackupRule(lines []codeLine, opts *Options) []Finding {
+	text := make([]string, len(lines))
+	for i, cl := range lines {
+		text[i] = cl.text
+	}
+	names := packageNames(lines)
+
+	var findings []Finding
+	for _, entry := range ParseBackup(strings.Join(text, "\n")) {
+		path := strings.TrimPrefix(entry, "/")
+		if !isSensitiveBackup(path) || isOwnScopedFile(path, names) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Kind: KindSensitiveBackup, Line: lineContaining(lines, entry), Zone: "backup",
+			Token: truncate(entry, 60), Level: types.EntropyHigh,
+			Note: fmt.Sprintf("package claims the security-sensitive file /%s", path),
+		})
+	}
+	return findings
+}
+
+// packageNames returns the names the PKGBUILD gives itself in its top-level
+// pkgname= and pkgbase= assignments.
+func packageNames(lines []codeLine) []string {
+	var names []string
+	for _, cl := range lines {
+		if cl.zone == "toplevel" && !cl.inArray {
+			if m := pkgnameRe.FindStringSubmatch(cl.text); m != nil {
+				names = append(names, m[1])
+			}
+		}
+	}
+	return names
+}
+
+// isSensitiveBackup reports whether path, relative to /, is a sensitive file.
+func isSensitiveBackup(path string) bool {
+	if sensitiveBackupFiles[path] {
+		return true
+	}
+	for _, dir := range sensitiveBackupDirs {
+		if strings.HasPrefix(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOwnScopedFile reports whether path is a file under packageScopedDirs
+// named after the package: one of its names or $pkgname, alone or followed
+// by a . or - (sddm, sddm-greeter, sddm.sh). The system's own files in
+// systemScopedFiles never count, whatever the package is called.
+func isOwnScopedFile(path string, names []string) bool {
+	for _, dir := range packageScopedDirs {
+		file, ok := strings.CutPrefix(path, dir)
+		if !ok || systemScopedFiles[file] {
+			continue
+		}
+		if loc := pkgnameVarRe.FindStringIndex(file); loc != nil && loc[0] == 0 && namedBoundary(file[loc[1]:]) {
+			return true
+		}
+		for _, name := range names {
+			if rest, ok := strings.CutPrefix(file, name); ok && namedBoundary(rest) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namedBoundary reports whether rest, what follows a package name in a file
+// name, keeps the name whole: nothing, or a . or - suffix.
+func namedBoundary(rest string) bool {
+	return rest == "" || rest[0] == '.' || rest[0] == '-'
+}
+
+// ParseBackup returns every entry of the PKGBUILD's backup arrays as written
+// (paths relative to /, without a leading slash by convention).
+func ParseBackup(pkgbuild string) []string {
+	var entries []string
+	for _, loc := range backupStartRe.FindAllStringIndex(pkgbuild, -1) {
+		entries = append(entries, quotedArrayValues(pkgbuild[loc[1]:])...)
+	}
+	return entries
+}
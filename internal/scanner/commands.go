@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// KindSuspiciousCommand: a function body runs a command from the configured
+// scanner.suspicious_commands list.
+const KindSuspiciousCommand Kind = "suspicious_command"
+
+func init() {
+	registerRule(suspiciousCommandRule, KindSuspiciousCommand)
+}
+
+// commandRe matches command in command position: at the start of the line or
+// after a separator, subshell, or backtick, and followed by whitespace or the
+// end of the line. Multi-word commands tolerate any run of whitespace.
+func commandRe(command string) *regexp.Regexp {
+	words := strings.Fields(command)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile(`(?:^|[\s;|&(` + "`" + `])` + strings.Join(words, `\s+`) + `(?:\s|$)`)
+}
+
+// suspiciousCommandRule flags each configured command run from a function
+// body, at the level configured for it. Top-level lines are skipped: there the
+// same words mostly appear in pkgdesc and other metadata. A downloader such as
+// curl in a build function is left to buildDownloadRule, which flags the same
+// line more specifically.
+func suspiciousCommandRule(lines []codeLine, opts *Options) []Finding {
+	var findings []Finding
+	for _, sc := range opts.SuspiciousCommands {
+		if strings.TrimSpace(sc.Command) == "" {
+			continue
+		}
+		re := commandRe(sc.Command)
+		fetches := fetchCmdRe.MatchString(sc.Command)
+		for _, cl := range lines {
+			if cl.inArray || !cl.inFunction() || !re.MatchString(cl.text) {
+				continue
+			}
+			if fetches && buildZones[cl.zone] {
+				continue // a build-time download finding covers it
+			}
+			findings = append(findings, Finding{
+				Kind: KindSuspiciousCommand, Line: cl.num, Zone: cl.zone,
+				Token: truncate(strings.TrimSpace(cl.text), 60), Level: sc.Level,
+				Note: fmt.Sprintf("runs `%s` in %s", sc.Command, cl.zone) + pkgverCaveat(cl.zone),
+			})
+		}
+	}
+	return findings
+}
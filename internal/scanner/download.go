@@ -0,0 +1,63 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindBuildTimeDownload: a build-time function (pkgver(), prepare(), build()
+// or check()) fetches something over the network. Downloads belong in
+// source=(), where makepkg verifies them against checksums; content fetched
+// at build time is unchecked and can change between the review and the
+// build.
+const KindBuildTimeDownload Kind = "build_time_download"
+
+// fetchCmdRe matches a network-fetching command in command position: a plain
+// downloader, a VCS clone/fetch, or a /dev/tcp redirection. Language package
+// managers (cargo fetch, npm install, …) are deliberately not included; they
+// resolve against lockfiles and are routine in prepare().
+var fetchCmdRe = regexp.MustCompile(`(?:^|[\s;|&(` + "`" + `])((?:curl|wget|aria2c|scp|sftp|nc|ncat|netcat|socat)\b|git\b[^;|&]*?\s(?:clone|fetch|pull)\b|svn\s+(?:co|checkout|export)\b|hg\s+(?:clone|pull)\b)|(/dev/(?:tcp|udp)/)`)
+
+func init() {
+	registerRule(buildDownloadRule, KindBuildTimeDownload)
+}
+
+// pkgverCaveat is appended to the note of a finding in pkgver(), which makepkg
+// runs on every build only to print a version.
+func pkgverCaveat(zone string) string {
+	if zone != "pkgver()" {
+		return ""
+	}
+	return "; pkgver() only needs to print a version"
+}
+
+// buildDownloadRule flags every network fetch in pkgver/prepare/build/check.
+func buildDownloadRule(lines []codeLine, _ *Options) []Finding {
+	var findings []Finding
+	for _, cl := range lines {
+		if cl.inArray || !buildZones[cl.zone] {
+			continue
+		}
+		m := fetchCmdRe.FindStringSubmatch(cl.text)
+		if m == nil {
+			continue
+		}
+		command := m[2]
+		if m[1] != "" {
+			command = strings.Fields(m[1])[0]
+			if command == "git" || command == "svn" || command == "hg" {
+				fields := strings.Fields(m[1])
+				command += " " + fields[len(fields)-1]
+			}
+		}
+		findings = append(findings, Finding{
+			Kind: KindBuildTimeDownload, Line: cl.num, Zone: cl.zone,
+			Token: truncate(strings.TrimSpace(cl.text), 60), Level: types.EntropyHigh,
+			Note: fmt.Sprintf("%s downloads during %s, outside the checksummed source array", command, cl.zone) + pkgverCaveat(cl.zone),
+		})
+	}
+	return findings
+}
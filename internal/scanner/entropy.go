@@ -21,6 +21,8 @@ import (
 	"math"
 	"regexp"
 	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
 )
 
 // Heuristic thresholds. Deliberately conservative and named so they are easy to
@@ -67,7 +69,8 @@ const (
 	KindDecodeExecShape Kind = "decode_exec_shape"
 )
 
-// Finding is one surfaced observation. It carries no severity verdict.
+// Finding is one surfaced observation. Entropy findings carry no severity
+// verdict; rule findings (see IsRule) carry the level their rule assigns.
 type Finding struct {
 	Kind     Kind
 	Line     int
@@ -77,12 +80,14 @@ type Finding struct {
 	Compress float64 // compressed/original ratio; ~1.0 = incompressible/opaque
 	Length   int
 	Note     string
+	Level    types.SecurityEntropy // rule findings only
+	AsRoot   bool                  // in an install hook, run as root; Level is already raised for it
 }
 
 // Report is the full deterministic pre-scan result.
 type Report struct {
 	Findings         []Finding
-	Sources          int // non-SKIP source() entries
+	Sources          int // source() entries, all architectures
 	Checksums        int // values seen in *sums=() arrays
 	PGPKeys          int // validpgpkeys=() entries
 	HighEntropyBlobs int // opaque tokens outside checksum/key positions
@@ -92,7 +97,6 @@ type Report struct {
 var (
 	sumsArrayRe  = regexp.MustCompile(`(?s)\b(?:ck|md5|sha1|sha224|sha256|sha384|sha512|b2)sums\w*=\(([^)]*)\)`)
 	pgpKeysRe    = regexp.MustCompile(`(?s)validpgpkeys=\(([^)]*)\)`)
-	sourceRe     = regexp.MustCompile(`(?s)\bsource\w*=\(([^)]*)\)`)
 	arrayOpenRe  = regexp.MustCompile(`^\s*(?:source|validpgpkeys|(?:ck|md5|sha1|sha224|sha256|sha384|sha512|b2)sums)\w*=\(`)
 	funcHeadRe   = regexp.MustCompile(`^\s*([a-zA-Z0-9_]+)\s*\(\)\s*\{?`)
 	// blobRe deliberately excludes '/' and '=': paths (/opt/a/b) and assignments
@@ -179,8 +183,13 @@ func truncate(s string, n int) string {
 }
 
 // Scan runs the deterministic pre-scan over a PKGBUILD (plus any concatenated
-// install/helper files).
+// install/helper files) with the default options.
 func Scan(pkgbuild string) *Report {
+	return ScanWithOptions(pkgbuild, DefaultOptions())
+}
+
+// ScanWithOptions runs the pre-scan with user-tuned thresholds.
+func ScanWithOptions(pkgbuild string, opts Options) *Report {
 	r := &Report{}
 	allow := map[string]bool{} // positions expected to hold high-entropy strings
 
@@ -209,12 +218,17 @@ func Scan(pkgbuild string) *Report {
 			allow[v] = true
 		}
 	}
-	if m := sourceRe.FindStringSubmatch(pkgbuild); m != nil {
-		r.Sources = len(arrayValues(m[1]))
+	if opts.Sources == nil {
+		opts.Sources = ParseSources(pkgbuild)
+	}
+	if opts.OptDepends == nil {
+		opts.OptDepends = ParseOptDepends(pkgbuild)
 	}
+	r.Sources = len(opts.Sources)
 
 	r.scanBlobs(pkgbuild, allow)
 	r.scanShapes(pkgbuild)
+	r.scanRules(pkgbuild, &opts)
 
 	// Count anomaly: more opaque blobs than sources to justify them suggests a
 	// hidden pair (e.g. decryptor + payload).
@@ -231,38 +245,12 @@ func Scan(pkgbuild string) *Report {
 // and source/checksum arrays, and tag which function body a token sits in).
 func (r *Report) scanBlobs(pkgbuild string, allow map[string]bool) {
 	seen := map[string]bool{}
-	zone := "toplevel"
-	braceDepth := 0
-	inArray := false
 
-	for i, line := range strings.Split(pkgbuild, "\n") {
-		trimmed := strings.TrimSpace(line)
-
-		// Track (and skip) multi-line source/checksum/key arrays.
-		if !inArray && arrayOpenRe.MatchString(trimmed) {
-			inArray = true
-		}
-		if inArray {
-			if strings.Contains(line, ")") {
-				inArray = false
-			}
+	for _, cl := range codeLines(pkgbuild) {
+		if cl.inArray {
 			continue
 		}
-		// Skip whole-line comments (avoids breaking ${x#...} parameter expansion).
-		if strings.HasPrefix(trimmed, "#") {
-			continue
-		}
-		// Zone tracking (approximate — a heuristic, not a bash parser).
-		if m := funcHeadRe.FindStringSubmatch(trimmed); m != nil && strings.Contains(line, "{") {
-			zone = m[1] + "()"
-		}
-		braceDepth += strings.Count(line, "{") - strings.Count(line, "}")
-		if braceDepth <= 0 {
-			braceDepth = 0
-			zone = "toplevel"
-		}
-
-		for _, tok := range blobRe.FindAllString(line, -1) {
+		for _, tok := range blobRe.FindAllString(cl.text, -1) {
 			if allow[tok] || seen[tok] || hexRe.MatchString(tok) {
 				continue // hex tops out at 4.0/char and can't be distinguished from a digest here
 			}
@@ -273,7 +261,7 @@ func (r *Report) scanBlobs(pkgbuild string, allow map[string]bool) {
 			seen[tok] = true
 			r.HighEntropyBlobs++
 			r.Findings = append(r.Findings, Finding{
-				Kind: KindUnexplainedEntropy, Line: i + 1, Zone: zone,
+				Kind: KindUnexplainedEntropy, Line: cl.num, Zone: cl.zone,
 				Token: truncate(tok, 48), Entropy: e, Compress: c, Length: len(tok),
 				Note: "opaque high-entropy string, not a checksum or PGP key",
 			})
@@ -309,6 +297,10 @@ func (r *Report) AgentBlock() string {
 		case KindDecodeExecShape:
 			fmt.Fprintf(&b, "  • line %d [%s] in %s — %s\n", f.Line, f.Kind, f.Zone, f.Note)
 		default:
+			if f.IsRule() {
+				fmt.Fprintf(&b, "  • line %d [%s, %s] in %s: %q — %s\n", f.Line, f.Kind, f.Level, f.Zone, f.Token, f.Note)
+				continue
+			}
 			fmt.Fprintf(&b, "  • line %d [%s] in %s: %q (entropy %.2f/char, compress %.2f, len %d) — %s\n",
 				f.Line, f.Kind, f.Zone, f.Token, f.Entropy, f.Compress, f.Length, f.Note)
 		}
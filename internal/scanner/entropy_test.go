@@ -194,3 +194,31 @@ func TestAgentBlockShape(t *testing.T) {
 		t.Errorf("dirty agent block missing expected content:\n%s", block)
 	}
 }
+
+func TestParseSources(t *testing.T) {
+	pkg := `pkgname=x
+source=("x-$pkgver.tar.gz::https://example.com/x.tar.gz"
+        # vendored patch
+        'fix.patch')
+source_x86_64=("git+https://github.com/x/x.git#tag=v1")
+source_aarch64+=(https://mirror.example.org/x-arm.tar.gz) # arm build
+_source=('not-a-source')`
+	got := ParseSources(pkg)
+	want := []string{
+		"x-$pkgver.tar.gz::https://example.com/x.tar.gz",
+		"fix.patch",
+		"git+https://github.com/x/x.git#tag=v1",
+		"https://mirror.example.org/x-arm.tar.gz",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseSources = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("source %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if r := Scan(pkg); r.Sources != len(want) {
+		t.Errorf("Report.Sources = %d, want %d across all architectures", r.Sources, len(want))
+	}
+}
@@ -0,0 +1,156 @@
+package scanner
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+const (
+	// KindHiddenSystemFile: a dot-file or dot-directory is installed into a
+	// system location, or shipped as a source, where it won't show up in an
+	// ordinary listing. A common way to hide persistence.
+	KindHiddenSystemFile Kind = "hidden_system_file"
+	// KindPathTraversal: a source name or a $pkgdir/$srcdir/absolute path
+	// climbs out with ../, escaping the directory it appears to target.
+	KindPathTraversal Kind = "path_traversal"
+)
+
+var (
+	// rootedPathRe splits an operand rooted in $pkgdir or $srcdir from the
+	// rest of the path.
+	rootedPathRe = regexp.MustCompile(`^\$\{?(pkgdir|srcdir)\}?(/.*)?$`)
+	// traversalRe matches a .. path component.
+	traversalRe = regexp.MustCompile(`(?:^|/)\.\.(?:/|$)`)
+)
+
+// creatingCmds are the writeCmdRe commands that put a file in place.
+var creatingCmds = map[string]bool{"install": true, "cp": true, "mv": true, "ln": true, "rsync": true, "mkdir": true, "touch": true, "tee": true}
+
+// systemTopDirs are the top-level directories of an installed system where a
+// hidden file has no business.
+var systemTopDirs = map[string]bool{"usr": true, "etc": true, "bin": true, "sbin": true, "lib": true, "lib64": true, "opt": true, "boot": true, "var": true}
+
+func init() {
+	registerRule(hiddenPathRule, KindHiddenSystemFile, KindPathTraversal)
+}
+
+// hiddenPathRule checks source file names and the destinations of commands
+// in every function body. Traversal out of $pkgdir, $srcdir or an absolute
+// path is HIGH, as is a hidden file installed under a system directory
+// (/etc/skel, which holds dot-files by design, and .keep placeholders
+// excepted). A hidden source file is MODERATE: it only hides in the package
+// directory. Plain relative ../ is left alone; build scripts cd around all the
+// time.
+func hiddenPathRule(lines []codeLine, opts *Options) []Finding {
+	var findings []Finding
+
+	for _, source := range opts.Sources {
+		name, ok := sourceFileName(source)
+		if !ok {
+			continue
+		}
+		if traversalRe.MatchString(name) {
+			findings = append(findings, hiddenPathFinding(KindPathTraversal, lineContaining(lines, source), "source", name,
+				"source is saved as %s, outside $srcdir", types.EntropyHigh))
+		} else if base := path.Base(name); strings.HasPrefix(base, ".") && base != "." {
+			findings = append(findings, hiddenPathFinding(KindHiddenSystemFile, lineContaining(lines, source), "source", name,
+				"source is saved as the hidden file %s", types.EntropyModerate))
+		}
+	}
+
+	for _, cl := range lines {
+		if cl.inArray || !cl.inFunction() {
+			continue
+		}
+		for _, m := range writeCmdRe.FindAllStringSubmatch(cl.text, -1) {
+			command := strings.Fields(m[1])[0]
+			if !creatingCmds[command] {
+				continue
+			}
+			for _, target := range writeOperands(command, strings.Fields(m[2])) {
+				if f, ok := checkTargetPath(cl, command, target); ok {
+					findings = append(findings, f)
+					break
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// sourceFileName returns the name makepkg saves a source under when the PKGBUILD
+// chooses it: the name:: rename prefix, or a local file's own path.
+func sourceFileName(source string) (string, bool) {
+	if name, _, ok := strings.Cut(source, "::"); ok && !strings.Contains(name, "://") {
+		return name, name != ""
+	}
+	if strings.Contains(source, "://") {
+		return "", false
+	}
+	return source, true
+}
+
+// writeOperands returns the operands a command writes to: the destination
+// for the copy-style commands, every operand otherwise.
+func writeOperands(command string, args []string) []string {
+	var operands []string
+	for _, arg := range args {
+		arg = strings.Trim(arg, `"'`)
+		if strings.HasPrefix(arg, "-") || arg == "" {
+			continue
+		}
+		operands = append(operands, arg)
+	}
+	if destinationLastCmds[command] && len(operands) > 0 {
+		operands = operands[len(operands)-1:]
+	}
+	return operands
+}
+
+// checkTargetPath returns a finding when target escapes its root with ../ or
+// is a hidden file under a system directory.
+func checkTargetPath(cl codeLine, command, target string) (Finding, bool) {
+	installed := ""
+	if m := rootedPathRe.FindStringSubmatch(target); m != nil {
+		if traversalRe.MatchString(m[2]) {
+			return hiddenPathFinding(KindPathTraversal, cl.num, cl.zone, target,
+				command+" writes to %s, climbing out of $"+m[1], types.EntropyHigh), true
+		}
+		if m[1] == "pkgdir" {
+			installed = strings.TrimPrefix(m[2], "/")
+		}
+	} else if strings.HasPrefix(target, "/") {
+		if traversalRe.MatchString(target) {
+			return hiddenPathFinding(KindPathTraversal, cl.num, cl.zone, target,
+				command+" writes to %s, using ../ in an absolute path", types.EntropyHigh), true
+		}
+		installed = strings.TrimPrefix(target, "/")
+	}
+
+	if installed == "" || strings.HasPrefix(installed, "etc/skel/") {
+		return Finding{}, false
+	}
+	parts := strings.Split(installed, "/")
+	if !systemTopDirs[parts[0]] {
+		return Finding{}, false
+	}
+	for _, part := range parts[1:] {
+		if strings.HasPrefix(part, ".") && part != "." && part != ".keep" && part != ".gitkeep" {
+			return hiddenPathFinding(KindHiddenSystemFile, cl.num, cl.zone, target,
+				command+" installs the hidden path %s into a system directory", types.EntropyHigh), true
+		}
+	}
+	return Finding{}, false
+}
+
+func hiddenPathFinding(kind Kind, line int, zone, target, format string, level types.SecurityLevel) Finding {
+	return Finding{
+		Kind: kind, Line: line, Zone: zone,
+		Token: truncate(target, 60), Level: level,
+		Note: fmt.Sprintf(format, target),
+	}
+}
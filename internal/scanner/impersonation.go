@@ -0,0 +1,243 @@
+package scanner
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindNameMismatch: the package's name or description doesn't fit where it
+// gets its files. Either it presents itself as a well-known application
+// while no source comes from that application's publisher (the shape of an
+// impersonation or typosquat), or it has a generic name such as "helper" or
+// "utils" and fetches executables from a host unrelated to its upstream.
+const KindNameMismatch Kind = "name_mismatch"
+
+var (
+	// pkgnameRe matches the top-level pkgbase= or pkgname= assignment, and
+	// the first name of a split package's array.
+	pkgnameRe = regexp.MustCompile(`^\s*pkg(?:base|name)=\(?\s*["']?([A-Za-z0-9@._+-]+)`)
+	// pkgdescRe matches the top-level pkgdesc= assignment.
+	pkgdescRe = regexp.MustCompile(`^\s*pkgdesc=["']?([^"'\n]*)`)
+)
+
+// wellKnownApps maps the package names of widely used applications to where
+// their publishers distribute them: a domain, or a forge owner written as
+// "github.com/<owner>". A package of that name fetching from none of these
+// is repackaging someone else's build, or isn't what it says it is.
+var wellKnownApps = map[string][]string{
+	"firefox":            {"mozilla.org", "mozilla.net"},
+	"thunderbird":        {"mozilla.org", "mozilla.net"},
+	"google-chrome":      {"google.com"},
+	"chrome":             {"google.com"},
+	"microsoft-edge":     {"microsoft.com"},
+	"visual-studio-code": {"microsoft.com", "visualstudio.com", "github.com/microsoft"},
+	"discord":            {"discord.com", "discordapp.net", "discordapp.com"},
+	"slack-desktop":      {"slack.com", "slack-edge.com"},
+	"spotify":            {"spotify.com", "scdn.co"},
+	"zoom":               {"zoom.us"},
+	"teamviewer":         {"teamviewer.com"},
+	"anydesk":            {"anydesk.com"},
+	"dropbox":            {"dropbox.com", "dropboxstatic.com"},
+	"telegram-desktop":   {"telegram.org", "github.com/telegramdesktop"},
+	"signal-desktop":     {"signal.org", "github.com/signalapp"},
+	"element-desktop":    {"element.io", "github.com/element-hq", "github.com/vector-im"},
+	"brave":              {"brave.com", "github.com/brave"},
+	"brave-browser":      {"brave.com", "github.com/brave"},
+	"vivaldi":            {"vivaldi.com"},
+	"opera":              {"opera.com"},
+	"1password":          {"1password.com", "agilebits.com"},
+	"bitwarden":          {"bitwarden.com", "github.com/bitwarden"},
+	"keepassxc":          {"keepassxc.org", "github.com/keepassxreboot"},
+	"protonvpn":          {"protonvpn.com", "proton.me", "github.com/protonvpn"},
+	"steam":              {"steampowered.com", "steamstatic.com"},
+	"minecraft-launcher": {"minecraft.net", "mojang.com"},
+	"obsidian":           {"obsidian.md", "github.com/obsidianmd"},
+	"sublime-text":       {"sublimetext.com"},
+	"jetbrains-toolbox":  {"jetbrains.com"},
+	"docker-desktop":     {"docker.com"},
+}
+
+// appVariantSuffixes mark builds of the same application: wellKnownApps
+// applies to firefox-nightly and brave-bin as to firefox and brave.
+var appVariantSuffixes = []string{"-bin", "-git", "-appimage", "-stable", "-beta", "-nightly", "-latest"}
+
+// genericNameParts are words that say nothing about what a package does. A
+// name made only of these ("system-helper", "linux-utils") hides its
+// purpose behind a plausible label.
+var genericNameParts = map[string]bool{
+	"helper": true, "helpers": true, "util": true, "utils": true, "utility": true, "utilities": true,
+	"tool": true, "tools": true, "toolkit": true, "common": true, "core": true, "lib": true, "libs": true,
+	"update": true, "updater": true, "system": true, "sys": true, "service": true, "services": true,
+	"daemon": true, "agent": true, "driver": true, "drivers": true, "fix": true, "patch": true,
+	"manager": true, "support": true, "linux": true, "arch": true, "extra": true, "base": true,
+	"plugin": true, "plugins": true, "addon": true, "client": true, "cli": true, "app": true,
+	"setup": true, "installer": true,
+}
+
+// executableExts are file extensions of ready-to-run programs.
+var executableExts = map[string]bool{
+	".bin": true, ".sh": true, ".run": true, ".exe": true, ".appimage": true, ".elf": true, ".jar": true,
+}
+
+func init() {
+	registerRule(nameMismatchRule, KindNameMismatch)
+}
+
+// nameMismatchRule cross-checks the package's name and description against
+// its remote sources. A name (less a -bin/-git/... suffix) or an "official"
+// claim in pkgdesc naming a wellKnownApps application, with no source from
+// that application's publisher, is one MODERATE finding. A name made only
+// of genericNameParts gets a MODERATE finding per executable source hosted
+// away from the upstream url= (or anywhere but a shared distribution host
+// when there is no url=). Sources whose host depends on a variable other
+// than $url are not judged.
+func nameMismatchRule(lines []codeLine, opts *Options) []Finding {
+	name, nameLine, desc := "", 0, ""
+	for _, cl := range lines {
+		if cl.zone != "toplevel" || cl.inArray {
+			continue
+		}
+		if m := pkgnameRe.FindStringSubmatch(cl.text); m != nil && name == "" {
+			name, nameLine = strings.ToLower(m[1]), cl.num
+		}
+		if m := pkgdescRe.FindStringSubmatch(cl.text); m != nil && desc == "" {
+			desc = m[1]
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	upstream := upstreamURL(lines)
+	var sources []remoteSource
+	for _, entry := range opts.Sources {
+		if expanded := urlVarRe.ReplaceAllLiteralString(entry, upstream); sourceHost(expanded) != "" {
+			sources = append(sources, remoteSource{entry: entry, expanded: expanded})
+		}
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	if finding, ok := impersonationFinding(name, desc, nameLine, sources); ok {
+		return []Finding{finding}
+	}
+	return genericNameFindings(lines, name, upstream, sources)
+}
+
+// remoteSource is a source entry with a known host, and the entry with $url
+// expanded.
+type remoteSource struct {
+	entry, expanded string
+}
+
+// impersonationFinding reports a package presenting itself as a
+// wellKnownApps application while fetching nothing from its publisher.
+func impersonationFinding(name, desc string, nameLine int, sources []remoteSource) (Finding, bool) {
+	app, claim := claimedApp(name, desc)
+	if app == "" {
+		return Finding{}, false
+	}
+	publishers := wellKnownApps[app]
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		if fromPublisher(source.expanded, publishers) {
+			return Finding{}, false
+		}
+		if host := sourceHost(source.expanded); !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return Finding{
+		Kind: KindNameMismatch, Line: nameLine, Zone: "toplevel",
+		Token: name, Level: types.EntropyModerate,
+		Note: fmt.Sprintf("%s %s, but no source comes from its publisher (%s); sources come from %s",
+			claim, app, strings.Join(publishers, ", "), strings.Join(hosts, ", ")),
+	}, true
+}
+
+// claimedApp returns the wellKnownApps application that name, less a variant
+// suffix, is, or that desc calls itself the official build of, with how the
+// claim was made.
+func claimedApp(name, desc string) (app, claim string) {
+	base := name
+	for _, suffix := range appVariantSuffixes {
+		base = strings.TrimSuffix(base, suffix)
+	}
+	if _, ok := wellKnownApps[base]; ok {
+		return base, "the name claims to be"
+	}
+
+	lower := strings.ToLower(desc)
+	if !strings.Contains(lower, "official") {
+		return "", ""
+	}
+	apps := make([]string, 0, len(wellKnownApps))
+	for app := range wellKnownApps {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+	for _, app := range apps {
+		words := regexp.QuoteMeta(strings.ReplaceAll(app, "-", " "))
+		re := regexp.MustCompile(`\b` + strings.ReplaceAll(words, " ", "[- ]") + `\b`)
+		if re.MatchString(lower) {
+			return app, "the description claims to be the official"
+		}
+	}
+	return "", ""
+}
+
+// fromPublisher reports whether source is hosted at one of publishers: on
+// the same domain, or under the named owner on a forge.
+func fromPublisher(source string, publishers []string) bool {
+	u := sourceURL(source)
+	domain := baseDomain(sourceHost(source))
+	owner := strings.ToLower(strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)[0])
+	for _, publisher := range publishers {
+		forge, forgeOwner, onForge := strings.Cut(publisher, "/")
+		switch {
+		case !onForge && domain == publisher:
+			return true
+		case onForge && (domain == forge || domain == "githubusercontent.com") && owner == forgeOwner:
+			return true
+		}
+	}
+	return false
+}
+
+// genericNameFindings reports the executable sources of a package whose name
+// is made only of genericNameParts, when they are hosted away from its
+// upstream.
+func genericNameFindings(lines []codeLine, name, upstream string, sources []remoteSource) []Finding {
+	parts := strings.FieldsFunc(vcsBaseName(name), func(r rune) bool { return r == '-' || r == '_' })
+	for _, part := range parts {
+		if !genericNameParts[part] {
+			return nil
+		}
+	}
+
+	upstreamDomain := baseDomain(sourceHost(upstream))
+	var findings []Finding
+	for _, source := range sources {
+		u := sourceURL(source.expanded)
+		host := sourceHost(source.expanded)
+		domain := baseDomain(host)
+		if !executableExts[strings.ToLower(path.Ext(u.Path))] || domain == upstreamDomain || distributionDomains[domain] {
+			continue
+		}
+		findings = append(findings, Finding{
+			Kind: KindNameMismatch, Line: sourceLine(lines, source.entry), Zone: "source",
+			Token: truncate(source.entry, 60), Level: types.EntropyModerate,
+			Note: fmt.Sprintf("generic package name %q says nothing about what it does, yet it fetches the executable %s from unrelated host %s",
+				name, path.Base(u.Path), host),
+		})
+	}
+	return findings
+}
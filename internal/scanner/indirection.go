@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindShellIndirection: a command is hidden behind shell indirection — ${IFS}
+// standing in for spaces, a command name assembled from variables, or a string
+// decoded from escape codes. These exist to defeat keyword matching (and
+// sometimes the model), so they are flagged on sight; fed to eval, CRITICAL.
+const KindShellIndirection Kind = "shell_indirection"
+
+var (
+	// ifsRe matches an expansion of IFS; it only counts when glued to a word.
+	ifsRe = regexp.MustCompile(`\$(?:\{IFS\}|IFS\b)`)
+	// cmdWordRe matches a word in command position made only of literal
+	// fragments, quotes, and variable expansions, such as "$c$l" or cu${r}l.
+	cmdWordRe = regexp.MustCompile(`(?:^|[;|&(` + "`" + `]|\b(?:then|do|else|eval|exec|command)\s)\s*((?:["']?\$\{\w+\}["']?|["']?\$\w+["']?|\w+)+)`)
+	// expansionRe matches one variable expansion.
+	expansionRe = regexp.MustCompile(`\$\{?\w+\}?`)
+	// adjacentExpansionRe matches two expansions joined with nothing between.
+	adjacentExpansionRe = regexp.MustCompile(`\$\{?\w+\}?["']?["']?\$\{?\w+\}?`)
+	// escapeBuildRe matches a run of three or more hex/octal escapes inside
+	// $'...' or a printf/echo -e command substitution. Isolated escapes such as
+	// terminal colour codes don't form a run.
+	escapeBuildRe = regexp.MustCompile(`(?:\$'|\$\(\s*(?:printf|echo\s+-e)\s[^)]*?)(?:\\x[0-9a-fA-F]{2}|\\[0-7]{3}){3,}`)
+	// printfFragmentsRe matches printf joining short fragments: $(printf %s%s cu rl).
+	printfFragmentsRe = regexp.MustCompile(`\$\(\s*printf\s+["']?(?:%[sc]){2,}["']?(?:\s+["']?\w{1,3}["']?){2,}\s*\)`)
+	// evalRe matches eval in command position.
+	evalRe = regexp.MustCompile(`(?:^|[;|&(\s])eval\s`)
+)
+
+func init() {
+	registerRule(indirectionRule, KindShellIndirection)
+}
+
+// indirectionRule flags one indirection construct per code line, anywhere in
+// the PKGBUILD: top-level code runs too when makepkg sources the file.
+func indirectionRule(lines []codeLine, _ *Options) []Finding {
+	var findings []Finding
+	for _, cl := range lines {
+		if cl.inArray {
+			continue
+		}
+		hasEval := evalRe.MatchString(cl.text)
+		token, what := indirection(cl.text, hasEval)
+		if token == "" {
+			continue
+		}
+		level, note := types.EntropyHigh, fmt.Sprintf("%s in %s", what, cl.zone)
+		if hasEval {
+			level, note = types.EntropyCritical, fmt.Sprintf("eval of %s in %s", what, cl.zone)
+		}
+		findings = append(findings, Finding{
+			Kind: KindShellIndirection, Line: cl.num, Zone: cl.zone,
+			Token: truncate(token, 60), Level: level, Note: note,
+		})
+	}
+	return findings
+}
+
+// indirection returns the first indirection construct on line and what it is,
+// or "" when there is none. With eval, any two adjacent expansions count too.
+func indirection(line string, eval bool) (token, what string) {
+	for _, loc := range ifsRe.FindAllStringIndex(line, -1) {
+		if gluedAt(line, loc[0], loc[1]) {
+			return line[loc[0]:loc[1]], "${IFS} used as a word separator"
+		}
+	}
+	for _, m := range cmdWordRe.FindAllStringSubmatchIndex(line, -1) {
+		word := line[m[2]:m[3]]
+		if m[3] < len(line) && !strings.ContainsRune(" \t;|&)`", rune(line[m[3]])) {
+			continue // part of an assignment, path, or option, not a command
+		}
+		if len(expansionRe.FindAllString(word, -1)) >= 2 {
+			return word, "a command name assembled from variables"
+		}
+	}
+	if m := escapeBuildRe.FindString(line); m != "" {
+		return m, "a string decoded from escape codes"
+	}
+	if m := printfFragmentsRe.FindString(line); m != "" {
+		return m, "a string assembled by printf"
+	}
+	if eval {
+		if m := adjacentExpansionRe.FindString(line); m != "" {
+			return m, "a string assembled from variables"
+		}
+	}
+	return "", ""
+}
+
+// gluedAt reports whether line[start:end] touches a word character on either
+// side, i.e. is used inside a word rather than standing alone or quoted.
+func gluedAt(line string, start, end int) bool {
+	const separators = " \t\"'=;|&()`"
+	before := start > 0 && !strings.ContainsRune(separators, rune(line[start-1]))
+	after := end < len(line) && !strings.ContainsRune(separators, rune(line[end]))
+	return before || after
+}
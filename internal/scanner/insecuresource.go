@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindInsecureSource: a source is fetched over plain http or ftp, or from a
+// raw IP address instead of a named host. Either makes it easier to swap the
+// download in transit or to serve it from infrastructure nobody can vouch for.
+const KindInsecureSource Kind = "insecure_source"
+
+func init() {
+	registerRule(insecureSourceRule, KindInsecureSource)
+}
+
+// insecureSourceRule checks the scheme and host of every remote source. A raw
+// IP host is HIGH; plain http or ftp to a named host is MODERATE. Loopback
+// hosts are skipped: they can only point at the builder's own machine. One
+// finding is emitted per source, carrying the offending URL.
+func insecureSourceRule(lines []codeLine, opts *Options) []Finding {
+	upstream := upstreamURL(lines)
+
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, source := range opts.Sources {
+		u := sourceURL(urlVarRe.ReplaceAllLiteralString(source, upstream))
+		if u == nil || seen[source] {
+			continue
+		}
+		seen[source] = true
+		host := strings.ToLower(u.Hostname())
+		if strings.Contains(host, "$") || host == "localhost" {
+			continue
+		}
+		ip := net.ParseIP(host)
+		if ip != nil && ip.IsLoopback() {
+			continue
+		}
+
+		var problems []string
+		level := types.EntropyModerate
+		switch scheme := strings.ToLower(u.Scheme); scheme {
+		case "http", "ftp":
+			problems = append(problems, fmt.Sprintf("fetched over unencrypted %s", scheme))
+		}
+		if ip != nil {
+			problems = append(problems, fmt.Sprintf("hosted on the raw IP address %s", host))
+			level = types.EntropyHigh
+		}
+		if len(problems) == 0 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Kind: KindInsecureSource, Line: sourceLine(lines, source), Zone: "source",
+			Token: truncate(u.String(), 60), Level: level,
+			Note: "source is " + strings.Join(problems, " and "),
+		})
+	}
+	return findings
+}
@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// InstallScriptRisk scores a package's install script on its own, from the
+// behavior rules that fire in it. Everything in the script runs as root on the
+// user's system when pacman sources it — the hooks and any top-level code — so
+// every rule finding counts. It returns nil when there is no script.
+func InstallScriptRisk(script string, opts Options) *types.InstallScriptRisk {
+	if strings.TrimSpace(script) == "" {
+		return nil
+	}
+	opts.Sources = nil // the PKGBUILD's sources say nothing about the script
+	opts.OptDepends = nil
+
+	risk := &types.InstallScriptRisk{Level: types.EntropyMinimal}
+	for _, f := range ScanWithOptions(script, opts).Findings {
+		if !f.IsRule() {
+			continue
+		}
+		if f.Level > risk.Level {
+			risk.Level = f.Level
+		}
+		risk.Reasons = append(risk.Reasons, fmt.Sprintf("line %d in %s: %s", f.Line, f.Zone, f.Note))
+	}
+	return risk
+}
+
+// RemovalHook is a pre_remove or post_remove function of an installed
+// package's install script, which pacman runs as root during removal.
+type RemovalHook struct {
+	Name    string              // "pre_remove" or "post_remove"
+	Body    []string            // the function's lines, comments dropped
+	Level   types.SecurityLevel // highest rule finding inside it
+	Reasons []string
+}
+
+// RemovalHooks returns the removal hooks the install script defines, in
+// script order, each scored by the behavior rules that fire inside it.
+func RemovalHooks(script string, opts Options) []RemovalHook {
+	opts.Sources = nil
+	opts.OptDepends = nil
+
+	var hooks []RemovalHook
+	index := make(map[string]int)
+	for _, line := range codeLines(script) {
+		name := strings.TrimSuffix(line.zone, "()")
+		if name != "pre_remove" && name != "post_remove" {
+			continue
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(hooks)
+			index[name] = i
+			hooks = append(hooks, RemovalHook{Name: name, Level: types.EntropyMinimal})
+		}
+		hooks[i].Body = append(hooks[i].Body, line.text)
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	for _, f := range ScanWithOptions(script, opts).Findings {
+		i, ok := index[strings.TrimSuffix(f.Zone, "()")]
+		if !ok || !f.IsRule() {
+			continue
+		}
+		if f.Level > hooks[i].Level {
+			hooks[i].Level = f.Level
+		}
+		hooks[i].Reasons = append(hooks[i].Reasons, fmt.Sprintf("line %d: %s", f.Line, f.Note))
+	}
+	return hooks
+}
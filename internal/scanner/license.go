@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindLicenseInfo: the package's license information is missing or doesn't
+// add up. The AUR lists the licenses from .SRCINFO, which the maintainer
+// regenerates from the PKGBUILD; a license the PKGBUILD never declares means
+// the two have drifted apart, or the published metadata describes a
+// different package than the one that gets built.
+const KindLicenseInfo Kind = "license_info"
+
+// licenseStartRe matches the opening of license=(), top-level or inside a
+// split package's function; the body is read by quotedArrayValues.
+var licenseStartRe = regexp.MustCompile(`(?m)^\s*license\+?=\(`)
+
+func init() {
+	registerRule(licenseInfoRule, KindLicenseInfo)
+}
+
+// licenseInfoRule compares the PKGBUILD's license arrays with the licenses
+// in the AUR metadata (opts.AURLicense). Both empty is one LOW finding: no
+// license anywhere. Each AUR license the PKGBUILD doesn't declare is a LOW
+// finding. Without AUR metadata (a local PKGBUILD) the rule is silent;
+// --lint reports a missing license= there.
+func licenseInfoRule(lines []codeLine, opts *Options) []Finding {
+	if opts.AURLicense == nil {
+		return nil
+	}
+	text := make([]string, len(lines))
+	for i, cl := range lines {
+		text[i] = cl.text
+	}
+	declared := ParseLicense(strings.Join(text, "\n"))
+
+	if len(declared) == 0 && len(opts.AURLicense) == 0 {
+		return []Finding{{
+			Kind: KindLicenseInfo, Zone: "toplevel", Token: "license=()", Level: types.EntropyLow,
+			Note: "neither the PKGBUILD nor the AUR metadata declares a license, so there is no telling what terms the software comes under",
+		}}
+	}
+
+	known := make(map[string]bool, len(declared))
+	for _, license := range declared {
+		known[strings.ToLower(license)] = true
+	}
+	var findings []Finding
+	for _, license := range opts.AURLicense {
+		if known[strings.ToLower(license)] {
+			continue
+		}
+		note := fmt.Sprintf("the AUR metadata lists license %q, which the PKGBUILD doesn't declare", license)
+		if len(declared) > 0 {
+			note += fmt.Sprintf(" (it declares %s)", strings.Join(declared, ", "))
+		}
+		findings = append(findings, Finding{
+			Kind: KindLicenseInfo, Line: lineContaining(lines, "license"), Zone: "toplevel",
+			Token: license, Level: types.EntropyLow,
+			Note: note + ": .SRCINFO and the PKGBUILD disagree",
+		})
+	}
+	return findings
+}
+
+// ParseLicense returns every entry of the PKGBUILD's license arrays as
+// written.
+func ParseLicense(pkgbuild string) []string {
+	var entries []string
+	for _, loc := range licenseStartRe.FindAllStringIndex(pkgbuild, -1) {
+		entries = append(entries, quotedArrayValues(pkgbuild[loc[1]:])...)
+	}
+	return entries
+}
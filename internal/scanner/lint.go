@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Lint checks a PKGBUILD for well-known packaging mistakes of the kind namcap
+// reports. These are a quality signal, not a security verdict: lint issues
+// carry no level and are never merged into an analysis.
+
+// LintIssue is one packaging mistake found by Lint.
+type LintIssue struct {
+	Check   string // short identifier, e.g. "missing-arch"
+	Line    int    // 0 when the issue is about something absent
+	Message string
+}
+
+// requiredVars must be set at the top level of every PKGBUILD.
+var requiredVars = []string{"pkgname", "pkgver", "pkgrel", "arch", "license"}
+
+var (
+	// topAssignRe matches a top-level variable or array assignment.
+	topAssignRe = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)=`)
+	// cdRe matches cd in command position.
+	cdRe = regexp.MustCompile(`(?:^|[\s;&(])cd(?:\s|$)`)
+	// cdGuardRe matches a cd whose failure is handled on the same line.
+	cdGuardRe = regexp.MustCompile(`\bcd\b[^;|&]*(?:\|\||&&)`)
+	// packageDirVarRe matches a $pkgdir/$srcdir reference, braced or not.
+	packageDirVarRe = regexp.MustCompile(`\$\{?(?:pkgdir|srcdir)\}?`)
+)
+
+// Lint returns the packaging issues found in pkgbuild, in line order after
+// any missing-variable issues.
+func Lint(pkgbuild string) []LintIssue {
+	lines := codeLines(pkgbuild)
+
+	set := map[string]bool{}
+	hasPackage := false
+	for _, cl := range lines {
+		if cl.zone == "toplevel" {
+			if m := topAssignRe.FindStringSubmatch(cl.text); m != nil {
+				set[m[1]] = true
+			}
+		}
+		if strings.HasPrefix(cl.zone, "package") {
+			hasPackage = true
+		}
+	}
+
+	var issues []LintIssue
+	for _, v := range requiredVars {
+		if !set[v] {
+			issues = append(issues, LintIssue{Check: "missing-" + v, Message: fmt.Sprintf("%s is not set", v)})
+		}
+	}
+	if !hasPackage {
+		issues = append(issues, LintIssue{Check: "missing-package", Message: "no package() function"})
+	}
+
+	for _, cl := range lines {
+		if cl.inArray || !cl.inFunction() {
+			continue
+		}
+		issues = append(issues, lintLine(cl)...)
+	}
+	return issues
+}
+
+// lintLine runs the per-line checks on one function-body line.
+func lintLine(cl codeLine) []LintIssue {
+	var issues []LintIssue
+
+	if strings.HasPrefix(cl.zone, "package") {
+		for _, m := range writeCmdRe.FindAllStringSubmatch(cl.text, -1) {
+			command := strings.Fields(m[1])[0]
+			if target := systemWriteTarget(command, strings.Fields(m[2])); target != "" {
+				issues = append(issues, LintIssue{
+					Check: "path-outside-pkgdir", Line: cl.num,
+					Message: fmt.Sprintf("%s installs to %s in %s; use \"$pkgdir%s\"", command, target, cl.zone, target),
+				})
+				break
+			}
+		}
+	}
+
+	if cdRe.MatchString(cl.text) && !cdGuardRe.MatchString(cl.text) {
+		issues = append(issues, LintIssue{
+			Check: "unchecked-cd", Line: cl.num,
+			Message: fmt.Sprintf("cd in %s without error handling; add `|| return` or `|| exit`", cl.zone),
+		})
+	}
+
+	for _, loc := range packageDirVarRe.FindAllStringIndex(cl.text, -1) {
+		if !inDoubleQuotes(cl.text, loc[0]) {
+			issues = append(issues, LintIssue{
+				Check: "unquoted-dir", Line: cl.num,
+				Message: fmt.Sprintf("%s is unquoted in %s; paths with spaces will break", cl.text[loc[0]:loc[1]], cl.zone),
+			})
+			break
+		}
+	}
+
+	return issues
+}
+
+// inDoubleQuotes reports whether idx in line falls inside a double-quoted
+// string, counting unescaped quotes before it. Single-quoted text is not
+// expanded, so a $pkgdir there is not a reference at all; it is treated as
+// quoted.
+func inDoubleQuotes(line string, idx int) bool {
+	inDouble, inSingle := false, false
+	for i := 0; i < idx; i++ {
+		switch line[i] {
+		case '\\':
+			if !inSingle {
+				i++
+			}
+		case '"':
+			if !inSingle {
+				inDouble = !inDouble
+			}
+		case '\'':
+			if !inDouble {
+				inSingle = !inSingle
+			}
+		}
+	}
+	return inDouble || inSingle
+}
@@ -0,0 +1,73 @@
+package scanner
+
+import "testing"
+
+// lintChecks returns the set of checks Lint reports for pkgbuild.
+func lintChecks(pkgbuild string) map[string]int {
+	checks := map[string]int{}
+	for _, issue := range Lint(pkgbuild) {
+		checks[issue.Check] = issue.Line
+	}
+	return checks
+}
+
+func TestLintCleanPKGBUILD(t *testing.T) {
+	pkg := `pkgname=hello
+pkgver=1.0
+pkgrel=1
+arch=('x86_64')
+license=('MIT')
+source=("hello-$pkgver.tar.gz")
+build() {
+  cd "$srcdir/hello-$pkgver" || return
+  make
+}
+package() {
+  cd "hello-$pkgver" || exit 1
+  install -Dm755 hello "$pkgdir/usr/bin/hello"
+  make DESTDIR="${pkgdir}" install
+  echo 'literal $pkgdir' > /dev/null
+}`
+	if issues := Lint(pkg); len(issues) != 0 {
+		t.Errorf("clean PKGBUILD produced lint issues: %+v", issues)
+	}
+}
+
+func TestLintCommonMistakes(t *testing.T) {
+	pkg := `pkgname=hello
+pkgver=1.0
+pkgrel=1
+build() {
+  cd $srcdir/hello
+  make
+}
+package() {
+  install -Dm755 hello /usr/bin/hello
+}`
+	checks := lintChecks(pkg)
+	want := map[string]int{
+		"missing-arch":        0,
+		"missing-license":     0,
+		"unchecked-cd":        5,
+		"unquoted-dir":        5,
+		"path-outside-pkgdir": 9,
+	}
+	for check, line := range want {
+		got, ok := checks[check]
+		if !ok {
+			t.Errorf("%s not reported: %+v", check, Lint(pkg))
+		} else if got != line {
+			t.Errorf("%s on line %d, want %d", check, got, line)
+		}
+	}
+	if _, ok := checks["missing-package"]; ok {
+		t.Error("missing-package reported although package() exists")
+	}
+}
+
+func TestLintIsNotASecurityFinding(t *testing.T) {
+	pkg := "pkgname=x\nbuild() {\n  cd $srcdir\n}"
+	for _, f := range Scan(pkg).Findings {
+		t.Errorf("lint-only PKGBUILD produced a security finding: %+v", f)
+	}
+}
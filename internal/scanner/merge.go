@@ -0,0 +1,49 @@
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// SecurityFindings converts the rule findings into analysis findings. Entropy
+// findings stay advisory (they only reach the model via AgentBlock); rule
+// findings carry their own level and belong in the verdict.
+func (r *Report) SecurityFindings() []types.SecurityFinding {
+	var out []types.SecurityFinding
+	for _, f := range r.Findings {
+		if !f.IsRule() {
+			continue
+		}
+		finding := types.SecurityFinding{
+			Type:         string(f.Kind),
+			Entropy:      f.Level,
+			Severity:     f.Level, // For compatibility
+			Description:  fmt.Sprintf("%s: %s", f.Note, f.Token),
+			LineNumber:   f.Line,
+			Context:      f.Token,
+			Suggestion:   "Review this line before installing; deterministic checks flag it regardless of the AI verdict",
+			EntropyNotes: fmt.Sprintf("Deterministic pre-scan rule in %s", f.Zone),
+		}
+		if f.AsRoot {
+			finding.AsRoot = true
+			finding.EntropyNotes += ", which pacman runs as root: level raised one step"
+		}
+		finding.Fingerprint = finding.ComputeFingerprint()
+		out = append(out, finding)
+	}
+	return out
+}
+
+// MergeInto appends the rule findings to analysis and raises its overall
+// entropy to the highest rule level, so a model that glosses over a
+// deterministic hit cannot talk the verdict below it.
+func (r *Report) MergeInto(analysis *types.SecurityAnalysis) {
+	for _, finding := range r.SecurityFindings() {
+		analysis.Findings = append(analysis.Findings, finding)
+		if finding.Entropy > analysis.OverallEntropy {
+			analysis.OverallEntropy = finding.Entropy
+			analysis.OverallLevel = finding.Entropy
+		}
+	}
+}
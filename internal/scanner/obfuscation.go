@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindObfuscatedLiteral: a long string literal, or a long one-line command,
+// whose character entropy says it is packed or minified rather than written.
+// Unlike unexplained_entropy this measures whole literals, punctuation
+// included, so it also catches blobs that don't split into one long token.
+const KindObfuscatedLiteral Kind = "obfuscated_literal"
+
+// lineEntropyMargin: a whole line mixes command words with its arguments, so
+// it must clear the literal threshold by this much before it is flagged.
+const lineEntropyMargin = 0.2
+
+// stringLiteralRe matches single-quoted, double-quoted and $'…' literals.
+var stringLiteralRe = regexp.MustCompile(`\$?'[^']*'|"(?:[^"\\]|\\.)*"`)
+
+func init() {
+	registerRule(obfuscationRule, KindObfuscatedLiteral)
+}
+
+// obfuscationRule measures each string literal, and each long code line, and
+// flags those above the configured entropy threshold. One finding per line.
+func obfuscationRule(lines []codeLine, opts *Options) []Finding {
+	var findings []Finding
+	for _, cl := range lines {
+		if cl.inArray {
+			continue
+		}
+
+		flagged := false
+		for _, lit := range stringLiteralRe.FindAllString(cl.text, -1) {
+			if len(lit) < opts.ObfuscationMinLength {
+				continue
+			}
+			if e := shannon(lit); e >= opts.ObfuscationEntropy {
+				findings = append(findings, obfuscationFinding(cl, lit, e, "string literal"))
+				flagged = true
+				break
+			}
+		}
+		if flagged {
+			continue
+		}
+
+		if len(cl.text) >= 2*opts.ObfuscationMinLength {
+			if e := shannon(cl.text); e >= opts.ObfuscationEntropy+lineEntropyMargin {
+				findings = append(findings, obfuscationFinding(cl, cl.text, e, "line"))
+			}
+		}
+	}
+	return findings
+}
+
+func obfuscationFinding(cl codeLine, text string, entropy float64, what string) Finding {
+	return Finding{
+		Kind: KindObfuscatedLiteral, Line: cl.num, Zone: cl.zone,
+		Token: truncate(text, 48), Entropy: entropy, Length: len(text), Level: types.EntropyModerate,
+		Note: fmt.Sprintf("%s reads as obfuscated (%.2f bits/char over %d chars)", what, entropy, len(text)),
+	}
+}
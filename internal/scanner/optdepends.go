@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindRiskyOptDepend: an optional dependency on tooling whose main use is
+// capturing input, mining, tunnelling out, or harvesting credentials. The
+// package may never call it, but offering it says something about what the
+// package is for.
+const KindRiskyOptDepend Kind = "risky_optdepend"
+
+// optdependsStartRe matches the opening of optdepends=() and its
+// architecture-specific variants; the body is read by quotedArrayValues.
+var optdependsStartRe = regexp.MustCompile(`(?m)^\s*optdepends(?:_\w+)?\+?=\(`)
+
+// riskyOptDepends maps package names to why depending on them is notable.
+var riskyOptDepends = map[string]string{
+	"logkeys":      "records keystrokes",
+	"xkeylogger":   "records keystrokes",
+	"keylogger":    "records keystrokes",
+	"xmrig":        "mines cryptocurrency",
+	"xmrig-cuda":   "mines cryptocurrency",
+	"cpuminer-opt": "mines cryptocurrency",
+	"ngrok":        "exposes local services through a public tunnel",
+	"chisel":       "tunnels traffic out through HTTP",
+	"frp":          "exposes local services through a reverse proxy",
+	"mimipenguin":  "dumps login passwords from memory",
+	"lazagne":      "harvests stored credentials",
+}
+
+// riskyOptDependParts catch renamed variants of the tools above.
+var riskyOptDependParts = map[string]string{
+	"keylog": "records keystrokes",
+	"xmrig":  "mines cryptocurrency",
+}
+
+func init() {
+	registerRule(riskyOptDependRule, KindRiskyOptDepend)
+}
+
+// riskyOptDependRule flags optional dependencies on known-risky tooling. They
+// are MODERATE: nothing is installed unless the user asks for it, but a
+// utility that suggests a keylogger deserves a second look.
+func riskyOptDependRule(lines []codeLine, opts *Options) []Finding {
+	var findings []Finding
+	for _, entry := range opts.OptDepends {
+		name := OptDependName(entry)
+		reason, risky := riskyOptDepends[vcsBaseName(name)]
+		for part, partReason := range riskyOptDependParts {
+			if !risky && strings.Contains(name, part) {
+				reason, risky = partReason, true
+			}
+		}
+		if !risky {
+			continue
+		}
+		findings = append(findings, Finding{
+			Kind: KindRiskyOptDepend, Line: lineContaining(lines, name), Zone: "optdepends",
+			Token: truncate(entry, 60), Level: types.EntropyModerate,
+			Note: fmt.Sprintf("optional dependency %s %s", name, reason),
+		})
+	}
+	return findings
+}
+
+// lineContaining returns the number of the first line containing s, or 0.
+func lineContaining(lines []codeLine, s string) int {
+	for _, cl := range lines {
+		if strings.Contains(cl.text, s) {
+			return cl.num
+		}
+	}
+	return 0
+}
+
+// vcsBaseName strips the AUR's -git/-bin style suffixes, so logkeys-git is
+// looked up as logkeys.
+func vcsBaseName(name string) string {
+	for _, suffix := range []string{"-git", "-bin", "-svn", "-hg"} {
+		if base, ok := strings.CutSuffix(name, suffix); ok {
+			return base
+		}
+	}
+	return name
+}
+
+// OptDependName returns the package name of an optdepends entry, without the
+// ": description" and any version constraint.
+func OptDependName(entry string) string {
+	name, _, _ := strings.Cut(entry, ":")
+	if i := strings.IndexAny(name, "<>="); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ParseOptDepends returns every entry of the PKGBUILD's optdepends arrays as
+// written ("name: description"). Unlike the other arrays, entries routinely
+// contain spaces, so they are split on quotes rather than whitespace.
+func ParseOptDepends(pkgbuild string) []string {
+	var entries []string
+	for _, loc := range optdependsStartRe.FindAllStringIndex(pkgbuild, -1) {
+		entries = append(entries, quotedArrayValues(pkgbuild[loc[1]:])...)
+	}
+	return entries
+}
+
+// quotedArrayValues reads a bash array body up to its closing parenthesis,
+// honoring single and double quotes and dropping comments.
+func quotedArrayValues(body string) []string {
+	var values []string
+	var current strings.Builder
+	inWord := false
+	var quote byte
+
+	flush := func() {
+		if inWord {
+			values = append(values, current.String())
+		}
+		current.Reset()
+		inWord = false
+	}
+
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == ')':
+			flush()
+			return values
+		case c == '#' && !inWord:
+			for i < len(body) && body[i] != '\n' {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\n':
+			flush()
+		default:
+			current.WriteByte(c)
+			inWord = true
+		}
+	}
+	flush()
+	return values
+}
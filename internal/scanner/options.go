@@ -0,0 +1,54 @@
+package scanner
+
+import "github.com/aaronsb/yay-friend/internal/types"
+
+// Defaults for the user-tunable checks.
+const (
+	// DefaultObfuscationEntropy sits above long legitimate literals (download
+	// URLs, flag strings top out around 5.1 bits/char) and below packed or
+	// minified content, which runs 5.5-6.0.
+	DefaultObfuscationEntropy = 5.3
+	// DefaultObfuscationMinLength: shorter literals can't carry a meaningful
+	// payload and their entropy estimate is too noisy to act on.
+	DefaultObfuscationMinLength = 40
+)
+
+// Options tunes the configurable checks. Start from DefaultOptions and override
+// what the user configured.
+type Options struct {
+	ObfuscationEntropy   float64 // bits/char at which a string literal reads as obfuscated
+	ObfuscationMinLength int     // literals shorter than this are not measured
+	SuspiciousCommands   []types.SuspiciousCommand
+	Sources              []string      // already-parsed source entries; nil = parse them from the text
+	OptDepends           []string      // already-parsed optdepends entries; nil = parse them from the text
+	AURLicense           []string      // licenses in the AUR metadata; nil = no metadata, skip the license check
+	UserRules            []ScannerRule // rules such as those from LoadUserRules, run after the built-in ones
+}
+
+// DefaultSuspiciousCommands are flagged out of the box. Levels follow how
+// often each has a legitimate use in a build: raw socket tools almost never,
+// downloads occasionally, decoders and inline interpreters fairly often.
+func DefaultSuspiciousCommands() []types.SuspiciousCommand {
+	return []types.SuspiciousCommand{
+		{Command: "nc", Level: types.EntropyHigh},
+		{Command: "ncat", Level: types.EntropyHigh},
+		{Command: "netcat", Level: types.EntropyHigh},
+		{Command: "socat", Level: types.EntropyHigh},
+		{Command: "curl", Level: types.EntropyModerate},
+		{Command: "wget", Level: types.EntropyModerate},
+		{Command: "base64", Level: types.EntropyLow},
+		{Command: "eval", Level: types.EntropyLow},
+		{Command: "python -c", Level: types.EntropyLow},
+		{Command: "python3 -c", Level: types.EntropyLow},
+		{Command: "perl -e", Level: types.EntropyLow},
+	}
+}
+
+// DefaultOptions returns the built-in thresholds.
+func DefaultOptions() Options {
+	return Options{
+		ObfuscationEntropy:   DefaultObfuscationEntropy,
+		ObfuscationMinLength: DefaultObfuscationMinLength,
+		SuspiciousCommands:   DefaultSuspiciousCommands(),
+	}
+}
@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindWorldWritable: package() or an install hook makes a file or directory
+// writable by every user, via chmod or install -m. Any local user can then
+// replace what root later runs or reads from it, a ready-made privilege
+// escalation.
+const KindWorldWritable Kind = "world_writable_mode"
+
+var (
+	// symbolicOtherWriteRe matches one clause of a symbolic mode that gives
+	// others write permission, such as o+w, a+rw or ugo=rwx. A clause without
+	// a who part (+w) is filtered by the umask, so it isn't matched.
+	symbolicOtherWriteRe = regexp.MustCompile(`^[ugoa]*[oa][ugoa]*[+=][rwxXst]*w[rwxXst]*$`)
+	// sharedPathRe matches a system or shared path once any $pkgdir prefix is
+	// removed.
+	sharedPathRe = regexp.MustCompile(`^/(?:usr|etc|bin|sbin|lib|lib64|opt|boot|var|srv)(?:/|$)`)
+	// pkgdirPrefixRe matches the $pkgdir prefix of a packaged path.
+	pkgdirPrefixRe = regexp.MustCompile(`^\$(?:pkgdir|\{pkgdir\})`)
+)
+
+func init() {
+	registerRule(worldWritableRule, KindWorldWritable)
+}
+
+// worldWritableRule flags chmod and install calls, outside quotes, in
+// package() and install hooks whose mode lets others write (o+w, 666, 777).
+// It is HIGH when the target is a system or shared path (/usr, /etc, /var, …
+// inside $pkgdir or on the live system) and MODERATE otherwise, or when the
+// sticky bit limits others to their own files, as on /tmp.
+func worldWritableRule(lines []codeLine, _ *Options) []Finding {
+	var findings []Finding
+	for _, cl := range lines {
+		if cl.inArray || !persistenceZone(cl.zone) {
+			continue
+		}
+		for _, m := range modeCmdRe.FindAllStringSubmatchIndex(cl.text, -1) {
+			if inDoubleQuotes(cl.text, m[0]) {
+				continue // mentioned in a message, not run
+			}
+			command, args := cl.text[m[2]:m[3]], strings.Fields(cl.text[m[4]:m[5]])
+			var mode, target string
+			if command == "chmod" {
+				mode = chmodMode(args)
+				target = chmodTarget(args, mode)
+			} else {
+				mode = installMode(args)
+				if len(args) > 0 {
+					target = strings.Trim(args[len(args)-1], `"'`)
+				}
+			}
+			writable, sticky := otherWritableMode(mode)
+			if !writable {
+				continue
+			}
+
+			level := types.EntropyModerate
+			path := pkgdirPrefixRe.ReplaceAllString(target, "")
+			if sharedPathRe.MatchString(path) && !sticky {
+				level = types.EntropyHigh
+			}
+			findings = append(findings, Finding{
+				Kind: KindWorldWritable, Line: cl.num, Zone: cl.zone,
+				Token: truncate(strings.TrimSpace(cl.text), 60), Level: level,
+				Note: fmt.Sprintf("%s %s makes %s writable by every user in %s", command, mode, displayTarget(target), cl.zone),
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// chmodTarget returns the first operand after chmod's mode.
+func chmodTarget(args []string, mode string) string {
+	seenMode := false
+	for _, arg := range args {
+		arg = strings.Trim(arg, `"'`)
+		switch {
+		case !seenMode && arg == mode:
+			seenMode = true
+		case seenMode:
+			return arg
+		}
+	}
+	return ""
+}
+
+// displayTarget names target in a note, or a placeholder when the command
+// line didn't show one.
+func displayTarget(target string) string {
+	if target == "" {
+		return "its target"
+	}
+	return target
+}
+
+// otherWritableMode reports whether mode gives others write permission, and
+// whether it also sets the sticky bit: an octal mode whose last digit
+// includes 2, or a symbolic mode adding w for o or a.
+func otherWritableMode(mode string) (writable, sticky bool) {
+	if mode == "" {
+		return false, false
+	}
+	if strings.Trim(mode, "01234567") == "" {
+		for len(mode) > 4 && mode[0] == '0' {
+			mode = mode[1:]
+		}
+		if len(mode) < 3 {
+			return false, false
+		}
+		writable = (mode[len(mode)-1]-'0')&2 != 0
+		sticky = len(mode) == 4 && (mode[0]-'0')&1 != 0
+		return writable, sticky
+	}
+	for _, clause := range strings.Split(mode, ",") {
+		if symbolicOtherWriteRe.MatchString(clause) {
+			writable = true
+		}
+		if op := strings.IndexAny(clause, "+="); op >= 0 && strings.Contains(clause[op:], "t") {
+			sticky = true
+		}
+	}
+	return writable, sticky
+}
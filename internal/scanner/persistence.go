@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindPersistence: the package sets something up to run again later on its
+// own — a cron job, a systemd timer or service, a desktop autostart entry.
+// Persistence is a key attacker objective, so each mechanism is named rather
+// than folded into a generic file-operations finding.
+const KindPersistence Kind = "persistence"
+
+var (
+	// cronPathRe matches the system cron directories and spool.
+	cronPathRe = regexp.MustCompile(`(?:^|[/"'\s])(etc/cron(?:tab\b|\.d\b|\.hourly\b|\.daily\b|\.weekly\b|\.monthly\b)|var/spool/cron\b)`)
+	// crontabCmdRe matches the crontab command in command position.
+	crontabCmdRe = regexp.MustCompile(`(?:^|[\s;|&(` + "`" + `])crontab\s`)
+	// systemdDirRe matches a systemd system or user unit directory.
+	systemdDirRe = regexp.MustCompile(`systemd/(?:system|user)\b`)
+	// unitNameRe matches a timer or service unit file name.
+	unitNameRe = regexp.MustCompile(`[A-Za-z0-9_@.${}-]+\.(timer|service)\b`)
+	// systemctlEnableRe matches enabling or starting a unit.
+	systemctlEnableRe = regexp.MustCompile(`(?:^|[\s;|&(` + "`" + `])systemctl\s+(?:--\S+\s+)*(enable|start|link)\s+(?:--\S+\s+)*([^\s;|&]+)`)
+	// autostartRe matches a user or system-wide XDG autostart directory.
+	autostartRe = regexp.MustCompile(`(?:\.config|etc/xdg)/autostart\b`)
+)
+
+func init() {
+	registerRule(persistenceRule, KindPersistence)
+}
+
+// persistenceZone reports whether a zone puts files on, or runs code on,
+// the installed system: package() (and split package_*()) and install hooks.
+func persistenceZone(zone string) bool {
+	return zone == "package()" || strings.HasPrefix(zone, "package_") || installHookZone(zone)
+}
+
+// installHookZone reports whether zone is a pacman install-script hook.
+func installHookZone(zone string) bool {
+	switch zone {
+	case "pre_install()", "post_install()", "pre_upgrade()", "post_upgrade()", "pre_remove()", "post_remove()":
+		return true
+	}
+	return false
+}
+
+// persistenceRule flags cron jobs (files under /etc/cron.* or the spool, and
+// the crontab command), systemd timers and services (unit files installed
+// into a systemd unit directory, and systemctl enable/start), and autostart
+// entries in package() and install hooks. Each is MODERATE, raised to HIGH
+// when the package also issues a network command: something that starts on
+// its own and talks to the network is the shape of an implant. One finding is
+// emitted per mechanism and target.
+func persistenceRule(lines []codeLine, _ *Options) []Finding {
+	level := types.EntropyModerate
+	if hasNetworkCommand(lines) {
+		level = types.EntropyHigh
+	}
+
+	var findings []Finding
+	seen := make(map[string]bool)
+	add := func(cl codeLine, what string) {
+		if seen[what] {
+			return
+		}
+		seen[what] = true
+		findings = append(findings, Finding{
+			Kind: KindPersistence, Line: cl.num, Zone: cl.zone,
+			Token: truncate(strings.TrimSpace(cl.text), 60), Level: level,
+			Note: fmt.Sprintf("persistence: %s in %s", what, cl.zone),
+		})
+	}
+
+	for _, cl := range lines {
+		if cl.inArray || !persistenceZone(cl.zone) {
+			continue
+		}
+		switch {
+		case cronPathRe.MatchString(cl.text):
+			add(cl, "installs a cron job under /"+cronPathRe.FindStringSubmatch(cl.text)[1])
+		case unquotedMatch(crontabCmdRe, cl.text) != nil:
+			add(cl, "edits a crontab")
+		case autostartRe.MatchString(cl.text):
+			add(cl, "adds a login autostart entry under "+autostartRe.FindString(cl.text))
+		case unquotedMatch(systemctlEnableRe, cl.text) != nil:
+			m := unquotedMatch(systemctlEnableRe, cl.text)
+			add(cl, fmt.Sprintf("runs systemctl %s %s", cl.text[m[2]:m[3]], cl.text[m[4]:m[5]]))
+		case systemdDirRe.MatchString(cl.text):
+			switch m := unitNameRe.FindStringSubmatch(cl.text); {
+			case m == nil:
+				add(cl, "installs systemd units")
+			case m[1] == "timer":
+				add(cl, "installs the systemd timer "+m[0]+", which runs on a schedule")
+			default:
+				add(cl, "installs the systemd service "+m[0])
+			}
+		}
+	}
+	return findings
+}
+
+// unquotedMatch returns the submatch indices of the first match of re in
+// line that starts outside quotes, so a command merely mentioned in an echo
+// message (echo 'run systemctl enable foo') doesn't count as running it.
+func unquotedMatch(re *regexp.Regexp, line string) []int {
+	for _, m := range re.FindAllStringSubmatchIndex(line, -1) {
+		if !inDoubleQuotes(line, m[0]) {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindPrivilegeEscalation: a function body runs sudo, su, pkexec, doas or
+// run0. makepkg builds and packages as an unprivileged user (package() under
+// fakeroot), and pacman already runs install hooks as root, so no step of a
+// package has a legitimate reason to ask for root itself.
+const KindPrivilegeEscalation Kind = "privilege_escalation"
+
+// privilegeCmdRe matches a privilege-escalation command, by name or absolute
+// path, in command position: at the start of the line or after a separator,
+// subshell or backtick, a keyword that runs a command (then, do, exec, env,
+// …), or variable assignments. An argument such as cd sudo or
+// install -Dm4755 su doesn't match.
+var privilegeCmdRe = regexp.MustCompile(`(?:^|[;|&(` + "`" + `]|(?:^|\s)(?:then|do|else|exec|nohup|env|time|command|xargs)\s)\s*(?:[A-Za-z_]\w*=\S*\s+)*(?:/[\w/]*/)?(sudo|su|pkexec|doas|run0)(?:\s|$)`)
+
+// suOptionsWithValue are the su options that take the next word as their
+// value.
+var suOptionsWithValue = map[string]bool{
+	"-c": true, "--command": true, "-s": true, "--shell": true,
+	"-g": true, "--group": true, "-G": true, "--supp-group": true,
+	"-w": true, "--whitelist-environment": true,
+}
+
+func init() {
+	registerRule(privilegeEscalationRule, KindPrivilegeEscalation)
+}
+
+// privilegeEscalationRule flags each line of a function body or install
+// hook that runs a privilege-escalation command outside quotes (a
+// post_install message telling the user to run sudo is fine). Each is HIGH.
+// su naming a user other than root (su postgres -c …) drops privileges
+// rather than gaining them, and is left alone.
+func privilegeEscalationRule(lines []codeLine, _ *Options) []Finding {
+	var findings []Finding
+	for _, cl := range lines {
+		if cl.inArray || !cl.inFunction() {
+			continue
+		}
+		for _, m := range privilegeCmdRe.FindAllStringSubmatchIndex(cl.text, -1) {
+			command := cl.text[m[2]:m[3]]
+			if inDoubleQuotes(cl.text, m[2]) || command == "su" && suDropsPrivileges(cl.text[m[3]:]) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Kind: KindPrivilegeEscalation, Line: cl.num, Zone: cl.zone,
+				Token: truncate(strings.TrimSpace(cl.text), 60), Level: types.EntropyHigh,
+				Note: fmt.Sprintf("runs %s in %s: packaging never needs to escalate privileges itself", command, cl.zone),
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// suDropsPrivileges reports whether the arguments after su name a target
+// user other than root.
+func suDropsPrivileges(args string) bool {
+	words := shellWords(args)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case suOptionsWithValue[word]:
+			i++
+		case strings.HasPrefix(word, "-"):
+		default:
+			return word != "root"
+		}
+	}
+	return false
+}
+
+// shellWords splits s into words at unquoted whitespace, dropping the quotes
+// around quoted parts, up to the first unquoted ;, |, & or ) that ends the
+// command. It is enough to step over a quoted argument, not a shell parser.
+func shellWords(s string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case strings.ContainsRune(";|&)", r):
+			if inWord {
+				words = append(words, word.String())
+			}
+			return words
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
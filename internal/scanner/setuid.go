@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindSetuidMode: a function body gives a file the setuid or setgid bit via
+// chmod or install. A setuid binary from the AUR runs with its owner's
+// privileges, usually root, for any user that executes it.
+const KindSetuidMode Kind = "setuid_mode"
+
+var (
+	// modeCmdRe matches chmod or install in command position with its arguments.
+	modeCmdRe = regexp.MustCompile(`(?:^|[\s;|&(])(chmod|install)\s+([^;|&]*)`)
+	// symbolicSetIDRe matches one clause of a symbolic mode that adds s, such
+	// as u+s, g+s, +s or u=rwxs.
+	symbolicSetIDRe = regexp.MustCompile(`^[ugoa]*[+=][rwxXst]*s[rwxXst]*$`)
+)
+
+func init() {
+	registerRule(setuidRule, KindSetuidMode)
+}
+
+// setuidRule flags chmod and install calls in any function body (build,
+// package, or install script hooks) whose mode sets the setuid or setgid bit.
+func setuidRule(lines []codeLine, _ *Options) []Finding {
+	var findings []Finding
+	for _, cl := range lines {
+		if cl.inArray || !cl.inFunction() {
+			continue
+		}
+		for _, m := range modeCmdRe.FindAllStringSubmatch(cl.text, -1) {
+			command := m[1]
+			var mode string
+			if command == "chmod" {
+				mode = chmodMode(strings.Fields(m[2]))
+			} else {
+				mode = installMode(strings.Fields(m[2]))
+			}
+			if !isSetIDMode(mode) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Kind: KindSetuidMode, Line: cl.num, Zone: cl.zone,
+				Token: truncate(strings.TrimSpace(cl.text), 60), Level: types.EntropyHigh,
+				Note: fmt.Sprintf("%s %s sets the setuid/setgid bit in %s", command, mode, cl.zone),
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// chmodMode returns chmod's mode operand: the first argument that isn't an
+// option. Symbolic modes that remove permissions (-w) look like options, but
+// those can't set a bit, so skipping them loses nothing.
+func chmodMode(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return strings.Trim(arg, `"'`)
+		}
+	}
+	return ""
+}
+
+// installMode returns the value given to install's -m/--mode option, which may
+// be attached (-m4755, -Dm4755, --mode=4755) or the next argument.
+func installMode(args []string) string {
+	for i, arg := range args {
+		var value string
+		switch {
+		case strings.HasPrefix(arg, "--mode="):
+			value = strings.TrimPrefix(arg, "--mode=")
+		case arg == "--mode":
+			if i+1 < len(args) {
+				value = args[i+1]
+			}
+		case strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--"):
+			idx := strings.IndexByte(arg, 'm')
+			if idx < 0 {
+				continue
+			}
+			value = arg[idx+1:]
+			if value == "" && i+1 < len(args) {
+				value = args[i+1]
+			}
+		default:
+			continue
+		}
+		return strings.Trim(value, `"'`)
+	}
+	return ""
+}
+
+// isSetIDMode reports whether mode sets the setuid or setgid bit: an octal
+// mode whose special-bits digit includes 4 or 2, or a symbolic mode adding s.
+func isSetIDMode(mode string) bool {
+	if mode == "" {
+		return false
+	}
+	if strings.Trim(mode, "01234567") == "" {
+		for len(mode) > 4 && mode[0] == '0' {
+			mode = mode[1:]
+		}
+		return len(mode) == 4 && (mode[0]-'0')&6 != 0
+	}
+	for _, clause := range strings.Split(mode, ",") {
+		if symbolicSetIDRe.MatchString(clause) {
+			return true
+		}
+	}
+	return false
+}
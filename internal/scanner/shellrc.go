@@ -0,0 +1,153 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindShellProfileWrite: the package writes to a shell startup file — a
+// user's ~/.bashrc or ~/.zshrc, or a system-wide /etc/profile.d script — so
+// whatever it adds runs at every login or every new shell. It is a quiet way
+// to keep code running long after the install.
+const KindShellProfileWrite Kind = "shell_profile_write"
+
+var (
+	// shellProfileRe matches a per-user or system-wide shell startup file.
+	shellProfileRe = regexp.MustCompile(`(?:~|\$\{?HOME\}?|/home/[^/\s"']+|/root|/etc/skel)/\.(?:bashrc|bash_profile|bash_login|profile|zshrc|zprofile|zshenv|zlogin|kshrc|mkshrc|cshrc|tcshrc|config/fish/config\.fish)\b|/etc/(?:profile(?:\.d/[^\s"';|&)]*)?|bash\.bashrc|bashrc|zshrc|zprofile|zshenv|zlogin|zsh/(?:zshrc|zprofile|zshenv|zlogin))\b`)
+	// redirectRe matches an output redirection and its target.
+	redirectRe = regexp.MustCompile(`>>?\s*(["']?[^\s;|&)]+)`)
+	// heredocRe matches the start of a here-document and its terminator.
+	heredocRe = regexp.MustCompile(`<<-?\s*["']?(\w+)["']?`)
+	// echoRe matches an echo or printf command and its arguments.
+	echoRe = regexp.MustCompile(`(?:^|[\s;&(])(?:echo|printf)\s+(?:-[neE]+\s+)*(.*)$`)
+)
+
+// maxAppendedLines bounds how much of a here-document is quoted in a finding.
+const maxAppendedLines = 3
+
+func init() {
+	registerRule(shellProfileRule, KindShellProfileWrite)
+}
+
+// shellProfileRule flags writes to shell startup files in package() and
+// install hooks: redirections (echo ... >> ~/.bashrc), tee, sed -i, and the
+// destination of file-creating commands (install -Dm644 x
+// "$pkgdir"/etc/profile.d/x.sh). Each is HIGH. The note names the file and,
+// where the line shows it, what gets written. A profile.d script package()
+// ships under the package's own name ($pkgdir/etc/profile.d/foo.sh in
+// package foo) is the package's file, tracked by pacman, and isn't flagged.
+func shellProfileRule(lines []codeLine, _ *Options) []Finding {
+	names := packageNames(lines)
+	var findings []Finding
+	for i, cl := range lines {
+		if cl.inArray || !persistenceZone(cl.zone) || !shellProfileRe.MatchString(cl.text) {
+			continue
+		}
+
+		if target, content, ok := shellProfileRedirect(lines, i); ok {
+			if !ownProfileScript(cl, target, names) {
+				findings = append(findings, shellProfileFinding(cl, target, content))
+			}
+			continue
+		}
+		for _, m := range writeCmdRe.FindAllStringSubmatch(cl.text, -1) {
+			command := strings.Fields(m[1])[0]
+			if !creatingCmds[command] && command != "sed" {
+				continue
+			}
+			target := ""
+			for _, operand := range writeOperands(command, strings.Fields(m[2])) {
+				if target = shellProfileRe.FindString(operand); target != "" {
+					break
+				}
+			}
+			if target == "" || ownProfileScript(cl, target, names) {
+				continue
+			}
+			content := ""
+			switch command {
+			case "tee":
+				content = pipedContent(cl.text)
+			case "install", "cp", "mv", "ln", "rsync":
+				if operands := writeOperands("", strings.Fields(m[2])); len(operands) > 1 {
+					content = "the contents of " + operands[len(operands)-2]
+				}
+			case "sed":
+				content = "an in-place edit"
+			}
+			findings = append(findings, shellProfileFinding(cl, target, content))
+			break
+		}
+	}
+	return findings
+}
+
+// ownProfileScript reports whether target, written on cl, is a profile.d
+// script named after the package that package() installs into $pkgdir.
+// Install hooks write to the live system, so their writes always count.
+func ownProfileScript(cl codeLine, target string, names []string) bool {
+	if installHookZone(cl.zone) || !strings.Contains(cl.text, "pkgdir") {
+		return false
+	}
+	path := strings.TrimPrefix(target, "/")
+	return strings.HasPrefix(path, "etc/profile.d/") && isOwnScopedFile(path, names)
+}
+
+// shellProfileRedirect reports an unquoted redirection on lines[i] into a
+// shell startup file, with what is written: the echo/printf arguments before
+// it, or the first lines of a here-document.
+func shellProfileRedirect(lines []codeLine, i int) (target, content string, ok bool) {
+	text := lines[i].text
+	for _, m := range redirectRe.FindAllStringSubmatchIndex(text, -1) {
+		target = shellProfileRe.FindString(text[m[2]:m[3]])
+		if target == "" || inDoubleQuotes(text, m[0]) {
+			continue
+		}
+
+		if h := heredocRe.FindStringSubmatch(text); h != nil {
+			var body []string
+			for _, next := range lines[i+1:] {
+				if strings.TrimSpace(next.text) == h[1] || len(body) == maxAppendedLines {
+					break
+				}
+				body = append(body, strings.TrimSpace(next.text))
+			}
+			return target, strings.Join(body, "; "), true
+		}
+		before := text[:m[0]]
+		if e := echoRe.FindStringSubmatch(before); e != nil {
+			return target, strings.TrimSpace(e[1]), true
+		}
+		return target, pipedContent(before), true
+	}
+	return "", "", false
+}
+
+// pipedContent returns the command whose output is piped into the last
+// command of text, or "" when there is no pipe.
+func pipedContent(text string) string {
+	i := strings.LastIndex(text, "|")
+	if i < 0 {
+		return ""
+	}
+	source := strings.TrimSpace(text[:i])
+	if e := echoRe.FindStringSubmatch(source); e != nil {
+		return strings.TrimSpace(e[1])
+	}
+	return source
+}
+
+func shellProfileFinding(cl codeLine, target, content string) Finding {
+	note := fmt.Sprintf("writes to shell startup file %s in %s, which runs at every login", target, cl.zone)
+	if content != "" {
+		note += "; writes: " + truncate(content, 80)
+	}
+	return Finding{
+		Kind: KindShellProfileWrite, Line: cl.num, Zone: cl.zone,
+		Token: truncate(strings.TrimSpace(cl.text), 60), Level: types.EntropyHigh,
+		Note: note,
+	}
+}
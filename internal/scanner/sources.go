@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// sourceArrayRe matches source=() and its architecture-specific variants
+// (source_x86_64=(), source_aarch64+=(), …).
+var sourceArrayRe = regexp.MustCompile(`(?ms)^\s*source(?:_\w+)?\+?=\(([^)]*)\)`)
+
+// ParseSources returns every entry of the PKGBUILD's source arrays, including
+// architecture-specific ones, in file order. Entries are returned as written
+// (rename prefixes like "name::" and VCS prefixes like "git+" are kept);
+// variables are not expanded. Comments inside multi-line arrays are dropped.
+func ParseSources(pkgbuild string) []string {
+	var sources []string
+	for _, m := range sourceArrayRe.FindAllStringSubmatch(pkgbuild, -1) {
+		for _, line := range strings.Split(m[1], "\n") {
+			sources = append(sources, arrayValues(stripComment(line))...)
+		}
+	}
+	return sources
+}
+
+// stripComment cuts a trailing shell comment from line. A '#' only starts a
+// comment at the start of a word, so URL fragments (…git#tag=v1) survive.
+func stripComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindSourceVersionMismatch: pkgver claims one release while the sources
+// name another, e.g. pkgver=2.4.1 with a hard-coded .../foo-2.3.9.tar.gz. A
+// tampered PKGBUILD can keep the version users expect while fetching a
+// different artifact.
+const KindSourceVersionMismatch Kind = "source_version_mismatch"
+
+var (
+	// pkgverRe matches the top-level pkgver= assignment.
+	pkgverRe = regexp.MustCompile(`^\s*pkgver=["']?([^"'\s]+)`)
+	// versionTokenRe matches a dotted version number (1.2, v3.10.4); see
+	// sourceVersions for the word boundary.
+	versionTokenRe = regexp.MustCompile(`v?(\d+(?:\.\d+)+)`)
+	// versionCoreRe matches the leading dotted number of a pkgver.
+	versionCoreRe = regexp.MustCompile(`^\d+(?:\.\d+)+`)
+	// versionVarRe matches a variable reference that may hold a version:
+	// $pkgver, ${_pkgver//./_}, $_tag, and anything else not known to be
+	// version-free.
+	versionVarRe = regexp.MustCompile(`\$\{?(\w+)`)
+)
+
+// versionFreeVars hold names and URLs, never a version.
+var versionFreeVars = map[string]bool{"pkgname": true, "_pkgname": true, "pkgbase": true, "url": true, "srcdir": true}
+
+func init() {
+	registerRule(sourceVersionRule, KindSourceVersionMismatch)
+}
+
+// sourceVersionRule compares the version numbers written into remote source
+// URLs (their path, query and #tag= fragment) with a literal pkgver. Each
+// source is judged on its own: one built from $pkgver or another variable is
+// consistent by construction and skipped, as is one whose numbers include
+// pkgver or a dotted prefix of it (a .../1.2/foo-1.2.3 layout). Only the
+// package's own sources are judged (see namesPackage): a bundled library or
+// patch carries its own version. Every other source naming a version gets a
+// MODERATE finding. VCS packages with a pkgver() function, and pkgvers
+// without a dotted number, are skipped.
+func sourceVersionRule(lines []codeLine, opts *Options) []Finding {
+	pkgver := ""
+	for _, cl := range lines {
+		if cl.zone == "pkgver()" {
+			return nil
+		}
+		if cl.zone == "toplevel" && !cl.inArray && pkgver == "" {
+			if m := pkgverRe.FindStringSubmatch(cl.text); m != nil {
+				pkgver = m[1]
+			}
+		}
+	}
+	core := versionCoreRe.FindString(strings.NewReplacer("_", ".", "-", ".").Replace(pkgver))
+	if core == "" || strings.Contains(pkgver, "$") {
+		return nil
+	}
+
+	names := packageNames(lines)
+	var findings []Finding
+	for _, source := range opts.Sources {
+		u := sourceURL(source)
+		if u == nil || hasVersionVar(source) {
+			continue // a variable we can't evaluate may hold the version
+		}
+		if !namesPackage(source, u, names) {
+			continue
+		}
+		versions := sourceVersions(u.Path + " " + u.RawQuery + " " + u.Fragment)
+		if len(versions) == 0 || slices.ContainsFunc(versions, func(v string) bool { return versionMatches(v, core) }) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Kind: KindSourceVersionMismatch, Line: sourceLine(lines, source), Zone: "source",
+			Token: truncate(source, 60), Level: types.EntropyModerate,
+			Note: fmt.Sprintf("source names version %s but pkgver is %s", strings.Join(versions, ", "), pkgver),
+		})
+	}
+	return findings
+}
+
+// sourceVersions returns the dotted version numbers in s that stand on their
+// own: python3.11 and dev1.2 don't count, foo-1.2.tar.gz and /v1.2/ do.
+func sourceVersions(s string) []string {
+	var versions []string
+	for _, m := range versionTokenRe.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] > 0 && strings.ContainsAny(s[m[0]-1:m[0]], "0123456789.abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+			continue
+		}
+		versions = append(versions, s[m[2]:m[3]])
+	}
+	return versions
+}
+
+// hasVersionVar reports whether source references a variable other than the
+// version-free ones.
+func hasVersionVar(source string) bool {
+	for _, m := range versionVarRe.FindAllStringSubmatch(source, -1) {
+		if !versionFreeVars[m[1]] {
+			return true
+		}
+	}
+	return false
+}
+
+// versionMatches reports whether a version from a source agrees with the
+// pkgver core: equal, or one a dotted prefix of the other (1.2 and 1.2.3).
+func versionMatches(version, core string) bool {
+	return version == core || strings.HasPrefix(core, version+".") || strings.HasPrefix(version, core+".")
+}
+
+// namesPackage reports whether source is the package's own artifact: its file
+// name (the name:: prefix, or the URL's last path segment) starts with one of
+// names, or $pkgname, without a variant suffix such as -bin and followed by
+// nothing, a . or a -. A file name that starts with a version, as in GitHub's
+// .../foo/archive/v1.2.tar.gz, says nothing about what it is, so the path
+// segments before it are checked instead.
+func namesPackage(source string, u *url.URL, names []string) bool {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	file := segments[len(segments)-1]
+	if i := strings.Index(source, "::"); i >= 0 && !strings.Contains(source[:i], "/") {
+		file = source[:i]
+	}
+	candidates := []string{file}
+	if loc := versionTokenRe.FindStringIndex(file); loc != nil && loc[0] == 0 {
+		candidates = segments[:len(segments)-1]
+	}
+
+	for _, candidate := range candidates {
+		if loc := pkgnameVarRe.FindStringIndex(candidate); loc != nil && loc[0] == 0 && namedBoundary(candidate[loc[1]:]) {
+			return true
+		}
+		for _, name := range names {
+			for _, suffix := range appVariantSuffixes {
+				name = strings.TrimSuffix(name, suffix)
+			}
+			if rest, ok := strings.CutPrefix(candidate, name); ok && name != "" && namedBoundary(rest) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindSystemWrite: a build-time function writing to an absolute system path.
+// prepare/pkgver/build/check run as the invoking user before packaging;
+// everything a package installs belongs under $pkgdir, so a direct write to
+// /usr, /etc, … at this stage is tampering with the live system.
+const KindSystemWrite Kind = "system_path_write"
+
+var (
+	// buildZones are the functions makepkg runs before package().
+	buildZones = map[string]bool{"prepare()": true, "pkgver()": true, "build()": true, "check()": true}
+	// systemPathRe matches an argument that is an absolute system path.
+	systemPathRe = regexp.MustCompile(`^/(?:usr|etc|bin|sbin|lib|lib64|opt|boot)(?:/|$)`)
+	// writeCmdRe matches a file-writing command in command position.
+	writeCmdRe = regexp.MustCompile(`(?:^|[\s;|&(])(install|cp|mv|ln|rsync|mkdir|rm|touch|chmod|chown|tee|dd|sed\s+-i\S*)\s+([^;|&]*)`)
+	// systemRedirectRe matches output redirected into a system path.
+	systemRedirectRe = regexp.MustCompile(`>>?\s*["']?(/(?:usr|etc|bin|sbin|lib|lib64|opt|boot)(?:/[^\s"';|&)]*)?)`)
+)
+
+// destinationLastCmds take their destination as the final argument, so only
+// that one is a write (cp /usr/share/x . reads from /usr).
+var destinationLastCmds = map[string]bool{"install": true, "cp": true, "mv": true, "ln": true, "rsync": true}
+
+func init() {
+	registerRule(systemWriteRule, KindSystemWrite)
+}
+
+// systemWriteRule flags writes to absolute system paths from build-time
+// functions: redirections, and the destination of file-writing commands. Paths
+// rooted in $pkgdir/$srcdir are never absolute system paths, so they pass.
+func systemWriteRule(lines []codeLine, _ *Options) []Finding {
+	var findings []Finding
+	for _, cl := range lines {
+		if cl.inArray || !buildZones[cl.zone] {
+			continue
+		}
+
+		if m := systemRedirectRe.FindStringSubmatch(cl.text); m != nil {
+			findings = append(findings, systemWriteFinding(cl, "redirect", m[1]))
+			continue
+		}
+
+		for _, m := range writeCmdRe.FindAllStringSubmatch(cl.text, -1) {
+			command := strings.Fields(m[1])[0]
+			if target := systemWriteTarget(command, strings.Fields(m[2])); target != "" {
+				findings = append(findings, systemWriteFinding(cl, command, target))
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// systemWriteTarget returns the system path a command writes to, if any.
+func systemWriteTarget(command string, args []string) string {
+	var operands []string
+	for _, arg := range args {
+		arg = strings.Trim(arg, `"'`)
+		if command == "dd" {
+			if of, ok := strings.CutPrefix(arg, "of="); ok {
+				operands = append(operands, of)
+			}
+			continue
+		}
+		if strings.HasPrefix(arg, "-") || arg == "" {
+			continue
+		}
+		operands = append(operands, arg)
+	}
+	if len(operands) == 0 {
+		return ""
+	}
+	if destinationLastCmds[command] {
+		operands = operands[len(operands)-1:]
+	}
+	for _, operand := range operands {
+		if systemPathRe.MatchString(operand) {
+			return operand
+		}
+	}
+	return ""
+}
+
+func systemWriteFinding(cl codeLine, command, target string) Finding {
+	return Finding{
+		Kind: KindSystemWrite, Line: cl.num, Zone: cl.zone,
+		Token: truncate(strings.TrimSpace(cl.text), 60), Level: types.EntropyHigh,
+		Note: fmt.Sprintf("%s writes to system path %s during %s; packages install into $pkgdir", command, target, cl.zone),
+	}
+}
@@ -0,0 +1,155 @@
+package scanner
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindSourceHostMismatch: a source is downloaded from a host unrelated to the
+// package's declared upstream url=, the shape of a supply-chain swap where the
+// metadata still names the real project.
+const KindSourceHostMismatch Kind = "source_host_mismatch"
+
+var (
+	// upstreamURLRe matches the top-level url= assignment.
+	upstreamURLRe = regexp.MustCompile(`^\s*url=["']?([^"'\s]+)`)
+	// urlVarRe matches a reference to the url variable inside a source entry.
+	urlVarRe = regexp.MustCompile(`\$\{url\}|\$url\b`)
+)
+
+// forgeDomains are code hosts a project's url= commonly points at. A source
+// from elsewhere is more surprising when the upstream is one of these.
+var forgeDomains = map[string]bool{
+	"github.com": true, "gitlab.com": true, "codeberg.org": true, "bitbucket.org": true,
+	"sr.ht": true, "sourceforge.net": true, "launchpad.net": true,
+}
+
+// distributionDomains serve release artifacts for many unrelated projects
+// (forges, language registries, distribution mirrors), so a source there is
+// never a mismatch on its own.
+var distributionDomains = map[string]bool{
+	"githubusercontent.com": true, "pythonhosted.org": true, "pypi.org": true, "pypi.io": true,
+	"crates.io": true, "npmjs.org": true, "rubygems.org": true, "haskell.org": true,
+	"cpan.org": true, "metacpan.org": true, "hex.pm": true, "golang.org": true,
+	"kernel.org": true, "gnu.org": true, "nongnu.org": true, "gnome.org": true, "kde.org": true,
+	"freedesktop.org": true, "apache.org": true, "debian.org": true, "archlinux.org": true,
+}
+
+func init() {
+	registerRule(sourceHostRule, KindSourceHostMismatch)
+}
+
+// sourceHostRule compares the host of url= with the host of every remote
+// source. Sources on the upstream's own domain, or on a shared distribution
+// host, pass. A mismatch is HIGH when the upstream is a well-known forge (the
+// project publishes there, yet the package fetches from somewhere else) and
+// MODERATE otherwise. One finding is emitted per mismatched host.
+func sourceHostRule(lines []codeLine, opts *Options) []Finding {
+	upstream := upstreamURL(lines)
+	upstreamHost := sourceHost(upstream)
+	if upstreamHost == "" {
+		return nil
+	}
+	upstreamDomain := baseDomain(upstreamHost)
+
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, source := range opts.Sources {
+		host := sourceHost(urlVarRe.ReplaceAllLiteralString(source, upstream))
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		domain := baseDomain(host)
+		if domain == upstreamDomain || forgeDomains[domain] || distributionDomains[domain] {
+			continue
+		}
+
+		level := types.EntropyModerate
+		if forgeDomains[upstreamDomain] {
+			level = types.EntropyHigh
+		}
+		findings = append(findings, Finding{
+			Kind: KindSourceHostMismatch, Line: sourceLine(lines, source), Zone: "source",
+			Token: truncate(source, 60), Level: level,
+			Note: fmt.Sprintf("source is fetched from %s, unrelated to the upstream url host %s", host, upstreamHost),
+		})
+	}
+	return findings
+}
+
+// upstreamURL returns the value of the top-level url= assignment, or "".
+func upstreamURL(lines []codeLine) string {
+	for _, cl := range lines {
+		if cl.zone == "toplevel" && !cl.inArray {
+			if m := upstreamURLRe.FindStringSubmatch(cl.text); m != nil {
+				return m[1]
+			}
+		}
+	}
+	return ""
+}
+
+// sourceURL parses a source entry or url, or returns nil for local files.
+// Rename prefixes (name::) and VCS prefixes (git+) are stripped first.
+func sourceURL(entry string) *url.URL {
+	if i := strings.Index(entry, "::"); i >= 0 && !strings.Contains(entry[:i], "/") {
+		entry = entry[i+2:]
+	}
+	if plus, colon := strings.Index(entry, "+"), strings.Index(entry, "://"); plus >= 0 && colon > plus {
+		entry = entry[plus+1:]
+	}
+	u, err := url.Parse(entry)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	return u
+}
+
+// sourceHost returns the lower-cased host of a source entry or url, or "" for
+// local files and hosts that depend on unexpanded variables.
+func sourceHost(entry string) string {
+	u := sourceURL(entry)
+	if u == nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, "$") {
+		return ""
+	}
+	return host
+}
+
+// baseDomain approximates the registrable domain of host: its last two labels,
+// or three under two-letter country domains with a generic second level
+// (example.co.uk). Good enough to tell download.foo.org from foo.org apart from
+// an unrelated host; it is not a public-suffix lookup.
+func baseDomain(host string) string {
+	labels := strings.Split(host, ".")
+	n := len(labels)
+	if n <= 2 {
+		return host
+	}
+	switch labels[n-2] {
+	case "co", "com", "org", "net", "ac", "gov", "edu":
+		if len(labels[n-1]) == 2 {
+			return strings.Join(labels[n-3:], ".")
+		}
+	}
+	return strings.Join(labels[n-2:], ".")
+}
+
+// sourceLine returns the line of the first source array entry containing
+// source, or 0 if it can't be found.
+func sourceLine(lines []codeLine, source string) int {
+	for _, cl := range lines {
+		if cl.inArray && strings.Contains(cl.text, source) {
+			return cl.num
+		}
+	}
+	return 0
+}
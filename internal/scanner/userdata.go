@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"regexp"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindUserDataAccess: a reference to a user's home directory or private data
+// (keys, credentials, browser profiles). Builds run in $srcdir and packages
+// install into $pkgdir; neither has a reason to touch a real user's files.
+const KindUserDataAccess Kind = "user_data_access"
+
+var (
+	// sensitiveUserDataRe matches well-known private-data locations under a home
+	// directory. Touching these is HIGH on its own.
+	sensitiveUserDataRe = regexp.MustCompile(`(?:~|\$\{?HOME\}?|/home/[^/\s"']+|/root)/\.(?:ssh|gnupg|aws|azure|kube|docker|netrc|git-credentials|password-store|mozilla|thunderbird|bash_history|zsh_history|local/share/keyrings|config/(?:google-chrome|chromium|BraveSoftware|vivaldi|opera|discord|Signal|gh))\b`)
+	// userHomeRe matches any other reference to a user's home or the invoking
+	// user behind sudo.
+	userHomeRe = regexp.MustCompile(`~/|\$\{?HOME\}?|\$\{?SUDO_USER\}?|/home/[A-Za-z_$]`)
+	// homeAssignRe matches a line that only redirects HOME into the build
+	// sandbox (export HOME="$srcdir"), a common and benign idiom. Anything
+	// else on the line is still checked.
+	homeAssignRe = regexp.MustCompile(`^\s*(?:export\s+)?HOME=(?:"[^"]*"|'[^']*'|[^\s;&|]*)\s*$`)
+)
+
+func init() {
+	registerRule(userDataRule, KindUserDataAccess)
+}
+
+// userDataRule flags references to home directories and user data. A generic
+// home reference is MODERATE, raised to HIGH when the package also issues a
+// network command (the read-then-send exfiltration shape); a reference to a
+// known private-data location is always HIGH.
+func userDataRule(lines []codeLine, _ *Options) []Finding {
+	network := hasNetworkCommand(lines)
+
+	var findings []Finding
+	for _, cl := range lines {
+		if cl.inArray || homeAssignRe.MatchString(cl.text) {
+			continue
+		}
+
+		if loc := sensitiveUserDataRe.FindStringIndex(cl.text); loc != nil && !underPackageRoot(cl.text, loc[0]) {
+			findings = append(findings, Finding{
+				Kind: KindUserDataAccess, Line: cl.num, Zone: cl.zone,
+				Token: truncate(wordAt(cl.text, loc[0]), 48), Level: types.EntropyHigh,
+				Note: "references private user data (keys, credentials, or browser profile)",
+			})
+			continue
+		}
+
+		if loc := userHomeRe.FindStringIndex(cl.text); loc != nil && !underPackageRoot(cl.text, loc[0]) {
+			f := Finding{
+				Kind: KindUserDataAccess, Line: cl.num, Zone: cl.zone,
+				Token: truncate(wordAt(cl.text, loc[0]), 48), Level: types.EntropyModerate,
+				Note: "references a user's home directory outside $pkgdir/$srcdir",
+			}
+			if network {
+				f.Level = types.EntropyHigh
+				f.Note += ", and the package also issues network commands"
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
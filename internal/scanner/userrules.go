@@ -0,0 +1,208 @@
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// UserKindPrefix starts the Kind of every finding from a user rule; the rest
+// is the rule's name, so "user:no-telemetry" comes from the rule no-telemetry.
+const UserKindPrefix = "user:"
+
+// Scopes a user rule can be limited to. Any other scope names one function,
+// such as "build()" or "post_install()".
+const (
+	ScopeAll       = "all"       // every line outside comments (the default)
+	ScopeFunctions = "functions" // function bodies
+	ScopeToplevel  = "toplevel"  // variable assignments and arrays outside functions
+	ScopeSource    = "source"    // each source=() entry
+	ScopeInstall   = "install"   // install-script hooks (pre_install() … post_remove())
+)
+
+var (
+	userRuleNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	scopeFuncRe    = regexp.MustCompile(`^\w+\(\)$`)
+)
+
+// UserRuleSpec is one rule as written in a rule file: a regular expression
+// matched against each line in scope, the level of a match, and the message
+// reported with it.
+type UserRuleSpec struct {
+	Name     string `yaml:"name" json:"name"`
+	Pattern  string `yaml:"pattern" json:"pattern"`
+	Severity string `yaml:"severity" json:"severity"` // MINIMAL, LOW, MODERATE, HIGH or CRITICAL, or 0-4
+	Message  string `yaml:"message" json:"message"`
+	Scope    string `yaml:"scope" json:"scope"`
+}
+
+// userRuleFile is the layout of a rule file: a list of rules under "rules".
+type userRuleFile struct {
+	Rules []UserRuleSpec `yaml:"rules"`
+}
+
+// UserRule is a compiled UserRuleSpec, as returned by LoadUserRules. It is a
+// ScannerRule.
+type UserRule struct {
+	name    string
+	pattern *regexp.Regexp
+	level   types.SecurityEntropy
+	message string
+	scope   string
+}
+
+func (r *UserRule) Name() string { return r.name }
+
+// Check reports every line of pkgInfo.PKGBUILD in the rule's scope that
+// matches its pattern, or for ScopeSource every matching entry of
+// pkgInfo.Sources (parsed from the PKGBUILD when nil).
+func (r *UserRule) Check(pkgInfo types.PackageInfo) []Finding {
+	lines := codeLines(pkgInfo.PKGBUILD)
+	var findings []Finding
+	if r.scope == ScopeSource {
+		sources := pkgInfo.Sources
+		if sources == nil {
+			sources = ParseSources(pkgInfo.PKGBUILD)
+		}
+		for _, source := range sources {
+			if r.pattern.MatchString(source) {
+				findings = append(findings, r.finding(sourceLine(lines, source), "source", source))
+			}
+		}
+		return findings
+	}
+	for _, cl := range lines {
+		if r.inScope(cl) && r.pattern.MatchString(cl.text) {
+			findings = append(findings, r.finding(cl.num, cl.zone, strings.TrimSpace(cl.text)))
+		}
+	}
+	return findings
+}
+
+func (r *UserRule) inScope(cl codeLine) bool {
+	switch r.scope {
+	case ScopeAll:
+		return true
+	case ScopeFunctions:
+		return cl.inFunction()
+	case ScopeToplevel:
+		return !cl.inFunction()
+	case ScopeInstall:
+		return installHookZone(cl.zone)
+	}
+	return cl.zone == r.scope
+}
+
+func (r *UserRule) finding(line int, zone, text string) Finding {
+	return Finding{
+		Kind: Kind(UserKindPrefix + r.name), Line: line, Zone: zone,
+		Token: truncate(text, 60), Level: r.level,
+		Note: r.message,
+	}
+}
+
+// LoadUserRules reads every .yaml, .yml and .json file in dir, in name order,
+// and compiles the rules they define. Each file holds a "rules" list of
+// UserRuleSpec entries. Names must be unique across files, lower-case words
+// joined by - or _; pattern must compile (Go RE2 syntax); severity is
+// required; scope defaults to ScopeAll. Any invalid rule fails the whole load,
+// naming its file.
+func LoadUserRules(dir string) ([]ScannerRule, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+
+	var rules []ScannerRule
+	seen := make(map[string]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rule file: %w", err)
+		}
+		var parsed userRuleFile
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&parsed); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse rule file %s: %w", file, err)
+		}
+		for i, spec := range parsed.Rules {
+			rule, err := compileUserRule(spec)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rules[%d]: %w", file, i, err)
+			}
+			if previous, dup := seen[rule.name]; dup {
+				return nil, fmt.Errorf("%s: rules[%d]: rule %q is already defined in %s", file, i, rule.name, previous)
+			}
+			seen[rule.name] = file
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// compileUserRule validates spec and compiles its pattern.
+func compileUserRule(spec UserRuleSpec) (*UserRule, error) {
+	if !userRuleNameRe.MatchString(spec.Name) {
+		return nil, fmt.Errorf("name %q must be lower-case letters, digits, - and _", spec.Name)
+	}
+	if spec.Pattern == "" {
+		return nil, fmt.Errorf("rule %s: pattern must not be empty", spec.Name)
+	}
+	pattern, err := regexp.Compile(spec.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: invalid pattern: %w", spec.Name, err)
+	}
+	level, err := parseSeverity(spec.Severity)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", spec.Name, err)
+	}
+	scope := spec.Scope
+	switch scope {
+	case "":
+		scope = ScopeAll
+	case ScopeAll, ScopeFunctions, ScopeToplevel, ScopeSource, ScopeInstall:
+	default:
+		if !scopeFuncRe.MatchString(scope) {
+			return nil, fmt.Errorf("rule %s: scope must be all, functions, toplevel, source, install or a function such as build(), got %q", spec.Name, scope)
+		}
+	}
+	message := spec.Message
+	if message == "" {
+		message = "matches user rule " + spec.Name
+	}
+	return &UserRule{name: spec.Name, pattern: pattern, level: level, message: message, scope: scope}, nil
+}
+
+// parseSeverity reads a level name (any case) or its number.
+func parseSeverity(severity string) (types.SecurityEntropy, error) {
+	if n, err := strconv.Atoi(severity); err == nil && n >= int(types.EntropyMinimal) && n <= int(types.EntropyCritical) {
+		return types.SecurityEntropy(n), nil
+	}
+	for level := types.EntropyMinimal; level <= types.EntropyCritical; level++ {
+		if strings.EqualFold(severity, level.String()) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("severity must be MINIMAL, LOW, MODERATE, HIGH or CRITICAL (or 0-4), got %q", severity)
+}
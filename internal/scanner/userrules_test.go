@@ -0,0 +1,137 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func writeRuleFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadUserRules(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "corp.yaml", `rules:
+  - name: no-telemetry
+    pattern: 'telemetry\.example\.com'
+    severity: HIGH
+    message: contacts the telemetry endpoint
+    scope: functions
+  - name: internal-mirror
+    pattern: '^https://mirror\.corp\.example/'
+    severity: 1
+    scope: source
+`)
+	writeRuleFile(t, dir, "extra.json", `{"rules": [{"name": "chmod-777", "pattern": "chmod\\s+777", "severity": "moderate", "scope": "package()"}]}`)
+	writeRuleFile(t, dir, "README.md", "not a rule file")
+
+	rules, err := LoadUserRules(dir)
+	if err != nil {
+		t.Fatalf("LoadUserRules: %v", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("loaded %d rules, want 3", len(rules))
+	}
+
+	pkg := `pkgname=foo
+pkgdesc="no telemetry.example.com here"
+source=("https://mirror.corp.example/foo.tar.gz")
+build() {
+  curl -s https://telemetry.example.com/ping
+  chmod 777 foo
+}
+package() {
+  chmod 777 "$pkgdir/usr/bin/foo"
+}`
+	opts := DefaultOptions()
+	opts.UserRules = rules
+	report := ScanWithOptions(pkg, opts)
+
+	f := ruleFinding(report, Kind(UserKindPrefix+"no-telemetry"))
+	if f == nil {
+		t.Fatal("user rule no-telemetry didn't fire")
+	}
+	if f.Level != types.EntropyHigh || f.Line != 5 || f.Note != "contacts the telemetry endpoint" || !f.IsRule() {
+		t.Errorf("finding = %+v, want HIGH on line 5 with the rule's message", f)
+	}
+	if f := ruleFinding(report, Kind(UserKindPrefix+"internal-mirror")); f == nil || f.Level != types.EntropyLow || f.Line != 3 {
+		t.Errorf("source-scoped rule = %+v, want LOW on line 3", f)
+	}
+	var chmods []int
+	for _, f := range report.Findings {
+		if f.Kind == Kind(UserKindPrefix+"chmod-777") {
+			chmods = append(chmods, f.Line)
+		}
+	}
+	if len(chmods) != 1 || chmods[0] != 9 {
+		t.Errorf("package()-scoped rule matched lines %v, want [9]", chmods)
+	}
+
+	var merged int
+	for _, finding := range report.SecurityFindings() {
+		if strings.HasPrefix(finding.Type, UserKindPrefix) {
+			merged++
+		}
+	}
+	if merged != 3 {
+		t.Errorf("%d user findings reached the analysis, want 3", merged)
+	}
+}
+
+// zoneRule is a ScannerRule written in Go rather than loaded from a file.
+type zoneRule struct{}
+
+func (zoneRule) Name() string { return "zone" }
+
+func (zoneRule) Check(pkgInfo types.PackageInfo) []Finding {
+	if !strings.Contains(pkgInfo.PKGBUILD, "post_install()") {
+		return nil
+	}
+	return []Finding{{Kind: Kind(UserKindPrefix + "zone"), Line: 1, Zone: "post_install()", Level: types.EntropyLow, Note: "has a post_install hook"}}
+}
+
+func TestScannerRuleRunsInPipeline(t *testing.T) {
+	opts := DefaultOptions()
+	opts.UserRules = []ScannerRule{zoneRule{}}
+	report := ScanWithOptions("post_install() {\n  true\n}", opts)
+
+	f := ruleFinding(report, Kind(UserKindPrefix+"zone"))
+	if f == nil {
+		t.Fatal("ScannerRule in Options.UserRules didn't run")
+	}
+	// Install-hook findings are raised like the built-in ones
+	if !f.AsRoot || f.Level != types.EntropyModerate {
+		t.Errorf("finding = %+v, want AsRoot at MODERATE", f)
+	}
+}
+
+func TestLoadUserRulesRejectsInvalid(t *testing.T) {
+	cases := map[string]string{
+		"bad pattern":   "rules:\n  - {name: x, pattern: '([', severity: HIGH}\n",
+		"bad severity":  "rules:\n  - {name: x, pattern: 'y', severity: SEVERE}\n",
+		"no severity":   "rules:\n  - {name: x, pattern: 'y'}\n",
+		"bad scope":     "rules:\n  - {name: x, pattern: 'y', severity: LOW, scope: everywhere}\n",
+		"bad name":      "rules:\n  - {name: 'Has Spaces', pattern: 'y', severity: LOW}\n",
+		"unknown field": "rules:\n  - {name: x, pattern: 'y', severity: LOW, level: 3}\n",
+		"duplicate":     "rules:\n  - {name: x, pattern: 'y', severity: LOW}\n  - {name: x, pattern: 'z', severity: LOW}\n",
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeRuleFile(t, dir, "rules.yaml", content)
+			if _, err := LoadUserRules(dir); err == nil {
+				t.Errorf("LoadUserRules accepted %q", content)
+			}
+		})
+	}
+	if _, err := LoadUserRules(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadUserRules accepted a missing directory")
+	}
+}
@@ -0,0 +1,124 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// KindUnpinnedVCSSource: a VCS source follows a branch instead of a commit
+// or tag, or the build pulls in git submodules. makepkg can't checksum a VCS
+// checkout, so a moving ref means every build may get different code than
+// the maintainer reviewed, and submodules bring in code that isn't listed in
+// source=() at all.
+const KindUnpinnedVCSSource Kind = "unpinned_vcs_source"
+
+var (
+	// vcsSourceRe matches the VCS client of a source entry: a git+/hg+/svn+/
+	// bzr+/fossil+ prefix, or a git://, svn:// … URL, after any name:: prefix.
+	vcsSourceRe = regexp.MustCompile(`^(?:[^/:]+::)?(?:(git|hg|svn|bzr|fossil)\+|(git|svn|bzr)://)`)
+	// vcsPackageRe matches the name of a VCS package, which follows upstream
+	// development by design.
+	vcsPackageRe = regexp.MustCompile(`-(?:git|hg|svn|bzr|fossil)$`)
+	// submoduleUpdateRe matches git submodule update and its arguments.
+	submoduleUpdateRe = regexp.MustCompile(`(?:^|[\s;|&(])git\b[^;|&]*?\ssubmodule\s+update\b([^;|&]*)`)
+	// submoduleURLRe matches pointing a submodule at a local checkout,
+	// e.g. git config submodule.lib.url "$srcdir/lib".
+	submoduleURLRe = regexp.MustCompile(`\bsubmodule\.[\w./-]+\.url\b`)
+)
+
+// pinnedFragments are the source fragments that fix a VCS checkout to one
+// revision.
+var pinnedFragments = []string{"commit=", "tag=", "revision="}
+
+func init() {
+	registerRule(unpinnedVCSRule, KindUnpinnedVCSSource)
+}
+
+// unpinnedVCSRule flags each VCS source without a #commit=, #tag= or
+// #revision= fragment (no fragment, or #branch=), once per source. That is
+// MODERATE, or LOW for a -git/-hg/… package, whose name says it tracks
+// upstream. It also flags git submodule update in a function body: MODERATE
+// with --remote, which follows each submodule's branch, and LOW otherwise,
+// unless the PKGBUILD points the submodules at local sources (git config
+// submodule.<name>.url).
+func unpinnedVCSRule(lines []codeLine, opts *Options) []Finding {
+	vcsPackage := false
+	localSubmodules := false
+	for _, cl := range lines {
+		if cl.zone == "toplevel" && !cl.inArray {
+			if m := pkgnameRe.FindStringSubmatch(cl.text); m != nil && vcsPackageRe.MatchString(m[1]) {
+				vcsPackage = true
+			}
+		}
+		if submoduleURLRe.MatchString(cl.text) {
+			localSubmodules = true
+		}
+	}
+
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, source := range opts.Sources {
+		m := vcsSourceRe.FindStringSubmatch(source)
+		if m == nil || seen[source] {
+			continue
+		}
+		seen[source] = true
+		client := m[1] + m[2]
+
+		_, fragment, _ := strings.Cut(source, "#")
+		if pinnedFragment(fragment) {
+			continue
+		}
+		ref := "its default branch"
+		if branch, ok := strings.CutPrefix(fragment, "branch="); ok {
+			ref = "branch " + branch
+		}
+		level := types.EntropyModerate
+		note := fmt.Sprintf("%s source follows %s, not a commit or tag: each build can fetch different code", client, ref)
+		if vcsPackage {
+			level = types.EntropyLow
+			note += " (expected for a VCS package)"
+		}
+		findings = append(findings, Finding{
+			Kind: KindUnpinnedVCSSource, Line: sourceLine(lines, source), Zone: "source",
+			Token: truncate(source, 60), Level: level, Note: note,
+		})
+	}
+
+	for _, cl := range lines {
+		if cl.inArray || !cl.inFunction() {
+			continue
+		}
+		m := unquotedMatch(submoduleUpdateRe, cl.text)
+		if m == nil {
+			continue
+		}
+		args := cl.text[m[2]:m[3]]
+		level, note := types.EntropyLow, "git submodule update fetches code that isn't listed in source=()"
+		switch {
+		case strings.Contains(args, "--remote"):
+			level, note = types.EntropyModerate, "git submodule update --remote moves each submodule to its branch's latest commit, code nobody pinned or reviewed"
+		case localSubmodules:
+			continue
+		}
+		findings = append(findings, Finding{
+			Kind: KindUnpinnedVCSSource, Line: cl.num, Zone: cl.zone,
+			Token: truncate(strings.TrimSpace(cl.text), 60), Level: level, Note: note,
+		})
+	}
+	return findings
+}
+
+// pinnedFragment reports whether a source fragment (the part after #) pins
+// the checkout to one revision.
+func pinnedFragment(fragment string) bool {
+	for _, pinned := range pinnedFragments {
+		if strings.HasPrefix(fragment, pinned) && len(fragment) > len(pinned) {
+			return true
+		}
+	}
+	return false
+}
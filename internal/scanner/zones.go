@@ -0,0 +1,73 @@
+package scanner
+
+import "strings"
+
+// codeLine is one non-comment line of the scanned text, tagged with where it
+// sits so rules can tell build-time code from install hooks and metadata.
+type codeLine struct {
+	num     int    // 1-based line number in the scanned text
+	text    string // the raw line
+	zone    string // "build()", "post_install()", "toplevel", …
+	inArray bool   // inside a source/checksum/key array
+}
+
+// codeLines splits text into lines, drops whole-line comments (avoids breaking
+// ${x#...} parameter expansion), and zones each remaining line by the function
+// body it sits in. Zone tracking is approximate — a heuristic, not a bash
+// parser. Lines of multi-line source/checksum/key arrays are kept but marked so
+// callers that only care about code can skip them.
+func codeLines(text string) []codeLine {
+	var out []codeLine
+	zone := "toplevel"
+	braceDepth := 0
+	inArray := false
+
+	for i, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if !inArray && arrayOpenRe.MatchString(trimmed) {
+			inArray = true
+		}
+		if inArray {
+			out = append(out, codeLine{num: i + 1, text: line, zone: zone, inArray: true})
+			if strings.Contains(line, ")") {
+				inArray = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if m := funcHeadRe.FindStringSubmatch(trimmed); m != nil && strings.Contains(line, "{") {
+			zone = m[1] + "()"
+		}
+		// A one-line function body still belongs to its function, so capture the
+		// zone before the closing brace resets it.
+		lineZone := zone
+		braceDepth += strings.Count(line, "{") - strings.Count(line, "}")
+		if braceDepth <= 0 {
+			braceDepth = 0
+			zone = "toplevel"
+		}
+		out = append(out, codeLine{num: i + 1, text: line, zone: lineZone})
+	}
+	return out
+}
+
+// HasPkgverFunction reports whether the PKGBUILD defines pkgver(), which
+// makepkg runs at build time to compute the version. Such a PKGBUILD's
+// pkgver= is only the version last recorded, usually by its maintainer.
+func HasPkgverFunction(pkgbuild string) bool {
+	for _, cl := range codeLines(pkgbuild) {
+		if cl.zone == "pkgver()" {
+			return true
+		}
+	}
+	return false
+}
+
+// inFunction reports whether the line sits inside any function body.
+func (cl codeLine) inFunction() bool {
+	return cl.zone != "toplevel"
+}
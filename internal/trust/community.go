@@ -0,0 +1,67 @@
+package trust
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// CommunityFinding checks pkgInfo's AUR votes and popularity against the
+// configured floors (0 disables a floor). It returns nil when the package
+// clears both, or when AUR metadata wasn't fetched and there is nothing to
+// compare. Missing one floor is LOW; missing both is MODERATE.
+func CommunityFinding(pkgInfo types.PackageInfo, minVotes int, minPopularity float64) *types.SecurityFinding {
+	if pkgInfo.FirstSubmitted == "" {
+		return nil // AUR metadata unavailable; zero votes would be a guess
+	}
+
+	var missed []string
+	if minVotes > 0 && pkgInfo.Votes < minVotes {
+		missed = append(missed, fmt.Sprintf("votes below %d", minVotes))
+	}
+	if minPopularity > 0 && pkgInfo.Popularity < minPopularity {
+		missed = append(missed, fmt.Sprintf("popularity below %g", minPopularity))
+	}
+	if len(missed) == 0 {
+		return nil
+	}
+
+	level := types.EntropyLow
+	if len(missed) == 2 {
+		level = types.EntropyModerate
+	}
+	return &types.SecurityFinding{
+		Type:         "limited_community_vetting",
+		Entropy:      level,
+		Severity:     level, // For compatibility
+		Description:  fmt.Sprintf("Only %d votes, popularity %.3f — limited community vetting", pkgInfo.Votes, pkgInfo.Popularity),
+		Suggestion:   "Few other users run this package; review the PKGBUILD and its sources yourself",
+		EntropyNotes: fmt.Sprintf("Configured community floor: %s", strings.Join(missed, ", ")),
+	}
+}
+
+// ApplyCommunityFloors adds the CommunityFinding, if any, to analysis and
+// raises its overall level to match, so the floor counts toward the decision.
+// It reports whether a finding was added.
+func ApplyCommunityFloors(analysis *types.SecurityAnalysis, pkgInfo types.PackageInfo, minVotes int, minPopularity float64) bool {
+	finding := CommunityFinding(pkgInfo, minVotes, minPopularity)
+	if finding == nil {
+		return false
+	}
+	addFinding(analysis, pkgInfo, *finding)
+	return true
+}
+
+// addFinding appends a locally computed finding to analysis and raises its
+// overall level to match, so the finding counts toward the decision. The risk
+// score is recomputed to include it.
+func addFinding(analysis *types.SecurityAnalysis, pkgInfo types.PackageInfo, finding types.SecurityFinding) {
+	finding.Fingerprint = finding.ComputeFingerprint()
+	analysis.Findings = append(analysis.Findings, finding)
+	if finding.Entropy > analysis.OverallEntropy {
+		analysis.OverallEntropy = finding.Entropy
+		analysis.OverallLevel = finding.Entropy
+	}
+	analysis.RiskScore = RiskScore(analysis, pkgInfo)
+}
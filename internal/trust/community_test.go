@@ -0,0 +1,47 @@
+package trust
+
+import (
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestCommunityFinding(t *testing.T) {
+	pkg := types.PackageInfo{FirstSubmitted: "2024-01-01", Votes: 3, Popularity: 0.01}
+
+	if f := CommunityFinding(pkg, 0, 0); f != nil {
+		t.Errorf("disabled floors produced a finding: %+v", f)
+	}
+	if f := CommunityFinding(pkg, 3, 0.01); f != nil {
+		t.Errorf("package at the floors produced a finding: %+v", f)
+	}
+	if f := CommunityFinding(pkg, 10, 0); f == nil || f.Entropy != types.EntropyLow {
+		t.Errorf("one missed floor = %+v, want LOW", f)
+	}
+	if f := CommunityFinding(pkg, 10, 0.5); f == nil || f.Entropy != types.EntropyModerate {
+		t.Errorf("both floors missed = %+v, want MODERATE", f)
+	}
+
+	pkg.FirstSubmitted = ""
+	if f := CommunityFinding(pkg, 10, 0.5); f != nil {
+		t.Errorf("missing AUR metadata produced a finding: %+v", f)
+	}
+}
+
+func TestApplyCommunityFloorsRaisesLevel(t *testing.T) {
+	pkg := types.PackageInfo{FirstSubmitted: "2024-01-01", Votes: 0, Popularity: 0}
+	analysis := &types.SecurityAnalysis{OverallEntropy: types.EntropyMinimal, OverallLevel: types.EntropyMinimal}
+
+	if !ApplyCommunityFloors(analysis, pkg, 5, 0.1) {
+		t.Fatal("expected a finding to be added")
+	}
+	if analysis.OverallLevel != types.EntropyModerate || len(analysis.Findings) != 1 {
+		t.Errorf("analysis = %+v, want MODERATE with one finding", analysis)
+	}
+
+	analysis = &types.SecurityAnalysis{OverallEntropy: types.EntropyHigh, OverallLevel: types.EntropyHigh}
+	ApplyCommunityFloors(analysis, pkg, 5, 0)
+	if analysis.OverallLevel != types.EntropyHigh {
+		t.Errorf("OverallLevel = %s, a floor must not lower it", analysis.OverallLevel)
+	}
+}
@@ -0,0 +1,46 @@
+package trust
+
+import (
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// ApplyTypeFloors raises every finding whose type has a floor in floors
+// (security.type_floors, matched case-insensitively) to at least that level,
+// marking it RaisedByPolicy, and raises the overall level to match, so the
+// policy holds however the model calibrated its ratings. A finding already
+// at or above its floor is left alone. It reports how many findings were
+// raised; the risk score is recomputed when any were.
+func ApplyTypeFloors(analysis *types.SecurityAnalysis, pkgInfo types.PackageInfo, floors map[string]types.SecurityLevel) int {
+	if len(floors) == 0 {
+		return 0
+	}
+	byType := make(map[string]types.SecurityLevel, len(floors))
+	for findingType, floor := range floors {
+		byType[strings.ToLower(strings.TrimSpace(findingType))] = floor
+	}
+
+	raised := 0
+	for i := range analysis.Findings {
+		finding := &analysis.Findings[i]
+		floor, ok := byType[strings.ToLower(strings.TrimSpace(finding.Type))]
+		if !ok || finding.Entropy >= floor {
+			continue
+		}
+		finding.Entropy = floor
+		if finding.Severity < floor {
+			finding.Severity = floor
+		}
+		finding.RaisedByPolicy = true
+		raised++
+		if floor > analysis.OverallEntropy {
+			analysis.OverallEntropy = floor
+			analysis.OverallLevel = floor
+		}
+	}
+	if raised > 0 {
+		analysis.RiskScore = RiskScore(analysis, pkgInfo)
+	}
+	return raised
+}
@@ -0,0 +1,35 @@
+package trust
+
+import (
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestApplyTypeFloors(t *testing.T) {
+	analysis := &types.SecurityAnalysis{
+		OverallEntropy: types.EntropyModerate, OverallLevel: types.EntropyModerate,
+		Findings: []types.SecurityFinding{
+			{Type: "malicious_code", Entropy: types.EntropyModerate, Severity: types.EntropyModerate},
+			{Type: "Code_Execution", Entropy: types.EntropyCritical, Severity: types.EntropyCritical},
+			{Type: "unverified_source", Entropy: types.EntropyLow, Severity: types.EntropyLow},
+		},
+	}
+	floors := map[string]types.SecurityLevel{"malicious_code": types.SecurityHigh, "code_execution": types.SecurityHigh}
+
+	if n := ApplyTypeFloors(analysis, types.PackageInfo{}, floors); n != 1 {
+		t.Fatalf("raised %d findings, want 1", n)
+	}
+	if f := analysis.Findings[0]; f.Entropy != types.EntropyHigh || f.Severity != types.EntropyHigh || !f.RaisedByPolicy {
+		t.Errorf("malicious_code = %+v, want raised to HIGH by policy", f)
+	}
+	if f := analysis.Findings[1]; f.Entropy != types.EntropyCritical || f.RaisedByPolicy {
+		t.Errorf("code_execution = %+v, a floor must not lower or mark it", f)
+	}
+	if f := analysis.Findings[2]; f.Entropy != types.EntropyLow || f.RaisedByPolicy {
+		t.Errorf("unverified_source = %+v, want it untouched", f)
+	}
+	if analysis.OverallLevel != types.EntropyHigh || analysis.RiskScore < 60 {
+		t.Errorf("overall = %s (score %.0f), want HIGH and rescored", analysis.OverallLevel, analysis.RiskScore)
+	}
+}
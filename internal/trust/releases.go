@@ -0,0 +1,220 @@
+package trust
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/netclient"
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// ErrRateLimited marks a GitHub API request refused for exceeding the rate
+// limit, which is low for anonymous requests.
+var ErrRateLimited = errors.New("GitHub API rate limit exceeded (set GITHUB_TOKEN to raise it)")
+
+// githubRepoRe matches a github.com repository URL, capturing owner and name.
+var githubRepoRe = regexp.MustCompile(`github\.com[/:]([A-Za-z0-9_.-]+)/([A-Za-z0-9_.-]+?)(?:\.git)?(?:[/#?]|$)`)
+
+// vcsSuffixes mark packages built from a VCS checkout, whose pkgver is a
+// revision rather than a release.
+var vcsSuffixes = []string{"-git", "-svn", "-hg", "-bzr", "-darcs", "-fossil", "-cvs"}
+
+// releasePage is how many releases (or tags) are fetched, the API maximum
+// for one page. Older releases than that are not considered.
+const releasePage = 100
+
+// ReleaseChecker compares a package's pkgver against its GitHub upstream's
+// releases.
+type ReleaseChecker struct {
+	client  *http.Client
+	apiBase string
+	token   string
+}
+
+// NewReleaseChecker creates a checker for the public GitHub API. It
+// authenticates with GITHUB_TOKEN (or GH_TOKEN) when set; anonymous requests
+// work but share a low hourly rate limit.
+func NewReleaseChecker() *ReleaseChecker {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	return &ReleaseChecker{
+		client:  netclient.New(15 * time.Second),
+		apiBase: "https://api.github.com",
+		token:   token,
+	}
+}
+
+// GitHubRepo returns the owner and name of the GitHub repository a package
+// comes from, taken from url= or, failing that, the first github.com source.
+// ok is false when neither points at GitHub.
+func GitHubRepo(pkgInfo types.PackageInfo) (owner, repo string, ok bool) {
+	for _, candidate := range append([]string{pkgInfo.URL}, pkgInfo.Sources...) {
+		m := githubRepoRe.FindStringSubmatch(candidate)
+		if m == nil || strings.Contains(m[0], "$") {
+			continue
+		}
+		return m[1], m[2], true
+	}
+	return "", "", false
+}
+
+// githubRelease is the subset of a GitHub release the check reads.
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// CheckRelease looks up the package's GitHub upstream and returns a finding
+// when its pkgver matches none of the recent releases (MODERATE), or matches
+// only a prerelease (LOW). Projects that publish no releases are checked
+// against their tags instead. It returns nil, nil when there is nothing to
+// check: no GitHub upstream, a VCS package, or a pkgver that isn't literal or
+// that a pkgver() function recomputes.
+func (c *ReleaseChecker) CheckRelease(ctx context.Context, pkgInfo types.PackageInfo) (*types.SecurityFinding, error) {
+	owner, repo, ok := GitHubRepo(pkgInfo)
+	if !ok || pkgInfo.Version == "" || pkgInfo.DynamicVersion || strings.Contains(pkgInfo.Version, "$") {
+		return nil, nil
+	}
+	for _, suffix := range vcsSuffixes {
+		if strings.HasSuffix(pkgInfo.Name, suffix) {
+			return nil, nil
+		}
+	}
+	slug := owner + "/" + repo
+
+	var releases []githubRelease
+	found, err := c.get(ctx, fmt.Sprintf("/repos/%s/releases?per_page=%d", slug, releasePage), &releases)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return upstreamFinding(types.EntropyModerate,
+			fmt.Sprintf("Upstream repository github.com/%s does not exist or is not public", slug),
+			"Check that url= and source=() point at the real project"), nil
+	}
+
+	source := "releases"
+	if len(releases) == 0 {
+		// Many projects only tag; tags carry the same names
+		var tags []struct {
+			Name string `json:"name"`
+		}
+		if _, err := c.get(ctx, fmt.Sprintf("/repos/%s/tags?per_page=%d", slug, releasePage), &tags); err != nil {
+			return nil, err
+		}
+		if len(tags) == 0 {
+			return nil, nil // nothing published to compare against
+		}
+		for _, tag := range tags {
+			releases = append(releases, githubRelease{TagName: tag.Name})
+		}
+		source = "tags"
+	}
+
+	want := normalizeVersion(pkgInfo.Version)
+	for _, release := range releases {
+		if normalizeVersion(stripTagPrefix(release.TagName, pkgInfo.Name, repo)) != want {
+			continue
+		}
+		if release.Prerelease {
+			return upstreamFinding(types.EntropyLow,
+				fmt.Sprintf("pkgver %s matches upstream prerelease %s of github.com/%s", pkgInfo.Version, release.TagName, slug),
+				"Prereleases can be withdrawn or replaced; check this one is intended"), nil
+		}
+		return nil, nil
+	}
+
+	return upstreamFinding(types.EntropyModerate,
+		fmt.Sprintf("pkgver %s matches none of the %d most recent %s of github.com/%s (latest: %s)",
+			pkgInfo.Version, len(releases), source, slug, releases[0].TagName),
+		"Confirm the version exists upstream; a made-up or withdrawn version can hide a swapped source"), nil
+}
+
+// get fetches an API path into out. It reports false, with no error, when
+// the API answers 404.
+func (c *ReleaseChecker) get(ctx context.Context, path string, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.apiBase+path, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "yay-friend/1.0 (security analysis tool)")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to query GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return false, ErrRateLimited
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to parse GitHub response: %w", err)
+	}
+	return true, nil
+}
+
+// stripTagPrefix removes the decorations tags commonly carry around a
+// version: a "v", "release-", or the project name.
+func stripTagPrefix(tag, pkgname, repo string) string {
+	tag = strings.ToLower(tag)
+	for _, prefix := range []string{strings.ToLower(repo), strings.ToLower(pkgname)} {
+		for _, sep := range []string{"-", "_", "/", " "} {
+			tag = strings.TrimPrefix(tag, prefix+sep)
+		}
+	}
+	tag = strings.TrimPrefix(tag, "release-")
+	tag = strings.TrimPrefix(tag, "release_")
+	tag = strings.TrimPrefix(tag, "v")
+	return tag
+}
+
+// normalizeVersion folds the separators pkgver can't hold: makepkg forbids
+// "-" in pkgver, so upstream 1.0-rc1 is packaged as 1.0_rc1 or 1.0.rc1.
+func normalizeVersion(version string) string {
+	version = strings.TrimPrefix(strings.ToLower(version), "v")
+	return strings.NewReplacer("-", ".", "_", ".").Replace(version)
+}
+
+func upstreamFinding(level types.SecurityEntropy, description, suggestion string) *types.SecurityFinding {
+	return &types.SecurityFinding{
+		Type:         "upstream_release_mismatch",
+		Entropy:      level,
+		Severity:     level, // For compatibility
+		Description:  description,
+		Suggestion:   suggestion,
+		EntropyNotes: "Checked with --compare-upstream against the GitHub releases API",
+	}
+}
+
+// ApplyUpstreamRelease adds the CheckRelease finding, if any, to analysis and
+// raises its overall level to match. It reports whether a finding was added.
+func ApplyUpstreamRelease(ctx context.Context, checker *ReleaseChecker, analysis *types.SecurityAnalysis, pkgInfo types.PackageInfo) (bool, error) {
+	finding, err := checker.CheckRelease(ctx, pkgInfo)
+	if err != nil || finding == nil {
+		return false, err
+	}
+	addFinding(analysis, pkgInfo, *finding)
+	return true, nil
+}
@@ -0,0 +1,120 @@
+package trust
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestGitHubRepo(t *testing.T) {
+	cases := []struct {
+		pkg         types.PackageInfo
+		owner, repo string
+		ok          bool
+	}{
+		{types.PackageInfo{URL: "https://github.com/sharkdp/bat"}, "sharkdp", "bat", true},
+		{types.PackageInfo{URL: "https://example.org", Sources: []string{"bat-1.0.tar.gz::https://github.com/sharkdp/bat.git#tag=v1.0"}}, "sharkdp", "bat", true},
+		{types.PackageInfo{URL: "https://example.org", Sources: []string{"https://github.com/$pkgname/$pkgname/archive/v1.tar.gz"}}, "", "", false},
+		{types.PackageInfo{URL: "https://gitlab.com/foo/bar"}, "", "", false},
+	}
+	for _, tc := range cases {
+		owner, repo, ok := GitHubRepo(tc.pkg)
+		if owner != tc.owner || repo != tc.repo || ok != tc.ok {
+			t.Errorf("GitHubRepo(%+v) = %q, %q, %v; want %q, %q, %v", tc.pkg, owner, repo, ok, tc.owner, tc.repo, tc.ok)
+		}
+	}
+}
+
+// releaseServer serves canned GitHub API responses keyed on the request path.
+func releaseServer(t *testing.T, responses map[string]string) *ReleaseChecker {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/acme/limited/releases" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return &ReleaseChecker{client: srv.Client(), apiBase: srv.URL}
+}
+
+func TestCheckRelease(t *testing.T) {
+	checker := releaseServer(t, map[string]string{
+		"/repos/acme/tool/releases":   `[{"tag_name":"v2.0.0-rc1","prerelease":true},{"tag_name":"v1.4.2"},{"tag_name":"tool-1.4.1"}]`,
+		"/repos/acme/tagged/releases": `[]`,
+		"/repos/acme/tagged/tags":     `[{"name":"release-0.9"}]`,
+	})
+	ctx := context.Background()
+
+	cases := []struct {
+		name, url, version string
+		want               types.SecurityEntropy // -1 = no finding
+	}{
+		{"tool", "https://github.com/acme/tool", "1.4.2", -1},
+		{"tool", "https://github.com/acme/tool", "1.4.1", -1},
+		{"tool", "https://github.com/acme/tool", "2.0.0_rc1", types.EntropyLow},
+		{"tool", "https://github.com/acme/tool", "9.9.9", types.EntropyModerate},
+		{"tool-git", "https://github.com/acme/tool", "r120.abc123", -1},
+		{"tagged", "https://github.com/acme/tagged", "0.9", -1},
+		{"gone", "https://github.com/acme/gone", "1.0", types.EntropyModerate},
+		{"other", "https://example.org", "1.0", -1},
+	}
+	for _, tc := range cases {
+		pkg := types.PackageInfo{Name: tc.name, URL: tc.url, Version: tc.version}
+		finding, err := checker.CheckRelease(ctx, pkg)
+		if err != nil {
+			t.Errorf("%s %s: unexpected error %v", tc.name, tc.version, err)
+			continue
+		}
+		switch {
+		case tc.want < 0 && finding != nil:
+			t.Errorf("%s %s: unexpected finding %+v", tc.name, tc.version, finding)
+		case tc.want >= 0 && (finding == nil || finding.Entropy != tc.want):
+			t.Errorf("%s %s: finding = %+v, want level %s", tc.name, tc.version, finding, tc.want)
+		}
+	}
+
+	// A pkgver() function recomputes the version, so the recorded one proves nothing
+	dynamic := types.PackageInfo{Name: "tool", URL: "https://github.com/acme/tool", Version: "9.9.9", DynamicVersion: true}
+	if finding, err := checker.CheckRelease(ctx, dynamic); finding != nil || err != nil {
+		t.Errorf("dynamic version: finding = %+v, err = %v; want neither", finding, err)
+	}
+}
+
+func TestCheckReleaseRateLimited(t *testing.T) {
+	checker := releaseServer(t, nil)
+	pkg := types.PackageInfo{Name: "limited", URL: "https://github.com/acme/limited", Version: "1.0"}
+
+	_, err := checker.CheckRelease(context.Background(), pkg)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("err = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestApplyUpstreamReleaseRaisesLevel(t *testing.T) {
+	checker := releaseServer(t, map[string]string{
+		"/repos/acme/tool/releases": `[{"tag_name":"v1.0"}]`,
+	})
+	pkg := types.PackageInfo{Name: "tool", URL: "https://github.com/acme/tool", Version: "1.1"}
+	analysis := &types.SecurityAnalysis{OverallEntropy: types.EntropyLow, OverallLevel: types.EntropyLow}
+
+	added, err := ApplyUpstreamRelease(context.Background(), checker, analysis, pkg)
+	if err != nil || !added {
+		t.Fatalf("ApplyUpstreamRelease = %v, %v; want a finding", added, err)
+	}
+	if analysis.OverallLevel != types.EntropyModerate || !strings.Contains(analysis.Findings[0].Description, "latest: v1.0") {
+		t.Errorf("analysis = %+v, want MODERATE naming the latest release", analysis)
+	}
+}
@@ -0,0 +1,82 @@
+package trust
+
+import (
+	"math"
+	"time"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// findingWeights is how much one finding at each entropy level adds to the
+// findings signal, out of 1.
+var findingWeights = map[types.SecurityEntropy]float64{
+	types.EntropyMinimal:  0,
+	types.EntropyLow:      0.05,
+	types.EntropyModerate: 0.15,
+	types.EntropyHigh:     0.3,
+	types.EntropyCritical: 0.5,
+}
+
+// RiskScore rates an analysis from 0 (predictable, well vetted) to 100. Each
+// overall level owns a band of 20 points (MINIMAL 0-20, ..., CRITICAL
+// 80-100), so sorting by score never contradicts the level; the position
+// within the band blends three signals, each from 0 to 1:
+//
+//   - findings (weight 0.5): every finding closes part of the remaining gap
+//     to 1, more the higher its entropy
+//   - unpredictability (0.3): 1 minus the predictability score, when the
+//     provider gave one
+//   - community (0.2): the share of weak AUR signals (under 5 votes,
+//     popularity under 0.1, submitted in the last 30 days), when AUR
+//     metadata was fetched
+//
+// Missing signals drop out and the others are reweighted.
+func RiskScore(analysis *types.SecurityAnalysis, pkgInfo types.PackageInfo) float64 {
+	level := analysis.DecisionLevel()
+	if level < types.EntropyMinimal {
+		level = types.EntropyMinimal
+	}
+	if level > types.EntropyCritical {
+		level = types.EntropyCritical
+	}
+
+	remaining := 1.0
+	for _, finding := range analysis.Findings {
+		remaining *= 1 - findingWeights[finding.Entropy]
+	}
+	sum, weights := 0.5*(1-remaining), 0.5
+
+	if p := analysis.PredictabilityScore; p > 0 && p <= 1 {
+		sum += 0.3 * (1 - p)
+		weights += 0.3
+	}
+
+	if signal, ok := communitySignal(pkgInfo); ok {
+		sum += 0.2 * signal
+		weights += 0.2
+	}
+
+	score := 20*float64(level) + 20*sum/weights
+	return math.Round(score*10) / 10
+}
+
+// communitySignal is the share of weak AUR signals for pkgInfo. ok is false
+// when AUR metadata wasn't fetched (local files, or the lookup failed).
+func communitySignal(pkgInfo types.PackageInfo) (float64, bool) {
+	submitted, err := time.Parse("2006-01-02", pkgInfo.FirstSubmitted)
+	if err != nil {
+		return 0, false
+	}
+
+	weak := 0
+	if pkgInfo.Votes < 5 {
+		weak++
+	}
+	if pkgInfo.Popularity < 0.1 {
+		weak++
+	}
+	if time.Since(submitted) < 30*24*time.Hour {
+		weak++
+	}
+	return float64(weak) / 3, true
+}
@@ -0,0 +1,48 @@
+package trust
+
+import (
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestRiskScoreStaysInLevelBand(t *testing.T) {
+	vetted := types.PackageInfo{FirstSubmitted: "2015-01-01", Votes: 500, Popularity: 5}
+	fresh := types.PackageInfo{FirstSubmitted: "2015-01-01", Votes: 0, Popularity: 0}
+
+	for level := types.EntropyMinimal; level <= types.EntropyCritical; level++ {
+		calm := &types.SecurityAnalysis{OverallEntropy: level, OverallLevel: level, PredictabilityScore: 1}
+		noisy := &types.SecurityAnalysis{OverallEntropy: level, OverallLevel: level, PredictabilityScore: 0.1,
+			Findings: []types.SecurityFinding{{Entropy: level}, {Entropy: types.EntropyHigh}, {Entropy: types.EntropyLow}}}
+
+		low, high := RiskScore(calm, vetted), RiskScore(noisy, fresh)
+		floor := 20 * float64(level)
+		if low < floor || high > floor+20 {
+			t.Errorf("%s: scores %.1f, %.1f outside band [%.0f, %.0f]", level, low, high, floor, floor+20)
+		}
+		if low >= high {
+			t.Errorf("%s: calm score %.1f not below noisy score %.1f", level, low, high)
+		}
+	}
+}
+
+func TestRiskScoreIgnoresMissingSignals(t *testing.T) {
+	analysis := &types.SecurityAnalysis{OverallEntropy: types.EntropyLow, OverallLevel: types.EntropyLow,
+		Findings: []types.SecurityFinding{{Entropy: types.EntropyModerate}}}
+
+	// Only the findings signal is available: 0.15 of the band above LOW
+	if got := RiskScore(analysis, types.PackageInfo{FirstSubmitted: "Not available (local PKGBUILD)"}); got != 23 {
+		t.Errorf("RiskScore = %.1f, want 23", got)
+	}
+}
+
+func TestApplyCommunityFloorsRescores(t *testing.T) {
+	pkg := types.PackageInfo{FirstSubmitted: "2024-01-01"}
+	analysis := &types.SecurityAnalysis{OverallEntropy: types.EntropyMinimal, OverallLevel: types.EntropyMinimal}
+	analysis.RiskScore = RiskScore(analysis, pkg)
+
+	ApplyCommunityFloors(analysis, pkg, 5, 0.1)
+	if analysis.RiskScore < 40 {
+		t.Errorf("RiskScore = %.1f after a MODERATE floor finding, want >= 40", analysis.RiskScore)
+	}
+}
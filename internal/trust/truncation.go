@@ -0,0 +1,33 @@
+package trust
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// PromptTruncatedType is the finding type for package content the provider's
+// analysis size forced out of the prompt.
+const PromptTruncatedType = "prompt_truncated"
+
+// ApplyPromptTruncation adds a HIGH PromptTruncatedType finding when cut names
+// anything the prompt had to leave out to fit the provider's analysis size,
+// and raises the overall level to match. The model never saw that content,
+// and padding a file until code falls out of the prompt is a way to slip it
+// past the analysis. Lines the user's context-lines limit left out don't
+// count. It reports whether a finding was added.
+func ApplyPromptTruncation(analysis *types.SecurityAnalysis, pkgInfo types.PackageInfo, cut []string) bool {
+	if len(cut) == 0 {
+		return false
+	}
+	addFinding(analysis, pkgInfo, types.SecurityFinding{
+		Type:         PromptTruncatedType,
+		Entropy:      types.EntropyHigh,
+		Severity:     types.EntropyHigh, // For compatibility
+		Description:  fmt.Sprintf("Too large to analyze whole: %s left out of the analysis", strings.Join(cut, ", ")),
+		Suggestion:   "Read the omitted content yourself; the verdict only covers what the model saw",
+		EntropyNotes: "The static pre-scan still read everything",
+	})
+	return true
+}
@@ -0,0 +1,24 @@
+package trust
+
+import (
+	"testing"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+func TestApplyPromptTruncation(t *testing.T) {
+	analysis := &types.SecurityAnalysis{OverallEntropy: types.EntropyLow, OverallLevel: types.EntropyLow}
+	if ApplyPromptTruncation(analysis, types.PackageInfo{}, nil) || len(analysis.Findings) != 0 {
+		t.Fatal("a prompt that fit produced a finding")
+	}
+	if !ApplyPromptTruncation(analysis, types.PackageInfo{}, []string{"the install script"}) {
+		t.Fatal("expected a finding to be added")
+	}
+	f := analysis.Findings[0]
+	if f.Type != PromptTruncatedType || f.Entropy != types.EntropyHigh || f.Fingerprint == "" {
+		t.Errorf("finding = %+v, want a fingerprinted HIGH %s", f, PromptTruncatedType)
+	}
+	if analysis.OverallLevel != types.EntropyHigh {
+		t.Errorf("overall level = %s, want HIGH", analysis.OverallLevel)
+	}
+}
@@ -1,8 +1,11 @@
 package trust
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -100,9 +103,9 @@ func NewTrustAnalyzer(cacheDir string) *TrustAnalyzer {
 }
 
 // AnalyzePackageTrust performs comprehensive trust analysis
-func (ta *TrustAnalyzer) AnalyzePackageTrust(packageName string) (*TrustAnalysis, error) {
+func (ta *TrustAnalyzer) AnalyzePackageTrust(ctx context.Context, packageName string) (*TrustAnalysis, error) {
 	// Get repository information
-	repoInfo, err := ta.getRepositoryInfo(packageName)
+	repoInfo, err := ta.getRepositoryInfo(ctx, packageName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repository info: %w", err)
 	}
@@ -135,94 +138,130 @@ func (ta *TrustAnalyzer) AnalyzePackageTrust(packageName string) (*TrustAnalysis
 }
 
 // getRepositoryInfo fetches git repository information for an AUR package
-func (ta *TrustAnalyzer) getRepositoryInfo(packageName string) (*RepositoryInfo, error) {
+func (ta *TrustAnalyzer) getRepositoryInfo(ctx context.Context, packageName string) (*RepositoryInfo, error) {
 	// AUR git URL format
 	gitURL := fmt.Sprintf("https://aur.archlinux.org/%s.git", packageName)
-	
-	// Clone to a temporary directory for analysis
-	tempDir := fmt.Sprintf("/tmp/yay-friend-trust-%s", packageName)
-	
-	// Clean up any existing directory
-	exec.Command("rm", "-rf", tempDir).Run()
-	
-	// Clone the repository
-	cmd := exec.Command("git", "clone", gitURL, tempDir)
-	if err := cmd.Run(); err != nil {
+
+	// Clone into a fresh temporary directory; a fixed /tmp path could be
+	// pre-created by another user, or left half-written by an interrupted
+	// run. Removed on return, including when ctx is cancelled and the git
+	// commands below are killed.
+	workDir, err := os.MkdirTemp("", "yay-friend-trust-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	tempDir := filepath.Join(workDir, "repo")
+
+	if err := cloneRepository(ctx, gitURL, tempDir); err != nil {
 		return nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
-	
-	// Ensure cleanup
-	defer exec.Command("rm", "-rf", tempDir).Run()
 
-	// Change to the repository directory for git operations
 	repoInfo := &RepositoryInfo{
 		PackageName: packageName,
 		GitURL:      gitURL,
 	}
+	// An empty or unreadable history leaves the figures at zero
+	_ = readRepositoryHistory(ctx, tempDir, repoInfo)
+	repoInfo.Maintainer = pkgbuildMaintainer(filepath.Join(tempDir, "PKGBUILD"))
 
-	// Get first commit
-	cmd = exec.Command("git", "-C", tempDir, "log", "--reverse", "--format=%ct", "--max-count=1")
-	output, err := cmd.Output()
-	if err == nil {
-		if timestamp, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
-			repoInfo.FirstCommit = time.Unix(timestamp, 0)
+	// Calculate derived metrics
+	if !repoInfo.FirstCommit.IsZero() {
+		repoInfo.RepoAge = time.Since(repoInfo.FirstCommit)
+		if repoInfo.RepoAge.Hours() > 0 {
+			monthsAge := repoInfo.RepoAge.Hours() / (24 * 30)
+			repoInfo.CommitFrequency = float64(repoInfo.CommitCount) / monthsAge
 		}
 	}
 
-	// Get last commit
-	cmd = exec.Command("git", "-C", tempDir, "log", "--format=%ct", "--max-count=1")
-	output, err = cmd.Output()
-	if err == nil {
-		if timestamp, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
-			repoInfo.LastCommit = time.Unix(timestamp, 0)
-		}
-	}
+	return repoInfo, nil
+}
 
-	// Get commit count
-	cmd = exec.Command("git", "-C", tempDir, "rev-list", "--count", "HEAD")
-	output, err = cmd.Output()
-	if err == nil {
-		if count, err := strconv.Atoi(strings.TrimSpace(string(output))); err == nil {
-			repoInfo.CommitCount = count
-		}
+// readRepositoryHistory fills in the commit dates, commit count and
+// contributors of info from the repository at dir, with a single git log
+// whose output is parsed here rather than one git process per figure.
+func readRepositoryHistory(ctx context.Context, dir string, info *RepositoryInfo) error {
+	output, err := exec.CommandContext(ctx, "git", "-C", dir, "log", "--format=%ct%x1f%an", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to read git history: %w", err)
 	}
 
-	// Get contributors
-	cmd = exec.Command("git", "-C", tempDir, "log", "--format=%an", "--all")
-	output, err = cmd.Output()
-	if err == nil {
-		contributors := make(map[string]bool)
-		for _, line := range strings.Split(string(output), "\n") {
-			if line = strings.TrimSpace(line); line != "" {
-				contributors[line] = true
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		timestamp, author, ok := strings.Cut(line, "\x1f")
+		if !ok {
+			continue
+		}
+		info.CommitCount++
+		if unix, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+			// Newest first: the first line is the last commit, the final
+			// line the first
+			if info.LastCommit.IsZero() {
+				info.LastCommit = time.Unix(unix, 0)
 			}
+			info.FirstCommit = time.Unix(unix, 0)
 		}
-		for contributor := range contributors {
-			repoInfo.Contributors = append(repoInfo.Contributors, contributor)
+		if author = strings.TrimSpace(author); author != "" && !seen[author] {
+			seen[author] = true
+			info.Contributors = append(info.Contributors, author)
 		}
 	}
+	return nil
+}
+
+// maintainerCommentRe matches a "# Maintainer: name <email>" comment.
+var maintainerCommentRe = regexp.MustCompile(`^#\s*[Mm]aintainer:\s*(.+)`)
 
-	// Get maintainer from PKGBUILD
-	cmd = exec.Command("grep", "-E", "^#.*[Mm]aintainer", fmt.Sprintf("%s/PKGBUILD", tempDir))
-	output, err = cmd.Output()
-	if err == nil {
-		// Extract maintainer name from comment
-		re := regexp.MustCompile(`#\s*[Mm]aintainer:\s*(.+)`)
-		if matches := re.FindStringSubmatch(string(output)); len(matches) > 1 {
-			repoInfo.Maintainer = strings.TrimSpace(matches[1])
+// pkgbuildMaintainer returns the first maintainer named in the comments of
+// the PKGBUILD at path, or "".
+func pkgbuildMaintainer(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := maintainerCommentRe.FindStringSubmatch(line); m != nil {
+			return strings.TrimSpace(m[1])
 		}
 	}
+	return ""
+}
 
-	// Calculate derived metrics
-	if !repoInfo.FirstCommit.IsZero() {
-		repoInfo.RepoAge = time.Since(repoInfo.FirstCommit)
-		if repoInfo.RepoAge.Hours() > 0 {
-			monthsAge := repoInfo.RepoAge.Hours() / (24 * 30)
-			repoInfo.CommitFrequency = float64(repoInfo.CommitCount) / monthsAge
+// cloneAttempts is how many times a clone is tried, so one network blip
+// doesn't fail the trust analysis.
+const cloneAttempts = 2
+
+// cloneRetryDelay is the pause before retrying a failed clone.
+var cloneRetryDelay = 2 * time.Second
+
+// cloneRepository clones gitURL into dest, clearing whatever an earlier
+// attempt left there first, and retries once unless ctx was cancelled. On
+// failure nothing is left at dest.
+func cloneRepository(ctx context.Context, gitURL, dest string) error {
+	var err error
+	for attempt := 1; attempt <= cloneAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(cloneRetryDelay):
+			}
+		}
+		if rmErr := os.RemoveAll(dest); rmErr != nil {
+			return fmt.Errorf("failed to clear %s: %w", dest, rmErr)
 		}
-	}
 
-	return repoInfo, nil
+		output, runErr := exec.CommandContext(ctx, "git", "clone", "--quiet", gitURL, dest).CombinedOutput()
+		if runErr == nil {
+			return nil
+		}
+		err = fmt.Errorf("%w: %s", runErr, strings.TrimSpace(string(output)))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	os.RemoveAll(dest)
+	return err
 }
 
 // getMaintainerReputation calculates maintainer reputation (stub implementation)
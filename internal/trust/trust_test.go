@@ -0,0 +1,139 @@
+package trust
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+// bareRepo creates a local repository with one commit to clone from.
+func bareRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	src := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet", src},
+		{"-C", src, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--quiet", "--allow-empty", "-m", "init"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	return src
+}
+
+func TestCloneRepositoryReplacesPartialClone(t *testing.T) {
+	src := bareRepo(t)
+	dest := filepath.Join(t.TempDir(), "repo")
+
+	// What an interrupted clone leaves behind: git refuses a non-empty target
+	if err := os.MkdirAll(filepath.Join(dest, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dest, "leftover"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cloneRepository(context.Background(), src, dest); err != nil {
+		t.Fatalf("clone over a partial clone failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "leftover")); !os.IsNotExist(err) {
+		t.Errorf("leftover file survived the clone (err = %v)", err)
+	}
+}
+
+func TestCloneRepositoryCleansUpOnFailure(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	defer func(delay time.Duration) { cloneRetryDelay = delay }(cloneRetryDelay)
+	cloneRetryDelay = 0
+
+	dest := filepath.Join(t.TempDir(), "repo")
+	if err := cloneRepository(context.Background(), filepath.Join(t.TempDir(), "missing"), dest); err == nil {
+		t.Fatal("clone of a missing repository succeeded")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("failed clone left %s behind (err = %v)", dest, err)
+	}
+}
+
+// historyRepo creates a repository with commits by two authors, a day apart,
+// and a PKGBUILD naming its maintainer.
+func historyRepo(t testing.TB, commits int) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	pkgbuild := "# Maintainer: Jane Doe <jane@example.com>\n# Contributor: Old Hand\npkgname=foo\n"
+	if err := os.WriteFile(filepath.Join(dir, "PKGBUILD"), []byte(pkgbuild), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run := func(env []string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+	run(nil, "init", "--quiet")
+	for i := 0; i < commits; i++ {
+		author := []string{"alice", "bob"}[i%2]
+		date := fmt.Sprintf("@%d +0000", 1700000000+i*86400)
+		run([]string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date},
+			"-c", "user.name="+author, "-c", "user.email="+author+"@example.com",
+			"commit", "--quiet", "--allow-empty", "-m", fmt.Sprintf("commit %d", i))
+	}
+	return dir
+}
+
+func TestReadRepositoryHistory(t *testing.T) {
+	dir := historyRepo(t, 3)
+
+	var info RepositoryInfo
+	start := time.Now()
+	if err := readRepositoryHistory(context.Background(), dir, &info); err != nil {
+		t.Fatalf("readRepositoryHistory: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("reading the history of 3 commits took %v", elapsed)
+	}
+
+	if info.CommitCount != 3 {
+		t.Errorf("CommitCount = %d, want 3", info.CommitCount)
+	}
+	if got := info.FirstCommit.Unix(); got != 1700000000 {
+		t.Errorf("FirstCommit = %d, want 1700000000", got)
+	}
+	if got := info.LastCommit.Unix(); got != 1700000000+2*86400 {
+		t.Errorf("LastCommit = %d, want %d", got, 1700000000+2*86400)
+	}
+	sort.Strings(info.Contributors)
+	if !slices.Equal(info.Contributors, []string{"alice", "bob"}) {
+		t.Errorf("Contributors = %q, want [alice bob]", info.Contributors)
+	}
+	if got := pkgbuildMaintainer(filepath.Join(dir, "PKGBUILD")); got != "Jane Doe <jane@example.com>" {
+		t.Errorf("pkgbuildMaintainer = %q", got)
+	}
+}
+
+func BenchmarkReadRepositoryHistory(b *testing.B) {
+	dir := historyRepo(b, 50)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var info RepositoryInfo
+		if err := readRepositoryHistory(ctx, dir, &info); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
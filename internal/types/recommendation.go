@@ -0,0 +1,94 @@
+package types
+
+import "strings"
+
+// Recommendation is the provider's verdict on a package.
+type Recommendation string
+
+const (
+	RecommendProceed Recommendation = "PROCEED"
+	RecommendReview  Recommendation = "REVIEW"
+	RecommendBlock   Recommendation = "BLOCK"
+)
+
+// recommendationWords maps the words models use for a verdict to it.
+var recommendationWords = map[string]Recommendation{
+	"PROCEED": RecommendProceed, "INSTALL": RecommendProceed, "APPROVE": RecommendProceed,
+	"APPROVED": RecommendProceed, "SAFE": RecommendProceed, "OK": RecommendProceed,
+	"ALLOW": RecommendProceed, "ACCEPT": RecommendProceed,
+	"REVIEW": RecommendReview, "CAUTION": RecommendReview, "WARN": RecommendReview,
+	"WARNING": RecommendReview, "INSPECT": RecommendReview, "VERIFY": RecommendReview,
+	"MANUAL": RecommendReview,
+	"BLOCK":  RecommendBlock, "BLOCKED": RecommendBlock, "REJECT": RecommendBlock,
+	"DENY": RecommendBlock, "AVOID": RecommendBlock, "ABORT": RecommendBlock,
+	"MALICIOUS": RecommendBlock, "DANGEROUS": RecommendBlock, "UNSAFE": RecommendBlock,
+	"INSECURE": RecommendBlock,
+}
+
+// negations turn a later PROCEED word in the same clause into BLOCK ("do
+// not install", "not recommended to install").
+var negations = map[string]bool{"NOT": true, "DONT": true, "DON'T": true, "NEVER": true}
+
+// negatedAdvice are words that, negated, advise against installing on their
+// own ("Not recommended").
+var negatedAdvice = map[string]bool{"RECOMMENDED": true, "ADVISED": true, "ADVISABLE": true}
+
+// isClauseBoundary reports whether r ends a clause, and with it a negation.
+func isClauseBoundary(r rune) bool {
+	return strings.ContainsRune(";,.:!?\n", r)
+}
+
+// ParseRecommendation reads the verdict from recommendation text: the first
+// word that names one, case-insensitively, so "Review - downloads at build
+// time" and "proceed." parse, as do variants such as "approve", "caution",
+// "unsafe" or "reject". A negation reaches to the end of its clause: a PROCEED
+// word after it ("Do not install", "not recommended to install") reads as
+// BLOCK, as does a negated "recommended" or "advised", but "Not a concern;
+// safe to install" reads as PROCEED. ok is false when the text names no
+// verdict.
+func ParseRecommendation(text string) (Recommendation, bool) {
+	for _, clause := range strings.FieldsFunc(strings.ToUpper(text), isClauseBoundary) {
+		words := strings.FieldsFunc(clause, func(r rune) bool {
+			return (r < 'A' || r > 'Z') && r != '\''
+		})
+		negated := false
+		for _, word := range words {
+			word = strings.Trim(word, "'")
+			if negations[word] {
+				negated = true
+				continue
+			}
+			if negated && negatedAdvice[word] {
+				return RecommendBlock, true
+			}
+			recommendation, ok := recommendationWords[word]
+			if !ok {
+				continue
+			}
+			if negated && recommendation == RecommendProceed {
+				return RecommendBlock, true
+			}
+			return recommendation, true
+		}
+	}
+	return "", false
+}
+
+// NormalizeRecommendation replaces a free-text Recommendation, as the model
+// writes it or as entries cached before normalization hold it, with its
+// verdict, keeping the original wording in RecommendationText when it says
+// more than the verdict. Text that names no verdict is moved to
+// RecommendationText, leaving Recommendation empty. It reports false only
+// when there is wording that names no verdict.
+func (a *SecurityAnalysis) NormalizeRecommendation() bool {
+	text := strings.TrimSpace(string(a.Recommendation))
+	if text == "" {
+		return a.RecommendationText == ""
+	}
+	recommendation, ok := ParseRecommendation(text)
+	a.Recommendation = recommendation
+	if !ok || !strings.EqualFold(strings.Trim(text, ".! "), string(recommendation)) {
+		a.RecommendationText = text
+	}
+	return ok
+}
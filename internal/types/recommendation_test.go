@@ -0,0 +1,62 @@
+package types
+
+import "testing"
+
+func TestParseRecommendation(t *testing.T) {
+	tests := []struct {
+		text string
+		want Recommendation
+		ok   bool
+	}{
+		{"PROCEED", RecommendProceed, true},
+		{"proceed.", RecommendProceed, true},
+		{"Review - downloads at build time", RecommendReview, true},
+		{"  block  ", RecommendBlock, true},
+		{"Safe to install", RecommendProceed, true},
+		{"Proceed with caution", RecommendProceed, true},
+		{"Caution: review the sources first", RecommendReview, true},
+		{"Reject this package", RecommendBlock, true},
+		{"Do NOT install", RecommendBlock, true},
+		{"Don't proceed", RecommendBlock, true},
+		{"not safe", RecommendBlock, true},
+		{"Not a concern; safe to install", RecommendProceed, true},
+		{"Not recommended to install", RecommendBlock, true},
+		{"Not recommended", RecommendBlock, true},
+		{"Unsafe to install", RecommendBlock, true},
+		{"Insecure; do not install", RecommendBlock, true},
+		{"Never install this package", RecommendBlock, true},
+		{"The package looks fine overall", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRecommendation(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRecommendation(%q) = %q, %v; want %q, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeRecommendation(t *testing.T) {
+	bare := SecurityAnalysis{Recommendation: "proceed."}
+	if !bare.NormalizeRecommendation() || bare.Recommendation != RecommendProceed || bare.RecommendationText != "" {
+		t.Errorf("bare = %q / %q", bare.Recommendation, bare.RecommendationText)
+	}
+
+	canonical := SecurityAnalysis{Recommendation: RecommendBlock}
+	if !canonical.NormalizeRecommendation() || canonical.Recommendation != RecommendBlock || canonical.RecommendationText != "" {
+		t.Errorf("canonical = %q / %q", canonical.Recommendation, canonical.RecommendationText)
+	}
+
+	// Normalizing again, as a cache load does, changes nothing
+	explained := SecurityAnalysis{Recommendation: "REVIEW - downloads at build time"}
+	explained.NormalizeRecommendation()
+	explained.NormalizeRecommendation()
+	if explained.Recommendation != RecommendReview || explained.RecommendationText != "REVIEW - downloads at build time" {
+		t.Errorf("explained = %q / %q", explained.Recommendation, explained.RecommendationText)
+	}
+
+	unknown := SecurityAnalysis{Recommendation: "looks fine"}
+	if unknown.NormalizeRecommendation() || unknown.Recommendation != "" || unknown.RecommendationText != "looks fine" {
+		t.Errorf("unknown = %q / %q", unknown.Recommendation, unknown.RecommendationText)
+	}
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -59,40 +60,80 @@ func (s *SecurityLevel) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 // SecurityFinding represents a specific security issue found
 type SecurityFinding struct {
-	Type         string          `json:"type"`
-	Entropy      SecurityEntropy `json:"entropy"`      // How much uncertainty this adds
-	Severity     SecurityLevel   `json:"severity"`     // Legacy field for compatibility
-	Description  string          `json:"description"`
-	LineNumber   int             `json:"line_number,omitempty"`
-	Context      string          `json:"context,omitempty"`
-	Suggestion   string          `json:"suggestion,omitempty"`
-	EntropyNotes string          `json:"entropy_notes,omitempty"` // Why this contributes to entropy
+	Type         string          `json:"type" yaml:"type"`
+	Entropy      SecurityEntropy `json:"entropy" yaml:"entropy"`      // How much uncertainty this adds
+	Severity     SecurityLevel   `json:"severity" yaml:"severity"`     // Legacy field for compatibility
+	Description  string          `json:"description" yaml:"description"`
+	LineNumber   int             `json:"line_number,omitempty" yaml:"line_number,omitempty"`
+	Context      string          `json:"context,omitempty" yaml:"context,omitempty"`
+	Suggestion   string          `json:"suggestion,omitempty" yaml:"suggestion,omitempty"`
+	EntropyNotes string          `json:"entropy_notes,omitempty" yaml:"entropy_notes,omitempty"` // Why this contributes to entropy
+	Fingerprint  string          `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`     // Stable identity across runs; see ComputeFingerprint
+	AsRoot       bool            `json:"as_root,omitempty" yaml:"as_root,omitempty"`             // In an install hook, which pacman runs as root
+	RaisedByPolicy bool          `json:"raised_by_policy,omitempty" yaml:"raised_by_policy,omitempty"` // Entropy raised to a security.type_floors floor
 }
 
 // SecurityAnalysis represents the complete security analysis of a PKGBUILD
 type SecurityAnalysis struct {
-	PackageName         string            `json:"package_name"`
-	OverallEntropy      SecurityEntropy   `json:"overall_entropy"`    // Primary entropy assessment
-	OverallLevel        SecurityLevel     `json:"overall_level"`      // Legacy compatibility
-	Findings            []SecurityFinding `json:"findings"`
-	Summary             string            `json:"summary"`
-	Recommendation      string            `json:"recommendation"`
-	AnalyzedAt          time.Time         `json:"analyzed_at"`
-	Provider            string            `json:"provider"`
-	EntropyFactors      []string          `json:"entropy_factors,omitempty"`      // What contributed to entropy
-	PredictabilityScore float64           `json:"predictability_score,omitempty"` // 0.0 (chaotic) to 1.0 (predictable)
-	EducationalSummary  string            `json:"educational_summary,omitempty"`  // Educational context for users
-	SecurityLessons     []string          `json:"security_lessons,omitempty"`     // Key takeaways for learning
+	PackageName         string            `json:"package_name" yaml:"package_name"`
+	PackageVersion      string            `json:"package_version,omitempty" yaml:"package_version,omitempty"` // Version analyzed, for comparing against later runs
+	Maintainer          string            `json:"maintainer,omitempty" yaml:"maintainer,omitempty"`      // Maintainer at analysis time
+	OverallEntropy      SecurityEntropy   `json:"overall_entropy" yaml:"overall_entropy"`    // Primary entropy assessment
+	OverallLevel        SecurityLevel     `json:"overall_level" yaml:"overall_level"`      // Legacy compatibility
+	Findings            []SecurityFinding `json:"findings" yaml:"findings"`
+	Summary             string            `json:"summary" yaml:"summary"`
+	Recommendation      Recommendation    `json:"recommendation" yaml:"recommendation"` // PROCEED, REVIEW or BLOCK; empty when the model named none
+	RecommendationText  string            `json:"recommendation_text,omitempty" yaml:"recommendation_text,omitempty"` // The model's wording, when it says more than the verdict
+	AnalyzedAt          time.Time         `json:"analyzed_at" yaml:"analyzed_at"`
+	Provider            string            `json:"provider" yaml:"provider"`
+	EntropyFactors      []string          `json:"entropy_factors,omitempty" yaml:"entropy_factors,omitempty"`      // What contributed to entropy
+	PredictabilityScore float64           `json:"predictability_score,omitempty" yaml:"predictability_score,omitempty"` // 0.0 (chaotic) to 1.0 (predictable)
+	RiskScore           float64           `json:"risk_score" yaml:"risk_score"`                   // 0 (low risk) to 100, within the overall level's band of 20
+	EducationalSummary  string            `json:"educational_summary,omitempty" yaml:"educational_summary,omitempty"`  // Educational context for users
+	SecurityLessons     []string          `json:"security_lessons,omitempty" yaml:"security_lessons,omitempty"`     // Key takeaways for learning
+	InstallScript       *InstallScriptRisk `json:"install_script,omitempty" yaml:"install_script,omitempty"`      // Separate verdict for the .install script, when there is one
+	DurationSeconds     float64           `json:"duration_seconds,omitempty" yaml:"duration_seconds,omitempty"` // How long the provider call took
+	PromptChars         int               `json:"prompt_chars,omitempty" yaml:"prompt_chars,omitempty"`         // Size of the prompt sent to the provider
+	PKGBUILDLines       int               `json:"pkgbuild_lines,omitempty" yaml:"pkgbuild_lines,omitempty"`     // Lines in the PKGBUILD, set when some were left out of the prompt
+	OmittedLines        int               `json:"omitted_lines,omitempty" yaml:"omitted_lines,omitempty"`       // PKGBUILD lines the context-lines limit left out of the prompt
+	Context             *ContextCompleteness `json:"context,omitempty" yaml:"context,omitempty"`           // Which inputs the analysis had; nil for local files and older cache entries
+	WeakenedChecks      []string          `json:"weakened_checks,omitempty" yaml:"weakened_checks,omitempty"` // Install options (and MAKEFLAGS) that skip checks the analysis assumes, one "option: effect" each
+	NewSinceCommit      string            `json:"new_since_commit,omitempty" yaml:"new_since_commit,omitempty"` // With --only-new-findings, the commit of the earlier analysis the findings are new against
+}
+
+// InstallScriptRisk is the verdict for a package's .install script on its
+// own. Its hooks run as root on the user's system, so it is shown apart from
+// the PKGBUILD's overall level and can trigger the warn/block decision alone.
+type InstallScriptRisk struct {
+	Level   SecurityLevel `json:"level" yaml:"level"`
+	Reasons []string      `json:"reasons,omitempty" yaml:"reasons,omitempty"` // One line per rule finding that set the level
+}
+
+// Truncated reports whether the provider saw only part of the PKGBUILD.
+func (a *SecurityAnalysis) Truncated() bool {
+	return a.OmittedLines > 0
+}
+
+// DecisionLevel returns the level the warn/block thresholds apply to: the
+// overall level, or the install script's when that is higher.
+func (a *SecurityAnalysis) DecisionLevel() SecurityLevel {
+	if a.InstallScript != nil && a.InstallScript.Level > a.OverallLevel {
+		return a.InstallScript.Level
+	}
+	return a.OverallLevel
 }
 
 // PackageInfo represents basic package information
 type PackageInfo struct {
 	Name        string `json:"name"`
+	PackageBase string `json:"package_base,omitempty"` // AUR pkgbase; split packages share one
 	Version     string `json:"version"`
+	DynamicVersion bool `json:"dynamic_version,omitempty"` // a pkgver() function sets the version at build time; Version is only the last recorded one
 	Description string `json:"description"`
 	URL         string `json:"url"`
 	Maintainer  string `json:"maintainer"`
 	PKGBUILD    string `json:"pkgbuild"`
+	Sources     []string `json:"sources,omitempty"` // source=() entries, all architectures, as written
 	CommitHash  string `json:"commit_hash"` // AUR git commit hash for caching
 	// AUR page context
 	AURPageURL       string   `json:"aur_page_url,omitempty"`
@@ -104,9 +145,44 @@ type PackageInfo struct {
 	Dependencies     []string `json:"dependencies,omitempty"`
 	MakeDepends      []string `json:"make_depends,omitempty"`
 	OptDepends       []string `json:"opt_depends,omitempty"`
+	License          []string `json:"license,omitempty"`  // as the AUR metadata lists it
+	Keywords         []string `json:"keywords,omitempty"` // AUR search keywords
 	// Additional files for analysis
 	InstallScript   string            `json:"install_script,omitempty"`
 	AdditionalFiles map[string]string `json:"additional_files,omitempty"` // filename -> content
+	// AUR git history, newest first (only when trust.include_git_log is set)
+	RecentCommits []GitCommit `json:"recent_commits,omitempty"`
+}
+
+// GitCommit is one commit of a package's AUR git repository.
+type GitCommit struct {
+	Hash    string    `json:"hash"`
+	Date    time.Time `json:"date"`
+	Author  string    `json:"author"`
+	Subject string    `json:"subject"`
+}
+
+// Base returns the AUR package base the package is built from, falling back to
+// its name when the base is unknown (not an AUR package, or not yet enriched).
+// Split packages share one PKGBUILD and one git repository under their base.
+func (p PackageInfo) Base() string {
+	if p.PackageBase != "" {
+		return p.PackageBase
+	}
+	return p.Name
+}
+
+// VersionLabel describes the version for display and prompts. A version that
+// pkgver() computes at build time is labeled as such, since the pkgver= in
+// the PKGBUILD is only the last one recorded and may be stale or empty.
+func (p PackageInfo) VersionLabel() string {
+	if !p.DynamicVersion {
+		return p.Version
+	}
+	if p.Version == "" {
+		return "dynamic (computed by pkgver() at build time)"
+	}
+	return fmt.Sprintf("dynamic (computed by pkgver() at build time; last recorded %s)", p.Version)
 }
 
 // AIProvider interface for different AI backends
@@ -115,9 +191,16 @@ type AIProvider interface {
 	Authenticate(ctx context.Context) error
 	IsAuthenticated() bool
 	AnalyzePKGBUILD(ctx context.Context, pkgInfo PackageInfo) (*SecurityAnalysis, error)
+	AnalyzePKGBUILDWithOptions(ctx context.Context, pkgInfo PackageInfo, opts AnalysisOptions) (*SecurityAnalysis, error)
 	GetCapabilities() ProviderCapabilities
 }
 
+// AnalysisOptions tunes a single analysis run. The zero value is the default
+// behavior, so AnalyzePKGBUILD is AnalyzePKGBUILDWithOptions with no options.
+type AnalysisOptions struct {
+	NoSpinner bool // disable progress animations (scripts/automation)
+}
+
 // ProviderCapabilities describes what a provider can do
 type ProviderCapabilities struct {
 	SupportsCodeAnalysis bool
@@ -130,10 +213,22 @@ type ProviderCapabilities struct {
 type Config struct {
 	DefaultProvider string            `yaml:"default_provider"`
 	Providers       map[string]string `yaml:"providers"` // provider_name -> config_path
+	Analysis struct {
+		Profile         string `yaml:"profile"`          // strict, balanced or lenient; explicit keys override it
+		DependencyDepth int    `yaml:"dependency_depth"` // levels of AUR dependencies analyzed on install (0 = off)
+	} `yaml:"analysis"`
+	Security struct {
+		AllowSkip    bool                     `yaml:"allow_skip"`    // false refuses --skip-analysis, making analysis mandatory
+		TypeFloors   map[string]SecurityLevel `yaml:"type_floors"`   // finding type -> minimum level, whatever the model rated it
+		TrustedRepos []string                 `yaml:"trusted_repos"` // sync repositories whose packages skip analysis
+	} `yaml:"security"`
 	SecurityThresholds struct {
 		BlockLevel    SecurityLevel `yaml:"block_level"`
 		WarnLevel     SecurityLevel `yaml:"warn_level"`
 		AutoProceed   bool          `yaml:"auto_proceed_safe"`
+		MinVotes      int           `yaml:"min_votes"`      // AUR votes below this add a finding (0 = off)
+		MinPopularity float64       `yaml:"min_popularity"` // AUR popularity below this adds a finding (0 = off)
+		BlockOnMaintainerChange bool `yaml:"block_on_maintainer_change"` // block when the maintainer differs from the last cached analysis
 	} `yaml:"security_thresholds"`
 	Cache struct {
 		Enabled      bool `yaml:"enabled"`
@@ -143,19 +238,59 @@ type Config struct {
 	} `yaml:"cache"`
 	Prompts struct {
 		SecurityAnalysis string `yaml:"security_analysis"`
+		MaxPKGBUILDLines int    `yaml:"max_pkgbuild_lines"` // 0 = send the whole PKGBUILD
 	} `yaml:"prompts"`
 	UI struct {
-		ShowDetails   bool `yaml:"show_details"`
-		UseColors     bool `yaml:"use_colors"`
-		VerboseOutput bool `yaml:"verbose_output"`
+		ShowDetails   bool   `yaml:"show_details"`
+		UseColors     bool   `yaml:"use_colors"`
+		VerboseOutput bool   `yaml:"verbose_output"`
+		ShowEducation bool   `yaml:"show_education"` // show the educational summary and lessons
+		UseIcons      bool   `yaml:"use_icons"`      // emoji status icons; false prints ASCII labels
+		Locale        string `yaml:"locale"`         // message catalog; empty follows LC_ALL/LC_MESSAGES/LANG
 	} `yaml:"ui"`
 	Yay struct {
 		Path  string   `yaml:"path"`
 		Flags []string `yaml:"default_flags"`
 	} `yaml:"yay"`
 	Claude struct {
-		Model string `yaml:"model"` // model alias passed to `claude --model` (e.g. "sonnet", "opus")
+		Model string   `yaml:"model"` // model alias passed to `claude --model` (e.g. "sonnet", "opus")
+		Path  string   `yaml:"path"`  // claude executable; empty = search PATH and the usual install locations
+		Args  []string `yaml:"args"`  // extra arguments appended to every claude invocation
+		// StreamPartial asks claude for partial message chunks while streaming
+		// (--include-partial-messages), so progress counts findings as they arrive
+		StreamPartial bool `yaml:"stream_partial"`
 	} `yaml:"claude"`
+	Scanner struct {
+		ObfuscationEntropy   float64 `yaml:"obfuscation_entropy"`    // bits/char at which a string literal reads as obfuscated
+		ObfuscationMinLength int     `yaml:"obfuscation_min_length"` // shorter literals are not measured
+		SuspiciousCommands   []SuspiciousCommand `yaml:"suspicious_commands"` // commands flagged in function bodies
+		RulesDir             string  `yaml:"rules_dir"`              // directory of user rule files (YAML/JSON); empty = none
+	} `yaml:"scanner"`
+	Network struct {
+		CACert string `yaml:"ca_cert"` // PEM file of extra root CAs, e.g. for a TLS-intercepting proxy
+	} `yaml:"network"`
+	AUR struct {
+		BaseURL string `yaml:"base_url"` // AUR web root; --url snapshots must be served from it
+	} `yaml:"aur"`
+	Trust struct {
+		KeepClone     bool `yaml:"keep_clone"`      // retain the AUR clone of HIGH/CRITICAL packages for inspection
+		IncludeGitLog bool `yaml:"include_git_log"` // add recent AUR commits to the analysis context
+		GitLogCommits int  `yaml:"git_log_commits"` // how many recent commits to include
+	} `yaml:"trust"`
+	Hooks struct {
+		PostAnalysis string `yaml:"post_analysis"` // sh command run after each analysis, with its JSON on stdin
+	} `yaml:"hooks"`
+	Namcap struct {
+		Enabled bool   `yaml:"enabled"` // add namcap's packaging errors and warnings to each analysis as MINIMAL findings
+		Path    string `yaml:"path"`    // namcap executable; empty = search PATH
+	} `yaml:"namcap"`
+}
+
+// SuspiciousCommand is a command the pre-scan flags wherever a function body
+// runs it, at the given level. Command may be several words ("python -c").
+type SuspiciousCommand struct {
+	Command string          `yaml:"command"`
+	Level   SecurityEntropy `yaml:"level"`
 }
 
 // YayOperation represents the operation to perform with yay
@@ -163,5 +298,5 @@ type YayOperation struct {
 	Command   string   `json:"command"`
 	Packages  []string `json:"packages"`
 	Flags     []string `json:"flags"`
-	Operation string   `json:"operation"` // install, upgrade, remove, etc.
+	Operation string   `json:"operation"` // install, analyze, query, remove, upgrade, files, database or other
 }
\ No newline at end of file
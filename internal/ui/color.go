@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gookit/color"
+	"golang.org/x/term"
+)
+
+// Color modes accepted by --color.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// SetColor decides whether all subsequent output is colored; it is the one
+// place that decision is made. ColorAlways and ColorNever are final, overriding
+// NO_COLOR and ui.use_colors (useColors). ColorAuto, the default, colors only
+// when useColors is set, NO_COLOR is unset and stdout is a terminal. It
+// reports whether color is on.
+func SetColor(mode string, useColors bool) (bool, error) {
+	noColor := os.Getenv("NO_COLOR") != ""
+	enabled, err := colorEnabled(mode, useColors, noColor, term.IsTerminal(int(os.Stdout.Fd())))
+	if err != nil {
+		return false, err
+	}
+	if !enabled {
+		color.Disable()
+		return false, nil
+	}
+	color.Enable = true
+	if mode == ColorAlways {
+		color.ForceOpenColor()
+	}
+	return true, nil
+}
+
+// colorEnabled is the decision behind SetColor, given the environment.
+func colorEnabled(mode string, useColors, noColor, terminal bool) (bool, error) {
+	switch mode {
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	case ColorAuto, "":
+		return useColors && !noColor && terminal, nil
+	}
+	return false, fmt.Errorf("invalid --color %q: use %s, %s or %s", mode, ColorAuto, ColorAlways, ColorNever)
+}
@@ -0,0 +1,33 @@
+package ui
+
+import "testing"
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		mode                         string
+		useColors, noColor, terminal bool
+		want                         bool
+	}{
+		{ColorAuto, true, false, true, true},
+		{ColorAuto, true, false, false, false},
+		{ColorAuto, true, true, true, false},
+		{ColorAuto, false, false, true, false},
+		{"", true, false, true, true},
+		{ColorAlways, false, true, false, true},
+		{ColorNever, true, false, true, false},
+	}
+	for _, tt := range tests {
+		got, err := colorEnabled(tt.mode, tt.useColors, tt.noColor, tt.terminal)
+		if err != nil {
+			t.Fatalf("colorEnabled(%q): %v", tt.mode, err)
+		}
+		if got != tt.want {
+			t.Errorf("colorEnabled(%q, useColors=%v, noColor=%v, terminal=%v) = %v, want %v",
+				tt.mode, tt.useColors, tt.noColor, tt.terminal, got, tt.want)
+		}
+	}
+
+	if _, err := colorEnabled("sometimes", true, false, true); err == nil {
+		t.Error("colorEnabled accepted an invalid mode")
+	}
+}
@@ -0,0 +1,83 @@
+// Package ui holds presentation helpers shared by the command-line output.
+package ui
+
+// Icon is a status marker printed at the start of an output line. It prints
+// as an emoji, or as a plain ASCII label when icons are turned off
+// (ui.use_icons: false or --no-icons) for screen readers and terminals that
+// render emoji poorly. Print icons with %s rather than embedding emoji in
+// format strings, so the setting applies everywhere.
+type Icon int
+
+const (
+	OK Icon = iota
+	Fail
+	Warn
+	Info
+	Tip
+	Search
+	Cached
+	Fresh
+	Clean
+	Delete
+	Folder
+	History
+	Send
+	Blocked
+	Note
+	Timer
+
+	// Security levels, as shown next to an analysis verdict.
+	LevelSafe
+	LevelModerate
+	LevelHigh
+	LevelCritical
+	LevelUnknown
+)
+
+// icons maps each Icon to its emoji and its ASCII label. Emoji written with a
+// variation selector (⚠️, ℹ️, 🗑️) render one column narrower than they are
+// wide in most terminals, so they carry an extra space to keep text aligned.
+var icons = map[Icon]struct{ emoji, label string }{
+	OK:      {"✅", "[OK]"},
+	Fail:    {"❌", "[FAIL]"},
+	Warn:    {"⚠️ ", "[WARN]"},
+	Info:    {"ℹ️ ", "[INFO]"},
+	Tip:     {"💡", "[TIP]"},
+	Search:  {"🔍", "[SCAN]"},
+	Cached:  {"📋", "[CACHE]"},
+	Fresh:   {"🤖", "[AI]"},
+	Clean:   {"🧹", "[CLEAN]"},
+	Delete:  {"🗑️ ", "[DELETE]"},
+	Folder:  {"📂", "[DIR]"},
+	History: {"📜", "[HISTORY]"},
+	Send:    {"📡", "[SEND]"},
+	Blocked: {"🚫", "[BLOCKED]"},
+	Note:    {"📝", "[NOTE]"},
+	Timer:   {"⏱️ ", "[TIME]"},
+
+	LevelSafe:     {"🟢", "[OK]"},
+	LevelModerate: {"🟡", "[WARN]"},
+	LevelHigh:     {"🔴", "[HIGH]"},
+	LevelCritical: {"🔴", "[CRIT]"},
+	LevelUnknown:  {"❓", "[?]"},
+}
+
+// useIcons selects emoji (true) or ASCII labels (false).
+var useIcons = true
+
+// SetIcons turns emoji icons on or off for all subsequent output.
+func SetIcons(enabled bool) {
+	useIcons = enabled
+}
+
+// String returns the emoji or the ASCII label, depending on SetIcons.
+func (i Icon) String() string {
+	icon, ok := icons[i]
+	if !ok {
+		return ""
+	}
+	if useIcons {
+		return icon.emoji
+	}
+	return icon.label
+}
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIconsSwitchToASCII(t *testing.T) {
+	defer SetIcons(true)
+
+	if got := fmt.Sprintf("%s done", OK); got != "✅ done" {
+		t.Errorf("with icons = %q, want emoji", got)
+	}
+
+	SetIcons(false)
+	if got := fmt.Sprintf("%s done", OK); got != "[OK] done" {
+		t.Errorf("without icons = %q, want ASCII label", got)
+	}
+	for i := OK; i <= LevelUnknown; i++ {
+		label := i.String()
+		if label == "" {
+			t.Errorf("icon %d has no label", i)
+		}
+		for _, r := range label {
+			if r > 127 {
+				t.Errorf("icon %d label %q is not ASCII", i, label)
+				break
+			}
+		}
+	}
+}
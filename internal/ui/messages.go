@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Message identifies a user-facing string in the message catalog. Output
+// that goes through T can be translated by adding a catalog for a locale;
+// anything a catalog lacks falls back to English. Message text is a format
+// string for the arguments T is given.
+type Message string
+
+// Analysis result display.
+const (
+	MsgResultsTitle          Message = "results.title"
+	MsgResultsFor            Message = "results.for"
+	MsgProvider              Message = "results.provider"
+	MsgAnalyzedAt            Message = "results.analyzed_at"
+	MsgSecurityEntropy       Message = "results.security_entropy"
+	MsgOverallLevel          Message = "results.overall_level"
+	MsgPredictability        Message = "results.predictability"
+	MsgRiskFactors           Message = "results.risk_factors"
+	MsgContext               Message = "results.context"
+	MsgContextMissing        Message = "results.context_missing"
+	MsgSummaryLabel          Message = "results.summary_label"
+	MsgSummaryHeading        Message = "results.summary_heading"
+	MsgRecommendationLabel   Message = "results.recommendation_label"
+	MsgUnknownRecommendation Message = "results.recommendation_unknown"
+	MsgBlocked               Message = "results.blocked"
+	MsgSecurityWarning       Message = "results.security_warning"
+	MsgBlockRecommended      Message = "results.block_recommended"
+	MsgContinuePrompt        Message = "results.continue_prompt"
+	MsgApproved              Message = "results.approved"
+	MsgDetailedAnalysis      Message = "findings.detailed_analysis"
+	MsgDetailedFindings      Message = "findings.detailed_findings"
+	MsgFindingDescription    Message = "findings.description_label"
+	MsgFindingCode           Message = "findings.code"
+	MsgFindingContext        Message = "findings.context"
+	MsgFindingAction         Message = "findings.action_label"
+	MsgFindingAnalysis       Message = "findings.analysis_label"
+	MsgFindingLine           Message = "findings.line"
+	MsgFindingAsRoot         Message = "findings.as_root"
+	MsgFindingRaisedByPolicy Message = "findings.raised_by_policy"
+	MsgNoFindingsOfType      Message = "findings.none_of_type"
+	MsgNoNewFindings         Message = "findings.none_new"
+	MsgNoFindings            Message = "findings.none"
+)
+
+// english is the built-in catalog, and the fallback for every other one.
+var english = map[Message]string{
+	MsgResultsTitle:          "Security Analysis Results: ",
+	MsgResultsFor:            "Security Analysis for %s",
+	MsgProvider:              "Provider: %s",
+	MsgAnalyzedAt:            "Analyzed: %s",
+	MsgSecurityEntropy:       "Security Entropy: %s %s (risk score %.0f/100)",
+	MsgOverallLevel:          "Overall Level: %s (risk score %.0f/100)",
+	MsgPredictability:        "Predictability Score: %.2f/1.0",
+	MsgRiskFactors:           "Risk Factors: %s",
+	MsgContext:               "Context: %s",
+	MsgContextMissing:        "; missing %s",
+	MsgSummaryLabel:          "Summary: ",
+	MsgSummaryHeading:        "Summary:",
+	MsgRecommendationLabel:   "Recommendation: ",
+	MsgUnknownRecommendation: "%s (names no verdict; the entropy level alone decides)",
+	MsgBlocked:               "BLOCKED: Package security level (%s) exceeds block threshold (%s)",
+	MsgSecurityWarning:       "WARNING: Security concerns detected (%s entropy level)",
+	MsgBlockRecommended:      "WARNING: The analysis recommends BLOCK, though the entropy level is only %s",
+	MsgContinuePrompt:        "Continue with installation? [y/N]: ",
+	MsgApproved:              "%s approved for installation",
+	MsgDetailedAnalysis:      "Detailed Security Analysis:",
+	MsgDetailedFindings:      "Detailed Findings:",
+	MsgFindingDescription:    "   Description: ",
+	MsgFindingCode:           "   Code: %s",
+	MsgFindingContext:        "   Context: %s",
+	MsgFindingAction:         "   Action: ",
+	MsgFindingAnalysis:       "   Analysis: ",
+	MsgFindingLine:           "   Line: %d",
+	MsgFindingAsRoot:         "executes as root",
+	MsgFindingRaisedByPolicy: "raised to %s by policy",
+	MsgNoFindingsOfType:      "No findings of type %s",
+	MsgNoNewFindings:         "No new findings since commit %s",
+	MsgNoFindings:            "No security issues found!",
+}
+
+// DefaultLocale is the locale of the built-in catalog.
+const DefaultLocale = "en"
+
+// catalogs maps a locale (a language such as "de", or a language and
+// territory such as "pt_BR") to its messages.
+var catalogs = map[string]map[Message]string{
+	DefaultLocale: english,
+}
+
+// catalog is the catalog selected by SetLocale.
+var catalog = english
+
+// Locales returns the locales that have a catalog, sorted.
+func Locales() []string {
+	names := make([]string, 0, len(catalogs))
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveLocale returns the catalog locale to use for setting (ui.locale),
+// or, when it is empty, for the environment's LC_ALL, LC_MESSAGES or LANG.
+// A locale such as de_DE.UTF-8 matches a de_DE catalog, else a de one;
+// anything without a catalog, including C and POSIX, resolves to
+// DefaultLocale.
+func ResolveLocale(setting string) string {
+	if setting == "" {
+		for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if setting = os.Getenv(name); setting != "" {
+				break
+			}
+		}
+	}
+	name, _, _ := strings.Cut(setting, ".")
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.ReplaceAll(name, "-", "_")
+	if _, ok := catalogs[name]; ok {
+		return name
+	}
+	language, _, _ := strings.Cut(name, "_")
+	if _, ok := catalogs[strings.ToLower(language)]; ok {
+		return strings.ToLower(language)
+	}
+	return DefaultLocale
+}
+
+// SetLocale selects the catalog for all subsequent output, falling back to
+// English for a locale without one. It returns the locale in effect.
+func SetLocale(setting string) string {
+	locale := ResolveLocale(setting)
+	catalog = catalogs[locale]
+	return locale
+}
+
+// T returns the text of m in the selected locale, formatted with args. A
+// message the locale's catalog lacks is taken from the English one.
+func T(m Message, args ...interface{}) string {
+	text, ok := catalog[m]
+	if !ok {
+		text = english[m]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
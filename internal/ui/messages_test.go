@@ -0,0 +1,42 @@
+package ui
+
+import "testing"
+
+func TestMessagesFallBackToEnglish(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+
+	catalogs["xx"] = map[Message]string{MsgApproved: "%s ok"}
+	defer delete(catalogs, "xx")
+
+	if got := SetLocale("xx_YY.UTF-8"); got != "xx" {
+		t.Fatalf("SetLocale = %q, want the language's catalog", got)
+	}
+	if got := T(MsgApproved, "pkg"); got != "pkg ok" {
+		t.Errorf("translated message = %q", got)
+	}
+	if got := T(MsgNoFindings); got != english[MsgNoFindings] {
+		t.Errorf("untranslated message = %q, want the English text", got)
+	}
+
+	if got := SetLocale("fr_FR.UTF-8"); got != DefaultLocale {
+		t.Errorf("locale without a catalog = %q, want %q", got, DefaultLocale)
+	}
+	if got := T(MsgApproved, "pkg"); got != "pkg approved for installation" {
+		t.Errorf("English message = %q", got)
+	}
+}
+
+func TestResolveLocaleFromEnvironment(t *testing.T) {
+	catalogs["xx"] = english
+	defer delete(catalogs, "xx")
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "xx_YY.UTF-8")
+	t.Setenv("LANG", "C")
+	if got := ResolveLocale(""); got != "xx" {
+		t.Errorf("ResolveLocale from LC_MESSAGES = %q, want xx", got)
+	}
+	if got := ResolveLocale("C"); got != DefaultLocale {
+		t.Errorf("ResolveLocale(C) = %q, want %q", got, DefaultLocale)
+	}
+}
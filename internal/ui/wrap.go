@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// DefaultWidth is the wrapping width when output isn't a terminal and no
+// width was set.
+const DefaultWidth = 80
+
+// width is the wrapping width set by --width; 0 means detect it.
+var width int
+
+// SetWidth fixes the wrapping width for all subsequent output. A width of 0
+// or less goes back to detecting it.
+func SetWidth(columns int) {
+	width = columns
+}
+
+// Width returns the column at which long text wraps: the width set by
+// SetWidth, else the terminal's width, else DefaultWidth.
+func Width() int {
+	if width > 0 {
+		return width
+	}
+	fd := int(os.Stdout.Fd())
+	if term.IsTerminal(fd) {
+		if columns, _, err := term.GetSize(fd); err == nil && columns > 0 {
+			return columns
+		}
+	}
+	return DefaultWidth
+}
+
+// Wrap word-wraps text to Width. The first line starts with prefix and every
+// following line with indent, so a label such as "   Description: " can hang
+// over an indented paragraph. Line breaks already in text are kept; words
+// longer than a line are left whole rather than split.
+func Wrap(prefix, indent, text string) string {
+	limit := Width()
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	col := displayWidth(prefix)
+	lineStart, needIndent := true, false
+	newLine := func() {
+		b.WriteString("\n")
+		col, lineStart, needIndent = 0, true, true
+	}
+
+	for i, paragraph := range strings.Split(text, "\n") {
+		if i > 0 {
+			newLine()
+		}
+		for _, word := range strings.Fields(paragraph) {
+			wordWidth := displayWidth(word)
+			if !lineStart && col+1+wordWidth > limit {
+				newLine()
+			}
+			if needIndent {
+				b.WriteString(indent)
+				col, needIndent = displayWidth(indent), false
+			} else if !lineStart {
+				b.WriteString(" ")
+				col++
+			}
+			b.WriteString(word)
+			col += wordWidth
+			lineStart = false
+		}
+	}
+	return b.String()
+}
+
+// displayWidth approximates the columns s takes up by counting runes.
+func displayWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
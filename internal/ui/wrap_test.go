@@ -0,0 +1,36 @@
+package ui
+
+import "testing"
+
+func TestWrap(t *testing.T) {
+	defer SetWidth(0)
+	SetWidth(20)
+
+	tests := []struct {
+		name, prefix, indent, text, want string
+	}{
+		{"fits", "", "", "short text", "short text"},
+		{"wraps at word", "", "", "the quick brown fox jumps over", "the quick brown fox\njumps over"},
+		{"hanging indent", "Summary: ", "   ", "one two three four five", "Summary: one two\n   three four five"},
+		{"keeps breaks", "", "  ", "first\n\nsecond", "first\n\n  second"},
+		{"long word whole", "", "", "a supercalifragilisticexpialidocious b", "a\nsupercalifragilisticexpialidocious\nb"},
+	}
+	for _, tt := range tests {
+		if got := Wrap(tt.prefix, tt.indent, tt.text); got != tt.want {
+			t.Errorf("%s: Wrap(%q, %q, %q) = %q, want %q", tt.name, tt.prefix, tt.indent, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWidthFallsBack(t *testing.T) {
+	defer SetWidth(0)
+
+	SetWidth(0)
+	if got := Width(); got <= 0 {
+		t.Errorf("Width() = %d, want a positive width", got)
+	}
+	SetWidth(100)
+	if got := Width(); got != 100 {
+		t.Errorf("Width() = %d after SetWidth(100)", got)
+	}
+}
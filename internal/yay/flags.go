@@ -0,0 +1,134 @@
+package yay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aaronsb/yay-friend/internal/types"
+)
+
+// valueFlags are the yay and pacman options that take the next argument as
+// their value, so the value isn't mistaken for a package name.
+var valueFlags = map[string]bool{
+	"--mflags": true, "--gpgflags": true, "--makepkgconf": true,
+	"--overwrite": true, "--assume-installed": true,
+	"--ignore": true, "--ignoregroup": true, "--mode": true,
+}
+
+// weakeningMakepkgFlags are the makepkg options that turn off a check the
+// analysis assumes will run, with what each one skips.
+var weakeningMakepkgFlags = map[string]string{
+	"--skipinteg":     "makepkg skips both checksum and PGP signature verification of the sources",
+	"--skipchecksums": "makepkg skips checksum verification of the sources",
+	"--skippgpcheck":  "makepkg skips PGP signature verification of the sources",
+	"--nocheck":       "makepkg skips the package's check() step",
+}
+
+// WeakenedChecks lists the options of operation that opt out of integrity
+// or safety checks the analysis takes for granted: makepkg flags passed
+// through --mflags (or given directly) that skip checksum or PGP
+// verification, and pacman's --nodeps and --overwrite. Each entry names the
+// option and what it turns off; an empty list means nothing is weakened.
+func WeakenedChecks(operation *types.YayOperation) []string {
+	var weakened []string
+	seen := make(map[string]bool)
+	add := func(flag, effect string) {
+		if !seen[flag] {
+			seen[flag] = true
+			weakened = append(weakened, fmt.Sprintf("%s: %s", flag, effect))
+		}
+	}
+
+	// -Sdd and friends carry pacman's -d in the command's modifiers
+	if !strings.HasPrefix(operation.Command, "--") && len(operation.Command) > 2 && strings.ContainsRune(operation.Command[2:], 'd') {
+		add(operation.Command, "pacman skips dependency version checks")
+	}
+
+	for i := 0; i < len(operation.Flags); i++ {
+		flag, value, hasValue := strings.Cut(operation.Flags[i], "=")
+		if valueFlags[flag] && !hasValue && i+1 < len(operation.Flags) {
+			i++
+			value = operation.Flags[i]
+		}
+
+		switch {
+		case flag == "--mflags":
+			for _, makepkgFlag := range strings.Fields(value) {
+				if effect, ok := weakeningMakepkgFlags[makepkgFlag]; ok {
+					add("--mflags "+makepkgFlag, effect)
+				}
+			}
+		case weakeningMakepkgFlags[flag] != "":
+			add(flag, weakeningMakepkgFlags[flag])
+		case flag == "--nodeps" || (strings.HasPrefix(flag, "-") && !strings.HasPrefix(flag, "--") && strings.ContainsRune(flag[1:], 'd')):
+			add(flag, "pacman skips dependency version checks")
+		case flag == "--overwrite":
+			add("--overwrite "+value, "pacman overwrites files owned by other packages")
+		}
+	}
+	return weakened
+}
+
+// makeflagsEffects are the make options in MAKEFLAGS that change what a
+// build() running make does, with their effect. Parallelism and output
+// options (-j, -l, -s, -w, -O and their long forms) are left alone.
+var makeflagsEffects = map[string]string{
+	"-i": "make ignores failing commands", "--ignore-errors": "make ignores failing commands",
+	"-k": "make keeps building after a target fails", "--keep-going": "make keeps building after a target fails",
+	"-e": "environment variables override the Makefile's", "--environment-overrides": "environment variables override the Makefile's",
+	"-n": "make only prints the commands", "--dry-run": "make only prints the commands",
+	"-t": "make marks targets built without building them", "--touch": "make marks targets built without building them",
+	"-B": "make rebuilds every target", "--always-make": "make rebuilds every target",
+	"-I": "make searches other directories for included Makefiles", "--include-dir": "make searches other directories for included Makefiles",
+	"-f": "make reads a different Makefile", "--file": "make reads a different Makefile",
+	"-C": "make runs in a different directory", "--directory": "make runs in a different directory",
+}
+
+// quietMakeflags are the MAKEFLAGS options that only change parallelism or
+// output.
+var quietMakeflags = map[string]bool{
+	"-j": true, "--jobs": true, "-l": true, "--load-average": true,
+	"-s": true, "--silent": true, "--quiet": true,
+	"-w": true, "--print-directory": true, "--no-print-directory": true,
+	"-O": true, "--output-sync": true,
+}
+
+// makeflagsWithValue are the quiet options whose value may be the next word.
+var makeflagsWithValue = map[string]bool{"-j": true, "--jobs": true, "-l": true, "--load-average": true}
+
+// WeakenedMakeflags lists the options in makeflags, the MAKEFLAGS value
+// makepkg passes on to make, that make a build() differ from what the
+// PKGBUILD shows, in the form WeakenedChecks uses: options such as -i, -e or
+// -k, and variable assignments, which override the Makefile's own.
+// Parallelism and output options don't count.
+func WeakenedMakeflags(makeflags string) []string {
+	var weakened []string
+	words := strings.Fields(makeflags)
+	for i, word := range words {
+		switch {
+		case !strings.HasPrefix(word, "-") && strings.Contains(word, "="):
+			name, _, _ := strings.Cut(word, "=")
+			weakened = append(weakened, fmt.Sprintf("MAKEFLAGS %s: sets the make variable %s over the Makefile's", word, name))
+			continue
+		case !strings.HasPrefix(word, "-"):
+			if i > 0 && makeflagsWithValue[words[i-1]] {
+				continue // the value of -j or -l
+			}
+			word = "-" + word // make's own form: single-letter options without the dash
+		}
+
+		option, _, _ := strings.Cut(word, "=")
+		if !strings.HasPrefix(option, "--") && len(option) > 2 {
+			option = option[:2] // -j8, -Idir
+		}
+		if quietMakeflags[option] {
+			continue
+		}
+		effect, ok := makeflagsEffects[option]
+		if !ok {
+			effect = "a make option the PKGBUILD doesn't set"
+		}
+		weakened = append(weakened, fmt.Sprintf("MAKEFLAGS %s: %s", word, effect))
+	}
+	return weakened
+}
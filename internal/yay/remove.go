@@ -0,0 +1,80 @@
+package yay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PacmanLocalDB is where pacman records installed packages, one directory
+// per package holding its desc file and, when it has one, its install script.
+const PacmanLocalDB = "/var/lib/pacman/local"
+
+// RemovalTargets returns every package a removal command would remove: the
+// named packages plus, for -Rs and friends, the dependencies that go with
+// them. It asks pacman with --print, which changes nothing.
+func RemovalTargets(ctx context.Context, command string, packages []string) ([]string, error) {
+	args := append([]string{command, "--print", "--print-format", "%n"}, packages...)
+	output, err := exec.CommandContext(ctx, "pacman", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("pacman could not resolve the removal: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("pacman could not resolve the removal: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// InstalledInstallScript returns the install script recorded for an
+// installed package in the local database at dbPath, or "" when the package
+// isn't installed or has no script.
+func InstalledInstallScript(dbPath, pkg string) (string, error) {
+	entries, err := os.ReadDir(dbPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pacman database: %w", err)
+	}
+
+	// Entries are named <pkgname>-<pkgver>-<pkgrel>; the prefix alone is
+	// ambiguous (foo vs foo-utils), so confirm against %NAME% in desc.
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), pkg+"-") {
+			continue
+		}
+		dir := filepath.Join(dbPath, entry.Name())
+		if name, err := descName(filepath.Join(dir, "desc")); err != nil || name != pkg {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "install"))
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read install script of %s: %w", pkg, err)
+		}
+		return string(data), nil
+	}
+	return "", nil
+}
+
+// descName reads the %NAME% field of a pacman desc file.
+func descName(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if scanner.Text() == "%NAME%" && scanner.Scan() {
+			return strings.TrimSpace(scanner.Text()), nil
+		}
+	}
+	return "", scanner.Err()
+}
@@ -3,13 +3,17 @@ package yay
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/aaronsb/yay-friend/internal/scanner"
 	"github.com/aaronsb/yay-friend/internal/types"
+	"github.com/aaronsb/yay-friend/internal/ui"
 )
 
 // PackageSearchResult represents a search result from yay
@@ -62,9 +66,11 @@ func (y *YayClient) GetPackageInfo(ctx context.Context, packageName string) (*ty
 
 	// Extract metadata from PKGBUILD
 	info.Version = extractPKGBUILDField(pkgbuild, "pkgver")
+	info.DynamicVersion = scanner.HasPkgverFunction(pkgbuild)
 	info.Description = extractPKGBUILDField(pkgbuild, "pkgdesc")
 	info.URL = extractPKGBUILDField(pkgbuild, "url")
 	info.Maintainer = extractMaintainer(pkgbuild)
+	info.Sources = scanner.ParseSources(pkgbuild)
 
 	return info, nil
 }
@@ -133,7 +139,7 @@ func (y *YayClient) SearchPackages(ctx context.Context, query string) ([]Package
 // InteractiveSearch performs interactive package selection like yay
 func (y *YayClient) InteractiveSearch(ctx context.Context, query string) ([]string, error) {
 	// Let yay handle the interactive search and capture the selection
-	fmt.Printf("🔍 Searching for packages matching '%s'...\n", query)
+	fmt.Printf("%s Searching for packages matching '%s'...\n", ui.Search, query)
 	
 	// Run yay in interactive mode and let it handle selection
 	cmd := exec.CommandContext(ctx, y.yayPath, query)
@@ -167,6 +173,98 @@ func (y *YayClient) CheckDependencies(ctx context.Context, packages []string) er
 	return nil
 }
 
+// RepoPackage reports the sync repository pacman -Si installs pkg from, and
+// whether that repository is one of trusted, whose packages are signed and
+// have no AUR PKGBUILD. pacman installs from the first repository listing a
+// package, so that is the one checked; a repo/name argument is looked up in
+// that repository, and aur/name never matches. Any failure, pacman missing
+// included, reports false, so the package is analyzed as any other.
+func RepoPackage(ctx context.Context, pkg string, trusted []string) (string, bool) {
+	if strings.HasPrefix(pkg, "aur/") {
+		return "", false
+	}
+	cmd := exec.CommandContext(ctx, "pacman", "-Si", "--", pkg)
+	cmd.Env = append(os.Environ(), "LC_ALL=C") // untranslated field names
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	repo := infoField(string(output), "Repository")
+	return repo, repo != "" && slices.Contains(trusted, repo)
+}
+
+// infoField returns the first value of field in pacman -Si/-Qi output.
+func infoField(output, field string) string {
+	for _, line := range strings.Split(output, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(name) == field {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// ForcesAUR reports whether operation makes yay take packages from the AUR
+// even when a sync repository has one of the same name: --aur, -a, or
+// --mode aur/a.
+func ForcesAUR(operation *types.YayOperation) bool {
+	if !strings.HasPrefix(operation.Command, "--") && len(operation.Command) > 2 && strings.ContainsRune(operation.Command[2:], 'a') {
+		return true
+	}
+	for i := 0; i < len(operation.Flags); i++ {
+		flag, value, hasValue := strings.Cut(operation.Flags[i], "=")
+		if valueFlags[flag] && !hasValue && i+1 < len(operation.Flags) {
+			i++
+			value = operation.Flags[i]
+		}
+		switch {
+		case flag == "--aur":
+			return true
+		case flag == "--mode":
+			if value == "aur" || value == "a" {
+				return true
+			}
+		case isShortCluster(flag) && strings.ContainsRune(flag[1:], 'a'):
+			return true
+		}
+	}
+	return false
+}
+
+// InstalledPackage is a package installed on the system, as pacman lists it.
+type InstalledPackage struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// ForeignPackages lists the installed packages that no sync database
+// provides (-Qm): AUR packages, and anything built or installed by hand.
+func (y *YayClient) ForeignPackages(ctx context.Context) ([]InstalledPackage, error) {
+	output, err := exec.CommandContext(ctx, y.yayPath, "-Qm").Output()
+	if err != nil {
+		// pacman exits 1 with no output when there is nothing to list
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(strings.TrimSpace(string(output))) == 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list foreign packages: %w", err)
+	}
+	return parseInstalledPackages(string(output)), nil
+}
+
+// parseInstalledPackages reads pacman -Q output: one "name version" per line.
+func parseInstalledPackages(output string) []InstalledPackage {
+	var packages []InstalledPackage
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		packages = append(packages, InstalledPackage{Name: fields[0], Version: fields[1]})
+	}
+	return packages
+}
+
 // ParseYayCommand parses a yay command into a YayOperation
 func ParseYayCommand(args []string) (*types.YayOperation, error) {
 	if len(args) == 0 {
@@ -185,27 +283,22 @@ func ParseYayCommand(args []string) (*types.YayOperation, error) {
 			Packages: []string{},
 		}
 
-		// Determine operation type
-		if strings.HasPrefix(operation.Command, "-S") {
-			operation.Operation = "install"
-		} else if strings.HasPrefix(operation.Command, "-R") {
-			operation.Operation = "remove"
-		} else if strings.HasPrefix(operation.Command, "-U") {
-			operation.Operation = "upgrade"
-		} else {
-			operation.Operation = "other"
-		}
-
-		// Separate flags from packages
+		// Separate flags from packages; an option such as --mflags keeps its
+		// value with it
 		for i := 1; i < len(args); i++ {
 			arg := args[i]
 			if strings.HasPrefix(arg, "-") {
 				operation.Flags = append(operation.Flags, arg)
+				if valueFlags[arg] && i+1 < len(args) {
+					i++
+					operation.Flags = append(operation.Flags, args[i])
+				}
 			} else {
 				operation.Packages = append(operation.Packages, arg)
 			}
 		}
 
+		operation.Operation = operationType(operation.Command, operation.Flags)
 		return operation, nil
 	} else {
 		// First arg is not a flag, assume it's just analysis (no install)
@@ -218,6 +311,118 @@ func ParseYayCommand(args []string) (*types.YayOperation, error) {
 	}
 }
 
+// syncQueryModifiers are the -S modifiers that only read the sync databases
+// or the package cache (search, info, clean, groups, list, print): with any
+// of them, -S installs nothing.
+var syncQueryModifiers = map[string]bool{
+	"s": true, "i": true, "c": true, "g": true, "l": true, "p": true,
+	"--search": true, "--info": true, "--clean": true, "--groups": true, "--list": true, "--print": true,
+}
+
+// syncModifierLetters are the letters a standalone short option after -S may
+// bundle: the query modifiers plus refresh, upgrade, download-only, quiet,
+// verbose and nodeps.
+const syncModifierLetters = "sicglpyuwqvd"
+
+// isSyncQueryCluster reports whether flag is a standalone short option, such
+// as -s or -yi, made only of -S modifier letters and naming at least one
+// query modifier. Anything else is not taken as a query, so an -S it follows
+// still installs and is analyzed.
+func isSyncQueryCluster(flag string) bool {
+	if !isShortCluster(flag) {
+		return false
+	}
+	letters := flag[1:]
+	for _, letter := range letters {
+		if !strings.ContainsRune(syncModifierLetters, letter) {
+			return false
+		}
+	}
+	return strings.ContainsAny(letters, "sicglp")
+}
+
+// Sysupgrade reports whether operation is an -S that upgrades every installed
+// package (-Syu, --sysupgrade), AUR packages included.
+func Sysupgrade(operation *types.YayOperation) bool {
+	command := operation.Command
+	if command != "--sync" && !strings.HasPrefix(command, "-S") {
+		return false
+	}
+	if !strings.HasPrefix(command, "--") && strings.ContainsRune(command[2:], 'u') {
+		return true
+	}
+	for i := 0; i < len(operation.Flags); i++ {
+		flag := operation.Flags[i]
+		if valueFlags[flag] {
+			i++ // skip its value
+			continue
+		}
+		if flag == "--sysupgrade" || (isShortCluster(flag) && strings.ContainsRune(flag[1:], 'u')) {
+			return true
+		}
+	}
+	return false
+}
+
+// isShortCluster reports whether flag is a standalone short option, one or
+// more letters after a single dash.
+func isShortCluster(flag string) bool {
+	return len(flag) >= 2 && flag[0] == '-' && flag[1] != '-'
+}
+
+// operationType classifies a command by its main operation: install (an -S
+// that installs), query (-Q, or -S with a query modifier such as -Ss or
+// -Si), remove (-R), upgrade (-U, local package files), files (-F),
+// database (-D), or other (yay's own -Y, -P, -G, ...).
+func operationType(command string, flags []string) string {
+	main, modifiers := command, ""
+	if !strings.HasPrefix(command, "--") && len(command) > 2 {
+		main, modifiers = command[:2], command[2:]
+	}
+
+	switch main {
+	case "-S", "--sync":
+		for _, letter := range modifiers {
+			if syncQueryModifiers[string(letter)] {
+				return "query"
+			}
+		}
+		for i := 0; i < len(flags); i++ {
+			flag := flags[i]
+			if valueFlags[flag] {
+				i++ // its value, such as --mflags "-A --noconfirm", is not a modifier
+				continue
+			}
+			if syncQueryModifiers[flag] || isSyncQueryCluster(flag) {
+				return "query"
+			}
+		}
+		return "install"
+	case "-Q", "--query":
+		return "query"
+	case "-R", "--remove":
+		return "remove"
+	case "-U", "--upgrade":
+		return "upgrade"
+	case "-F", "--files":
+		return "files"
+	case "-D", "--database":
+		return "database"
+	}
+	return "other"
+}
+
+// NeedsAnalysis reports whether an operation installs named packages from a
+// PKGBUILD, and so must be analyzed first. Everything else — queries,
+// database and file operations, removals, and -S modifiers that install
+// nothing — goes straight to yay without fetching a PKGBUILD.
+func NeedsAnalysis(operation *types.YayOperation) bool {
+	if len(operation.Packages) == 0 {
+		return false
+	}
+	return operation.Operation == "install" || operation.Operation == "analyze"
+}
+
 // extractPKGBUILDField extracts a field value from PKGBUILD content
 func extractPKGBUILDField(pkgbuild, field string) string {
 	re := regexp.MustCompile(fmt.Sprintf(`%s\s*=\s*['"]?([^'"'\n\r]*)['"]?`, field))
@@ -0,0 +1,267 @@
+package yay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseYayCommand(t *testing.T) {
+	tests := []struct {
+		args      []string
+		operation string
+		packages  []string
+		flags     []string
+	}{
+		{nil, "upgrade", nil, nil},
+		{[]string{"-S", "foo", "bar"}, "install", []string{"foo", "bar"}, nil},
+		{[]string{"-Syu", "--noconfirm", "foo"}, "install", []string{"foo"}, []string{"--noconfirm"}},
+		{[]string{"--sync", "foo"}, "install", []string{"foo"}, nil},
+		{[]string{"-Ss", "firefox"}, "query", []string{"firefox"}, nil},
+		{[]string{"-Si", "foo"}, "query", []string{"foo"}, nil},
+		{[]string{"-S", "--info", "foo"}, "query", []string{"foo"}, []string{"--info"}},
+		{[]string{"-S", "-s", "foo"}, "query", []string{"foo"}, []string{"-s"}},
+		{[]string{"-Scc"}, "query", nil, nil},
+		{[]string{"-Qi", "foo"}, "query", []string{"foo"}, nil},
+		{[]string{"-Rns", "foo"}, "remove", []string{"foo"}, nil},
+		{[]string{"-U", "foo.pkg.tar.zst"}, "upgrade", []string{"foo.pkg.tar.zst"}, nil},
+		{[]string{"-Fy"}, "files", nil, nil},
+		{[]string{"-D", "--asdeps", "foo"}, "database", []string{"foo"}, []string{"--asdeps"}},
+		{[]string{"-Yc"}, "other", nil, nil},
+		{[]string{"foo", "bar"}, "analyze", []string{"foo", "bar"}, nil},
+		{[]string{"-S", "--mflags", "--skipinteg --nocheck", "foo"}, "install", []string{"foo"}, []string{"--mflags", "--skipinteg --nocheck"}},
+		{[]string{"-S", "--overwrite", "*", "foo"}, "install", []string{"foo"}, []string{"--overwrite", "*"}},
+	}
+
+	for _, test := range tests {
+		operation, err := ParseYayCommand(test.args)
+		if err != nil {
+			t.Errorf("ParseYayCommand(%q): %v", test.args, err)
+			continue
+		}
+		if operation.Operation != test.operation {
+			t.Errorf("ParseYayCommand(%q).Operation = %q, expected %q", test.args, operation.Operation, test.operation)
+		}
+		if !slices.Equal(operation.Packages, test.packages) {
+			t.Errorf("ParseYayCommand(%q).Packages = %q, expected %q", test.args, operation.Packages, test.packages)
+		}
+		if !slices.Equal(operation.Flags, test.flags) {
+			t.Errorf("ParseYayCommand(%q).Flags = %q, expected %q", test.args, operation.Flags, test.flags)
+		}
+	}
+}
+
+func TestNeedsAnalysis(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"-S", "foo"}, true},
+		{[]string{"-Syu", "foo"}, true},
+		{[]string{"foo"}, true},
+		{[]string{"-Syu"}, false},
+		{[]string{"-Ss", "foo"}, false},
+		{[]string{"-Si", "foo"}, false},
+		{[]string{"-Sp", "foo"}, false},
+		{[]string{"-Q", "foo"}, false},
+		{[]string{"-F", "/usr/bin/foo"}, false},
+		{[]string{"-D", "--asexplicit", "foo"}, false},
+		{[]string{"-R", "foo"}, false},
+		{[]string{"-U", "foo.pkg.tar.zst"}, false},
+		{[]string{"-G", "foo"}, false},
+		{[]string{"-S", "--mflags", "-A --noconfirm", "foo"}, true},
+		{[]string{"-S", "--ignore", "-s", "foo"}, true},
+		{[]string{"-S", "-yi", "foo"}, false},
+		{[]string{"-S", "-x", "foo"}, true},
+	}
+
+	for _, test := range tests {
+		operation, err := ParseYayCommand(test.args)
+		if err != nil {
+			t.Errorf("ParseYayCommand(%q): %v", test.args, err)
+			continue
+		}
+		if result := NeedsAnalysis(operation); result != test.expected {
+			t.Errorf("NeedsAnalysis(%q) = %v, expected %v", test.args, result, test.expected)
+		}
+	}
+}
+
+func TestWeakenedChecks(t *testing.T) {
+	tests := []struct {
+		args      []string
+		operation string
+		weakened  []string
+	}{
+		{[]string{"-S", "foo"}, "install", nil},
+		{[]string{"-S", "--noconfirm", "--needed", "foo"}, "install", nil},
+		{[]string{"-S", "--mflags", "-A --noconfirm", "foo"}, "install", nil},
+		{[]string{"-S", "--mflags", "--skipinteg --nocheck", "foo"}, "install", []string{"--mflags --skipinteg", "--mflags --nocheck"}},
+		{[]string{"-S", "--mflags=--skippgpcheck", "foo"}, "install", []string{"--mflags --skippgpcheck"}},
+		{[]string{"-S", "--skipchecksums", "foo"}, "install", []string{"--skipchecksums"}},
+		{[]string{"-Sdd", "foo"}, "install", []string{"-Sdd"}},
+		{[]string{"-S", "--nodeps", "foo"}, "install", []string{"--nodeps"}},
+		{[]string{"-S", "--overwrite", "/usr/*", "foo"}, "install", []string{"--overwrite /usr/*"}},
+	}
+
+	for _, test := range tests {
+		operation, err := ParseYayCommand(test.args)
+		if err != nil {
+			t.Errorf("ParseYayCommand(%q): %v", test.args, err)
+			continue
+		}
+		if operation.Operation != test.operation {
+			t.Errorf("ParseYayCommand(%q).Operation = %q, expected %q", test.args, operation.Operation, test.operation)
+		}
+		var flags []string
+		for _, check := range WeakenedChecks(operation) {
+			flag, _, _ := strings.Cut(check, ": ")
+			flags = append(flags, flag)
+		}
+		if !slices.Equal(flags, test.weakened) {
+			t.Errorf("WeakenedChecks(%q) = %q, expected %q", test.args, flags, test.weakened)
+		}
+	}
+}
+
+func TestWeakenedMakeflags(t *testing.T) {
+	tests := []struct {
+		makeflags string
+		weakened  []string
+	}{
+		{"", nil},
+		{"-j8", nil},
+		{"-j 8 -s --no-print-directory", nil},
+		{"--jobs=4 -l2", nil},
+		{"--jobs 4", nil},
+		{"-j8 -i", []string{"MAKEFLAGS -i"}},
+		{"-e CFLAGS=-O0", []string{"MAKEFLAGS -e", "MAKEFLAGS CFLAGS=-O0"}},
+		{"k", []string{"MAKEFLAGS -k"}},
+	}
+	for _, test := range tests {
+		var flags []string
+		for _, check := range WeakenedMakeflags(test.makeflags) {
+			flag, _, _ := strings.Cut(check, ": ")
+			flags = append(flags, flag)
+		}
+		if !slices.Equal(flags, test.weakened) {
+			t.Errorf("WeakenedMakeflags(%q) = %q, expected %q", test.makeflags, flags, test.weakened)
+		}
+	}
+}
+
+func TestRepoPackage(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+[ "$1 $2" = '-Si --' ] || exit 2
+case "$3" in
+bash|core/bash) printf 'Repository      : core\nName            : bash\n' ;;
+yay|chaotic-aur/yay) printf 'Repository      : chaotic-aur\nName            : yay\n' ;;
+*) echo "error: package '$3' was not found" >&2; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "pacman"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	ctx := context.Background()
+	trusted := []string{"core", "extra", "multilib"}
+	for pkg, want := range map[string]bool{"bash": true, "core/bash": true, "aur/bash": false, "yay-bin": false, "yay": false, "chaotic-aur/yay": false} {
+		if _, got := RepoPackage(ctx, pkg, trusted); got != want {
+			t.Errorf("RepoPackage(%q) = %v, want %v", pkg, got, want)
+		}
+	}
+	if repo, ok := RepoPackage(ctx, "yay", append(trusted, "chaotic-aur")); !ok || repo != "chaotic-aur" {
+		t.Errorf("RepoPackage with chaotic-aur trusted = %q, %v", repo, ok)
+	}
+
+	// Without pacman every package is analyzed
+	t.Setenv("PATH", t.TempDir())
+	if _, ok := RepoPackage(ctx, "bash", trusted); ok {
+		t.Error("RepoPackage without pacman = true")
+	}
+}
+
+func TestForcesAUR(t *testing.T) {
+	tests := []struct {
+		args []string
+		aur  bool
+	}{
+		{[]string{"-S", "foo"}, false},
+		{[]string{"-S", "--aur", "foo"}, true},
+		{[]string{"-Sa", "foo"}, true},
+		{[]string{"-S", "-a", "foo"}, true},
+		{[]string{"-S", "--mode=a", "foo"}, true},
+		{[]string{"-S", "--mode", "aur", "foo"}, true},
+		{[]string{"-S", "--mode", "repo", "foo"}, false},
+		{[]string{"-S", "--mflags", "-a", "foo"}, false},
+	}
+
+	for _, test := range tests {
+		operation, err := ParseYayCommand(test.args)
+		if err != nil {
+			t.Errorf("ParseYayCommand(%q): %v", test.args, err)
+			continue
+		}
+		if got := ForcesAUR(operation); got != test.aur {
+			t.Errorf("ForcesAUR(%q) = %v, expected %v", test.args, got, test.aur)
+		}
+	}
+}
+
+func TestSysupgrade(t *testing.T) {
+	tests := []struct {
+		args    []string
+		upgrade bool
+	}{
+		{nil, true},
+		{[]string{"-Syu"}, true},
+		{[]string{"-Su", "foo"}, true},
+		{[]string{"-S", "--sysupgrade"}, true},
+		{[]string{"-S", "-yu"}, true},
+		{[]string{"-S", "foo"}, false},
+		{[]string{"-Sy", "foo"}, false},
+		{[]string{"-S", "--mflags", "-u", "foo"}, false},
+		{[]string{"-Qu"}, false},
+	}
+
+	for _, test := range tests {
+		operation, err := ParseYayCommand(test.args)
+		if err != nil {
+			t.Errorf("ParseYayCommand(%q): %v", test.args, err)
+			continue
+		}
+		if got := Sysupgrade(operation); got != test.upgrade {
+			t.Errorf("Sysupgrade(%q) = %v, expected %v", test.args, got, test.upgrade)
+		}
+	}
+}
+
+func TestForeignPackages(t *testing.T) {
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "yay")
+	script := "#!/bin/sh\n[ \"$1\" = -Qm ] || exit 2\nprintf 'google-chrome 126.0.6478.126-1\\nyay-bin 12.3.5-1\\n\\n'\n"
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	packages, err := NewYayClient(fake).ForeignPackages(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []InstalledPackage{{"google-chrome", "126.0.6478.126-1"}, {"yay-bin", "12.3.5-1"}}
+	if !slices.Equal(packages, want) {
+		t.Errorf("ForeignPackages() = %v, want %v", packages, want)
+	}
+
+	// pacman exits 1 without output when no foreign package is installed
+	if err := os.WriteFile(fake, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if packages, err := NewYayClient(fake).ForeignPackages(context.Background()); err != nil || len(packages) != 0 {
+		t.Errorf("ForeignPackages() with none installed = %v, %v; want none, no error", packages, err)
+	}
+}